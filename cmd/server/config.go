@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zcking/go-api-template/internal/config"
+)
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the server configuration",
+	}
+	cmd.AddCommand(newConfigPrintCommand())
+	return cmd
+}
+
+func newConfigPrintCommand() *cobra.Command {
+	var (
+		output      string
+		showSecrets bool
+	)
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the configuration serve would run with",
+		Long: `Print the configuration serve would run with, given the same configuration
+file, environment and flags, as YAML or as environment variable assignments.
+Secrets are redacted unless --show-secrets is set. The configuration is
+printed even if it is invalid; use serve --config-check to validate it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if !showSecrets {
+				cfg = cfg.Redacted()
+			}
+			w := cmd.OutOrStdout()
+			switch output {
+			case "yaml":
+				data, err := yaml.Marshal(cfg)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(data)
+				return err
+			case "env":
+				fmt.Fprintln(w, strings.Join(cfg.Environment(), "\n"))
+				return nil
+			default:
+				return fmt.Errorf("invalid output format %q: must be yaml or env", output)
+			}
+		},
+	}
+	config.RegisterFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format: yaml or env")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Print secrets instead of redacting them")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"yaml", "env"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
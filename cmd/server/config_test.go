@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigPrint(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("database:\n  host: file-host\n  name: file-db\n"), 0o600))
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		contains []string
+		excludes []string
+		wantErr  string
+	}{
+		{
+			name:     "defaults as yaml",
+			contains: []string{"grpc_addr: :8080\n", "job_workers: 4\n", "migrate_on_start: true\n"},
+		},
+		{
+			name:     "defaults as environment variables",
+			args:     []string{"-o", "env"},
+			contains: []string{"DB_HOST=localhost\n", "JOB_WORKERS=4\n", "MIGRATE_ON_START=true\n", "SCIM_BEARER_TOKEN=\n"},
+		},
+		{
+			name:     "file, environment and flags",
+			env:      map[string]string{"DB_HOST": "env-host", "JOB_WORKERS": "8"},
+			args:     []string{"--config", configFile, "--migrate-on-start=false", "-o", "env"},
+			contains: []string{"DB_HOST=env-host\n", "DB_NAME=file-db\n", "JOB_WORKERS=8\n", "MIGRATE_ON_START=false\n"},
+		},
+		{
+			name:     "config file from the environment",
+			env:      map[string]string{"CONFIG_FILE": configFile},
+			contains: []string{"host: file-host\n"},
+		},
+		{
+			name:     "secrets are redacted",
+			env:      map[string]string{"DB_PASSWORD": "hunter2", "SCIM_BEARER_TOKEN": "s3cret"},
+			contains: []string{"password: REDACTED\n", "scim_bearer_token: REDACTED\n"},
+			excludes: []string{"hunter2", "s3cret"},
+		},
+		{
+			name:     "secrets are shown on request",
+			env:      map[string]string{"DB_PASSWORD": "hunter2"},
+			args:     []string{"--show-secrets", "-o", "env"},
+			contains: []string{"DB_PASSWORD=hunter2\n"},
+		},
+		{
+			name:    "invalid output",
+			args:    []string{"-o", "json"},
+			wantErr: `invalid output format "json": must be yaml or env`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var out bytes.Buffer
+			root := newRootCommand()
+			root.SetOut(&out)
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(append([]string{"config", "print"}, tt.args...))
+
+			err := root.Execute()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, out.String(), s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, out.String(), s)
+			}
+		})
+	}
+}
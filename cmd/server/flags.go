@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zcking/go-api-template/internal/config"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/secrets"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+// configPath returns the configuration file named by --config or
+// CONFIG_FILE, or "" if there is none
+func configPath(cmd *cobra.Command) (string, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	return path, nil
+}
+
+// loadConfig loads the configuration from the file named by --config or
+// CONFIG_FILE, the environment and the flags of cmd
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	path, err := configPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(path, cmd.Flags())
+}
+
+// loadDatabaseConfig loads the configuration of a command that only connects
+// to the database, validating only the database settings. The password is
+// read from its file or command, once.
+func loadDatabaseConfig(cmd *cobra.Command) (*config.Config, *secrets.Secret, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.ValidateDatabase(); err != nil {
+		return nil, nil, err
+	}
+	password, err := secrets.Load(cmd.Context(), "database.password", cfg.DatabasePassword(), slog.Default())
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, password, nil
+}
+
+// usersConfig converts the database settings to the users service's
+// connection settings, with the password from credentials
+func usersConfig(d config.DatabaseConfig, credentials *secrets.Secret) users.Config {
+	return users.Config{
+		DSN:         d.DSN(),
+		Pool:        d.Pool(),
+		Credentials: credentials,
+	}
+}
+
+// loadKeyring loads the keyring that encrypts user PII from the file named
+// by auth, refusing a development keyring unless auth allows it
+func loadKeyring(auth config.AuthConfig) (*encryption.Keyring, error) {
+	path := auth.KeyringFile
+	if path == "" {
+		return nil, errors.New("a keyring file is required to encrypt user PII; set --keyring-file or KEYRING_FILE")
+	}
+	keyring, err := encryption.LoadKeyring(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keyring %s: %w", path, err)
+	}
+	if err := refuseDevKeyring(keyring, auth); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// refuseDevKeyring returns an error if keyring is a development keyring,
+// whose keys are public, and auth does not allow one
+func refuseDevKeyring(keyring *encryption.Keyring, auth config.AuthConfig) error {
+	if keyring.Dev() && !auth.AllowDevKeyring {
+		return fmt.Errorf("keyring %s is a development keyring; set --allow-dev-keyring or ALLOW_DEV_KEYRING to use it", auth.KeyringFile)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zcking/go-api-template/internal/config"
+	"github.com/zcking/go-api-template/internal/database"
+	"github.com/zcking/go-api-template/internal/schema"
+)
+
+// migrateCommands are the subcommands of migrate and their arguments
+var migrateCommands = []struct {
+	use   string
+	short string
+	args  int
+}{
+	{"up", "Apply all pending migrations", 0},
+	{"down N", "Roll back the last N migrations", 1},
+	{"goto V", "Migrate up or down to version V", 1},
+	{"force V", "Mark version V as applied and clear the dirty flag, without running migrations (-1 marks no migration applied)", 1},
+	{"version", "Print the applied version", 0},
+	{"status", "List migrations and whether each is applied", 0},
+}
+
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back database migrations",
+		Long: `Apply or roll back database migrations.
+
+Every command that changes the schema holds the same advisory lock as startup
+migrations, so it never runs concurrently with a starting replica.`,
+	}
+	config.RegisterFlags(cmd.PersistentFlags(), "database", "server.migrations_dir")
+
+	for _, c := range migrateCommands {
+		cmd.AddCommand(&cobra.Command{
+			Use:   c.use,
+			Short: c.short,
+			Args:  cobra.ExactArgs(c.args),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, password, err := loadDatabaseConfig(cmd)
+				if err != nil {
+					return err
+				}
+				logger := newLogger(os.Stderr, cfg.Server.Level())
+				src, err := schema.Source(cfg.Server.MigrationsDir)
+				if err != nil {
+					return err
+				}
+				connector, err := database.NewConnector(cfg.Database.DSN(), password)
+				if err != nil {
+					return err
+				}
+				migrator, err := schema.NewMigrator(src, connector, logger)
+				if err != nil {
+					return err
+				}
+				defer migrator.Close()
+				return migrator.Run(cmd.Context(), append([]string{cmd.Name()}, args...), cmd.OutOrStdout())
+			},
+		})
+	}
+	return cmd
+}
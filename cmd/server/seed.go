@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/config"
+	"github.com/zcking/go-api-template/internal/groups"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+// seedUsers are the users created by seed, all members of seedGroup
+var seedUsers = []*userspb.CreateUserRequest{
+	{Name: "Ada Lovelace", Email: "ada@example.com"},
+	{Name: "Grace Hopper", Email: "grace@example.com"},
+	{Name: "Alan Turing", Email: "alan@example.com"},
+}
+
+// seedGroup is the group created by seed
+var seedGroup = &groupspb.CreateGroupRequest{Name: "engineering", Description: "Sample group created by seed"}
+
+func newSeedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill an empty database with sample users and groups",
+		Long: `Fill an empty database with sample users and a group they are members of,
+for local development. A database that already has users is left untouched.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg, password, err := loadDatabaseConfig(cmd)
+			if err != nil {
+				return err
+			}
+			logger := newLogger(os.Stderr, cfg.Server.Level())
+			keyring, err := loadKeyring(cfg.Auth)
+			if err != nil {
+				return err
+			}
+			usersService, err := users.NewService(usersConfig(cfg.Database, password), keyring, logger)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := usersService.Close(); err != nil {
+					slog.Warn("failed to close database connection", "error", err)
+				}
+			}()
+			groupsService := groups.NewService(usersService.DB(), logger)
+
+			var seeded bool
+			if err := usersService.DB().QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users)").Scan(&seeded); err != nil {
+				return err
+			}
+			if seeded {
+				fmt.Fprintln(cmd.OutOrStdout(), "the database already has users; nothing to seed")
+				return nil
+			}
+
+			group, err := groupsService.CreateGroup(ctx, seedGroup)
+			if err != nil {
+				return fmt.Errorf("failed to create group %q: %w", seedGroup.GetName(), err)
+			}
+			for _, req := range seedUsers {
+				resp, err := usersService.CreateUser(ctx, req)
+				if err != nil {
+					return fmt.Errorf("failed to create user %s: %w", req.GetEmail(), err)
+				}
+				_, err = groupsService.AddMember(ctx, &groupspb.AddMemberRequest{
+					GroupId: group.GetGroup().GetId(),
+					Member:  &groupspb.Member{Member: &groupspb.Member_UserId{UserId: resp.GetUser().GetId()}},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to add user %s to group %q: %w", req.GetEmail(), seedGroup.GetName(), err)
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "created %d users in group %q\n", len(seedUsers), seedGroup.GetName())
+			return nil
+		},
+	}
+	config.RegisterFlags(cmd.Flags(), "database", "auth.keyring_file", "auth.allow_dev_keyring")
+	return cmd
+}
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	grpc_logging "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	gatewayruntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	auditpb "github.com/zcking/go-api-template/gen/go/audit/v1"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/config"
+	"github.com/zcking/go-api-template/internal/cors"
+	"github.com/zcking/go-api-template/internal/database"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/groups"
+	"github.com/zcking/go-api-template/internal/health"
+	"github.com/zcking/go-api-template/internal/invitations"
+	"github.com/zcking/go-api-template/internal/jobs"
+	"github.com/zcking/go-api-template/internal/oidc"
+	"github.com/zcking/go-api-template/internal/operations"
+	"github.com/zcking/go-api-template/internal/permissions"
+	"github.com/zcking/go-api-template/internal/privacy"
+	"github.com/zcking/go-api-template/internal/ratelimit"
+	"github.com/zcking/go-api-template/internal/scheduler"
+	"github.com/zcking/go-api-template/internal/schema"
+	"github.com/zcking/go-api-template/internal/scim"
+	"github.com/zcking/go-api-template/internal/secrets"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+func newServeCommand() *cobra.Command {
+	var check bool
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the gRPC server and the REST gateway",
+		Long: `Run the gRPC server and the REST gateway.
+
+The server waits for the database, retrying with backoff for up to
+--db-startup-timeout, and reports not ready on /readyz meanwhile. Pending
+migrations are then applied unless --migrate-on-start=false, in which case the
+server refuses to start until the schema is current. With
+--config-check, the configuration, secrets, keyring and permission namespaces
+are validated and serve exits without starting.
+
+The log level, rate limits, CORS origins and feature flags are reloaded when
+the configuration file changes or the server receives SIGHUP. Changes to other
+settings are logged and ignored until the next restart. Secrets read from files
+or commands are read again every --secret-refresh-interval.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if check {
+				if err := checkConfig(cmd.Context(), cfg); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+				return nil
+			}
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			path, err := configPath(cmd)
+			if err != nil {
+				return err
+			}
+			serve(cfg, path, cmd.Flags())
+			return nil
+		},
+	}
+	config.RegisterFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&check, "config-check", false, "Validate the configuration and the files it names, then exit")
+	return cmd
+}
+
+// checkConfig validates cfg, reads its secrets and loads the files it names,
+// reporting every problem at once
+func checkConfig(ctx context.Context, cfg *config.Config) error {
+	var errs []error
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := cfg.DatabasePassword().Secret(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("database.password: %w", err))
+	}
+	if cfg.Auth.SCIMEnabled() {
+		if _, err := cfg.SCIMBearerToken().Secret(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("auth.scim_bearer_token: %w", err))
+		}
+	}
+	if cfg.Auth.KeyringFile != "" {
+		keyring, err := encryption.LoadKeyring(cfg.Auth.KeyringFile)
+		if err == nil {
+			err = refuseDevKeyring(keyring, cfg.Auth)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("auth.keyring_file: %w", err))
+		}
+	}
+	if cfg.Server.PermissionsConfig != "" {
+		if _, err := permissions.LoadNamespaceConfig(cfg.Server.PermissionsConfig); err != nil {
+			errs = append(errs, fmt.Errorf("server.permissions_config: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// serve runs the gRPC server and the REST gateway until it receives SIGINT
+// or SIGTERM, reloading cfg from path, the environment and flags on SIGHUP
+// or when the file changes
+func serve(cfg *config.Config, path string, flags *pflag.FlagSet) {
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.Server.Level())
+	logger := newLogger(os.Stdout, logLevel)
+
+	// Initialize OpenTelemetry (optional - will use no-op if OTLP endpoint is not configured)
+	ctx := context.Background()
+	otelConfig := internal.OTelConfig{
+		ServiceName:     cfg.OTel.ServiceName,
+		ShutdownTimeout: cfg.OTel.ShutdownTimeout,
+	}
+	tp, err := internal.InitOTel(ctx, otelConfig)
+	if err != nil {
+		slog.Error("failed to initialize OpenTelemetry traces", "error", err)
+		os.Exit(1)
+	}
+	// Only defer shutdown if TracerProvider was created (tp != nil)
+	if tp != nil {
+		defer func() {
+			if err := internal.ShutdownOTel(ctx, tp, otelConfig.ShutdownTimeout); err != nil {
+				slog.Error("failed to shutdown OpenTelemetry traces", "error", err)
+			}
+		}()
+	}
+
+	// Initialize OpenTelemetry metrics (optional - will use no-op if OTLP endpoint is not configured)
+	mp, err := internal.InitOTelMetrics(ctx, otelConfig)
+	if err != nil {
+		slog.Error("failed to initialize OpenTelemetry metrics", "error", err)
+		os.Exit(1)
+	}
+	// Only defer shutdown if MeterProvider was created (mp != nil)
+	if mp != nil {
+		defer func() {
+			if err := internal.ShutdownOTelMetrics(ctx, mp, otelConfig.ShutdownTimeout); err != nil {
+				slog.Error("failed to shutdown OpenTelemetry metrics", "error", err)
+			}
+		}()
+
+		// Start runtime metrics collection (goroutines, memory, GC stats, CPU usage)
+		// This will automatically export metrics via the MeterProvider
+		if err := runtime.Start(runtime.WithMinimumReadMemStatsInterval(time.Second)); err != nil {
+			slog.Warn("failed to start runtime metrics collection", "error", err)
+		}
+	}
+
+	// Apply reloadable settings while running
+	reloader, err := config.NewReloader(path, flags, cfg, logger)
+	if err != nil {
+		slog.Error("failed to create configuration reloader", "error", err)
+		os.Exit(1)
+	}
+	limiter := ratelimit.NewLimiter(cfg.Limits.RequestsPerSecond, cfg.Limits.RequestBurst)
+	reloader.OnReload(func(c *config.Config) {
+		logLevel.Set(c.Server.Level())
+		limiter.SetLimit(c.Limits.RequestsPerSecond, c.Limits.RequestBurst)
+	})
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go func() {
+		if err := reloader.Watch(watchCtx); err != nil {
+			slog.Warn("configuration will not be reloaded", "error", err)
+		}
+	}()
+
+	// Read the database password, and keep it current as it is rotated
+	dbPassword, err := secrets.Load(ctx, "database.password", cfg.DatabasePassword(), logger)
+	if err != nil {
+		slog.Error("failed to read database password", "error", err)
+		os.Exit(1)
+	}
+	dbConfig := usersConfig(cfg.Database, dbPassword)
+	dbConfig.Replicas = cfg.Database.ReplicaURLs
+	dbConfig.ReplicaCheckInterval = cfg.Database.ReplicaCheckInterval
+
+	// Serve liveness and readiness from the start, so the server reports
+	// not ready, rather than exiting, while it waits for the database. The
+	// API is registered on httpMux once the server has started.
+	checker := health.NewChecker(logger)
+	httpMux := http.NewServeMux()
+	rootMux := http.NewServeMux()
+	checker.Register(rootMux)
+	rootMux.Handle("/", otelhttp.NewHandler(checker.Gate(httpMux), "grpc-gateway",
+		otelhttp.WithMessageEvents(otelhttp.ReadEvents, otelhttp.WriteEvents),
+	))
+	gwServer := &http.Server{
+		Addr:    cfg.Server.HTTPAddr,
+		Handler: rootMux,
+	}
+	httpLis, err := net.Listen("tcp", cfg.Server.HTTPAddr)
+	if err != nil {
+		slog.Error("failed to create (HTTP) listener", "error", err)
+		os.Exit(1)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- gwServer.Serve(httpLis)
+	}()
+	slog.Info("gRPC Gateway listening", "address", cfg.Server.HTTPAddr)
+
+	// Wait for the database and migrate (or check) the schema before
+	// serving, giving up after the startup timeout or on SIGINT or SIGTERM
+	signalCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	startCtx, cancelStartup := context.WithTimeout(signalCtx, cfg.Database.StartupTimeout)
+	defer cancelStartup()
+	connector, err := database.NewConnector(dbConfig.DSN, dbPassword)
+	if err != nil {
+		slog.Error("invalid database connection string", "error", err)
+		os.Exit(1)
+	}
+	if err := prepareDatabase(startCtx, cfg, connector, logger); err != nil {
+		if signalCtx.Err() != nil {
+			slog.Info("received signal while waiting for the database, exiting")
+			return
+		}
+		slog.Error("failed to prepare the database", "error", err)
+		os.Exit(1)
+	}
+
+	// Load the permission namespace configuration
+	namespaces, err := permissions.LoadNamespaceConfig(cfg.Server.PermissionsConfig)
+	if err != nil {
+		slog.Error("failed to load permission namespace configuration", "error", err, "path", cfg.Server.PermissionsConfig)
+		os.Exit(1)
+	}
+
+	// Load the keys that encrypt user PII
+	keyring, err := loadKeyring(cfg.Auth)
+	if err != nil {
+		slog.Error("failed to load keyring", "error", err)
+		os.Exit(1)
+	}
+
+	// Create a TCP listener for the gRPC server
+	lis, err := net.Listen("tcp", cfg.Server.GRPCAddr)
+	if err != nil {
+		slog.Error("failed to create (gRPC) listener", "error", err)
+		os.Exit(1)
+	}
+
+	impl, err := users.NewService(dbConfig, keyring, logger)
+	if err != nil {
+		slog.Error("failed to create users service instance", "error", err)
+		os.Exit(1)
+	}
+	go dbPassword.Watch(watchCtx, cfg.Secrets.RefreshInterval)
+	auditService := audit.NewService(impl.DB(), logger)
+	jobQueue, err := jobs.NewQueue(impl.DB(), jobs.Config{Workers: cfg.Limits.JobWorkers}, logger)
+	if err != nil {
+		slog.Error("failed to create job queue", "error", err)
+		os.Exit(1)
+	}
+
+	// Create a gRPC server and attach our implementation
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			grpc_logging.UnaryServerInterceptor(interceptorLogger(logger)),
+			limiter.UnaryServerInterceptor(),
+			// Outside the audit interceptor, so the session LSN follows the
+			// audit event too
+			impl.Router().UnaryServerInterceptor(),
+			auditService.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			grpc_logging.StreamServerInterceptor(interceptorLogger(logger)),
+			limiter.StreamServerInterceptor(),
+		),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	}
+	grpcServer := grpc.NewServer(opts...)
+	userspb.RegisterUserServiceServer(grpcServer, impl)
+	groupsService := groups.NewService(impl.DB(), logger)
+	groupspb.RegisterGroupServiceServer(grpcServer, groupsService)
+	permissionspb.RegisterPermissionServiceServer(grpcServer, permissions.NewService(impl.DB(), namespaces, logger))
+	invitationsConfig := invitations.Config{AcceptURL: cfg.Server.InvitationAcceptURL}
+	invitationspb.RegisterInvitationServiceServer(grpcServer, invitations.NewService(impl.DB(), invitationsConfig, impl, groupsService, logger))
+	auditpb.RegisterAuditServiceServer(grpcServer, auditService)
+	operationsService := operations.NewService(impl.DB(), cfg.Limits.OperationWorkers, logger)
+	longrunningpb.RegisterOperationsServer(grpcServer, operationsService)
+	// Operations left running by instances that stopped will never finish
+	if err := failAbandonedOperations(operationsService)(context.Background()); err != nil {
+		slog.Error("failed to fail abandoned operations", "error", err)
+	}
+	privacyService := privacy.NewService(impl.DB(), operationsService, impl, logger)
+	privacypb.RegisterPrivacyServiceServer(grpcServer, privacyService)
+
+	// Serve the gRPC server, in a separate goroutine to avoid blocking
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			slog.Error("gRPC server failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Now setup the gRPC Gateway, a REST proxy to the gRPC server
+	conn, err := grpc.NewClient(
+		dialAddr(lis.Addr()),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		slog.Error("failed to create gRPC client", "error", err)
+		os.Exit(1)
+	}
+
+	// Forward the authenticated user set by the proxy in front of the gateway,
+	// so mutating calls are attributed in the audit log, and the session LSN
+	// token both ways, as the X-Db-Lsn header
+	mux := gatewayruntime.NewServeMux(
+		gatewayruntime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			switch http.CanonicalHeaderKey(key) {
+			case http.CanonicalHeaderKey(cfg.Auth.ActorHeader):
+				return audit.ActorMetadataKey, true
+			case http.CanonicalHeaderKey(database.LSNMetadataKey):
+				return database.LSNMetadataKey, true
+			}
+			return gatewayruntime.DefaultHeaderMatcher(key)
+		}),
+		gatewayruntime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
+			if key == database.LSNMetadataKey {
+				return http.CanonicalHeaderKey(key), true
+			}
+			// The default: other metadata is prefixed with Grpc-Metadata-
+			return gatewayruntime.MetadataHeaderPrefix + key, true
+		}),
+	)
+	err = userspb.RegisterUserServiceHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+	err = groupspb.RegisterGroupServiceHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+	err = permissionspb.RegisterPermissionServiceHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+	err = invitationspb.RegisterInvitationServiceHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+	err = auditpb.RegisterAuditServiceHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+	err = privacypb.RegisterPrivacyServiceHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+	err = operations.RegisterGatewayHandler(context.Background(), mux, conn)
+	if err != nil {
+		slog.Error("failed to register gRPC gateway", "error", err)
+		os.Exit(1)
+	}
+
+	// Background job handlers, registered before the workers start
+	jobs.Register(jobQueue, privacy.PurgeExpiredExportsJob, purgeExpiredExports(privacyService))
+	jobQueue.Start()
+
+	// Periodic tasks run once per schedule across all instances
+	taskScheduler := scheduler.NewScheduler(impl.DB(), logger)
+	registerTask(taskScheduler, "privacy.purge_expired_exports", "@hourly", enqueueJob(jobQueue, impl.DB(), privacy.PurgeExpiredExportsJob))
+	registerTask(taskScheduler, "users.reencrypt", "* * * * *", reencryptUsers(impl))
+	registerTask(taskScheduler, "operations.fail_abandoned", "@every 1m", failAbandonedOperations(operationsService))
+
+	// Serve the gateway, and the SCIM API next to it when a token is configured
+	httpMux.Handle("/", cors.Handler(mux, func() []string { return reloader.Current().Server.CORSOrigins }))
+	if cfg.Auth.SCIMEnabled() {
+		scimToken, err := secrets.Load(ctx, "auth.scim_bearer_token", cfg.SCIMBearerToken(), logger)
+		if err != nil {
+			slog.Error("failed to read SCIM bearer token", "error", err)
+			os.Exit(1)
+		}
+		go scimToken.Watch(watchCtx, cfg.Secrets.RefreshInterval)
+		scimConfig := scim.Config{TokenSource: scimToken.Value, BaseURL: cfg.Auth.SCIMBaseURL, Audit: auditService}
+		httpMux.Handle(scim.Prefix+"/", scim.NewHandler(impl.DB(), impl, groupsService, scimConfig, logger))
+	}
+	if cfg.Auth.OIDCIssuer != "" {
+		authenticator := oidc.HeaderAuthenticator{Header: cfg.Auth.OIDCUserHeader, LoginURL: cfg.Auth.OIDCLoginURL}
+		provider, err := oidc.NewProvider(impl.DB(), impl, authenticator, oidc.Config{Issuer: cfg.Auth.OIDCIssuer}, logger)
+		if err != nil {
+			slog.Error("failed to create OpenID Connect provider", "error", err)
+			os.Exit(1)
+		}
+		provider.Register(httpMux)
+		registerTask(taskScheduler, "oidc.purge_expired_codes", "@hourly", purgeExpiredCodes(provider))
+	}
+	taskScheduler.Start()
+
+	// Ready: the database is reachable and the API is registered
+	checker.AddCheck("database", impl.Ping)
+	checker.Started()
+	slog.Info("server started")
+
+	// Catch interrupt signal to gracefully shutdown the server
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	stopSignals()
+
+	steps := &drain{
+		gateway:         gwServer,
+		grpc:            grpcServer,
+		operations:      operationsService,
+		scheduler:       taskScheduler,
+		jobs:            jobQueue,
+		close:           impl.Close,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+		drainTimeout:    cfg.Server.DrainTimeout,
+	}
+	err = awaitShutdown(signalChan, serveErr, func() {
+		stopWatching()
+
+		// Drain running work and close the database connection
+		if err := steps.run(); err != nil {
+			slog.Error("failed to properly close users service", "error", err)
+			os.Exit(1)
+		}
+
+		// Shutdown OpenTelemetry traces
+		if err := internal.ShutdownOTel(context.Background(), tp, otelConfig.ShutdownTimeout); err != nil {
+			slog.Error("failed to shutdown OpenTelemetry traces", "error", err)
+		}
+
+		// Shutdown OpenTelemetry metrics
+		if mp != nil {
+			if err := internal.ShutdownOTelMetrics(context.Background(), mp, otelConfig.ShutdownTimeout); err != nil {
+				slog.Error("failed to shutdown OpenTelemetry metrics", "error", err)
+			}
+		}
+	})
+	if err != nil {
+		slog.Error("HTTP server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// prepareDatabase waits for the database to be reachable, then applies
+// pending migrations, or checks there are none if they do not run on
+// start. Both steps are retried with backoff while the database is
+// unreachable, until ctx is done.
+func prepareDatabase(ctx context.Context, cfg *config.Config, connector *database.Connector, logger *slog.Logger) error {
+	backoff := cfg.Database.StartupBackoff()
+	if err := database.Retry(ctx, "connect", backoff, logger, connector.Ping); err != nil {
+		return err
+	}
+	return database.Retry(ctx, "migrate", backoff, logger, func(ctx context.Context) error {
+		src, err := schema.Source(cfg.Server.MigrationsDir)
+		if err != nil {
+			return fmt.Errorf("failed to open migrations in %q: %w", cfg.Server.MigrationsDir, err)
+		}
+		migrator, err := schema.NewMigrator(src, connector, logger)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := migrator.Close(); err != nil {
+				slog.Warn("failed to close migrator", "error", err)
+			}
+		}()
+		if cfg.Server.MigrateOnStart {
+			return migrator.Up(ctx)
+		}
+		return migrator.CheckCurrent()
+	})
+}
+
+// dialAddr returns the address the gateway dials to reach the gRPC server
+// listening on addr, replacing an unspecified host with localhost
+func dialAddr(addr net.Addr) string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok || !tcp.IP.IsUnspecified() {
+		return addr.String()
+	}
+	return net.JoinHostPort("localhost", strconv.Itoa(tcp.Port))
+}
+
+// registerTask schedules a periodic task, exiting if its schedule is invalid
+func registerTask(s *scheduler.Scheduler, name, spec string, run func(ctx context.Context) error) {
+	if err := s.Register(name, spec, run); err != nil {
+		slog.Error("failed to register scheduled task", "error", err)
+		os.Exit(1)
+	}
+}
+
+// purgeExpiredCodes deletes OpenID Connect authorization codes that expired
+// without being exchanged
+func purgeExpiredCodes(provider *oidc.Provider) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := provider.PurgeExpiredCodes(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("purged expired authorization codes", "count", n)
+		return nil
+	}
+}
+
+// enqueueJob enqueues a job of kind, which takes no arguments, unless one is
+// already queued, so a periodic task hands its work to the job queue and
+// gets its retries
+func enqueueJob(queue *jobs.Queue, db *sql.DB, kind jobs.Kind[struct{}]) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := jobs.Enqueue(ctx, queue, db, kind, struct{}{}, jobs.EnqueueOptions{UniqueKey: string(kind)})
+		if errors.Is(err, jobs.ErrDuplicate) {
+			return nil
+		}
+		return err
+	}
+}
+
+// purgeExpiredExports deletes user data exports past their download window
+func purgeExpiredExports(service *privacy.Service) func(ctx context.Context, _ struct{}) error {
+	return func(ctx context.Context, _ struct{}) error {
+		n, err := service.PurgeExpiredExports(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("purged expired user data exports", "count", n)
+		return nil
+	}
+}
+
+// failAbandonedOperations fails operations whose instance stopped before
+// finishing them
+func failAbandonedOperations(service *operations.Service) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := service.FailAbandoned(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			slog.Warn("failed abandoned operations", "count", n)
+		}
+		return nil
+	}
+}
+
+// reencryptUsers encrypts legacy plaintext users and rewraps data keys under
+// the keyring's primary key, in batches until none remain
+func reencryptUsers(service *users.Service) func(ctx context.Context) error {
+	const batchSize = 500
+	return func(ctx context.Context) error {
+		total := 0
+		defer func() {
+			if total > 0 {
+				slog.Info("re-encrypted users", "count", total)
+			}
+		}()
+		var after int64
+		for {
+			n, last, err := service.ReencryptUsers(ctx, after, batchSize)
+			if err != nil {
+				return err
+			}
+			total += n
+			if last == after {
+				return nil
+			}
+			after = last
+		}
+	}
+}
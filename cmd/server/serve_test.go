@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyring writes a keyring file, marked as a development keyring if
+// dev, and returns its path. The Docker build runs the tests without the
+// secrets directory, so the checked-in development keyring is not used.
+func writeKeyring(t *testing.T, dev bool) string {
+	t.Helper()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	content := fmt.Sprintf(`{"primary": "1", "keys": {"1": %q}, "blind_index_key": %q, "dev": %t}`, key, key, dev)
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestServe_ConfigCheck(t *testing.T) {
+	keyring, devKeyring := writeKeyring(t, false), writeKeyring(t, true)
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantErrs []string
+	}{
+		{
+			name:    "valid",
+			args:    []string{"--keyring-file", keyring, "--permissions-config", "../../config/permissions.yaml"},
+			wantOut: "configuration is valid\n",
+		},
+		{
+			name:    "development keyring allowed",
+			args:    []string{"--keyring-file", devKeyring, "--allow-dev-keyring", "--permissions-config", "../../config/permissions.yaml"},
+			wantOut: "configuration is valid\n",
+		},
+		{
+			name: "every problem is reported",
+			args: []string{"--grpc-addr", "8080", "--job-workers", "0", "--keyring-file", "missing.json", "--permissions-config", "missing.yaml"},
+			wantErrs: []string{
+				`server.grpc_addr: must be host:port or :port, got "8080"`,
+				"limits.job_workers: must be at least 1, got 0",
+				"auth.keyring_file: failed to read keyring",
+				"server.permissions_config: failed to read namespace config",
+			},
+		},
+		{
+			name:     "development keyring",
+			args:     []string{"--keyring-file", devKeyring, "--permissions-config", "../../config/permissions.yaml"},
+			wantErrs: []string{"auth.keyring_file: keyring " + devKeyring + " is a development keyring"},
+		},
+		{
+			name: "unreadable secrets",
+			args: []string{
+				"--keyring-file", keyring, "--permissions-config", "../../config/permissions.yaml",
+				"--db-password-file", "missing-password", "--scim-bearer-token-command", "exit 1",
+			},
+			wantErrs: []string{
+				"database.password: failed to read secret file",
+				"auth.scim_bearer_token: secret command failed: exit status 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			root := newRootCommand()
+			root.SetOut(&out)
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(append([]string{"serve", "--config-check"}, tt.args...))
+
+			err := root.Execute()
+			if len(tt.wantErrs) == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantOut, out.String())
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErrs {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestDialAddr(t *testing.T) {
+	tests := []struct {
+		addr net.Addr
+		want string
+	}{
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 8080}, "localhost:8080"},
+		{&net.TCPAddr{IP: net.IPv4zero, Port: 8080}, "localhost:8080"},
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9090}, "127.0.0.1:9090"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, dialAddr(tt.addr))
+	}
+}
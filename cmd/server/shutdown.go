@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/zcking/go-api-template/internal/jobs"
+	"github.com/zcking/go-api-template/internal/operations"
+	"github.com/zcking/go-api-template/internal/scheduler"
+)
+
+// drain is what serve stops on shutdown, in order
+type drain struct {
+	gateway    *http.Server
+	grpc       *grpc.Server
+	operations *operations.Service
+	scheduler  *scheduler.Scheduler
+	jobs       *jobs.Queue
+	// close releases the database once nothing uses it
+	close func() error
+	// shutdownTimeout bounds closing the gateway's connections
+	shutdownTimeout time.Duration
+	// drainTimeout bounds waiting for operations, tasks and jobs
+	drainTimeout time.Duration
+}
+
+// run stops accepting requests, lets running operations, tasks and jobs
+// finish, then closes the database. Work still running after drainTimeout
+// is canceled, and retried elsewhere where it can be.
+func (d *drain) run() error {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), d.shutdownTimeout)
+	defer cancel()
+	if err := d.gateway.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shutdown HTTP server", "error", err)
+	}
+	d.grpc.GracefulStop()
+
+	// Let running operations, jobs and tasks finish before the database goes away
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), d.drainTimeout)
+	defer cancelDrain()
+	if err := d.operations.Wait(drainCtx); err != nil {
+		slog.Error("failed to drain operations; unfinished operations were canceled", "error", err)
+	}
+	if err := d.scheduler.Stop(drainCtx); err != nil {
+		slog.Error("failed to stop scheduled tasks cleanly", "error", err)
+	}
+	if err := d.jobs.Shutdown(drainCtx); err != nil {
+		slog.Error("failed to drain job queue; unfinished jobs will be retried", "error", err)
+	}
+	return d.close()
+}
+
+// awaitShutdown waits for a signal, then runs shutdown and returns once it
+// is done. The gateway stops serving as soon as its shutdown starts, so
+// returning when serveErr yields would exit in the middle of the drain. An
+// error serving the gateway is returned right away.
+func awaitShutdown(signals <-chan os.Signal, serveErr <-chan error, shutdown func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sig := <-signals
+		slog.Info("received signal, shutting down servers", "signal", sig.String())
+		shutdown()
+	}()
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-done
+	return nil
+}
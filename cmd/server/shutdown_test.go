@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zcking/go-api-template/internal/jobs"
+	"github.com/zcking/go-api-template/internal/operations"
+	"github.com/zcking/go-api-template/internal/scheduler"
+)
+
+func TestAwaitShutdown(t *testing.T) {
+	t.Run("a running job finishes before exit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		queue, err := jobs.NewQueue(db, jobs.Config{Workers: 1, PollInterval: time.Hour}, logger)
+		require.NoError(t, err)
+		started, finished := make(chan struct{}), make(chan struct{})
+		jobs.Register(queue, jobs.Kind[struct{}]("test.slow"), func(ctx context.Context, _ struct{}) error {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			close(finished)
+			return nil
+		})
+		mock.ExpectQuery(regexp.QuoteMeta("UPDATE jobs SET state = 'running'")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "payload", "attempts", "max_attempts", "run_at"}).
+				AddRow(1, "test.slow", []byte(`{}`), 1, 3, time.Now()))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM jobs WHERE id = $1 AND attempts = $2")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		queue.Start()
+		<-started
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		gateway := &http.Server{Handler: http.NotFoundHandler()}
+		serveErr := make(chan error, 1)
+		go func() { serveErr <- gateway.Serve(lis) }()
+
+		closed := false
+		steps := &drain{
+			gateway:    gateway,
+			grpc:       grpc.NewServer(),
+			operations: operations.NewService(db, 1, logger),
+			scheduler:  scheduler.NewScheduler(db, logger),
+			jobs:       queue,
+			close: func() error {
+				select {
+				case <-finished:
+				default:
+					t.Error("database closed while a job was running")
+				}
+				closed = true
+				return nil
+			},
+			shutdownTimeout: time.Second,
+			drainTimeout:    time.Second,
+		}
+
+		signals := make(chan os.Signal, 1)
+		signals <- syscall.SIGTERM
+		require.NoError(t, awaitShutdown(signals, serveErr, func() {
+			assert.NoError(t, steps.run())
+		}))
+
+		select {
+		case <-finished:
+		default:
+			t.Fatal("returned before the running job finished")
+		}
+		assert.True(t, closed, "returned before the database was closed")
+	})
+
+	t.Run("serve error", func(t *testing.T) {
+		serveErr := make(chan error, 1)
+		serveErr <- errors.New("listener closed")
+
+		err := awaitShutdown(make(chan os.Signal), serveErr, func() { t.Error("shutdown must not run") })
+		assert.EqualError(t, err, "listener closed")
+	})
+}
+
+func TestEnqueueJob(t *testing.T) {
+	const kind jobs.Kind[struct{}] = "test.purge"
+	insert := regexp.QuoteMeta("INSERT INTO jobs")
+
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+	}{
+		{name: "enqueued", rows: sqlmock.NewRows([]string{"id"}).AddRow(1)},
+		{name: "already queued", rows: sqlmock.NewRows([]string{"id"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			queue, err := jobs.NewQueue(db, jobs.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			require.NoError(t, err)
+
+			mock.ExpectQuery(insert).
+				WithArgs(string(kind), []byte("{}"), sql.NullString{String: string(kind), Valid: true}, sqlmock.AnyArg(), jobs.DefaultMaxAttempts, sqlmock.AnyArg()).
+				WillReturnRows(tt.rows)
+
+			assert.NoError(t, enqueueJob(queue, db, kind)(t.Context()), "a job already queued is not an error")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
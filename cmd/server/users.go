@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/config"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+// userFlags are the flags of the users commands
+type userFlags struct {
+	output string
+}
+
+// openUsers connects to the database configured for cmd, logging to stderr
+// so the output of the command can be piped
+func openUsers(cmd *cobra.Command) (*users.Service, error) {
+	cfg, password, err := loadDatabaseConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	logger := newLogger(os.Stderr, cfg.Server.Level())
+	keyring, err := loadKeyring(cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	return users.NewService(usersConfig(cfg.Database, password), keyring, logger)
+}
+
+func newUsersCommand() *cobra.Command {
+	var f userFlags
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Manage users directly in the database",
+		Long: `Manage users directly in the database, for operators.
+
+These commands bypass the API and its audit log.`,
+	}
+	fs := cmd.PersistentFlags()
+	config.RegisterFlags(fs, "database", "auth.keyring_file", "auth.allow_dev_keyring")
+	fs.StringVarP(&f.output, "output", "o", "table", "Output format: table or json")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	cmd.AddCommand(
+		newUsersCreateCommand(&f),
+		newUsersListCommand(&f),
+		newUsersGetCommand(&f),
+		newUsersDeleteCommand(&f),
+		newUsersImportCommand(&f),
+		newUsersWatchCommand(&f),
+	)
+	return cmd
+}
+
+func newUsersCreateCommand(f *userFlags) *cobra.Command {
+	var name, email string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user",
+		Args:  cobra.NoArgs,
+		RunE: withUsers(f, func(ctx context.Context, service *users.Service, w io.Writer) error {
+			resp, err := service.CreateUser(ctx, &userspb.CreateUserRequest{Name: name, Email: email})
+			if err != nil {
+				return err
+			}
+			return writeUsers(w, f.output, resp.GetUser())
+		}),
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Name of the user")
+	cmd.Flags().StringVar(&email, "email", "", "Email address of the user")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("email")
+	return cmd
+}
+
+func newUsersListCommand(f *userFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all users",
+		Args:  cobra.NoArgs,
+		RunE: withUsers(f, func(ctx context.Context, service *users.Service, w io.Writer) error {
+			resp, err := service.ListUsers(ctx, &userspb.ListUsersRequest{})
+			if err != nil {
+				return err
+			}
+			return writeUsers(w, f.output, resp.GetUsers()...)
+		}),
+	}
+}
+
+func newUsersGetCommand(f *userFlags) *cobra.Command {
+	var id int64
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Show a user",
+		Args:  userIDArg(&id),
+		RunE: withUsers(f, func(ctx context.Context, service *users.Service, w io.Writer) error {
+			user, err := service.GetUserByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeUsers(w, f.output, user)
+		}),
+	}
+}
+
+func newUsersDeleteCommand(f *userFlags) *cobra.Command {
+	var id int64
+	return &cobra.Command{
+		Use:   "delete ID",
+		Short: "Delete a user",
+		Long: `Delete a user. Their group memberships and sign-ins are deleted with them.
+
+To erase everything stored about a person, including invitations sent to them
+and their details in the audit log, use the PrivacyService's EraseUser instead.`,
+		Args: userIDArg(&id),
+		RunE: withUsers(f, func(ctx context.Context, service *users.Service, w io.Writer) error {
+			if err := service.DeleteUser(ctx, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "deleted user %d\n", id)
+			return nil
+		}),
+	}
+}
+
+func newUsersImportCommand(f *userFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import [FILE]",
+		Short: "Create users in bulk",
+		Long: `Create users in bulk from FILE, or standard input if FILE is - or not given.
+Each line is a JSON object with the email and name of a user, as written by
+"users list --output json", so users can be copied between databases. Other
+fields, such as id, are ignored. Either every user is created or none is.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := cmd.InOrStdin()
+			if len(args) == 1 && args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				in = file
+			}
+			// Read everything before connecting, so bad input fails fast
+			reqs, err := readUsers(in)
+			if err != nil {
+				return err
+			}
+			return withUsers(f, func(ctx context.Context, service *users.Service, w io.Writer) error {
+				n, err := service.ImportUsers(ctx, reqs)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "imported %d users\n", n)
+				return nil
+			})(cmd, args)
+		},
+	}
+}
+
+func newUsersWatchCommand(f *userFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch",
+		Short: "Print changes to users as they happen",
+		Long: `Print users as they are created, changed or deleted, through the API, SCIM
+or these commands, until interrupted. Changes made while the connection to the
+database is being reestablished are not printed.`,
+		Args: cobra.NoArgs,
+		RunE: withUsers(f, func(ctx context.Context, service *users.Service, w io.Writer) error {
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return service.WatchUsers(ctx, func(change users.UserChange) {
+				if err := writeChange(w, f.output, change); err != nil {
+					slog.Warn("failed to write change", "error", err)
+				}
+			})
+		}),
+	}
+}
+
+// withUsers returns a command function that runs fn with a users service
+func withUsers(f *userFlags, fn func(ctx context.Context, service *users.Service, w io.Writer) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if f.output != "table" && f.output != "json" {
+			return fmt.Errorf("invalid output format %q: must be table or json", f.output)
+		}
+		service, err := openUsers(cmd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := service.Close(); err != nil {
+				slog.Warn("failed to close database connection", "error", err)
+			}
+		}()
+		return fn(cmd.Context(), service, cmd.OutOrStdout())
+	}
+}
+
+// userIDArg validates that a command has exactly one argument, a user id,
+// and stores it in id
+func userIDArg(id *int64) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		n, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid user id %q", args[0])
+		}
+		*id = n
+		return nil
+	}
+}
+
+// writeUsers writes users to w as a table, or as JSON, one object per line
+func writeUsers(w io.Writer, format string, list ...*userspb.User) error {
+	if format == "json" {
+		for _, user := range list {
+			data, err := protojson.Marshal(user)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(data))
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tEMAIL")
+	for _, user := range list {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", user.GetId(), user.GetName(), user.GetEmail())
+	}
+	return tw.Flush()
+}
+
+// readUsers reads users to create from r, one JSON object per line. Blank
+// lines and unknown fields are ignored.
+func readUsers(r io.Reader) ([]*userspb.CreateUserRequest, error) {
+	var reqs []*userspb.CreateUserRequest
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		req := &userspb.CreateUserRequest{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(text, req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if req.GetEmail() == "" {
+			return nil, fmt.Errorf("line %d: email is required", line)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, scanner.Err()
+}
+
+// changeVerbs describe the operations of a users.UserChange
+var changeVerbs = map[string]string{"INSERT": "created", "UPDATE": "updated", "DELETE": "deleted"}
+
+// writeChange writes change to w as a line of text, or as JSON
+func writeChange(w io.Writer, format string, change users.UserChange) error {
+	if format == "json" {
+		return json.NewEncoder(w).Encode(change)
+	}
+	_, err := fmt.Fprintf(w, "%s user %d\n", changeVerbs[change.Op], change.ID)
+	return err
+}
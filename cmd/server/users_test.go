@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+func TestWriteUsers(t *testing.T) {
+	list := []*userspb.User{
+		{Id: 1, Name: "Ada Lovelace", Email: "ada@example.com"},
+		{Id: 12, Name: "Alan Turing", Email: "alan@example.com"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "table",
+			format: "table",
+			want: "ID  NAME          EMAIL\n" +
+				"1   Ada Lovelace  ada@example.com\n" +
+				"12  Alan Turing   alan@example.com\n",
+		},
+		{
+			name:   "json",
+			format: "json",
+			want: `{"id":"1","name":"Ada Lovelace","email":"ada@example.com"}` + "\n" +
+				`{"id":"12","name":"Alan Turing","email":"alan@example.com"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, writeUsers(&out, tt.format, list...))
+			if tt.format == "json" {
+				lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+				wantLines := bytes.Split(bytes.TrimSpace([]byte(tt.want)), []byte("\n"))
+				require.Len(t, lines, len(wantLines))
+				for i := range lines {
+					assert.JSONEq(t, string(wantLines[i]), string(lines[i]))
+				}
+				return
+			}
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestUserIDArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{name: "valid", args: []string{"42"}, want: 42},
+		{name: "missing", args: nil, wantErr: true},
+		{name: "too many", args: []string{"1", "2"}, wantErr: true},
+		{name: "not a number", args: []string{"ada"}, wantErr: true},
+		{name: "not positive", args: []string{"0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id int64
+			err := userIDArg(&id)(&cobra.Command{}, tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
+
+func TestUsersCommand_InvalidOutput(t *testing.T) {
+	root := newRootCommand()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"users", "list", "--output", "yaml"})
+
+	assert.EqualError(t, root.Execute(), `invalid output format "yaml": must be table or json`)
+}
+
+func TestReadUsers(t *testing.T) {
+	in := `{"id":"1","name":"Ada Lovelace","email":"ada@example.com"}
+
+{"email":"alan@example.com","name":"Alan Turing"}
+`
+	reqs, err := readUsers(strings.NewReader(in))
+	require.NoError(t, err)
+	require.Len(t, reqs, 2, "blank lines are skipped")
+	assert.Equal(t, "ada@example.com", reqs[0].GetEmail(), "output of users list is accepted")
+	assert.Equal(t, "Alan Turing", reqs[1].GetName())
+
+	_, err = readUsers(strings.NewReader(`{"email":"ada@example.com"}` + "\n" + `{"name":"Alan"`))
+	assert.ErrorContains(t, err, "line 2:")
+	_, err = readUsers(strings.NewReader(`{"name":"Alan Turing"}`))
+	assert.EqualError(t, err, "line 1: email is required")
+}
+
+func TestWriteChange(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeChange(&out, "table", users.UserChange{Op: "DELETE", ID: 7}))
+	require.NoError(t, writeChange(&out, "json", users.UserChange{Op: "INSERT", ID: 8}))
+
+	assert.Equal(t, "deleted user 7\n"+`{"op":"INSERT","id":8}`+"\n", out.String())
+}
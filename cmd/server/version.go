@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// version is the release of the server, set at build time with
+// -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// buildInfo describes the running binary
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// readBuildInfo returns the version set at build time and the VCS details
+// the Go toolchain stamps into binaries built from a repository
+func readBuildInfo() buildInfo {
+	info := buildInfo{Version: version, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				info.BuildTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+func newVersionCommand() *cobra.Command {
+	var (
+		short  bool
+		output string
+	)
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := readBuildInfo()
+			w := cmd.OutOrStdout()
+			switch {
+			case short:
+				fmt.Fprintln(w, info.Version)
+			case output == "json":
+				return json.NewEncoder(w).Encode(info)
+			case output == "text":
+				fmt.Fprintf(w, "version: %s\ncommit:  %s\nbuilt:   %s\ngo:      %s\n", info.Version, info.Commit, info.BuildTime, info.GoVersion)
+				if info.Modified {
+					fmt.Fprintln(w, "built from a modified working tree")
+				}
+			default:
+				return fmt.Errorf("invalid output format %q: must be text or json", output)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&short, "short", false, "Print only the version number")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format: text or json")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCommand(t *testing.T) {
+	run := func(t *testing.T, args ...string) (string, error) {
+		var out bytes.Buffer
+		root := newRootCommand()
+		root.SetOut(&out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"version"}, args...))
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	t.Run("short", func(t *testing.T) {
+		out, err := run(t, "--short")
+		require.NoError(t, err)
+		assert.Equal(t, version+"\n", out)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		out, err := run(t)
+		require.NoError(t, err)
+		assert.Contains(t, out, "version: "+version+"\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := run(t, "--output", "json")
+		require.NoError(t, err)
+		var info buildInfo
+		require.NoError(t, json.Unmarshal([]byte(out), &info))
+		assert.Equal(t, version, info.Version)
+		assert.NotEmpty(t, info.GoVersion)
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		_, err := run(t, "--output", "yaml")
+		assert.EqualError(t, err, `invalid output format "yaml": must be text or json`)
+	})
+}
@@ -0,0 +1,567 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: audit/v1/audit.proto
+
+package auditv1
+
+import (
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AuditEvent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// Id of the authenticated user who made the call, empty if the caller was
+	// not authenticated.
+	Actor string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Full gRPC method name, e.g. /users.v1.UserService/CreateUser.
+	Method string `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	// Resource changed by the call, e.g. users/42. Empty if the call failed
+	// before the resource was known.
+	Resource string `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Hex-encoded SHA-256 digest of the serialized request message.
+	RequestDigest string `protobuf:"bytes,6,opt,name=request_digest,json=requestDigest,proto3" json:"request_digest,omitempty"`
+	// gRPC status code of the call, e.g. OK or NotFound.
+	Outcome  string `protobuf:"bytes,7,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	TraceId  string `protobuf:"bytes,8,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SourceIp string `protobuf:"bytes,9,opt,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"`
+	// Hex-encoded hash of the previous event in the chain.
+	PreviousHash string `protobuf:"bytes,10,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+	// Hex-encoded SHA-256 hash over previous_hash and this event's fields, with
+	// salted commitments in place of request_digest and source_ip.
+	Hash string `protobuf:"bytes,11,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Set when the personal fields of the event (request_digest, and
+	// source_ip if the actor was the erased user) were redacted because a user
+	// was erased. Their commitments are kept, so the hash can still be
+	// recomputed.
+	RedactTime    *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=redact_time,json=redactTime,proto3" json:"redact_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	mi := &file_audit_v1_audit_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_audit_v1_audit_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_audit_v1_audit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuditEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetRequestDigest() string {
+	if x != nil {
+		return x.RequestDigest
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetSourceIp() string {
+	if x != nil {
+		return x.SourceIp
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetPreviousHash() string {
+	if x != nil {
+		return x.PreviousHash
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetRedactTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RedactTime
+	}
+	return nil
+}
+
+type ListAuditEventsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PageSize  int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Only return events by this actor.
+	Actor string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Only return events for this full gRPC method name.
+	Method string `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	// Only return events for this resource.
+	Resource string `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Only return events with this outcome, e.g. OK or PermissionDenied.
+	Outcome string `protobuf:"bytes,6,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	// Only return events created at or after this time.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Only return events created before this time.
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_audit_v1_audit_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_audit_v1_audit_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_audit_v1_audit_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListAuditEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ListAuditEventsRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type ListAuditEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuditEvents   []*AuditEvent          `protobuf:"bytes,1,rep,name=audit_events,json=auditEvents,proto3" json:"audit_events,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsResponse) Reset() {
+	*x = ListAuditEventsResponse{}
+	mi := &file_audit_v1_audit_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsResponse) ProtoMessage() {}
+
+func (x *ListAuditEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_audit_v1_audit_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return file_audit_v1_audit_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListAuditEventsResponse) GetAuditEvents() []*AuditEvent {
+	if x != nil {
+		return x.AuditEvents
+	}
+	return nil
+}
+
+func (x *ListAuditEventsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type VerifyAuditChainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAuditChainRequest) Reset() {
+	*x = VerifyAuditChainRequest{}
+	mi := &file_audit_v1_audit_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAuditChainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAuditChainRequest) ProtoMessage() {}
+
+func (x *VerifyAuditChainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_audit_v1_audit_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAuditChainRequest.ProtoReflect.Descriptor instead.
+func (*VerifyAuditChainRequest) Descriptor() ([]byte, []int) {
+	return file_audit_v1_audit_proto_rawDescGZIP(), []int{3}
+}
+
+type VerifyAuditChainResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// True if every event's hash matches its contents and links to the event
+	// before it.
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Number of events checked.
+	CheckedCount int64 `protobuf:"varint,2,opt,name=checked_count,json=checkedCount,proto3" json:"checked_count,omitempty"`
+	// Id of the first event that failed verification, when valid is false.
+	FirstInvalidId int64 `protobuf:"varint,3,opt,name=first_invalid_id,json=firstInvalidId,proto3" json:"first_invalid_id,omitempty"`
+	// Number of checked events that were redacted. They are verified like the
+	// others, except those recorded before commitments were introduced (see
+	// unverifiable_count).
+	RedactedCount int64 `protobuf:"varint,4,opt,name=redacted_count,json=redactedCount,proto3" json:"redacted_count,omitempty"`
+	// Number of events whose own contents could not be verified: events
+	// recorded before commitments were introduced and later redacted. Their
+	// hash no longer matches their contents, so only their links to the events
+	// around them are checked, and they do not make the chain invalid.
+	UnverifiableCount int64 `protobuf:"varint,5,opt,name=unverifiable_count,json=unverifiableCount,proto3" json:"unverifiable_count,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *VerifyAuditChainResponse) Reset() {
+	*x = VerifyAuditChainResponse{}
+	mi := &file_audit_v1_audit_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAuditChainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAuditChainResponse) ProtoMessage() {}
+
+func (x *VerifyAuditChainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_audit_v1_audit_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAuditChainResponse.ProtoReflect.Descriptor instead.
+func (*VerifyAuditChainResponse) Descriptor() ([]byte, []int) {
+	return file_audit_v1_audit_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyAuditChainResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyAuditChainResponse) GetCheckedCount() int64 {
+	if x != nil {
+		return x.CheckedCount
+	}
+	return 0
+}
+
+func (x *VerifyAuditChainResponse) GetFirstInvalidId() int64 {
+	if x != nil {
+		return x.FirstInvalidId
+	}
+	return 0
+}
+
+func (x *VerifyAuditChainResponse) GetRedactedCount() int64 {
+	if x != nil {
+		return x.RedactedCount
+	}
+	return 0
+}
+
+func (x *VerifyAuditChainResponse) GetUnverifiableCount() int64 {
+	if x != nil {
+		return x.UnverifiableCount
+	}
+	return 0
+}
+
+var File_audit_v1_audit_proto protoreflect.FileDescriptor
+
+const file_audit_v1_audit_proto_rawDesc = "" +
+	"\n" +
+	"\x14audit/v1/audit.proto\x12\baudit.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x92\x03\n" +
+	"\n" +
+	"AuditEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12;\n" +
+	"\vcreate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1a\n" +
+	"\bresource\x18\x05 \x01(\tR\bresource\x12%\n" +
+	"\x0erequest_digest\x18\x06 \x01(\tR\rrequestDigest\x12\x18\n" +
+	"\aoutcome\x18\a \x01(\tR\aoutcome\x12\x19\n" +
+	"\btrace_id\x18\b \x01(\tR\atraceId\x12\x1b\n" +
+	"\tsource_ip\x18\t \x01(\tR\bsourceIp\x12#\n" +
+	"\rprevious_hash\x18\n" +
+	" \x01(\tR\fpreviousHash\x12\x12\n" +
+	"\x04hash\x18\v \x01(\tR\x04hash\x12;\n" +
+	"\vredact_time\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"redactTime\"\xaa\x02\n" +
+	"\x16ListAuditEventsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1a\n" +
+	"\bresource\x18\x05 \x01(\tR\bresource\x12\x18\n" +
+	"\aoutcome\x18\x06 \x01(\tR\aoutcome\x129\n" +
+	"\n" +
+	"start_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\"z\n" +
+	"\x17ListAuditEventsResponse\x127\n" +
+	"\faudit_events\x18\x01 \x03(\v2\x14.audit.v1.AuditEventR\vauditEvents\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x19\n" +
+	"\x17VerifyAuditChainRequest\"\xd5\x01\n" +
+	"\x18VerifyAuditChainResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12#\n" +
+	"\rchecked_count\x18\x02 \x01(\x03R\fcheckedCount\x12(\n" +
+	"\x10first_invalid_id\x18\x03 \x01(\x03R\x0efirstInvalidId\x12%\n" +
+	"\x0eredacted_count\x18\x04 \x01(\x03R\rredactedCount\x12-\n" +
+	"\x12unverifiable_count\x18\x05 \x01(\x03R\x11unverifiableCount2\xf4\x03\n" +
+	"\fAuditService\x12\xdf\x01\n" +
+	"\x0fListAuditEvents\x12 .audit.v1.ListAuditEventsRequest\x1a!.audit.v1.ListAuditEventsResponse\"\x86\x01\x92Ag\n" +
+	"\x05Audit\x12\x11List audit events\x1a:List audit events matching the given filters, oldest first*\x0flistAuditEvents\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/audit-events\x12\x81\x02\n" +
+	"\x10VerifyAuditChain\x12!.audit.v1.VerifyAuditChainRequest\x1a\".audit.v1.VerifyAuditChainResponse\"\xa5\x01\x92A\x7f\n" +
+	"\x05Audit\x12\x16Verify the audit chain\x1a^Recompute the hash chain over every audit event and report the first event that does not match\x82\xd3\xe4\x93\x02\x1d\x12\x1b/api/v1/audit-events:verifyB\xf2\x01\x92A`\x12\x12\n" +
+	"\tAudit API2\x051.0.0*\x01\x02rG\n" +
+	"\x1ago-api-template repository\x12)https://github.com/zcking/go-api-template\n" +
+	"\fcom.audit.v1B\n" +
+	"AuditProtoP\x01Z2github.com/zcking/go-api-template/audit/v1;auditv1\xa2\x02\x03AXX\xaa\x02\bAudit.V1\xca\x02\bAudit\\V1\xe2\x02\x14Audit\\V1\\GPBMetadata\xea\x02\tAudit::V1b\x06proto3"
+
+var (
+	file_audit_v1_audit_proto_rawDescOnce sync.Once
+	file_audit_v1_audit_proto_rawDescData []byte
+)
+
+func file_audit_v1_audit_proto_rawDescGZIP() []byte {
+	file_audit_v1_audit_proto_rawDescOnce.Do(func() {
+		file_audit_v1_audit_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_audit_v1_audit_proto_rawDesc), len(file_audit_v1_audit_proto_rawDesc)))
+	})
+	return file_audit_v1_audit_proto_rawDescData
+}
+
+var file_audit_v1_audit_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_audit_v1_audit_proto_goTypes = []any{
+	(*AuditEvent)(nil),               // 0: audit.v1.AuditEvent
+	(*ListAuditEventsRequest)(nil),   // 1: audit.v1.ListAuditEventsRequest
+	(*ListAuditEventsResponse)(nil),  // 2: audit.v1.ListAuditEventsResponse
+	(*VerifyAuditChainRequest)(nil),  // 3: audit.v1.VerifyAuditChainRequest
+	(*VerifyAuditChainResponse)(nil), // 4: audit.v1.VerifyAuditChainResponse
+	(*timestamppb.Timestamp)(nil),    // 5: google.protobuf.Timestamp
+}
+var file_audit_v1_audit_proto_depIdxs = []int32{
+	5, // 0: audit.v1.AuditEvent.create_time:type_name -> google.protobuf.Timestamp
+	5, // 1: audit.v1.AuditEvent.redact_time:type_name -> google.protobuf.Timestamp
+	5, // 2: audit.v1.ListAuditEventsRequest.start_time:type_name -> google.protobuf.Timestamp
+	5, // 3: audit.v1.ListAuditEventsRequest.end_time:type_name -> google.protobuf.Timestamp
+	0, // 4: audit.v1.ListAuditEventsResponse.audit_events:type_name -> audit.v1.AuditEvent
+	1, // 5: audit.v1.AuditService.ListAuditEvents:input_type -> audit.v1.ListAuditEventsRequest
+	3, // 6: audit.v1.AuditService.VerifyAuditChain:input_type -> audit.v1.VerifyAuditChainRequest
+	2, // 7: audit.v1.AuditService.ListAuditEvents:output_type -> audit.v1.ListAuditEventsResponse
+	4, // 8: audit.v1.AuditService.VerifyAuditChain:output_type -> audit.v1.VerifyAuditChainResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_audit_v1_audit_proto_init() }
+func file_audit_v1_audit_proto_init() {
+	if File_audit_v1_audit_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_audit_v1_audit_proto_rawDesc), len(file_audit_v1_audit_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_audit_v1_audit_proto_goTypes,
+		DependencyIndexes: file_audit_v1_audit_proto_depIdxs,
+		MessageInfos:      file_audit_v1_audit_proto_msgTypes,
+	}.Build()
+	File_audit_v1_audit_proto = out.File
+	file_audit_v1_audit_proto_goTypes = nil
+	file_audit_v1_audit_proto_depIdxs = nil
+}
@@ -0,0 +1,891 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: invitations/v1/invitations.proto
+
+package invitationsv1
+
+import (
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Invitation_State int32
+
+const (
+	Invitation_STATE_UNSPECIFIED Invitation_State = 0
+	Invitation_STATE_PENDING     Invitation_State = 1
+	Invitation_STATE_ACCEPTED    Invitation_State = 2
+	Invitation_STATE_REVOKED     Invitation_State = 3
+	Invitation_STATE_EXPIRED     Invitation_State = 4
+)
+
+// Enum value maps for Invitation_State.
+var (
+	Invitation_State_name = map[int32]string{
+		0: "STATE_UNSPECIFIED",
+		1: "STATE_PENDING",
+		2: "STATE_ACCEPTED",
+		3: "STATE_REVOKED",
+		4: "STATE_EXPIRED",
+	}
+	Invitation_State_value = map[string]int32{
+		"STATE_UNSPECIFIED": 0,
+		"STATE_PENDING":     1,
+		"STATE_ACCEPTED":    2,
+		"STATE_REVOKED":     3,
+		"STATE_EXPIRED":     4,
+	}
+)
+
+func (x Invitation_State) Enum() *Invitation_State {
+	p := new(Invitation_State)
+	*p = x
+	return p
+}
+
+func (x Invitation_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Invitation_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_invitations_v1_invitations_proto_enumTypes[0].Descriptor()
+}
+
+func (Invitation_State) Type() protoreflect.EnumType {
+	return &file_invitations_v1_invitations_proto_enumTypes[0]
+}
+
+func (x Invitation_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Invitation_State.Descriptor instead.
+func (Invitation_State) EnumDescriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type Invitation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	// Name given to the user when the invitation is accepted, unless the
+	// accepting request provides one.
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Groups the user is added to when the invitation is accepted.
+	GroupIds []int64          `protobuf:"varint,4,rep,packed,name=group_ids,json=groupIds,proto3" json:"group_ids,omitempty"`
+	State    Invitation_State `protobuf:"varint,5,opt,name=state,proto3,enum=invitations.v1.Invitation_State" json:"state,omitempty"`
+	// Number of times the invitation has been sent, including the first time.
+	SendCount  int32                  `protobuf:"varint,6,opt,name=send_count,json=sendCount,proto3" json:"send_count,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	ExpireTime *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	// Set once the invitation has been accepted.
+	AcceptedUserId int64                  `protobuf:"varint,9,opt,name=accepted_user_id,json=acceptedUserId,proto3" json:"accepted_user_id,omitempty"`
+	LastSendTime   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_send_time,json=lastSendTime,proto3" json:"last_send_time,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Invitation) Reset() {
+	*x = Invitation{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Invitation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Invitation) ProtoMessage() {}
+
+func (x *Invitation) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Invitation.ProtoReflect.Descriptor instead.
+func (*Invitation) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Invitation) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Invitation) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Invitation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Invitation) GetGroupIds() []int64 {
+	if x != nil {
+		return x.GroupIds
+	}
+	return nil
+}
+
+func (x *Invitation) GetState() Invitation_State {
+	if x != nil {
+		return x.State
+	}
+	return Invitation_STATE_UNSPECIFIED
+}
+
+func (x *Invitation) GetSendCount() int32 {
+	if x != nil {
+		return x.SendCount
+	}
+	return 0
+}
+
+func (x *Invitation) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Invitation) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+func (x *Invitation) GetAcceptedUserId() int64 {
+	if x != nil {
+		return x.AcceptedUserId
+	}
+	return 0
+}
+
+func (x *Invitation) GetLastSendTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSendTime
+	}
+	return nil
+}
+
+type CreateInvitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	GroupIds      []int64                `protobuf:"varint,3,rep,packed,name=group_ids,json=groupIds,proto3" json:"group_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInvitationRequest) Reset() {
+	*x = CreateInvitationRequest{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInvitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInvitationRequest) ProtoMessage() {}
+
+func (x *CreateInvitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInvitationRequest.ProtoReflect.Descriptor instead.
+func (*CreateInvitationRequest) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateInvitationRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateInvitationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateInvitationRequest) GetGroupIds() []int64 {
+	if x != nil {
+		return x.GroupIds
+	}
+	return nil
+}
+
+type CreateInvitationResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Invitation *Invitation            `protobuf:"bytes,1,opt,name=invitation,proto3" json:"invitation,omitempty"`
+	// Secret token for accepting the invitation. Only its hash is stored.
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// Link for accepting the invitation, when the server has an invitation
+	// URL configured.
+	AcceptUrl     string `protobuf:"bytes,3,opt,name=accept_url,json=acceptUrl,proto3" json:"accept_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInvitationResponse) Reset() {
+	*x = CreateInvitationResponse{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInvitationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInvitationResponse) ProtoMessage() {}
+
+func (x *CreateInvitationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInvitationResponse.ProtoReflect.Descriptor instead.
+func (*CreateInvitationResponse) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateInvitationResponse) GetInvitation() *Invitation {
+	if x != nil {
+		return x.Invitation
+	}
+	return nil
+}
+
+func (x *CreateInvitationResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateInvitationResponse) GetAcceptUrl() string {
+	if x != nil {
+		return x.AcceptUrl
+	}
+	return ""
+}
+
+type ListInvitationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInvitationsRequest) Reset() {
+	*x = ListInvitationsRequest{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInvitationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInvitationsRequest) ProtoMessage() {}
+
+func (x *ListInvitationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInvitationsRequest.ProtoReflect.Descriptor instead.
+func (*ListInvitationsRequest) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListInvitationsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListInvitationsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListInvitationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invitations   []*Invitation          `protobuf:"bytes,1,rep,name=invitations,proto3" json:"invitations,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInvitationsResponse) Reset() {
+	*x = ListInvitationsResponse{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInvitationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInvitationsResponse) ProtoMessage() {}
+
+func (x *ListInvitationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInvitationsResponse.ProtoReflect.Descriptor instead.
+func (*ListInvitationsResponse) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListInvitationsResponse) GetInvitations() []*Invitation {
+	if x != nil {
+		return x.Invitations
+	}
+	return nil
+}
+
+func (x *ListInvitationsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ResendInvitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResendInvitationRequest) Reset() {
+	*x = ResendInvitationRequest{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResendInvitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResendInvitationRequest) ProtoMessage() {}
+
+func (x *ResendInvitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResendInvitationRequest.ProtoReflect.Descriptor instead.
+func (*ResendInvitationRequest) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ResendInvitationRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ResendInvitationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invitation    *Invitation            `protobuf:"bytes,1,opt,name=invitation,proto3" json:"invitation,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	AcceptUrl     string                 `protobuf:"bytes,3,opt,name=accept_url,json=acceptUrl,proto3" json:"accept_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResendInvitationResponse) Reset() {
+	*x = ResendInvitationResponse{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResendInvitationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResendInvitationResponse) ProtoMessage() {}
+
+func (x *ResendInvitationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResendInvitationResponse.ProtoReflect.Descriptor instead.
+func (*ResendInvitationResponse) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ResendInvitationResponse) GetInvitation() *Invitation {
+	if x != nil {
+		return x.Invitation
+	}
+	return nil
+}
+
+func (x *ResendInvitationResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ResendInvitationResponse) GetAcceptUrl() string {
+	if x != nil {
+		return x.AcceptUrl
+	}
+	return ""
+}
+
+type RevokeInvitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeInvitationRequest) Reset() {
+	*x = RevokeInvitationRequest{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeInvitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeInvitationRequest) ProtoMessage() {}
+
+func (x *RevokeInvitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeInvitationRequest.ProtoReflect.Descriptor instead.
+func (*RevokeInvitationRequest) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RevokeInvitationRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type RevokeInvitationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invitation    *Invitation            `protobuf:"bytes,1,opt,name=invitation,proto3" json:"invitation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeInvitationResponse) Reset() {
+	*x = RevokeInvitationResponse{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeInvitationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeInvitationResponse) ProtoMessage() {}
+
+func (x *RevokeInvitationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeInvitationResponse.ProtoReflect.Descriptor instead.
+func (*RevokeInvitationResponse) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RevokeInvitationResponse) GetInvitation() *Invitation {
+	if x != nil {
+		return x.Invitation
+	}
+	return nil
+}
+
+type AcceptInvitationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Overrides the name set on the invitation.
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptInvitationRequest) Reset() {
+	*x = AcceptInvitationRequest{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptInvitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptInvitationRequest) ProtoMessage() {}
+
+func (x *AcceptInvitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptInvitationRequest.ProtoReflect.Descriptor instead.
+func (*AcceptInvitationRequest) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AcceptInvitationRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *AcceptInvitationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type AcceptInvitationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invitation    *Invitation            `protobuf:"bytes,1,opt,name=invitation,proto3" json:"invitation,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptInvitationResponse) Reset() {
+	*x = AcceptInvitationResponse{}
+	mi := &file_invitations_v1_invitations_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptInvitationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptInvitationResponse) ProtoMessage() {}
+
+func (x *AcceptInvitationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_invitations_v1_invitations_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptInvitationResponse.ProtoReflect.Descriptor instead.
+func (*AcceptInvitationResponse) Descriptor() ([]byte, []int) {
+	return file_invitations_v1_invitations_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AcceptInvitationResponse) GetInvitation() *Invitation {
+	if x != nil {
+		return x.Invitation
+	}
+	return nil
+}
+
+func (x *AcceptInvitationResponse) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+var File_invitations_v1_invitations_proto protoreflect.FileDescriptor
+
+const file_invitations_v1_invitations_proto_rawDesc = "" +
+	"\n" +
+	" invitations/v1/invitations.proto\x12\x0einvitations.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x8d\x04\n" +
+	"\n" +
+	"Invitation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1b\n" +
+	"\tgroup_ids\x18\x04 \x03(\x03R\bgroupIds\x126\n" +
+	"\x05state\x18\x05 \x01(\x0e2 .invitations.v1.Invitation.StateR\x05state\x12\x1d\n" +
+	"\n" +
+	"send_count\x18\x06 \x01(\x05R\tsendCount\x12;\n" +
+	"\vcreate_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vexpire_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\x12(\n" +
+	"\x10accepted_user_id\x18\t \x01(\x03R\x0eacceptedUserId\x12@\n" +
+	"\x0elast_send_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\flastSendTime\"k\n" +
+	"\x05State\x12\x15\n" +
+	"\x11STATE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rSTATE_PENDING\x10\x01\x12\x12\n" +
+	"\x0eSTATE_ACCEPTED\x10\x02\x12\x11\n" +
+	"\rSTATE_REVOKED\x10\x03\x12\x11\n" +
+	"\rSTATE_EXPIRED\x10\x04\"`\n" +
+	"\x17CreateInvitationRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tgroup_ids\x18\x03 \x03(\x03R\bgroupIds\"\x8b\x01\n" +
+	"\x18CreateInvitationResponse\x12:\n" +
+	"\n" +
+	"invitation\x18\x01 \x01(\v2\x1a.invitations.v1.InvitationR\n" +
+	"invitation\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"accept_url\x18\x03 \x01(\tR\tacceptUrl\"T\n" +
+	"\x16ListInvitationsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"\x7f\n" +
+	"\x17ListInvitationsResponse\x12<\n" +
+	"\vinvitations\x18\x01 \x03(\v2\x1a.invitations.v1.InvitationR\vinvitations\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\")\n" +
+	"\x17ResendInvitationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x8b\x01\n" +
+	"\x18ResendInvitationResponse\x12:\n" +
+	"\n" +
+	"invitation\x18\x01 \x01(\v2\x1a.invitations.v1.InvitationR\n" +
+	"invitation\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"accept_url\x18\x03 \x01(\tR\tacceptUrl\")\n" +
+	"\x17RevokeInvitationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"V\n" +
+	"\x18RevokeInvitationResponse\x12:\n" +
+	"\n" +
+	"invitation\x18\x01 \x01(\v2\x1a.invitations.v1.InvitationR\n" +
+	"invitation\"C\n" +
+	"\x17AcceptInvitationRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"o\n" +
+	"\x18AcceptInvitationResponse\x12:\n" +
+	"\n" +
+	"invitation\x18\x01 \x01(\v2\x1a.invitations.v1.InvitationR\n" +
+	"invitation\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId2\xc7\n" +
+	"\n" +
+	"\x11InvitationService\x12\x92\x02\n" +
+	"\x10CreateInvitation\x12'.invitations.v1.CreateInvitationRequest\x1a(.invitations.v1.CreateInvitationResponse\"\xaa\x01\x92A\x88\x01\n" +
+	"\vInvitations\x12\x14Create an invitation\x1acInvite an email address. The response contains the invitation token, which is never returned again.\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/api/v1/invitations\x12\xd3\x01\n" +
+	"\x0fListInvitations\x12&.invitations.v1.ListInvitationsRequest\x1a'.invitations.v1.ListInvitationsResponse\"o\x92AQ\n" +
+	"\vInvitations\x12\x10List invitations\x1a\x1fList invitations, ordered by id*\x0flistInvitations\x82\xd3\xe4\x93\x02\x15\x12\x13/api/v1/invitations\x12\xb8\x02\n" +
+	"\x10ResendInvitation\x12'.invitations.v1.ResendInvitationRequest\x1a(.invitations.v1.ResendInvitationResponse\"\xd0\x01\x92A\xa2\x01\n" +
+	"\vInvitations\x12\x14Resend an invitation\x1a}Issue a new token for a pending invitation and extend its expiry. The previous token stops working. Resends are rate limited.\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/invitations/{id}:resend\x12\x81\x02\n" +
+	"\x10RevokeInvitation\x12'.invitations.v1.RevokeInvitationRequest\x1a(.invitations.v1.RevokeInvitationResponse\"\x99\x01\x92A`\n" +
+	"\vInvitations\x12\x14Revoke an invitation\x1a;Revoke a pending invitation so it can no longer be accepted\x82\xd3\xe4\x93\x020:\x01*b\n" +
+	"invitation\"\x1f/api/v1/invitations/{id}:revoke\x12\x87\x02\n" +
+	"\x10AcceptInvitation\x12'.invitations.v1.AcceptInvitationRequest\x1a(.invitations.v1.AcceptInvitationResponse\"\x9f\x01\x92Aw\n" +
+	"\vInvitations\x12\x14Accept an invitation\x1aRAccept an invitation by token, creating the invited user and its group memberships\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/api/v1/invitations:acceptB\xa8\x02\x92Af\x12\x18\n" +
+	"\x0fInvitations API2\x051.0.0*\x01\x02rG\n" +
+	"\x1ago-api-template repository\x12)https://github.com/zcking/go-api-template\n" +
+	"\x12com.invitations.v1B\x10InvitationsProtoP\x01Z>github.com/zcking/go-api-template/invitations/v1;invitationsv1\xa2\x02\x03IXX\xaa\x02\x0eInvitations.V1\xca\x02\x0eInvitations\\V1\xe2\x02\x1aInvitations\\V1\\GPBMetadata\xea\x02\x0fInvitations::V1b\x06proto3"
+
+var (
+	file_invitations_v1_invitations_proto_rawDescOnce sync.Once
+	file_invitations_v1_invitations_proto_rawDescData []byte
+)
+
+func file_invitations_v1_invitations_proto_rawDescGZIP() []byte {
+	file_invitations_v1_invitations_proto_rawDescOnce.Do(func() {
+		file_invitations_v1_invitations_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_invitations_v1_invitations_proto_rawDesc), len(file_invitations_v1_invitations_proto_rawDesc)))
+	})
+	return file_invitations_v1_invitations_proto_rawDescData
+}
+
+var file_invitations_v1_invitations_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_invitations_v1_invitations_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_invitations_v1_invitations_proto_goTypes = []any{
+	(Invitation_State)(0),            // 0: invitations.v1.Invitation.State
+	(*Invitation)(nil),               // 1: invitations.v1.Invitation
+	(*CreateInvitationRequest)(nil),  // 2: invitations.v1.CreateInvitationRequest
+	(*CreateInvitationResponse)(nil), // 3: invitations.v1.CreateInvitationResponse
+	(*ListInvitationsRequest)(nil),   // 4: invitations.v1.ListInvitationsRequest
+	(*ListInvitationsResponse)(nil),  // 5: invitations.v1.ListInvitationsResponse
+	(*ResendInvitationRequest)(nil),  // 6: invitations.v1.ResendInvitationRequest
+	(*ResendInvitationResponse)(nil), // 7: invitations.v1.ResendInvitationResponse
+	(*RevokeInvitationRequest)(nil),  // 8: invitations.v1.RevokeInvitationRequest
+	(*RevokeInvitationResponse)(nil), // 9: invitations.v1.RevokeInvitationResponse
+	(*AcceptInvitationRequest)(nil),  // 10: invitations.v1.AcceptInvitationRequest
+	(*AcceptInvitationResponse)(nil), // 11: invitations.v1.AcceptInvitationResponse
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+}
+var file_invitations_v1_invitations_proto_depIdxs = []int32{
+	0,  // 0: invitations.v1.Invitation.state:type_name -> invitations.v1.Invitation.State
+	12, // 1: invitations.v1.Invitation.create_time:type_name -> google.protobuf.Timestamp
+	12, // 2: invitations.v1.Invitation.expire_time:type_name -> google.protobuf.Timestamp
+	12, // 3: invitations.v1.Invitation.last_send_time:type_name -> google.protobuf.Timestamp
+	1,  // 4: invitations.v1.CreateInvitationResponse.invitation:type_name -> invitations.v1.Invitation
+	1,  // 5: invitations.v1.ListInvitationsResponse.invitations:type_name -> invitations.v1.Invitation
+	1,  // 6: invitations.v1.ResendInvitationResponse.invitation:type_name -> invitations.v1.Invitation
+	1,  // 7: invitations.v1.RevokeInvitationResponse.invitation:type_name -> invitations.v1.Invitation
+	1,  // 8: invitations.v1.AcceptInvitationResponse.invitation:type_name -> invitations.v1.Invitation
+	2,  // 9: invitations.v1.InvitationService.CreateInvitation:input_type -> invitations.v1.CreateInvitationRequest
+	4,  // 10: invitations.v1.InvitationService.ListInvitations:input_type -> invitations.v1.ListInvitationsRequest
+	6,  // 11: invitations.v1.InvitationService.ResendInvitation:input_type -> invitations.v1.ResendInvitationRequest
+	8,  // 12: invitations.v1.InvitationService.RevokeInvitation:input_type -> invitations.v1.RevokeInvitationRequest
+	10, // 13: invitations.v1.InvitationService.AcceptInvitation:input_type -> invitations.v1.AcceptInvitationRequest
+	3,  // 14: invitations.v1.InvitationService.CreateInvitation:output_type -> invitations.v1.CreateInvitationResponse
+	5,  // 15: invitations.v1.InvitationService.ListInvitations:output_type -> invitations.v1.ListInvitationsResponse
+	7,  // 16: invitations.v1.InvitationService.ResendInvitation:output_type -> invitations.v1.ResendInvitationResponse
+	9,  // 17: invitations.v1.InvitationService.RevokeInvitation:output_type -> invitations.v1.RevokeInvitationResponse
+	11, // 18: invitations.v1.InvitationService.AcceptInvitation:output_type -> invitations.v1.AcceptInvitationResponse
+	14, // [14:19] is the sub-list for method output_type
+	9,  // [9:14] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_invitations_v1_invitations_proto_init() }
+func file_invitations_v1_invitations_proto_init() {
+	if File_invitations_v1_invitations_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_invitations_v1_invitations_proto_rawDesc), len(file_invitations_v1_invitations_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_invitations_v1_invitations_proto_goTypes,
+		DependencyIndexes: file_invitations_v1_invitations_proto_depIdxs,
+		EnumInfos:         file_invitations_v1_invitations_proto_enumTypes,
+		MessageInfos:      file_invitations_v1_invitations_proto_msgTypes,
+	}.Build()
+	File_invitations_v1_invitations_proto = out.File
+	file_invitations_v1_invitations_proto_goTypes = nil
+	file_invitations_v1_invitations_proto_depIdxs = nil
+}
@@ -0,0 +1,820 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: permissions/v1/permissions.proto
+
+package permissionsv1
+
+import (
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ObjectReference identifies an object, e.g. document:readme or user:42.
+type ObjectReference struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectType    string                 `protobuf:"bytes,1,opt,name=object_type,json=objectType,proto3" json:"object_type,omitempty"`
+	ObjectId      string                 `protobuf:"bytes,2,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObjectReference) Reset() {
+	*x = ObjectReference{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObjectReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectReference) ProtoMessage() {}
+
+func (x *ObjectReference) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectReference.ProtoReflect.Descriptor instead.
+func (*ObjectReference) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ObjectReference) GetObjectType() string {
+	if x != nil {
+		return x.ObjectType
+	}
+	return ""
+}
+
+func (x *ObjectReference) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+// SubjectReference is either an object (user:42) or, when optional_relation
+// is set, the set of subjects having that relation on the object
+// (group:eng#member).
+type SubjectReference struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Object           *ObjectReference       `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
+	OptionalRelation string                 `protobuf:"bytes,2,opt,name=optional_relation,json=optionalRelation,proto3" json:"optional_relation,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SubjectReference) Reset() {
+	*x = SubjectReference{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubjectReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubjectReference) ProtoMessage() {}
+
+func (x *SubjectReference) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubjectReference.ProtoReflect.Descriptor instead.
+func (*SubjectReference) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubjectReference) GetObject() *ObjectReference {
+	if x != nil {
+		return x.Object
+	}
+	return nil
+}
+
+func (x *SubjectReference) GetOptionalRelation() string {
+	if x != nil {
+		return x.OptionalRelation
+	}
+	return ""
+}
+
+// Relationship is a single relation tuple: resource#relation@subject.
+type Relationship struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Relation      string                 `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	Subject       *SubjectReference      `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Relationship) Reset() {
+	*x = Relationship{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Relationship) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Relationship) ProtoMessage() {}
+
+func (x *Relationship) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Relationship.ProtoReflect.Descriptor instead.
+func (*Relationship) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Relationship) GetResource() *ObjectReference {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *Relationship) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+func (x *Relationship) GetSubject() *SubjectReference {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+// RelationshipFilter selects relationships. resource_type is required; every
+// other field is optional and only constrains the match when set.
+type RelationshipFilter struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType    string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	ResourceId      string                 `protobuf:"bytes,2,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Relation        string                 `protobuf:"bytes,3,opt,name=relation,proto3" json:"relation,omitempty"`
+	SubjectType     string                 `protobuf:"bytes,4,opt,name=subject_type,json=subjectType,proto3" json:"subject_type,omitempty"`
+	SubjectId       string                 `protobuf:"bytes,5,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	SubjectRelation string                 `protobuf:"bytes,6,opt,name=subject_relation,json=subjectRelation,proto3" json:"subject_relation,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RelationshipFilter) Reset() {
+	*x = RelationshipFilter{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationshipFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationshipFilter) ProtoMessage() {}
+
+func (x *RelationshipFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationshipFilter.ProtoReflect.Descriptor instead.
+func (*RelationshipFilter) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RelationshipFilter) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *RelationshipFilter) GetResourceId() string {
+	if x != nil {
+		return x.ResourceId
+	}
+	return ""
+}
+
+func (x *RelationshipFilter) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+func (x *RelationshipFilter) GetSubjectType() string {
+	if x != nil {
+		return x.SubjectType
+	}
+	return ""
+}
+
+func (x *RelationshipFilter) GetSubjectId() string {
+	if x != nil {
+		return x.SubjectId
+	}
+	return ""
+}
+
+func (x *RelationshipFilter) GetSubjectRelation() string {
+	if x != nil {
+		return x.SubjectRelation
+	}
+	return ""
+}
+
+type WriteRelationshipsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Relationships []*Relationship        `protobuf:"bytes,1,rep,name=relationships,proto3" json:"relationships,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteRelationshipsRequest) Reset() {
+	*x = WriteRelationshipsRequest{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRelationshipsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRelationshipsRequest) ProtoMessage() {}
+
+func (x *WriteRelationshipsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteRelationshipsRequest.ProtoReflect.Descriptor instead.
+func (*WriteRelationshipsRequest) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WriteRelationshipsRequest) GetRelationships() []*Relationship {
+	if x != nil {
+		return x.Relationships
+	}
+	return nil
+}
+
+type WriteRelationshipsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteRelationshipsResponse) Reset() {
+	*x = WriteRelationshipsResponse{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRelationshipsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRelationshipsResponse) ProtoMessage() {}
+
+func (x *WriteRelationshipsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteRelationshipsResponse.ProtoReflect.Descriptor instead.
+func (*WriteRelationshipsResponse) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{5}
+}
+
+type DeleteRelationshipsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *RelationshipFilter    `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRelationshipsRequest) Reset() {
+	*x = DeleteRelationshipsRequest{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRelationshipsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRelationshipsRequest) ProtoMessage() {}
+
+func (x *DeleteRelationshipsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRelationshipsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRelationshipsRequest) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteRelationshipsRequest) GetFilter() *RelationshipFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type DeleteRelationshipsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeletedCount  int64                  `protobuf:"varint,1,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRelationshipsResponse) Reset() {
+	*x = DeleteRelationshipsResponse{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRelationshipsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRelationshipsResponse) ProtoMessage() {}
+
+func (x *DeleteRelationshipsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRelationshipsResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRelationshipsResponse) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteRelationshipsResponse) GetDeletedCount() int64 {
+	if x != nil {
+		return x.DeletedCount
+	}
+	return 0
+}
+
+type CheckRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Resource *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	// The relation or permission to check, as defined in the namespace configuration.
+	Permission    string            `protobuf:"bytes,2,opt,name=permission,proto3" json:"permission,omitempty"`
+	Subject       *SubjectReference `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckRequest) GetResource() *ObjectReference {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *CheckRequest) GetPermission() string {
+	if x != nil {
+		return x.Permission
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetSubject() *SubjectReference {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+type CheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CheckResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+type LookupResourcesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType  string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	Permission    string                 `protobuf:"bytes,2,opt,name=permission,proto3" json:"permission,omitempty"`
+	Subject       *SubjectReference      `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResourcesRequest) Reset() {
+	*x = LookupResourcesRequest{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResourcesRequest) ProtoMessage() {}
+
+func (x *LookupResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResourcesRequest.ProtoReflect.Descriptor instead.
+func (*LookupResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LookupResourcesRequest) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *LookupResourcesRequest) GetPermission() string {
+	if x != nil {
+		return x.Permission
+	}
+	return ""
+}
+
+func (x *LookupResourcesRequest) GetSubject() *SubjectReference {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *LookupResourcesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *LookupResourcesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type LookupResourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResourceIds   []string               `protobuf:"bytes,1,rep,name=resource_ids,json=resourceIds,proto3" json:"resource_ids,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResourcesResponse) Reset() {
+	*x = LookupResourcesResponse{}
+	mi := &file_permissions_v1_permissions_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResourcesResponse) ProtoMessage() {}
+
+func (x *LookupResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_v1_permissions_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResourcesResponse.ProtoReflect.Descriptor instead.
+func (*LookupResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_permissions_v1_permissions_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LookupResourcesResponse) GetResourceIds() []string {
+	if x != nil {
+		return x.ResourceIds
+	}
+	return nil
+}
+
+func (x *LookupResourcesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+var File_permissions_v1_permissions_proto protoreflect.FileDescriptor
+
+const file_permissions_v1_permissions_proto_rawDesc = "" +
+	"\n" +
+	" permissions/v1/permissions.proto\x12\x0epermissions.v1\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"O\n" +
+	"\x0fObjectReference\x12\x1f\n" +
+	"\vobject_type\x18\x01 \x01(\tR\n" +
+	"objectType\x12\x1b\n" +
+	"\tobject_id\x18\x02 \x01(\tR\bobjectId\"x\n" +
+	"\x10SubjectReference\x127\n" +
+	"\x06object\x18\x01 \x01(\v2\x1f.permissions.v1.ObjectReferenceR\x06object\x12+\n" +
+	"\x11optional_relation\x18\x02 \x01(\tR\x10optionalRelation\"\xa3\x01\n" +
+	"\fRelationship\x12;\n" +
+	"\bresource\x18\x01 \x01(\v2\x1f.permissions.v1.ObjectReferenceR\bresource\x12\x1a\n" +
+	"\brelation\x18\x02 \x01(\tR\brelation\x12:\n" +
+	"\asubject\x18\x03 \x01(\v2 .permissions.v1.SubjectReferenceR\asubject\"\xe3\x01\n" +
+	"\x12RelationshipFilter\x12#\n" +
+	"\rresource_type\x18\x01 \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\x02 \x01(\tR\n" +
+	"resourceId\x12\x1a\n" +
+	"\brelation\x18\x03 \x01(\tR\brelation\x12!\n" +
+	"\fsubject_type\x18\x04 \x01(\tR\vsubjectType\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x05 \x01(\tR\tsubjectId\x12)\n" +
+	"\x10subject_relation\x18\x06 \x01(\tR\x0fsubjectRelation\"_\n" +
+	"\x19WriteRelationshipsRequest\x12B\n" +
+	"\rrelationships\x18\x01 \x03(\v2\x1c.permissions.v1.RelationshipR\rrelationships\"\x1c\n" +
+	"\x1aWriteRelationshipsResponse\"X\n" +
+	"\x1aDeleteRelationshipsRequest\x12:\n" +
+	"\x06filter\x18\x01 \x01(\v2\".permissions.v1.RelationshipFilterR\x06filter\"B\n" +
+	"\x1bDeleteRelationshipsResponse\x12#\n" +
+	"\rdeleted_count\x18\x01 \x01(\x03R\fdeletedCount\"\xa7\x01\n" +
+	"\fCheckRequest\x12;\n" +
+	"\bresource\x18\x01 \x01(\v2\x1f.permissions.v1.ObjectReferenceR\bresource\x12\x1e\n" +
+	"\n" +
+	"permission\x18\x02 \x01(\tR\n" +
+	"permission\x12:\n" +
+	"\asubject\x18\x03 \x01(\v2 .permissions.v1.SubjectReferenceR\asubject\")\n" +
+	"\rCheckResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\"\xd5\x01\n" +
+	"\x16LookupResourcesRequest\x12#\n" +
+	"\rresource_type\x18\x01 \x01(\tR\fresourceType\x12\x1e\n" +
+	"\n" +
+	"permission\x18\x02 \x01(\tR\n" +
+	"permission\x12:\n" +
+	"\asubject\x18\x03 \x01(\v2 .permissions.v1.SubjectReferenceR\asubject\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"d\n" +
+	"\x17LookupResourcesResponse\x12!\n" +
+	"\fresource_ids\x18\x01 \x03(\tR\vresourceIds\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken2\xf7\a\n" +
+	"\x11PermissionService\x12\x9d\x02\n" +
+	"\x12WriteRelationships\x12).permissions.v1.WriteRelationshipsRequest\x1a*.permissions.v1.WriteRelationshipsResponse\"\xaf\x01\x92A\x85\x01\n" +
+	"\vPermissions\x12\x13Write relationships\x1aaAtomically write a batch of relationships. Writing a relationship that already exists is a no-op.\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/api/v1/relationships:write\x12\xea\x01\n" +
+	"\x13DeleteRelationships\x12*.permissions.v1.DeleteRelationshipsRequest\x1a+.permissions.v1.DeleteRelationshipsResponse\"z\x92AP\n" +
+	"\vPermissions\x12\x14Delete relationships\x1a+Delete every relationship matching a filter\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/api/v1/relationships:delete\x12\xd3\x01\n" +
+	"\x05Check\x12\x1c.permissions.v1.CheckRequest\x1a\x1d.permissions.v1.CheckResponse\"\x8c\x01\x92Ae\n" +
+	"\vPermissions\x12\x12Check a permission\x1aBCheck whether a subject has a relation or permission on a resource\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/permissions:check\x12\xfe\x01\n" +
+	"\x0fLookupResources\x12&.permissions.v1.LookupResourcesRequest\x1a'.permissions.v1.LookupResourcesResponse\"\x99\x01\x92Ah\n" +
+	"\vPermissions\x12\x10Lookup resources\x1aGList the ids of resources of a type on which a subject has a permission\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/permissions:lookupResourcesB\xa8\x02\x92Af\x12\x18\n" +
+	"\x0fPermissions API2\x051.0.0*\x01\x02rG\n" +
+	"\x1ago-api-template repository\x12)https://github.com/zcking/go-api-template\n" +
+	"\x12com.permissions.v1B\x10PermissionsProtoP\x01Z>github.com/zcking/go-api-template/permissions/v1;permissionsv1\xa2\x02\x03PXX\xaa\x02\x0ePermissions.V1\xca\x02\x0ePermissions\\V1\xe2\x02\x1aPermissions\\V1\\GPBMetadata\xea\x02\x0fPermissions::V1b\x06proto3"
+
+var (
+	file_permissions_v1_permissions_proto_rawDescOnce sync.Once
+	file_permissions_v1_permissions_proto_rawDescData []byte
+)
+
+func file_permissions_v1_permissions_proto_rawDescGZIP() []byte {
+	file_permissions_v1_permissions_proto_rawDescOnce.Do(func() {
+		file_permissions_v1_permissions_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_permissions_v1_permissions_proto_rawDesc), len(file_permissions_v1_permissions_proto_rawDesc)))
+	})
+	return file_permissions_v1_permissions_proto_rawDescData
+}
+
+var file_permissions_v1_permissions_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_permissions_v1_permissions_proto_goTypes = []any{
+	(*ObjectReference)(nil),             // 0: permissions.v1.ObjectReference
+	(*SubjectReference)(nil),            // 1: permissions.v1.SubjectReference
+	(*Relationship)(nil),                // 2: permissions.v1.Relationship
+	(*RelationshipFilter)(nil),          // 3: permissions.v1.RelationshipFilter
+	(*WriteRelationshipsRequest)(nil),   // 4: permissions.v1.WriteRelationshipsRequest
+	(*WriteRelationshipsResponse)(nil),  // 5: permissions.v1.WriteRelationshipsResponse
+	(*DeleteRelationshipsRequest)(nil),  // 6: permissions.v1.DeleteRelationshipsRequest
+	(*DeleteRelationshipsResponse)(nil), // 7: permissions.v1.DeleteRelationshipsResponse
+	(*CheckRequest)(nil),                // 8: permissions.v1.CheckRequest
+	(*CheckResponse)(nil),               // 9: permissions.v1.CheckResponse
+	(*LookupResourcesRequest)(nil),      // 10: permissions.v1.LookupResourcesRequest
+	(*LookupResourcesResponse)(nil),     // 11: permissions.v1.LookupResourcesResponse
+}
+var file_permissions_v1_permissions_proto_depIdxs = []int32{
+	0,  // 0: permissions.v1.SubjectReference.object:type_name -> permissions.v1.ObjectReference
+	0,  // 1: permissions.v1.Relationship.resource:type_name -> permissions.v1.ObjectReference
+	1,  // 2: permissions.v1.Relationship.subject:type_name -> permissions.v1.SubjectReference
+	2,  // 3: permissions.v1.WriteRelationshipsRequest.relationships:type_name -> permissions.v1.Relationship
+	3,  // 4: permissions.v1.DeleteRelationshipsRequest.filter:type_name -> permissions.v1.RelationshipFilter
+	0,  // 5: permissions.v1.CheckRequest.resource:type_name -> permissions.v1.ObjectReference
+	1,  // 6: permissions.v1.CheckRequest.subject:type_name -> permissions.v1.SubjectReference
+	1,  // 7: permissions.v1.LookupResourcesRequest.subject:type_name -> permissions.v1.SubjectReference
+	4,  // 8: permissions.v1.PermissionService.WriteRelationships:input_type -> permissions.v1.WriteRelationshipsRequest
+	6,  // 9: permissions.v1.PermissionService.DeleteRelationships:input_type -> permissions.v1.DeleteRelationshipsRequest
+	8,  // 10: permissions.v1.PermissionService.Check:input_type -> permissions.v1.CheckRequest
+	10, // 11: permissions.v1.PermissionService.LookupResources:input_type -> permissions.v1.LookupResourcesRequest
+	5,  // 12: permissions.v1.PermissionService.WriteRelationships:output_type -> permissions.v1.WriteRelationshipsResponse
+	7,  // 13: permissions.v1.PermissionService.DeleteRelationships:output_type -> permissions.v1.DeleteRelationshipsResponse
+	9,  // 14: permissions.v1.PermissionService.Check:output_type -> permissions.v1.CheckResponse
+	11, // 15: permissions.v1.PermissionService.LookupResources:output_type -> permissions.v1.LookupResourcesResponse
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_permissions_v1_permissions_proto_init() }
+func file_permissions_v1_permissions_proto_init() {
+	if File_permissions_v1_permissions_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_permissions_v1_permissions_proto_rawDesc), len(file_permissions_v1_permissions_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_permissions_v1_permissions_proto_goTypes,
+		DependencyIndexes: file_permissions_v1_permissions_proto_depIdxs,
+		MessageInfos:      file_permissions_v1_permissions_proto_msgTypes,
+	}.Build()
+	File_permissions_v1_permissions_proto = out.File
+	file_permissions_v1_permissions_proto_goTypes = nil
+	file_permissions_v1_permissions_proto_depIdxs = nil
+}
@@ -0,0 +1,450 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: privacy/v1/privacy.proto
+
+package privacyv1
+
+import (
+	longrunningpb "cloud.google.com/go/longrunning/autogen/longrunningpb"
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ExportUserDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserDataRequest) Reset() {
+	*x = ExportUserDataRequest{}
+	mi := &file_privacy_v1_privacy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserDataRequest) ProtoMessage() {}
+
+func (x *ExportUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_privacy_v1_privacy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_privacy_v1_privacy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportUserDataRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ExportUserDataResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resource name of the export, e.g. users/42/exports/7.
+	Export      string `protobuf:"bytes,1,opt,name=export,proto3" json:"export,omitempty"`
+	RecordCount int64  `protobuf:"varint,2,opt,name=record_count,json=recordCount,proto3" json:"record_count,omitempty"`
+	SizeBytes   int64  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// The export can be downloaded until this time.
+	ExpireTime    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserDataResponse) Reset() {
+	*x = ExportUserDataResponse{}
+	mi := &file_privacy_v1_privacy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserDataResponse) ProtoMessage() {}
+
+func (x *ExportUserDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_privacy_v1_privacy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserDataResponse.ProtoReflect.Descriptor instead.
+func (*ExportUserDataResponse) Descriptor() ([]byte, []int) {
+	return file_privacy_v1_privacy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExportUserDataResponse) GetExport() string {
+	if x != nil {
+		return x.Export
+	}
+	return ""
+}
+
+func (x *ExportUserDataResponse) GetRecordCount() int64 {
+	if x != nil {
+		return x.RecordCount
+	}
+	return 0
+}
+
+func (x *ExportUserDataResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *ExportUserDataResponse) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+type DownloadUserDataExportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ExportId      int64                  `protobuf:"varint,2,opt,name=export_id,json=exportId,proto3" json:"export_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadUserDataExportRequest) Reset() {
+	*x = DownloadUserDataExportRequest{}
+	mi := &file_privacy_v1_privacy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadUserDataExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadUserDataExportRequest) ProtoMessage() {}
+
+func (x *DownloadUserDataExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_privacy_v1_privacy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadUserDataExportRequest.ProtoReflect.Descriptor instead.
+func (*DownloadUserDataExportRequest) Descriptor() ([]byte, []int) {
+	return file_privacy_v1_privacy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DownloadUserDataExportRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *DownloadUserDataExportRequest) GetExportId() int64 {
+	if x != nil {
+		return x.ExportId
+	}
+	return 0
+}
+
+type EraseUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserRequest) Reset() {
+	*x = EraseUserRequest{}
+	mi := &file_privacy_v1_privacy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserRequest) ProtoMessage() {}
+
+func (x *EraseUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_privacy_v1_privacy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserRequest) Descriptor() ([]byte, []int) {
+	return file_privacy_v1_privacy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EraseUserRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type EraseUserResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of audit events whose personal fields were redacted.
+	RedactedAuditEventCount int64 `protobuf:"varint,1,opt,name=redacted_audit_event_count,json=redactedAuditEventCount,proto3" json:"redacted_audit_event_count,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *EraseUserResponse) Reset() {
+	*x = EraseUserResponse{}
+	mi := &file_privacy_v1_privacy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserResponse) ProtoMessage() {}
+
+func (x *EraseUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_privacy_v1_privacy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserResponse.ProtoReflect.Descriptor instead.
+func (*EraseUserResponse) Descriptor() ([]byte, []int) {
+	return file_privacy_v1_privacy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EraseUserResponse) GetRedactedAuditEventCount() int64 {
+	if x != nil {
+		return x.RedactedAuditEventCount
+	}
+	return 0
+}
+
+type UserDataOperationMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The user the operation acts on, e.g. users/42.
+	Target string `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	// "export" or "erase".
+	Verb          string                 `protobuf:"bytes,2,opt,name=verb,proto3" json:"verb,omitempty"`
+	CreateTime    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserDataOperationMetadata) Reset() {
+	*x = UserDataOperationMetadata{}
+	mi := &file_privacy_v1_privacy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserDataOperationMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserDataOperationMetadata) ProtoMessage() {}
+
+func (x *UserDataOperationMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_privacy_v1_privacy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserDataOperationMetadata.ProtoReflect.Descriptor instead.
+func (*UserDataOperationMetadata) Descriptor() ([]byte, []int) {
+	return file_privacy_v1_privacy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UserDataOperationMetadata) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *UserDataOperationMetadata) GetVerb() string {
+	if x != nil {
+		return x.Verb
+	}
+	return ""
+}
+
+func (x *UserDataOperationMetadata) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+var File_privacy_v1_privacy_proto protoreflect.FileDescriptor
+
+const file_privacy_v1_privacy_proto_rawDesc = "" +
+	"\n" +
+	"\x18privacy/v1/privacy.proto\x12\n" +
+	"privacy.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x19google/api/httpbody.proto\x1a#google/longrunning/operations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"0\n" +
+	"\x15ExportUserDataRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\xaf\x01\n" +
+	"\x16ExportUserDataResponse\x12\x16\n" +
+	"\x06export\x18\x01 \x01(\tR\x06export\x12!\n" +
+	"\frecord_count\x18\x02 \x01(\x03R\vrecordCount\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\x12;\n" +
+	"\vexpire_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\"U\n" +
+	"\x1dDownloadUserDataExportRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\texport_id\x18\x02 \x01(\x03R\bexportId\"+\n" +
+	"\x10EraseUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"P\n" +
+	"\x11EraseUserResponse\x12;\n" +
+	"\x1aredacted_audit_event_count\x18\x01 \x01(\x03R\x17redactedAuditEventCount\"\x84\x01\n" +
+	"\x19UserDataOperationMetadata\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12\x12\n" +
+	"\x04verb\x18\x02 \x01(\tR\x04verb\x12;\n" +
+	"\vcreate_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime2\xe6\x06\n" +
+	"\x0ePrivacyService\x12\xa6\x02\n" +
+	"\x0eExportUserData\x12!.privacy.v1.ExportUserDataRequest\x1a\x1d.google.longrunning.Operation\"\xd1\x01\x92Ak\n" +
+	"\aPrivacy\x12\x14Export a user's data\x1aJStart collecting everything stored about a user into a downloadable export\xcaA3\n" +
+	"\x16ExportUserDataResponse\x12\x19UserDataOperationMetadata\x82\xd3\xe4\x93\x02':\x01*\"\"/api/v1/users/{user_id}:exportData\x12\x8c\x02\n" +
+	"\x16DownloadUserDataExport\x12).privacy.v1.DownloadUserDataExportRequest\x1a\x14.google.api.HttpBody\"\xae\x01\x92Ao\n" +
+	"\aPrivacy\x12\x1bDownload a user data export\x1aGStream a finished export as newline-delimited JSON, one record per line\x82\xd3\xe4\x93\x026\x124/api/v1/users/{user_id}/exports/{export_id}:download0\x01\x12\x9b\x02\n" +
+	"\tEraseUser\x12\x1c.privacy.v1.EraseUserRequest\x1a\x1d.google.longrunning.Operation\"\xd0\x01\x92At\n" +
+	"\aPrivacy\x12\fErase a user\x1a[Start deleting a user's personal data. Audit events are kept with personal fields redacted.\xcaA.\n" +
+	"\x11EraseUserResponse\x12\x19UserDataOperationMetadata\x82\xd3\xe4\x93\x02\":\x01*\"\x1d/api/v1/users/{user_id}:eraseB\x84\x02\x92Ab\x12\x14\n" +
+	"\vPrivacy API2\x051.0.0*\x01\x02rG\n" +
+	"\x1ago-api-template repository\x12)https://github.com/zcking/go-api-template\n" +
+	"\x0ecom.privacy.v1B\fPrivacyProtoP\x01Z6github.com/zcking/go-api-template/privacy/v1;privacyv1\xa2\x02\x03PXX\xaa\x02\n" +
+	"Privacy.V1\xca\x02\n" +
+	"Privacy\\V1\xe2\x02\x16Privacy\\V1\\GPBMetadata\xea\x02\vPrivacy::V1b\x06proto3"
+
+var (
+	file_privacy_v1_privacy_proto_rawDescOnce sync.Once
+	file_privacy_v1_privacy_proto_rawDescData []byte
+)
+
+func file_privacy_v1_privacy_proto_rawDescGZIP() []byte {
+	file_privacy_v1_privacy_proto_rawDescOnce.Do(func() {
+		file_privacy_v1_privacy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_privacy_v1_privacy_proto_rawDesc), len(file_privacy_v1_privacy_proto_rawDesc)))
+	})
+	return file_privacy_v1_privacy_proto_rawDescData
+}
+
+var file_privacy_v1_privacy_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_privacy_v1_privacy_proto_goTypes = []any{
+	(*ExportUserDataRequest)(nil),         // 0: privacy.v1.ExportUserDataRequest
+	(*ExportUserDataResponse)(nil),        // 1: privacy.v1.ExportUserDataResponse
+	(*DownloadUserDataExportRequest)(nil), // 2: privacy.v1.DownloadUserDataExportRequest
+	(*EraseUserRequest)(nil),              // 3: privacy.v1.EraseUserRequest
+	(*EraseUserResponse)(nil),             // 4: privacy.v1.EraseUserResponse
+	(*UserDataOperationMetadata)(nil),     // 5: privacy.v1.UserDataOperationMetadata
+	(*timestamppb.Timestamp)(nil),         // 6: google.protobuf.Timestamp
+	(*longrunningpb.Operation)(nil),       // 7: google.longrunning.Operation
+	(*httpbody.HttpBody)(nil),             // 8: google.api.HttpBody
+}
+var file_privacy_v1_privacy_proto_depIdxs = []int32{
+	6, // 0: privacy.v1.ExportUserDataResponse.expire_time:type_name -> google.protobuf.Timestamp
+	6, // 1: privacy.v1.UserDataOperationMetadata.create_time:type_name -> google.protobuf.Timestamp
+	0, // 2: privacy.v1.PrivacyService.ExportUserData:input_type -> privacy.v1.ExportUserDataRequest
+	2, // 3: privacy.v1.PrivacyService.DownloadUserDataExport:input_type -> privacy.v1.DownloadUserDataExportRequest
+	3, // 4: privacy.v1.PrivacyService.EraseUser:input_type -> privacy.v1.EraseUserRequest
+	7, // 5: privacy.v1.PrivacyService.ExportUserData:output_type -> google.longrunning.Operation
+	8, // 6: privacy.v1.PrivacyService.DownloadUserDataExport:output_type -> google.api.HttpBody
+	7, // 7: privacy.v1.PrivacyService.EraseUser:output_type -> google.longrunning.Operation
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_privacy_v1_privacy_proto_init() }
+func file_privacy_v1_privacy_proto_init() {
+	if File_privacy_v1_privacy_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_privacy_v1_privacy_proto_rawDesc), len(file_privacy_v1_privacy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_privacy_v1_privacy_proto_goTypes,
+		DependencyIndexes: file_privacy_v1_privacy_proto_depIdxs,
+		MessageInfos:      file_privacy_v1_privacy_proto_msgTypes,
+	}.Build()
+	File_privacy_v1_privacy_proto = out.File
+	file_privacy_v1_privacy_proto_goTypes = nil
+	file_privacy_v1_privacy_proto_depIdxs = nil
+}
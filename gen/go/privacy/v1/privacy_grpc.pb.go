@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             (unknown)
+// source: privacy/v1/privacy.proto
+
+package privacyv1
+
+import (
+	longrunningpb "cloud.google.com/go/longrunning/autogen/longrunningpb"
+	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PrivacyService_ExportUserData_FullMethodName         = "/privacy.v1.PrivacyService/ExportUserData"
+	PrivacyService_DownloadUserDataExport_FullMethodName = "/privacy.v1.PrivacyService/DownloadUserDataExport"
+	PrivacyService_EraseUser_FullMethodName              = "/privacy.v1.PrivacyService/EraseUser"
+)
+
+// PrivacyServiceClient is the client API for PrivacyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PrivacyService answers data subject requests: exporting everything stored
+// about a user, and erasing it. Both run as long-running operations that are
+// polled with google.longrunning.Operations.GetOperation.
+type PrivacyServiceClient interface {
+	ExportUserData(ctx context.Context, in *ExportUserDataRequest, opts ...grpc.CallOption) (*longrunningpb.Operation, error)
+	DownloadUserDataExport(ctx context.Context, in *DownloadUserDataExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[httpbody.HttpBody], error)
+	EraseUser(ctx context.Context, in *EraseUserRequest, opts ...grpc.CallOption) (*longrunningpb.Operation, error)
+}
+
+type privacyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPrivacyServiceClient(cc grpc.ClientConnInterface) PrivacyServiceClient {
+	return &privacyServiceClient{cc}
+}
+
+func (c *privacyServiceClient) ExportUserData(ctx context.Context, in *ExportUserDataRequest, opts ...grpc.CallOption) (*longrunningpb.Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(longrunningpb.Operation)
+	err := c.cc.Invoke(ctx, PrivacyService_ExportUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *privacyServiceClient) DownloadUserDataExport(ctx context.Context, in *DownloadUserDataExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[httpbody.HttpBody], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PrivacyService_ServiceDesc.Streams[0], PrivacyService_DownloadUserDataExport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadUserDataExportRequest, httpbody.HttpBody]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PrivacyService_DownloadUserDataExportClient = grpc.ServerStreamingClient[httpbody.HttpBody]
+
+func (c *privacyServiceClient) EraseUser(ctx context.Context, in *EraseUserRequest, opts ...grpc.CallOption) (*longrunningpb.Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(longrunningpb.Operation)
+	err := c.cc.Invoke(ctx, PrivacyService_EraseUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PrivacyServiceServer is the server API for PrivacyService service.
+// All implementations must embed UnimplementedPrivacyServiceServer
+// for forward compatibility.
+//
+// PrivacyService answers data subject requests: exporting everything stored
+// about a user, and erasing it. Both run as long-running operations that are
+// polled with google.longrunning.Operations.GetOperation.
+type PrivacyServiceServer interface {
+	ExportUserData(context.Context, *ExportUserDataRequest) (*longrunningpb.Operation, error)
+	DownloadUserDataExport(*DownloadUserDataExportRequest, grpc.ServerStreamingServer[httpbody.HttpBody]) error
+	EraseUser(context.Context, *EraseUserRequest) (*longrunningpb.Operation, error)
+	mustEmbedUnimplementedPrivacyServiceServer()
+}
+
+// UnimplementedPrivacyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPrivacyServiceServer struct{}
+
+func (UnimplementedPrivacyServiceServer) ExportUserData(context.Context, *ExportUserDataRequest) (*longrunningpb.Operation, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExportUserData not implemented")
+}
+func (UnimplementedPrivacyServiceServer) DownloadUserDataExport(*DownloadUserDataExportRequest, grpc.ServerStreamingServer[httpbody.HttpBody]) error {
+	return status.Error(codes.Unimplemented, "method DownloadUserDataExport not implemented")
+}
+func (UnimplementedPrivacyServiceServer) EraseUser(context.Context, *EraseUserRequest) (*longrunningpb.Operation, error) {
+	return nil, status.Error(codes.Unimplemented, "method EraseUser not implemented")
+}
+func (UnimplementedPrivacyServiceServer) mustEmbedUnimplementedPrivacyServiceServer() {}
+func (UnimplementedPrivacyServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafePrivacyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PrivacyServiceServer will
+// result in compilation errors.
+type UnsafePrivacyServiceServer interface {
+	mustEmbedUnimplementedPrivacyServiceServer()
+}
+
+func RegisterPrivacyServiceServer(s grpc.ServiceRegistrar, srv PrivacyServiceServer) {
+	// If the following call panics, it indicates UnimplementedPrivacyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PrivacyService_ServiceDesc, srv)
+}
+
+func _PrivacyService_ExportUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrivacyServiceServer).ExportUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PrivacyService_ExportUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrivacyServiceServer).ExportUserData(ctx, req.(*ExportUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PrivacyService_DownloadUserDataExport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadUserDataExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PrivacyServiceServer).DownloadUserDataExport(m, &grpc.GenericServerStream[DownloadUserDataExportRequest, httpbody.HttpBody]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PrivacyService_DownloadUserDataExportServer = grpc.ServerStreamingServer[httpbody.HttpBody]
+
+func _PrivacyService_EraseUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrivacyServiceServer).EraseUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PrivacyService_EraseUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrivacyServiceServer).EraseUser(ctx, req.(*EraseUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PrivacyService_ServiceDesc is the grpc.ServiceDesc for PrivacyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PrivacyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "privacy.v1.PrivacyService",
+	HandlerType: (*PrivacyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExportUserData",
+			Handler:    _PrivacyService_ExportUserData_Handler,
+		},
+		{
+			MethodName: "EraseUser",
+			Handler:    _PrivacyService_EraseUser_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DownloadUserDataExport",
+			Handler:       _PrivacyService_DownloadUserDataExport_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "privacy/v1/privacy.proto",
+}
@@ -11,6 +11,7 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -23,6 +24,64 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ActivityEvent_Type int32
+
+const (
+	ActivityEvent_TYPE_UNSPECIFIED   ActivityEvent_Type = 0
+	ActivityEvent_TYPE_CREATED       ActivityEvent_Type = 1
+	ActivityEvent_TYPE_EMAIL_CHANGED ActivityEvent_Type = 2
+	ActivityEvent_TYPE_LOGIN         ActivityEvent_Type = 3
+	ActivityEvent_TYPE_MFA_ENROLLED  ActivityEvent_Type = 4
+	ActivityEvent_TYPE_DELETED       ActivityEvent_Type = 5
+)
+
+// Enum value maps for ActivityEvent_Type.
+var (
+	ActivityEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_EMAIL_CHANGED",
+		3: "TYPE_LOGIN",
+		4: "TYPE_MFA_ENROLLED",
+		5: "TYPE_DELETED",
+	}
+	ActivityEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED":   0,
+		"TYPE_CREATED":       1,
+		"TYPE_EMAIL_CHANGED": 2,
+		"TYPE_LOGIN":         3,
+		"TYPE_MFA_ENROLLED":  4,
+		"TYPE_DELETED":       5,
+	}
+)
+
+func (x ActivityEvent_Type) Enum() *ActivityEvent_Type {
+	p := new(ActivityEvent_Type)
+	*p = x
+	return p
+}
+
+func (x ActivityEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ActivityEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_users_v1_users_proto_enumTypes[0].Descriptor()
+}
+
+func (ActivityEvent_Type) Type() protoreflect.EnumType {
+	return &file_users_v1_users_proto_enumTypes[0]
+}
+
+func (x ActivityEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ActivityEvent_Type.Descriptor instead.
+func (ActivityEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_users_v1_users_proto_rawDescGZIP(), []int{7, 0}
+}
+
 type CreateUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -259,11 +318,203 @@ func (x *User) GetEmail() string {
 	return ""
 }
 
+type GetUserActivityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserActivityRequest) Reset() {
+	*x = GetUserActivityRequest{}
+	mi := &file_users_v1_users_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserActivityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserActivityRequest) ProtoMessage() {}
+
+func (x *GetUserActivityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_users_v1_users_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserActivityRequest.ProtoReflect.Descriptor instead.
+func (*GetUserActivityRequest) Descriptor() ([]byte, []int) {
+	return file_users_v1_users_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetUserActivityRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetUserActivityRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetUserActivityRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type GetUserActivityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*ActivityEvent       `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserActivityResponse) Reset() {
+	*x = GetUserActivityResponse{}
+	mi := &file_users_v1_users_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserActivityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserActivityResponse) ProtoMessage() {}
+
+func (x *GetUserActivityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_users_v1_users_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserActivityResponse.ProtoReflect.Descriptor instead.
+func (*GetUserActivityResponse) Descriptor() ([]byte, []int) {
+	return file_users_v1_users_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetUserActivityResponse) GetEvents() []*ActivityEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetUserActivityResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ActivityEvent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Type       ActivityEvent_Type     `protobuf:"varint,1,opt,name=type,proto3,enum=users.v1.ActivityEvent_Type" json:"type,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// Id of the user who caused the event, empty if unknown.
+	Actor string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Where the event was read from: "audit" or "session".
+	Source string `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	// Source-specific detail, e.g. the gRPC method or the OpenID Connect client
+	// signed in to.
+	Detail        string `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActivityEvent) Reset() {
+	*x = ActivityEvent{}
+	mi := &file_users_v1_users_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivityEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivityEvent) ProtoMessage() {}
+
+func (x *ActivityEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_users_v1_users_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivityEvent.ProtoReflect.Descriptor instead.
+func (*ActivityEvent) Descriptor() ([]byte, []int) {
+	return file_users_v1_users_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ActivityEvent) GetType() ActivityEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return ActivityEvent_TYPE_UNSPECIFIED
+}
+
+func (x *ActivityEvent) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *ActivityEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ActivityEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ActivityEvent) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
 var File_users_v1_users_proto protoreflect.FileDescriptor
 
 const file_users_v1_users_proto_rawDesc = "" +
 	"\n" +
-	"\x14users/v1/users.proto\x12\busers.v1\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"=\n" +
+	"\x14users/v1/users.proto\x12\busers.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"=\n" +
 	"\x11CreateUserRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\"8\n" +
@@ -275,16 +526,41 @@ const file_users_v1_users_proto_rawDesc = "" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05email\x18\x03 \x01(\tR\x05email2\xeb\x02\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\"m\n" +
+	"\x16GetUserActivityRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"r\n" +
+	"\x17GetUserActivityResponse\x12/\n" +
+	"\x06events\x18\x01 \x03(\v2\x17.users.v1.ActivityEventR\x06events\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xc5\x02\n" +
+	"\rActivityEvent\x120\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1c.users.v1.ActivityEvent.TypeR\x04type\x12;\n" +
+	"\vcreate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x16\n" +
+	"\x06detail\x18\x05 \x01(\tR\x06detail\"\x7f\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x16\n" +
+	"\x12TYPE_EMAIL_CHANGED\x10\x02\x12\x0e\n" +
+	"\n" +
+	"TYPE_LOGIN\x10\x03\x12\x15\n" +
+	"\x11TYPE_MFA_ENROLLED\x10\x04\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x052\x8a\x05\n" +
 	"\vUserService\x12\xc1\x01\n" +
 	"\n" +
 	"CreateUser\x12\x1b.users.v1.CreateUserRequest\x1a\x1c.users.v1.CreateUserResponse\"x\x92AW\n" +
 	"\x05Users\x12\x11Create a new user\x1a\x11Create a new userJ(\n" +
 	"\x03201\x12!\n" +
 	"\fUser created\x12\x11\n" +
-	"\x0f\x1a\r.auth.v1.User\x82\xd3\xe4\x93\x02\x18:\x01*b\x04user\"\r/api/v1/users\x12\x97\x01\n" +
-	"\tListUsers\x12\x1a.users.v1.ListUsersRequest\x1a\x1b.users.v1.ListUsersResponse\"Q\x92A2\n" +
-	"\x05Users\x12\x0eList all users\x1a\x0eList all users*\tlistUsers\x82\xd3\xe4\x93\x02\x16b\x05users\x12\r/api/v1/usersB\xf2\x01\x92A`\x12\x12\n" +
+	"\x0f\x1a\r.auth.v1.User\x82\xd3\xe4\x93\x02\x18:\x01*b\x04user\"\r/api/v1/users\x12\x9a\x01\n" +
+	"\tListUsers\x12\x1a.users.v1.ListUsersRequest\x1a\x1b.users.v1.ListUsersResponse\"T\x92A2\n" +
+	"\x05Users\x12\x0eList all users\x1a\x0eList all users*\tlistUsers\x82\xd3\xe4\x93\x02\x16b\x05users\x12\r/api/v1/users\x90\x02\x01\x12\x99\x02\n" +
+	"\x0fGetUserActivity\x12 .users.v1.GetUserActivityRequest\x1a!.users.v1.GetUserActivityResponse\"\xc0\x01\x92A\x91\x01\n" +
+	"\x05Users\x12\x15Get a user's activity\x1a`List the lifecycle events of a user, newest first, merged from the audit log and sign-in history*\x0fgetUserActivity\x82\xd3\xe4\x93\x02\"\x12 /api/v1/users/{user_id}/activity\x90\x02\x01B\xf2\x01\x92A`\x12\x12\n" +
 	"\tUsers API2\x051.0.0*\x01\x02rG\n" +
 	"\x1ago-api-template repository\x12)https://github.com/zcking/go-api-template\n" +
 	"\fcom.users.v1B\n" +
@@ -302,26 +578,37 @@ func file_users_v1_users_proto_rawDescGZIP() []byte {
 	return file_users_v1_users_proto_rawDescData
 }
 
-var file_users_v1_users_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_users_v1_users_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_users_v1_users_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_users_v1_users_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),  // 0: users.v1.CreateUserRequest
-	(*CreateUserResponse)(nil), // 1: users.v1.CreateUserResponse
-	(*ListUsersRequest)(nil),   // 2: users.v1.ListUsersRequest
-	(*ListUsersResponse)(nil),  // 3: users.v1.ListUsersResponse
-	(*User)(nil),               // 4: users.v1.User
+	(ActivityEvent_Type)(0),         // 0: users.v1.ActivityEvent.Type
+	(*CreateUserRequest)(nil),       // 1: users.v1.CreateUserRequest
+	(*CreateUserResponse)(nil),      // 2: users.v1.CreateUserResponse
+	(*ListUsersRequest)(nil),        // 3: users.v1.ListUsersRequest
+	(*ListUsersResponse)(nil),       // 4: users.v1.ListUsersResponse
+	(*User)(nil),                    // 5: users.v1.User
+	(*GetUserActivityRequest)(nil),  // 6: users.v1.GetUserActivityRequest
+	(*GetUserActivityResponse)(nil), // 7: users.v1.GetUserActivityResponse
+	(*ActivityEvent)(nil),           // 8: users.v1.ActivityEvent
+	(*timestamppb.Timestamp)(nil),   // 9: google.protobuf.Timestamp
 }
 var file_users_v1_users_proto_depIdxs = []int32{
-	4, // 0: users.v1.CreateUserResponse.user:type_name -> users.v1.User
-	4, // 1: users.v1.ListUsersResponse.users:type_name -> users.v1.User
-	0, // 2: users.v1.UserService.CreateUser:input_type -> users.v1.CreateUserRequest
-	2, // 3: users.v1.UserService.ListUsers:input_type -> users.v1.ListUsersRequest
-	1, // 4: users.v1.UserService.CreateUser:output_type -> users.v1.CreateUserResponse
-	3, // 5: users.v1.UserService.ListUsers:output_type -> users.v1.ListUsersResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	5, // 0: users.v1.CreateUserResponse.user:type_name -> users.v1.User
+	5, // 1: users.v1.ListUsersResponse.users:type_name -> users.v1.User
+	8, // 2: users.v1.GetUserActivityResponse.events:type_name -> users.v1.ActivityEvent
+	0, // 3: users.v1.ActivityEvent.type:type_name -> users.v1.ActivityEvent.Type
+	9, // 4: users.v1.ActivityEvent.create_time:type_name -> google.protobuf.Timestamp
+	1, // 5: users.v1.UserService.CreateUser:input_type -> users.v1.CreateUserRequest
+	3, // 6: users.v1.UserService.ListUsers:input_type -> users.v1.ListUsersRequest
+	6, // 7: users.v1.UserService.GetUserActivity:input_type -> users.v1.GetUserActivityRequest
+	2, // 8: users.v1.UserService.CreateUser:output_type -> users.v1.CreateUserResponse
+	4, // 9: users.v1.UserService.ListUsers:output_type -> users.v1.ListUsersResponse
+	7, // 10: users.v1.UserService.GetUserActivity:output_type -> users.v1.GetUserActivityResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_users_v1_users_proto_init() }
@@ -334,13 +621,14 @@ func file_users_v1_users_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_users_v1_users_proto_rawDesc), len(file_users_v1_users_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_users_v1_users_proto_goTypes,
 		DependencyIndexes: file_users_v1_users_proto_depIdxs,
+		EnumInfos:         file_users_v1_users_proto_enumTypes,
 		MessageInfos:      file_users_v1_users_proto_msgTypes,
 	}.Build()
 	File_users_v1_users_proto = out.File
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// maxDigestBytes caps how much of an HTTP request body is read to digest
+// it. Larger bodies are digested by their first maxDigestBytes; the APIs
+// audited this way reject them anyway.
+const maxDigestBytes = 1 << 20
+
+// HTTPHandler wraps next, the handler of an HTTP request that changes state
+// but is not an RPC (such as a SCIM request), to audit it the way
+// UnaryServerInterceptor audits a mutating RPC. method names the request
+// in the log, e.g. "SCIM PATCH /Users/{id}", and actor the authenticated
+// caller. next calls Record in the transaction that makes the change;
+// requests that fail (status 400 and above), or whose handler does not
+// record them, are appended on their own.
+func (s *Service) HTTPHandler(actor, method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := newHTTPEvent(r, actor, method)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(withEvent(r.Context(), e)))
+
+		outcome := codeFromHTTPStatus(rec.status)
+		if outcome == codes.OK && e.recorded {
+			return
+		}
+		ctx := context.WithoutCancel(r.Context())
+		if err := s.appendOwn(ctx, e, outcome); err != nil {
+			s.logger.ErrorContext(ctx, "failed to record audit event",
+				"error", err, "method", e.method, "outcome", outcome.String())
+		}
+	})
+}
+
+// SetMethod renames the event of the current request, for requests that
+// can make different kinds of change, such as a SCIM PUT that may or may
+// not change the user's email. It does nothing outside of an audited
+// request.
+func SetMethod(ctx context.Context, method string) {
+	if e := eventFromContext(ctx); e != nil {
+		e.method = method
+	}
+}
+
+// newHTTPEvent describes an incoming HTTP request. Its body is read to
+// digest it and put back for the handler.
+func newHTTPEvent(r *http.Request, actor, method string) *event {
+	e := &event{actor: actor, method: method}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		e.sourceIP = host
+	} else {
+		e.sourceIP = r.RemoteAddr
+	}
+	if r.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, maxDigestBytes))
+		sum := sha256.Sum256(body)
+		e.requestDigest = sum[:]
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	}
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		e.traceID = sc.TraceID().String()
+	}
+	return e
+}
+
+// statusRecorder records the status code of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// codeFromHTTPStatus maps the status code of a response to the gRPC code
+// recorded as its outcome
+func codeFromHTTPStatus(status int) codes.Code {
+	switch {
+	case status < http.StatusBadRequest:
+		return codes.OK
+	case status == http.StatusBadRequest:
+		return codes.InvalidArgument
+	case status == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case status == http.StatusForbidden:
+		return codes.PermissionDenied
+	case status == http.StatusNotFound:
+		return codes.NotFound
+	case status == http.StatusConflict:
+		return codes.AlreadyExists
+	case status == http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case status == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case status == http.StatusNotImplemented:
+		return codes.Unimplemented
+	case status == http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case status >= http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestNewHTTPEvent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/1", strings.NewReader(`{"active":false}`))
+	req.RemoteAddr = "203.0.113.9:4242"
+
+	e := newHTTPEvent(req, "scim", "SCIM PATCH /Users/{id}")
+	assert.Equal(t, "scim", e.actor)
+	assert.Equal(t, "SCIM PATCH /Users/{id}", e.method)
+	assert.Equal(t, "203.0.113.9", e.sourceIP)
+	sum := sha256.Sum256([]byte(`{"active":false}`))
+	assert.Equal(t, sum[:], e.requestDigest)
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"active":false}`, string(body), "the body is put back for the handler")
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	tests := map[int]codes.Code{
+		http.StatusOK:                  codes.OK,
+		http.StatusNoContent:           codes.OK,
+		http.StatusBadRequest:          codes.InvalidArgument,
+		http.StatusNotFound:            codes.NotFound,
+		http.StatusConflict:            codes.AlreadyExists,
+		http.StatusPreconditionFailed:  codes.FailedPrecondition,
+		http.StatusInternalServerError: codes.Internal,
+		http.StatusTeapot:              codes.Unknown,
+	}
+	for status, want := range tests {
+		assert.Equal(t, want, codeFromHTTPStatus(status), status)
+	}
+}
+
+func TestService_HTTPHandler(t *testing.T) {
+	const method = "SCIM DELETE /Users/{id}"
+	insert := regexp.QuoteMeta("INSERT INTO audit_events")
+
+	tests := []struct {
+		name      string
+		handler   func(s *Service) http.HandlerFunc
+		mockSetup func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "recorded by the handler",
+			handler: func(s *Service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					tx, err := s.db.BeginTx(r.Context(), nil)
+					require.NoError(t, err)
+					require.NoError(t, Record(r.Context(), tx, "users/1"))
+					require.NoError(t, tx.Commit())
+					w.WriteHeader(http.StatusNoContent)
+				}
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT hash FROM audit_events").WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectExec(insert).
+					WithArgs(sqlmock.AnyArg(), "scim", method, "users/1", sqlmock.AnyArg(), "OK",
+						"", "192.0.2.1", sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "renamed by the handler",
+			handler: func(s *Service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					SetMethod(r.Context(), method+" userName")
+					w.WriteHeader(http.StatusOK)
+				}
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT hash FROM audit_events").WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectExec(insert).
+					WithArgs(testNow, "scim", method+" userName", "", sqlmock.AnyArg(), "OK",
+						"", "192.0.2.1", sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "failed request is appended on its own",
+			handler: func(*Service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT hash FROM audit_events").WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectExec(insert).
+					WithArgs(testNow, "scim", method, "", sqlmock.AnyArg(), "NotFound",
+						"", "192.0.2.1", sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "audit failure does not fail the request",
+			handler: func(*Service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/scim/v2/Users/1", nil)
+			service.HTTPHandler("scim", method, tt.handler(service)).ServeHTTP(rec, req)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
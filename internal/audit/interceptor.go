@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"net"
+	"net/netip"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ActorMetadataKey is the gRPC metadata key carrying the id of the
+// authenticated caller. It is set by the authenticating proxy in front of
+// the service (and forwarded by the gateway from the HTTP header of the same
+// name), which must strip it from incoming requests.
+const ActorMetadataKey = "x-authenticated-user-id"
+
+// readOnlyPrefixes are the method name prefixes of RPCs that do not change
+// anything and are not audited
+var readOnlyPrefixes = []string{"Get", "List", "Check", "Lookup", "Verify", "Wait"}
+
+// UnaryServerInterceptor returns an interceptor that audits every mutating
+// RPC. Successful calls are expected to call Record in the transaction that
+// makes the change; if a handler does not, the event is appended after the
+// fact in a transaction of its own. Failed calls are always appended on their
+// own, since their transaction, if any, was rolled back.
+func (s *Service) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isMutating(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		e := newEvent(ctx, info.FullMethod, req)
+		resp, err := handler(withEvent(ctx, e), req)
+		if err == nil && e.recorded {
+			return resp, nil
+		}
+
+		// The request context may be what failed the call
+		if recordErr := s.appendOwn(context.WithoutCancel(ctx), e, status.Code(err)); recordErr != nil {
+			s.logger.ErrorContext(ctx, "failed to record audit event",
+				"error", recordErr, "method", info.FullMethod, "outcome", status.Code(err).String())
+		}
+		return resp, err
+	}
+}
+
+// appendOwn appends e in a transaction of its own
+func (s *Service) appendOwn(ctx context.Context, e *event, outcome codes.Code) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(tx, &err)
+
+	if err = appendEvent(ctx, tx, e, outcome, s.now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isMutating reports whether fullMethod (/package.Service/Method) changes state
+func isMutating(fullMethod string) bool {
+	name := path.Base(fullMethod)
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// newEvent describes an incoming call
+func newEvent(ctx context.Context, fullMethod string, req any) *event {
+	e := &event{method: fullMethod, sourceIP: sourceIP(ctx)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ActorMetadataKey); len(values) > 0 {
+			e.actor = values[0]
+		}
+	}
+	if msg, ok := req.(proto.Message); ok {
+		if b, err := (proto.MarshalOptions{Deterministic: true}).Marshal(msg); err == nil {
+			sum := sha256.Sum256(b)
+			e.requestDigest = sum[:]
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		e.traceID = sc.TraceID().String()
+	}
+	return e
+}
+
+// sourceIP returns the address of the caller. Calls relayed by the gateway
+// arrive from loopback; for those the gateway's own client, which it appends
+// to x-forwarded-for, is the caller. Earlier x-forwarded-for entries are set
+// by clients and are not trusted.
+func sourceIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && addr.IsLoopback() {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("x-forwarded-for"); len(values) > 0 {
+				hops := strings.Split(values[len(values)-1], ",")
+				return strings.TrimSpace(hops[len(hops)-1])
+			}
+		}
+	}
+	return host
+}
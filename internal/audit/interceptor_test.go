@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestIsMutating(t *testing.T) {
+	tests := map[string]bool{
+		"/users.v1.UserService/CreateUser":                   true,
+		"/groups.v1.GroupService/RemoveMember":               true,
+		"/invitations.v1.InvitationService/AcceptInvitation": true,
+		"/users.v1.UserService/ListUsers":                    false,
+		"/groups.v1.GroupService/GetGroup":                   false,
+		"/groups.v1.GroupService/CheckMembership":            false,
+		"/permissions.v1.PermissionService/LookupResources":  false,
+		"/audit.v1.AuditService/VerifyAuditChain":            false,
+		"/google.longrunning.Operations/WaitOperation":       false,
+		"/google.longrunning.Operations/CancelOperation":     true,
+	}
+	for method, want := range tests {
+		assert.Equal(t, want, isMutating(method), method)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	req := wrapperspb.String("ada@example.com")
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{1},
+	}))
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ActorMetadataKey, "7"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.9"), Port: 51234}})
+
+	e := newEvent(ctx, "/users.v1.UserService/CreateUser", req)
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	require.NoError(t, err)
+	digest := sha256.Sum256(b)
+	assert.Equal(t, "7", e.actor)
+	assert.Equal(t, "/users.v1.UserService/CreateUser", e.method)
+	assert.Equal(t, digest[:], e.requestDigest)
+	assert.Equal(t, traceID.String(), e.traceID)
+	assert.Equal(t, "203.0.113.9", e.sourceIP)
+}
+
+func TestSourceIP(t *testing.T) {
+	tests := []struct {
+		name string
+		addr net.Addr
+		xff  []string
+		want string
+	}{
+		{name: "direct client", addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.9"), Port: 1}, want: "203.0.113.9"},
+		{name: "direct client with spoofed header", addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.9"), Port: 1}, xff: []string{"10.0.0.1"}, want: "203.0.113.9"},
+		{name: "via gateway", addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1}, xff: []string{"198.51.100.4"}, want: "198.51.100.4"},
+		{name: "via gateway with client header", addr: &net.TCPAddr{IP: net.ParseIP("::1"), Port: 1}, xff: []string{"10.0.0.1, 198.51.100.4"}, want: "198.51.100.4"},
+		{name: "loopback without gateway", addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1}, want: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.addr})
+			if tt.xff != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.MD{"x-forwarded-for": tt.xff})
+			}
+			assert.Equal(t, tt.want, sourceIP(ctx))
+		})
+	}
+	assert.Equal(t, "", sourceIP(context.Background()))
+}
+
+func TestService_UnaryServerInterceptor(t *testing.T) {
+	const method = "/groups.v1.GroupService/CreateGroup"
+	insert := regexp.QuoteMeta("INSERT INTO audit_events")
+
+	tests := []struct {
+		name      string
+		method    string
+		handler   func(s *Service) grpc.UnaryHandler
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantErr   codes.Code
+	}{
+		{
+			name:   "recorded by the handler",
+			method: method,
+			handler: func(s *Service) grpc.UnaryHandler {
+				return func(ctx context.Context, req any) (any, error) {
+					tx, err := s.db.BeginTx(ctx, nil)
+					if err != nil {
+						return nil, err
+					}
+					if err := Record(ctx, tx, "groups/1"); err != nil {
+						return nil, err
+					}
+					return "ok", tx.Commit()
+				}
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT hash FROM audit_events").WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectExec(insert).
+					WithArgs(sqlmock.AnyArg(), "7", method, "groups/1", sqlmock.AnyArg(), "OK",
+						"", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "failed call is appended on its own",
+			method: method,
+			handler: func(*Service) grpc.UnaryHandler {
+				return func(context.Context, any) (any, error) {
+					return nil, status.Error(codes.AlreadyExists, "group exists")
+				}
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT hash FROM audit_events").WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectExec(insert).
+					WithArgs(testNow, "7", method, "", sqlmock.AnyArg(), "AlreadyExists",
+						"", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: codes.AlreadyExists,
+		},
+		{
+			name:   "handler that does not record is appended afterwards",
+			method: method,
+			handler: func(*Service) grpc.UnaryHandler {
+				return func(context.Context, any) (any, error) { return "ok", nil }
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT hash FROM audit_events").WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+				mock.ExpectExec(insert).
+					WithArgs(testNow, "7", method, "", sqlmock.AnyArg(), "OK",
+						"", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "audit failure does not fail the call",
+			method: method,
+			handler: func(*Service) grpc.UnaryHandler {
+				return func(context.Context, any) (any, error) { return "ok", nil }
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(context.DeadlineExceeded)
+			},
+		},
+		{
+			name:   "read-only call is not audited",
+			method: "/groups.v1.GroupService/ListGroups",
+			handler: func(*Service) grpc.UnaryHandler {
+				return func(context.Context, any) (any, error) { return "ok", nil }
+			},
+			mockSetup: func(sqlmock.Sqlmock) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ActorMetadataKey, "7"))
+			_, err := service.UnaryServerInterceptor()(ctx, wrapperspb.String("req"),
+				&grpc.UnaryServerInfo{FullMethod: tt.method}, tt.handler(service))
+
+			if tt.wantErr != codes.OK {
+				assert.Equal(t, tt.wantErr, status.Code(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
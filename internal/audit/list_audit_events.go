@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	auditpb "github.com/zcking/go-api-template/gen/go/audit/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAuditEvents retrieves a page of audit events matching the request
+// filters, ordered by id (oldest first)
+func (s *Service) ListAuditEvents(ctx context.Context, req *auditpb.ListAuditEventsRequest) (*auditpb.ListAuditEventsResponse, error) {
+	afterID, err := pagination.DecodeToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+	if req.GetStartTime() != nil && req.GetEndTime() != nil &&
+		!req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		return nil, status.Error(codes.InvalidArgument, "start_time must be before end_time")
+	}
+
+	// Build the WHERE clause from the filters that are set
+	conditions := []string{"id > $1"}
+	args := []any{afterID}
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	for _, filter := range []struct {
+		column string
+		value  string
+	}{
+		{"actor", req.GetActor()},
+		{"method", req.GetMethod()},
+		{"resource", req.GetResource()},
+		{"outcome", req.GetOutcome()},
+	} {
+		if filter.value != "" {
+			addCondition(filter.column+" = $%d", filter.value)
+		}
+	}
+	if req.GetStartTime() != nil {
+		addCondition("created_at >= $%d", req.GetStartTime().AsTime())
+	}
+	if req.GetEndTime() != nil {
+		addCondition("created_at < $%d", req.GetEndTime().AsTime())
+	}
+	args = append(args, pageSize+1)
+
+	// Fetch one extra row to find out whether there is a next page
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM audit_events WHERE %s ORDER BY id LIMIT $%d",
+		eventColumns, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*auditpb.AuditEvent, 0)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e.toProto())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	resp := &auditpb.ListAuditEventsResponse{AuditEvents: events}
+	if len(events) > pageSize {
+		resp.AuditEvents = events[:pageSize]
+		resp.NextPageToken = pagination.NextToken(len(events), pageSize, events[pageSize-1].Id)
+	}
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auditpb "github.com/zcking/go-api-template/gen/go/audit/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestService_ListAuditEvents(t *testing.T) {
+	selectEvents := regexp.QuoteMeta("SELECT " + eventColumns + " FROM audit_events")
+
+	t.Run("success - first page with next page token", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(selectEvents+regexp.QuoteMeta(" WHERE id > $1 ORDER BY id LIMIT $2")).
+			WithArgs(int64(0), 3).
+			WillReturnRows(eventRows(chain(3)...))
+
+		resp, err := service.ListAuditEvents(context.Background(), &auditpb.ListAuditEventsRequest{PageSize: 2})
+		require.NoError(t, err)
+		require.Len(t, resp.AuditEvents, 2)
+		assert.Equal(t, int64(2), resp.AuditEvents[1].Id)
+		assert.Equal(t, pagination.EncodeToken(2), resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - filters", func(t *testing.T) {
+		service, mock := newMockService(t)
+		start, end := testNow, testNow.Add(time.Hour)
+		mock.ExpectQuery(selectEvents+regexp.QuoteMeta(" WHERE id > $1 AND actor = $2 AND resource = $3 AND outcome = $4"+
+			" AND created_at >= $5 AND created_at < $6 ORDER BY id LIMIT $7")).
+			WithArgs(int64(2), "7", "groups/1", "OK", start, end, 51).
+			WillReturnRows(eventRows(chain(3)[2]))
+
+		resp, err := service.ListAuditEvents(context.Background(), &auditpb.ListAuditEventsRequest{
+			PageToken: pagination.EncodeToken(2),
+			Actor:     "7",
+			Resource:  "groups/1",
+			Outcome:   "OK",
+			StartTime: timestamppb.New(start),
+			EndTime:   timestamppb.New(end),
+		})
+		require.NoError(t, err)
+		assert.Len(t, resp.AuditEvents, 1)
+		assert.Empty(t, resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invalid page token", func(t *testing.T) {
+		service, mock := newMockService(t)
+
+		_, err := service.ListAuditEvents(context.Background(), &auditpb.ListAuditEventsRequest{PageToken: "!"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - start time not before end time", func(t *testing.T) {
+		service, mock := newMockService(t)
+
+		_, err := service.ListAuditEvents(context.Background(), &auditpb.ListAuditEventsRequest{
+			StartTime: timestamppb.New(testNow),
+			EndTime:   timestamppb.New(testNow),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - database error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(selectEvents).WillReturnError(errors.New("database error"))
+
+		_, err := service.ListAuditEvents(context.Background(), &auditpb.ListAuditEventsRequest{})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+
+	"github.com/zcking/go-api-template/internal/txn"
+)
+
+// chainLockKey is the transaction-level advisory lock held while appending
+// to the audit log. Appends are serialized so each event links to the one
+// committed before it: the lock is held from the append until the
+// transaction commits, so audited writes across all instances are bounded
+// by one over that time (see the README). appendTo does its work before
+// taking it, and callers record right before committing.
+const chainLockKey int64 = 0x6175646974 // "audit"
+
+// event is the audit event of the RPC or HTTP request in progress. The
+// interceptor fills in everything about the call; the service sets the
+// resource when it records the change.
+type event struct {
+	actor         string
+	method        string
+	resource      string
+	requestDigest []byte
+	traceID       string
+	sourceIP      string
+	recorded      bool
+	// recordedIn is the transaction the event was recorded in
+	recordedIn appendTx
+}
+
+type eventKey struct{}
+
+func withEvent(ctx context.Context, e *event) context.Context {
+	return context.WithValue(ctx, eventKey{}, e)
+}
+
+func eventFromContext(ctx context.Context) *event {
+	e, _ := ctx.Value(eventKey{}).(*event)
+	return e
+}
+
+// Record appends the audit event of the current RPC within tx, the
+// transaction that makes the change, so the change and its audit event are
+// committed or rolled back together. resources name what was changed, e.g.
+// "users/42"; a change to several resources, such as accepting an
+// invitation that creates a user, appends one event for each. Call it once
+// per RPC, after the last write and right before committing: it holds the
+// audit log lock until tx ends.
+//
+// tx must be READ COMMITTED, the default: the head of the chain is read
+// once the lock is held, which under REPEATABLE READ or SERIALIZABLE would
+// still see the snapshot taken by the transaction's first statement and
+// could miss events committed since, forking the chain. Record fails in a
+// transaction begun by txn.Manager.DoWith with a stricter isolation level;
+// transactions begun otherwise, or under a changed
+// default_transaction_isolation, are not checked.
+//
+// Record does nothing outside of an RPC intercepted by
+// Service.UnaryServerInterceptor (or a request wrapped by
+// Service.HTTPHandler), e.g. in unit tests, or when the event was already
+// recorded in tx. Called again in another transaction, such as the next
+// attempt of one retried by txn.Manager, it records the event there.
+func Record(ctx context.Context, tx *sql.Tx, resources ...string) error {
+	switch level := txn.Isolation(ctx); level {
+	case sql.LevelDefault, sql.LevelReadUncommitted, sql.LevelReadCommitted:
+	default:
+		return fmt.Errorf("audit events must be recorded under READ COMMITTED, not %s", level)
+	}
+	return record(ctx, sqlTx{tx}, resources)
+}
+
+// RecordPgx is Record for a pgx transaction, which must be READ COMMITTED
+// too
+func RecordPgx(ctx context.Context, tx pgx.Tx, resources ...string) error {
+	return record(ctx, pgxTx{tx}, resources)
+}
+
+func record(ctx context.Context, tx appendTx, resources []string) error {
+	e := eventFromContext(ctx)
+	if e == nil || e.recordedIn == tx {
+		return nil
+	}
+	now := time.Now()
+	for _, resource := range resources {
+		e.resource = resource
+		if err := appendTo(ctx, tx, e, codes.OK, now); err != nil {
+			return err
+		}
+	}
+	e.recorded, e.recordedIn = true, tx
+	return nil
+}
+
+// appendTx is the part of a transaction that events are appended with, so
+// they can be appended within database/sql and pgx transactions alike
+type appendTx interface {
+	exec(ctx context.Context, query string, args ...any) error
+	queryRow(ctx context.Context, query string, args ...any) interface{ Scan(dest ...any) error }
+}
+
+type sqlTx struct{ tx *sql.Tx }
+
+func (t sqlTx) exec(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (t sqlTx) queryRow(ctx context.Context, query string, args ...any) interface{ Scan(dest ...any) error } {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+type pgxTx struct{ tx pgx.Tx }
+
+func (t pgxTx) exec(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.Exec(ctx, query, args...)
+	return err
+}
+
+func (t pgxTx) queryRow(ctx context.Context, query string, args ...any) interface{ Scan(dest ...any) error } {
+	return t.tx.QueryRow(ctx, query, args...)
+}
+
+// appendEvent inserts e with the given outcome at the end of the hash chain
+func appendEvent(ctx context.Context, tx *sql.Tx, e *event, outcome codes.Code, now time.Time) error {
+	return appendTo(ctx, sqlTx{tx}, e, outcome, now)
+}
+
+// appendTo builds the row before locking the chain, so only reading the head
+// and inserting happen under the lock. The head is read in its own
+// statement, whose snapshot is taken once the lock is held.
+func appendTo(ctx context.Context, tx appendTx, e *event, outcome codes.Code, now time.Time) error {
+	// Postgres stores microseconds; truncate so the hash can be recomputed
+	// from the stored value
+	row := eventRow{
+		createdAt:     now.UTC().Truncate(time.Microsecond),
+		actor:         e.actor,
+		method:        e.method,
+		resource:      e.resource,
+		requestDigest: e.requestDigest,
+		outcome:       outcome.String(),
+		traceID:       e.traceID,
+		sourceIP:      e.sourceIP,
+	}
+	row.commitFields()
+
+	if err := tx.exec(ctx, "SELECT pg_advisory_xact_lock($1)", chainLockKey); err != nil {
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	var prevHash []byte
+	err := tx.queryRow(ctx, "SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1").Scan(&prevHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		prevHash = genesisHash
+	} else if err != nil {
+		return fmt.Errorf("failed to read audit log head: %w", err)
+	}
+
+	err = tx.exec(ctx, `INSERT INTO audit_events
+		(created_at, actor, method, resource, request_digest, outcome, trace_id, source_ip, prev_hash, hash,
+		 request_digest_salt, request_digest_commitment, source_ip_salt, source_ip_commitment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		row.createdAt, row.actor, row.method, row.resource, row.requestDigest, row.outcome,
+		row.traceID, row.sourceIP, prevHash, chainHash(prevHash, &row),
+		row.requestDigestSalt, row.requestDigestCommitment, row.sourceIPSalt, row.sourceIPCommitment)
+	if err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+	return nil
+}
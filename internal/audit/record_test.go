@@ -0,0 +1,240 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/zcking/go-api-template/internal/txn"
+)
+
+// captured records the value of a query argument
+type captured struct{ value driver.Value }
+
+func (c *captured) Match(v driver.Value) bool {
+	c.value = v
+	return true
+}
+
+// appended holds the captured arguments of an appended event that are not
+// known in advance
+type appended struct {
+	createdAt, hash                        captured
+	requestDigestSalt, requestDigestCommit captured
+	sourceIPSalt, sourceIPCommit           captured
+}
+
+// row returns the stored row of the appended event e
+func (a *appended) row(e *event, outcome string) *eventRow {
+	return &eventRow{
+		createdAt:               a.createdAt.value.(time.Time),
+		actor:                   e.actor,
+		method:                  e.method,
+		resource:                e.resource,
+		requestDigest:           e.requestDigest,
+		outcome:                 outcome,
+		traceID:                 e.traceID,
+		sourceIP:                e.sourceIP,
+		requestDigestSalt:       a.requestDigestSalt.value.([]byte),
+		requestDigestCommitment: a.requestDigestCommit.value.([]byte),
+		sourceIPSalt:            a.sourceIPSalt.value.([]byte),
+		sourceIPCommitment:      a.sourceIPCommit.value.([]byte),
+	}
+}
+
+// expectAppend expects an event to be appended after head, the hash of the
+// last event in the log (nil for an empty log). It returns the captured
+// arguments.
+func expectAppend(mock sqlmock.Sqlmock, head []byte, e *event, outcome string) *appended {
+	a := &appended{}
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WithArgs(chainLockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	headRows := sqlmock.NewRows([]string{"hash"})
+	if head != nil {
+		headRows.AddRow(head)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1")).
+		WillReturnRows(headRows)
+	prevHash := head
+	if prevHash == nil {
+		prevHash = genesisHash
+	}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_events")).
+		WithArgs(&a.createdAt, e.actor, e.method, e.resource, e.requestDigest, outcome,
+			e.traceID, e.sourceIP, prevHash, &a.hash,
+			&a.requestDigestSalt, &a.requestDigestCommit, &a.sourceIPSalt, &a.sourceIPCommit).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	return a
+}
+
+func testEvent() *event {
+	return &event{
+		actor:         "7",
+		method:        "/users.v1.UserService/CreateUser",
+		requestDigest: []byte{1, 2, 3},
+		traceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		sourceIP:      "203.0.113.9",
+	}
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("appends to the chain within the transaction", func(t *testing.T) {
+		service, mock := newMockService(t)
+		e := testEvent()
+		head := chain(1)[0].hash
+
+		mock.ExpectBegin()
+		expected := *e
+		expected.resource = "users/42"
+		a := expectAppend(mock, head, &expected, "OK")
+		mock.ExpectCommit()
+
+		ctx := withEvent(context.Background(), e)
+		tx, err := service.db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		require.NoError(t, Record(ctx, tx, "users/42"))
+		// A second call in the same RPC does nothing
+		require.NoError(t, Record(ctx, tx, "users/43"))
+		require.NoError(t, tx.Commit())
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		assert.True(t, e.recorded)
+		stored := a.row(&expected, "OK")
+		stored.prevHash, stored.hash = head, a.hash.value.([]byte)
+		assert.Equal(t, stored.createdAt, stored.createdAt.Truncate(time.Microsecond))
+		assert.True(t, stored.intact(), "stored hash must be recomputable from the row")
+
+		redact(stored)
+		assert.True(t, stored.intact(), "and still once the row is redacted")
+	})
+
+	t.Run("recorded again in a retried transaction", func(t *testing.T) {
+		service, mock := newMockService(t)
+		e := testEvent()
+		expected := *e
+		expected.resource = "users/42"
+
+		mock.ExpectBegin()
+		expectAppend(mock, nil, &expected, "OK")
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		expectAppend(mock, nil, &expected, "OK")
+		mock.ExpectCommit()
+
+		ctx := withEvent(context.Background(), e)
+		first, err := service.db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		require.NoError(t, Record(ctx, first, "users/42"))
+		require.NoError(t, first.Rollback())
+		retry, err := service.db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		require.NoError(t, Record(ctx, retry, "users/42"))
+		require.NoError(t, retry.Commit())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("first event links to the genesis hash", func(t *testing.T) {
+		service, mock := newMockService(t)
+		e := testEvent()
+		e.resource = "users/1"
+
+		mock.ExpectBegin()
+		expectAppend(mock, nil, e, "NotFound")
+		mock.ExpectCommit()
+
+		tx, err := service.db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, appendEvent(context.Background(), tx, e, codes.NotFound, testNow))
+		require.NoError(t, tx.Commit())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no-op outside an audited RPC", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		tx, err := service.db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, Record(context.Background(), tx, "users/42"))
+		require.NoError(t, tx.Commit())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - stricter isolation than READ COMMITTED", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		ctx := withEvent(context.Background(), testEvent())
+		err := txn.NewManager(service.db, slog.New(slog.NewTextHandler(io.Discard, nil))).DoWith(ctx,
+			&sql.TxOptions{Isolation: sql.LevelSerializable},
+			func(ctx context.Context) error {
+				tx, _ := txn.Tx(ctx)
+				return Record(ctx, tx, "users/42")
+			})
+		assert.ErrorContains(t, err, "audit events must be recorded under READ COMMITTED, not Serializable")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		e := testEvent()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_events")).
+			WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_events")).
+			WillReturnError(errors.New("database error"))
+
+		ctx := withEvent(context.Background(), e)
+		tx, err := service.db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		assert.Error(t, Record(ctx, tx, "users/42"))
+		assert.False(t, e.recorded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecordPgx(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	e := testEvent()
+	head := chain(1)[0].hash
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WithArgs(chainLockKey).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1")).
+		WillReturnRows(pgxmock.NewRows([]string{"hash"}).AddRow(head))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_events")).
+		WithArgs(pgxmock.AnyArg(), e.actor, e.method, "users/42", e.requestDigest, "OK",
+			e.traceID, e.sourceIP, head, pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	ctx := withEvent(context.Background(), e)
+	tx, err := mock.Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, RecordPgx(ctx, tx, "users/42"))
+	require.NoError(t, tx.Commit(ctx))
+	assert.True(t, e.recorded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
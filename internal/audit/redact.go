@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// RedactUser blanks the personal fields of the audit events of an erased
+// user within tx. The request digest is cleared on every event made by the
+// user or about one of their resources, and the source IP on the events the
+// user made, each with the salt of its commitment. The events themselves,
+// including the user's id as actor, are kept and still match their hashes. It returns the number of events redacted.
+func RedactUser(ctx context.Context, tx *sql.Tx, userID int64) (int64, error) {
+	actor := strconv.FormatInt(userID, 10)
+	resource := fmt.Sprintf("users/%d", userID)
+	result, err := tx.ExecContext(ctx,
+		`UPDATE audit_events
+		 SET request_digest = '', request_digest_salt = NULL,
+		     source_ip = CASE WHEN actor = $1 THEN '' ELSE source_ip END,
+		     source_ip_salt = CASE WHEN actor = $1 THEN NULL ELSE source_ip_salt END,
+		     redacted_at = now()
+		 WHERE redacted_at IS NULL AND (actor = $1 OR resource = $2 OR resource LIKE '%/' || $2)`,
+		actor, resource)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
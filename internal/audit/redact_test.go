@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactUser(t *testing.T) {
+	update := regexp.QuoteMeta("UPDATE audit_events")
+
+	t.Run("success", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(update+`\s+SET request_digest = '', request_digest_salt = NULL,`+
+			`\s+source_ip = CASE WHEN actor = \$1 THEN '' ELSE source_ip END,`+
+			`\s+source_ip_salt = CASE WHEN actor = \$1 THEN NULL ELSE source_ip_salt END`).
+			WithArgs("42", "users/42").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		tx, err := service.db.Begin()
+		require.NoError(t, err)
+		n, err := RedactUser(t.Context(), tx, 42)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, int64(3), n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(update).WillReturnError(errors.New("database error"))
+
+		tx, err := service.db.Begin()
+		require.NoError(t, err)
+		_, err = RedactUser(t.Context(), tx, 42)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	auditpb "github.com/zcking/go-api-template/gen/go/audit/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventColumns is the column list scanned by scanEvent
+const eventColumns = "id, created_at, actor, method, resource, request_digest, outcome, trace_id, source_ip, prev_hash, hash, redacted_at, " +
+	"request_digest_salt, request_digest_commitment, source_ip_salt, source_ip_commitment"
+
+// genesisHash is the previous hash of the first event in the chain
+var genesisHash = make([]byte, sha256.Size)
+
+// Service handles gRPC requests for reading the audit log
+type Service struct {
+	auditpb.UnimplementedAuditServiceServer
+	db     *sql.DB
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new audit service using an existing database connection
+func NewService(db *sql.DB, logger *slog.Logger) *Service {
+	return &Service{
+		db:     db,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// eventRow is an audit_events row
+type eventRow struct {
+	id            int64
+	createdAt     time.Time
+	actor         string
+	method        string
+	resource      string
+	requestDigest []byte
+	outcome       string
+	traceID       string
+	sourceIP      string
+	prevHash      []byte
+	hash          []byte
+	redactedAt    sql.NullTime
+	// Salted commitments to requestDigest and sourceIP (see commit), which
+	// the hash covers instead of the values so that it can still be checked
+	// once redaction has cleared a value and its salt. Events recorded before
+	// commitments were introduced have none.
+	requestDigestSalt       []byte
+	requestDigestCommitment []byte
+	sourceIPSalt            []byte
+	sourceIPCommitment      []byte
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanEvent scans a row of eventColumns
+func scanEvent(row rowScanner) (*eventRow, error) {
+	var e eventRow
+	err := row.Scan(&e.id, &e.createdAt, &e.actor, &e.method, &e.resource, &e.requestDigest,
+		&e.outcome, &e.traceID, &e.sourceIP, &e.prevHash, &e.hash, &e.redactedAt,
+		&e.requestDigestSalt, &e.requestDigestCommitment, &e.sourceIPSalt, &e.sourceIPCommitment)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// toProto converts an audit_events row into an AuditEvent
+func (e *eventRow) toProto() *auditpb.AuditEvent {
+	pb := &auditpb.AuditEvent{
+		Id:            e.id,
+		CreateTime:    timestamppb.New(e.createdAt),
+		Actor:         e.actor,
+		Method:        e.method,
+		Resource:      e.resource,
+		RequestDigest: hex.EncodeToString(e.requestDigest),
+		Outcome:       e.outcome,
+		TraceId:       e.traceID,
+		SourceIp:      e.sourceIP,
+		PreviousHash:  hex.EncodeToString(e.prevHash),
+		Hash:          hex.EncodeToString(e.hash),
+	}
+	if e.redactedAt.Valid {
+		pb.RedactTime = timestamppb.New(e.redactedAt.Time)
+	}
+	return pb
+}
+
+// saltSize is the size of the salt of a commitment
+const saltSize = 16
+
+// commit returns the commitment to value with salt
+func commit(salt, value []byte) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(value)
+	return h.Sum(nil)
+}
+
+// commitFields salts and commits to the request digest and source IP of e
+func (e *eventRow) commitFields() {
+	e.requestDigestSalt, e.sourceIPSalt = make([]byte, saltSize), make([]byte, saltSize)
+	_, _ = rand.Read(e.requestDigestSalt) // never fails
+	_, _ = rand.Read(e.sourceIPSalt)
+	e.requestDigestCommitment = commit(e.requestDigestSalt, e.requestDigest)
+	e.sourceIPCommitment = commit(e.sourceIPSalt, []byte(e.sourceIP))
+}
+
+// committed reports whether e was recorded with commitments
+func (e *eventRow) committed() bool {
+	return e.requestDigestCommitment != nil
+}
+
+// intact reports whether the hash of e matches its contents. The request
+// digest and source IP must open their commitments, unless redaction cleared
+// them with their salts. An unverifiable event is never intact.
+func (e *eventRow) intact() bool {
+	if !e.committed() {
+		return !e.redactedAt.Valid && bytes.Equal(e.hash, chainHash(e.prevHash, e))
+	}
+	return opens(e.requestDigestCommitment, e.requestDigestSalt, e.requestDigest, e.redactedAt.Valid) &&
+		opens(e.sourceIPCommitment, e.sourceIPSalt, []byte(e.sourceIP), e.redactedAt.Valid) &&
+		bytes.Equal(e.hash, chainHash(e.prevHash, e))
+}
+
+// unverifiable reports whether e was recorded without commitments and then
+// redacted: its hash covered the cleared fields themselves, so nothing is left
+// to check it against
+func (e *eventRow) unverifiable() bool {
+	return !e.committed() && e.redactedAt.Valid
+}
+
+// opens reports whether salt and value open commitment, or were both
+// cleared by redaction
+func opens(commitment, salt, value []byte, redacted bool) bool {
+	if salt == nil {
+		return redacted && len(value) == 0
+	}
+	return bytes.Equal(commitment, commit(salt, value))
+}
+
+// chainHash computes the hash of an event from the previous event's hash and
+// the event's fields, with the commitments in place of the request digest and
+// source IP when it has them. Each field is length-prefixed so that moving
+// bytes between adjacent fields changes the hash. The id is not covered: it
+// comes from a sequence and only orders the chain.
+func chainHash(prevHash []byte, e *eventRow) []byte {
+	requestDigest, sourceIP := e.requestDigest, []byte(e.sourceIP)
+	if e.committed() {
+		requestDigest, sourceIP = e.requestDigestCommitment, e.sourceIPCommitment
+	}
+	h := sha256.New()
+	h.Write(prevHash)
+	for _, field := range [][]byte{
+		[]byte(e.createdAt.UTC().Format(time.RFC3339Nano)),
+		[]byte(e.actor),
+		[]byte(e.method),
+		[]byte(e.resource),
+		requestDigest,
+		[]byte(e.outcome),
+		[]byte(e.traceID),
+		sourceIP,
+	} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		h.Write(length[:])
+		h.Write(field)
+	}
+	return h.Sum(nil)
+}
+
+// rollback rolls back tx if err is set. It is meant to be deferred with a
+// pointer to the caller's named error result.
+func rollback(tx *sql.Tx, err *error) {
+	if *err != nil {
+		_ = tx.Rollback()
+	}
+}
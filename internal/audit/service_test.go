@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newMockService creates a Service backed by go-sqlmock whose clock is fixed at testNow
+func newMockService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	service := NewService(db, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	service.now = func() time.Time { return testNow }
+	return service, mock
+}
+
+// eventColumnNames are the columns of eventColumns, for mocked rows
+var eventColumnNames = []string{"id", "created_at", "actor", "method", "resource", "request_digest",
+	"outcome", "trace_id", "source_ip", "prev_hash", "hash", "redacted_at",
+	"request_digest_salt", "request_digest_commitment", "source_ip_salt", "source_ip_commitment"}
+
+// chain builds a valid hash chain of n events
+func chain(n int) []*eventRow {
+	events := make([]*eventRow, 0, n)
+	prev := genesisHash
+	for i := range n {
+		e := &eventRow{
+			id:            int64(i + 1),
+			createdAt:     testNow.Add(time.Duration(i) * time.Minute),
+			actor:         "7",
+			method:        "/groups.v1.GroupService/CreateGroup",
+			resource:      "groups/1",
+			requestDigest: []byte{byte(i)},
+			outcome:       "OK",
+			sourceIP:      "203.0.113.9",
+			prevHash:      prev,
+		}
+		e.commitFields()
+		e.hash = chainHash(prev, e)
+		prev = e.hash
+		events = append(events, e)
+	}
+	return events
+}
+
+// relink recomputes the links and hashes of events after they were changed
+func relink(events []*eventRow) []*eventRow {
+	prev := genesisHash
+	for _, e := range events {
+		e.prevHash = prev
+		e.hash = chainHash(prev, e)
+		prev = e.hash
+	}
+	return events
+}
+
+// uncommitted clears the commitments of e, as if it was recorded before
+// they were introduced
+func uncommitted(e *eventRow) {
+	e.requestDigestSalt, e.requestDigestCommitment = nil, nil
+	e.sourceIPSalt, e.sourceIPCommitment = nil, nil
+}
+
+// redact clears the request digest and source IP of e as RedactUser does
+func redact(e *eventRow) {
+	e.requestDigest, e.requestDigestSalt = []byte{}, nil
+	e.sourceIP, e.sourceIPSalt = "", nil
+	e.redactedAt = sql.NullTime{Time: testNow, Valid: true}
+}
+
+// eventRows returns mocked rows for events
+func eventRows(events ...*eventRow) *sqlmock.Rows {
+	rows := sqlmock.NewRows(eventColumnNames)
+	for _, e := range events {
+		var redactedAt any
+		if e.redactedAt.Valid {
+			redactedAt = e.redactedAt.Time
+		}
+		rows.AddRow(e.id, e.createdAt, e.actor, e.method, e.resource, e.requestDigest,
+			e.outcome, e.traceID, e.sourceIP, e.prevHash, e.hash, redactedAt,
+			e.requestDigestSalt, e.requestDigestCommitment, e.sourceIPSalt, e.sourceIPCommitment)
+	}
+	return rows
+}
+
+func TestChainHash(t *testing.T) {
+	base := chain(1)[0]
+	hash := chainHash(genesisHash, base)
+	assert.Len(t, hash, 32)
+	assert.Equal(t, hash, chainHash(genesisHash, base), "hash must be deterministic")
+
+	// The same instant in another time zone hashes the same
+	local := *base
+	local.createdAt = base.createdAt.In(time.FixedZone("UTC+2", 2*60*60))
+	assert.Equal(t, hash, chainHash(genesisHash, &local))
+
+	changes := map[string]func(e *eventRow){
+		"created_at": func(e *eventRow) { e.createdAt = e.createdAt.Add(time.Microsecond) },
+		"actor":      func(e *eventRow) { e.actor = "8" },
+		"method":     func(e *eventRow) { e.method = "/groups.v1.GroupService/DeleteGroup" },
+		"resource":   func(e *eventRow) { e.resource = "groups/2" },
+		"digest":     func(e *eventRow) { e.requestDigestCommitment = commit(e.requestDigestSalt, []byte{9}) },
+		"outcome":    func(e *eventRow) { e.outcome = "NotFound" },
+		"trace id":   func(e *eventRow) { e.traceID = "abc" },
+		"source ip":  func(e *eventRow) { e.sourceIPCommitment = commit(e.sourceIPSalt, []byte("198.51.100.1")) },
+		// moving bytes between adjacent fields
+		"field boundary": func(e *eventRow) { e.actor, e.method = e.actor+e.method[:1], e.method[1:] },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			changed := *base
+			change(&changed)
+			assert.NotEqual(t, hash, chainHash(genesisHash, &changed))
+		})
+	}
+
+	assert.NotEqual(t, hash, chainHash(bytes.Repeat([]byte{1}, 32), base), "hash must cover the previous hash")
+
+	// Without commitments the hash covers the values themselves
+	legacy := *base
+	uncommitted(&legacy)
+	legacyHash := chainHash(genesisHash, &legacy)
+	for name, change := range map[string]func(e *eventRow){
+		"digest":    func(e *eventRow) { e.requestDigest = []byte{9} },
+		"source ip": func(e *eventRow) { e.sourceIP = "198.51.100.1" },
+	} {
+		t.Run("without commitments/"+name, func(t *testing.T) {
+			changed := legacy
+			change(&changed)
+			assert.NotEqual(t, legacyHash, chainHash(genesisHash, &changed))
+		})
+	}
+}
+
+func TestEventRow_Intact(t *testing.T) {
+	e := chain(1)[0]
+	assert.True(t, e.intact())
+	assert.NotEqual(t, e.requestDigestSalt, e.sourceIPSalt, "each field has its own salt")
+
+	redacted := *e
+	redact(&redacted)
+	assert.True(t, redacted.intact(), "redaction keeps the hash checkable")
+
+	changed := *e
+	changed.requestDigest = []byte{9}
+	assert.False(t, changed.intact(), "a digest that does not open its commitment")
+
+	cleared := *e
+	cleared.sourceIP, cleared.sourceIPSalt = "", nil
+	assert.False(t, cleared.intact(), "only a redacted event may clear its fields")
+
+	rewritten := redacted
+	rewritten.actor = "8"
+	assert.False(t, rewritten.intact(), "the rest of a redacted event is still covered")
+	assert.False(t, redacted.unverifiable())
+
+	legacy := *e
+	uncommitted(&legacy)
+	relink([]*eventRow{&legacy})
+	assert.True(t, legacy.intact())
+	assert.False(t, legacy.unverifiable())
+
+	redact(&legacy)
+	assert.False(t, legacy.intact(), "nothing is left to check the hash against")
+	assert.True(t, legacy.unverifiable())
+}
+
+func TestEventRow_ToProto(t *testing.T) {
+	e := chain(1)[0]
+	pb := e.toProto()
+	assert.Equal(t, int64(1), pb.Id)
+	assert.Equal(t, "00", pb.RequestDigest)
+	assert.Equal(t, hex.EncodeToString(genesisHash), pb.PreviousHash)
+	assert.Equal(t, hex.EncodeToString(e.hash), pb.Hash)
+	assert.True(t, testNow.Equal(pb.CreateTime.AsTime()))
+	assert.Nil(t, pb.RedactTime)
+
+	e.redactedAt = sql.NullTime{Time: testNow, Valid: true}
+	assert.True(t, testNow.Equal(e.toProto().RedactTime.AsTime()))
+}
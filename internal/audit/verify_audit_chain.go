@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+
+	auditpb "github.com/zcking/go-api-template/gen/go/audit/v1"
+)
+
+// VerifyAuditChain walks the audit log in order and checks that every event
+// links to the hash of the event before it and that its own hash matches its
+// contents, redacted or not (see eventRow.intact). It detects rewritten and
+// deleted events; events removed from the end of the log leave a valid,
+// shorter chain. Events recorded without commitments must all come before
+// the first one with them. Those that were later redacted cannot be checked:
+// only their link is, and they are counted as unverifiable rather than
+// invalidating the chain.
+func (s *Service) VerifyAuditChain(ctx context.Context, req *auditpb.VerifyAuditChainRequest) (*auditpb.VerifyAuditChainResponse, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+eventColumns+" FROM audit_events ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp := &auditpb.VerifyAuditChainResponse{Valid: true}
+	prevHash := genesisHash
+	committed := false
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		resp.CheckedCount++
+		if e.redactedAt.Valid {
+			resp.RedactedCount++
+		}
+		if e.unverifiable() {
+			resp.UnverifiableCount++
+		}
+		if !bytes.Equal(e.prevHash, prevHash) || (committed && !e.committed()) || (!e.unverifiable() && !e.intact()) {
+			resp.Valid = false
+			resp.FirstInvalidId = e.id
+			s.logger.WarnContext(ctx, "audit chain verification failed", "event_id", e.id)
+			return resp, nil
+		}
+		prevHash = e.hash
+		committed = committed || e.committed()
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,195 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auditpb "github.com/zcking/go-api-template/gen/go/audit/v1"
+)
+
+func TestService_VerifyAuditChain(t *testing.T) {
+	selectEvents := regexp.QuoteMeta("SELECT " + eventColumns + " FROM audit_events ORDER BY id")
+
+	tests := []struct {
+		name             string
+		events           func() []*eventRow
+		wantValid        bool
+		wantChecked      int64
+		wantInvalid      int64
+		wantRedacted     int64
+		wantUnverifiable int64
+	}{
+		{
+			name:        "empty log",
+			events:      func() []*eventRow { return nil },
+			wantValid:   true,
+			wantChecked: 0,
+		},
+		{
+			name:        "valid chain",
+			events:      func() []*eventRow { return chain(3) },
+			wantValid:   true,
+			wantChecked: 3,
+		},
+		{
+			name: "rewritten event",
+			events: func() []*eventRow {
+				events := chain(3)
+				events[1].actor = "8"
+				return events
+			},
+			wantValid:   false,
+			wantChecked: 2,
+			wantInvalid: 2,
+		},
+		{
+			name: "deleted event",
+			events: func() []*eventRow {
+				events := chain(3)
+				return []*eventRow{events[0], events[2]}
+			},
+			wantValid:   false,
+			wantChecked: 2,
+			wantInvalid: 3,
+		},
+		{
+			name: "redacted event",
+			events: func() []*eventRow {
+				events := chain(3)
+				redact(events[1])
+				return events
+			},
+			wantValid:    true,
+			wantChecked:  3,
+			wantRedacted: 1,
+		},
+		{
+			name: "redacted event keeping the source IP",
+			events: func() []*eventRow {
+				events := chain(3)
+				events[1].requestDigest, events[1].requestDigestSalt = []byte{}, nil
+				events[1].redactedAt = sql.NullTime{Time: testNow, Valid: true}
+				return events
+			},
+			wantValid:    true,
+			wantChecked:  3,
+			wantRedacted: 1,
+		},
+		{
+			name: "rewritten event marked as redacted",
+			events: func() []*eventRow {
+				events := chain(3)
+				redact(events[1])
+				events[1].actor = "8"
+				return events
+			},
+			wantValid:    false,
+			wantChecked:  2,
+			wantInvalid:  2,
+			wantRedacted: 1,
+		},
+		{
+			name: "source IP that does not open its commitment",
+			events: func() []*eventRow {
+				events := chain(3)
+				events[1].sourceIP = "198.51.100.1"
+				return events
+			},
+			wantValid:   false,
+			wantChecked: 2,
+			wantInvalid: 2,
+		},
+		{
+			name: "events recorded before commitments",
+			events: func() []*eventRow {
+				events := chain(3)
+				uncommitted(events[0])
+				uncommitted(events[1])
+				relink(events)
+				redact(events[1])
+				return events
+			},
+			wantValid:        true,
+			wantChecked:      3,
+			wantRedacted:     1,
+			wantUnverifiable: 1,
+		},
+		{
+			name: "redacted event recorded before commitments with a broken link",
+			events: func() []*eventRow {
+				events := chain(3)
+				uncommitted(events[0])
+				uncommitted(events[1])
+				relink(events)
+				redact(events[1])
+				events[1].prevHash = events[1].hash
+				return events
+			},
+			wantValid:        false,
+			wantChecked:      2,
+			wantInvalid:      2,
+			wantRedacted:     1,
+			wantUnverifiable: 1,
+		},
+		{
+			name: "event without commitments after one with them",
+			events: func() []*eventRow {
+				events := chain(3)
+				uncommitted(events[1])
+				relink(events)
+				redact(events[1])
+				return events
+			},
+			wantValid:        false,
+			wantChecked:      2,
+			wantInvalid:      2,
+			wantRedacted:     1,
+			wantUnverifiable: 1,
+		},
+		{
+			name: "redacted event with a broken link",
+			events: func() []*eventRow {
+				events := chain(3)
+				events[1].prevHash = events[1].hash
+				events[1].redactedAt = sql.NullTime{Time: testNow, Valid: true}
+				return events
+			},
+			wantValid:    false,
+			wantChecked:  2,
+			wantInvalid:  2,
+			wantRedacted: 1,
+		},
+		{
+			name: "rehashed event without relinking the next",
+			events: func() []*eventRow {
+				events := chain(3)
+				events[1].outcome = "NotFound"
+				events[1].hash = chainHash(events[1].prevHash, events[1])
+				return events
+			},
+			wantValid:   false,
+			wantChecked: 3,
+			wantInvalid: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			mock.ExpectQuery(selectEvents).WillReturnRows(eventRows(tt.events()...))
+
+			resp, err := service.VerifyAuditChain(context.Background(), &auditpb.VerifyAuditChainRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantValid, resp.Valid)
+			assert.Equal(t, tt.wantChecked, resp.CheckedCount)
+			assert.Equal(t, tt.wantInvalid, resp.FirstInvalidId)
+			assert.Equal(t, tt.wantRedacted, resp.RedactedCount)
+			assert.Equal(t, tt.wantUnverifiable, resp.UnverifiableCount)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package config defines the server's configuration and loads it in layers:
+// built-in defaults, then a YAML or TOML file, then environment variables,
+// then command line flags, each overriding the ones before.
+//
+// Every setting is a field of a section struct whose tags name its key in
+// files (yaml and toml), its environment variable (env), its flag (flag) and
+// the flag's help (usage). Fields tagged secret:"true" are redacted when the
+// configuration is printed, and fields tagged reload:"true" can change while
+// the server runs (see Reloader).
+package config
+
+import (
+	"log/slog"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/zcking/go-api-template/internal/database"
+	"github.com/zcking/go-api-template/internal/jobs"
+	"github.com/zcking/go-api-template/internal/operations"
+)
+
+// Config is the configuration of the server
+type Config struct {
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	OTel     OTelConfig     `yaml:"otel" toml:"otel"`
+	Auth     AuthConfig     `yaml:"auth" toml:"auth"`
+	Limits   LimitsConfig   `yaml:"limits" toml:"limits"`
+	Secrets  SecretsConfig  `yaml:"secrets" toml:"secrets"`
+
+	// Features are feature flags, set only in the configuration file. They
+	// can change while the server runs.
+	Features map[string]bool `yaml:"features" toml:"features"`
+}
+
+// Feature reports whether the feature flag name is on. Unknown flags are off.
+func (c *Config) Feature(name string) bool {
+	return c.Features[name]
+}
+
+// ServerConfig configures the listeners, startup and shutdown
+type ServerConfig struct {
+	GRPCAddr            string        `yaml:"grpc_addr" toml:"grpc_addr" env:"GRPC_ADDR" flag:"grpc-addr" usage:"Address the gRPC server listens on"`
+	HTTPAddr            string        `yaml:"http_addr" toml:"http_addr" env:"HTTP_ADDR" flag:"http-addr" usage:"Address the REST gateway listens on"`
+	ShutdownTimeout     time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"How long in-flight HTTP requests may take to finish at shutdown"`
+	DrainTimeout        time.Duration `yaml:"drain_timeout" toml:"drain_timeout" env:"DRAIN_TIMEOUT" flag:"drain-timeout" usage:"How long running scheduled tasks and background jobs may take to finish at shutdown"`
+	MigrateOnStart      bool          `yaml:"migrate_on_start" toml:"migrate_on_start" env:"MIGRATE_ON_START" flag:"migrate-on-start" usage:"Apply pending migrations at startup, one replica at a time; when false, startup fails unless the schema is current"`
+	MigrationsDir       string        `yaml:"migrations_dir" toml:"migrations_dir" env:"MIGRATIONS_DIR" flag:"migrations-dir" usage:"Load migrations from this directory instead of those embedded in the binary (for development)"`
+	PermissionsConfig   string        `yaml:"permissions_config" toml:"permissions_config" env:"PERMISSIONS_CONFIG" flag:"permissions-config" usage:"Permission namespace configuration file"`
+	InvitationAcceptURL string        `yaml:"invitation_accept_url" toml:"invitation_accept_url" env:"INVITATION_ACCEPT_URL" flag:"invitation-accept-url" usage:"URL of the page that accepts invitations; the token is appended as a query parameter"`
+	LogLevel            string        `yaml:"log_level" toml:"log_level" env:"LOG_LEVEL" flag:"log-level" usage:"Minimum level of log records: debug, info, warn or error" reload:"true"`
+	CORSOrigins         []string      `yaml:"cors_origins" toml:"cors_origins" env:"CORS_ORIGINS" flag:"cors-origins" usage:"Comma-separated origins browsers may call the REST gateway from, or * for any" reload:"true"`
+}
+
+// Level returns the log level, or info if it is invalid
+func (s ServerConfig) Level() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// DatabaseConfig configures the Postgres connection
+type DatabaseConfig struct {
+	URL      string `yaml:"url" toml:"url" env:"DATABASE_URL" flag:"database-url" usage:"Connection string, a postgres:// URL or keyword=value pairs, which may list several hosts and set sslrootcert and target_session_attrs; replaces the host, port, user, name and SSL mode settings" secret:"true"`
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST" flag:"db-host" usage:"Database host"`
+	Port     int    `yaml:"port" toml:"port" env:"DB_PORT" flag:"db-port" usage:"Database port"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER" flag:"db-user" usage:"Database user"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD" flag:"db-password" usage:"Database password; replaces the password in the connection string" secret:"true"`
+	Name     string `yaml:"name" toml:"name" env:"DB_NAME" flag:"db-name" usage:"Database name"`
+	SSLMode  string `yaml:"ssl_mode" toml:"ssl_mode" env:"DB_SSLMODE" flag:"db-ssl-mode" usage:"Database SSL mode"`
+
+	PasswordFile    string `yaml:"password_file" toml:"password_file" env:"DB_PASSWORD_FILE" flag:"db-password-file" usage:"File containing the database password, such as a Docker or Kubernetes secret; read again when secrets are refreshed"`
+	PasswordCommand string `yaml:"password_command" toml:"password_command" env:"DB_PASSWORD_COMMAND" flag:"db-password-command" usage:"Shell command printing the database password, such as a secret manager's client; run again when secrets are refreshed"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns" usage:"Maximum number of open connections to the primary, shared by the server's pgx and database/sql pools, plus one for change notifications; 0 is unlimited"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns" usage:"Maximum number of idle connections kept open"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime" usage:"How long a connection may be reused before it is replaced; 0 is forever"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" flag:"db-conn-max-idle-time" usage:"How long a connection may be idle before it is closed; 0 is forever"`
+
+	ReplicaURLs          []string      `yaml:"replica_urls" toml:"replica_urls" env:"DATABASE_REPLICA_URLS" flag:"database-replica-urls" usage:"Comma-separated connection strings of read replicas, one host each, that serve read-only RPCs; they use the password and pool settings of the primary" secret:"true"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" toml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL" flag:"db-replica-check-interval" usage:"How often read replicas are checked and their replay position read"`
+
+	StartupTimeout    time.Duration `yaml:"startup_timeout" toml:"startup_timeout" env:"DB_STARTUP_TIMEOUT" flag:"db-startup-timeout" usage:"How long serve waits for the database to become reachable before it exits"`
+	StartupMaxBackoff time.Duration `yaml:"startup_max_backoff" toml:"startup_max_backoff" env:"DB_STARTUP_MAX_BACKOFF" flag:"db-startup-max-backoff" usage:"Longest wait between attempts to reach the database at startup"`
+}
+
+// DSN returns the connection string: URL if it is set, or else a URL built
+// from the host, port, user, name and SSL mode. It never includes Password,
+// which connections read from Config.DatabasePassword.
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(d.User),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// initialStartupBackoff is the wait after the first failed attempt to reach
+// the database at startup
+const initialStartupBackoff = 500 * time.Millisecond
+
+// StartupBackoff returns how long to wait between attempts to reach the
+// database at startup: doubling from half a second up to StartupMaxBackoff
+func (d DatabaseConfig) StartupBackoff() database.Backoff {
+	return database.Backoff{Initial: min(initialStartupBackoff, d.StartupMaxBackoff), Max: d.StartupMaxBackoff}
+}
+
+// Pool returns the connection pool settings
+func (d DatabaseConfig) Pool() database.Pool {
+	return database.Pool{
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+		ConnMaxIdleTime: d.ConnMaxIdleTime,
+	}
+}
+
+// OTelConfig configures OpenTelemetry. Exporters are configured with the
+// standard OTEL_EXPORTER_OTLP_* environment variables.
+type OTelConfig struct {
+	ServiceName     string        `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME" flag:"otel-service-name" usage:"OpenTelemetry service name"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"OTEL_SHUTDOWN_TIMEOUT" flag:"otel-shutdown-timeout" usage:"How long flushing traces and metrics may take at shutdown"`
+}
+
+// AuthConfig configures authentication, identity providers and encryption
+type AuthConfig struct {
+	KeyringFile            string `yaml:"keyring_file" toml:"keyring_file" env:"KEYRING_FILE" flag:"keyring-file" usage:"JSON keyring with the key encryption keys and blind index key protecting user PII"`
+	AllowDevKeyring        bool   `yaml:"allow_dev_keyring" toml:"allow_dev_keyring" env:"ALLOW_DEV_KEYRING" flag:"allow-dev-keyring" usage:"Accept a keyring marked \"dev\", whose keys are public; for local development only"`
+	ActorHeader            string `yaml:"actor_header" toml:"actor_header" env:"ACTOR_HEADER" flag:"actor-header" usage:"Header carrying the authenticated user's id on API requests, recorded as the actor in the audit log"`
+	SCIMBearerToken        string `yaml:"scim_bearer_token" toml:"scim_bearer_token" env:"SCIM_BEARER_TOKEN" flag:"scim-bearer-token" usage:"Bearer token identity providers use for SCIM provisioning; SCIM is disabled when no token is configured" secret:"true"`
+	SCIMBearerTokenFile    string `yaml:"scim_bearer_token_file" toml:"scim_bearer_token_file" env:"SCIM_BEARER_TOKEN_FILE" flag:"scim-bearer-token-file" usage:"File containing the SCIM bearer token; read again when secrets are refreshed"`
+	SCIMBearerTokenCommand string `yaml:"scim_bearer_token_command" toml:"scim_bearer_token_command" env:"SCIM_BEARER_TOKEN_COMMAND" flag:"scim-bearer-token-command" usage:"Shell command printing the SCIM bearer token; run again when secrets are refreshed"`
+	SCIMBaseURL            string `yaml:"scim_base_url" toml:"scim_base_url" env:"SCIM_BASE_URL" flag:"scim-base-url" usage:"Externally visible URL of the SCIM API, used in resource locations"`
+	OIDCIssuer             string `yaml:"oidc_issuer" toml:"oidc_issuer" env:"OIDC_ISSUER" flag:"oidc-issuer" usage:"Externally visible issuer URL of the OpenID Connect provider; the provider is disabled when empty"`
+	OIDCUserHeader         string `yaml:"oidc_user_header" toml:"oidc_user_header" env:"OIDC_USER_HEADER" flag:"oidc-user-header" usage:"Header carrying the signed-in user's id, set by the authenticating proxy in front of /authorize"`
+	OIDCLoginURL           string `yaml:"oidc_login_url" toml:"oidc_login_url" env:"OIDC_LOGIN_URL" flag:"oidc-login-url" usage:"Login page users without a session are redirected to from /authorize"`
+}
+
+// LimitsConfig bounds the work the server does concurrently
+type LimitsConfig struct {
+	OperationWorkers  int `yaml:"operation_workers" toml:"operation_workers" env:"OPERATION_WORKERS" flag:"operation-workers" usage:"Number of long-running operations run concurrently"`
+	JobWorkers        int `yaml:"job_workers" toml:"job_workers" env:"JOB_WORKERS" flag:"job-workers" usage:"Number of background jobs run concurrently"`
+	RequestsPerSecond int `yaml:"requests_per_second" toml:"requests_per_second" env:"RATE_LIMIT_RPS" flag:"rate-limit-rps" usage:"API requests accepted per second across all clients; 0 disables rate limiting" reload:"true"`
+	RequestBurst      int `yaml:"request_burst" toml:"request_burst" env:"RATE_LIMIT_BURST" flag:"rate-limit-burst" usage:"API requests accepted at once above the rate; 0 means the same as the rate" reload:"true"`
+}
+
+// SecretsConfig configures how secrets kept outside the configuration are
+// read. See DatabasePassword and SCIMBearerToken.
+type SecretsConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval" toml:"refresh_interval" env:"SECRET_REFRESH_INTERVAL" flag:"secret-refresh-interval" usage:"How often secret files are read and secret commands run again, to pick up rotated secrets"`
+	CommandTimeout  time.Duration `yaml:"command_timeout" toml:"command_timeout" env:"SECRET_COMMAND_TIMEOUT" flag:"secret-command-timeout" usage:"How long a secret command may run"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			GRPCAddr:          ":8080",
+			HTTPAddr:          ":8081",
+			ShutdownTimeout:   10 * time.Second,
+			DrainTimeout:      30 * time.Second,
+			MigrateOnStart:    true,
+			PermissionsConfig: "config/permissions.yaml",
+			LogLevel:          "info",
+		},
+		Database: DatabaseConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "postgres",
+			Name:    "go_api_template",
+			SSLMode: "disable",
+
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+
+			ReplicaCheckInterval: 2 * time.Second,
+
+			StartupTimeout:    2 * time.Minute,
+			StartupMaxBackoff: 10 * time.Second,
+		},
+		OTel: OTelConfig{
+			ServiceName:     "go-api-template",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Auth: AuthConfig{
+			ActorHeader:    "X-Authenticated-User-Id",
+			OIDCUserHeader: "X-Authenticated-User-Id",
+		},
+		Limits: LimitsConfig{
+			OperationWorkers: operations.DefaultWorkers,
+			JobWorkers:       jobs.DefaultWorkers,
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: 5 * time.Minute,
+			CommandTimeout:  10 * time.Second,
+		},
+	}
+}
+
+// Redacted returns a copy of c with every secret that is set replaced
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	for _, f := range settings(&redacted) {
+		if !f.secret || f.String() == "" {
+			continue
+		}
+		if list, ok := f.value.Interface().([]string); ok {
+			// A new slice, since c shares the original
+			f.value.Set(reflect.ValueOf(slices.Repeat([]string{"REDACTED"}, len(list))))
+		} else {
+			f.value.SetString("REDACTED")
+		}
+	}
+	return &redacted
+}
+
+// Environment returns c as NAME=value assignments of the settings'
+// environment variables, in declaration order
+func (c *Config) Environment() []string {
+	var env []string
+	for _, s := range settings(c) {
+		env = append(env, s.env+"="+s.String())
+	}
+	return env
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// setting is one leaf field of a Config
+type setting struct {
+	path   string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	reload bool
+	value  reflect.Value
+}
+
+// settings lists the settings of c, in declaration order. Their values are
+// addressable, so setting them changes c. Features are not settings: they have
+// no environment variables or flags.
+func settings(c *Config) []setting {
+	var out []setting
+	v := reflect.ValueOf(c).Elem()
+	for i := range v.NumField() {
+		section, sectionValue := v.Type().Field(i), v.Field(i)
+		if sectionValue.Kind() != reflect.Struct {
+			continue
+		}
+		for j := range sectionValue.NumField() {
+			f := section.Type.Field(j)
+			out = append(out, setting{
+				path:   section.Tag.Get("yaml") + "." + f.Tag.Get("yaml"),
+				env:    f.Tag.Get("env"),
+				flag:   f.Tag.Get("flag"),
+				usage:  f.Tag.Get("usage"),
+				secret: f.Tag.Get("secret") == "true",
+				reload: f.Tag.Get("reload") == "true",
+				value:  sectionValue.Field(j),
+			})
+		}
+	}
+	return out
+}
+
+// set parses value into the setting. Lists are comma-separated.
+func (s setting) set(value string) error {
+	if s.value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("must be a duration such as 30s or 1m")
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	}
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		s.value.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		s.value.SetBool(b)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		s.value.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", s.value.Type())
+	}
+	return nil
+}
+
+// String formats the setting's value the way set parses it
+func (s setting) String() string {
+	if list, ok := s.value.Interface().([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(s.value.Interface())
+}
+
+// matches reports whether the setting is selected by one of paths, each a
+// section ("database") or a setting ("server.migrations_dir"). No paths
+// select every setting.
+func (s setting) matches(paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if s.path == p || strings.HasPrefix(s.path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterFlags defines a flag for each setting selected by paths, each a
+// section ("database") or a setting ("server.migrations_dir"); with no paths,
+// every setting gets a flag. Flag defaults are the built-in defaults, and
+// the help names the setting's environment variable.
+func RegisterFlags(fs *pflag.FlagSet, paths ...string) {
+	for _, s := range settings(Default()) {
+		if !s.matches(paths) {
+			continue
+		}
+		usage := fmt.Sprintf("%s [$%s]", s.usage, s.env)
+		switch v := s.value.Interface().(type) {
+		case time.Duration:
+			fs.Duration(s.flag, v, usage)
+		case string:
+			fs.String(s.flag, v, usage)
+		case int:
+			fs.Int(s.flag, v, usage)
+		case bool:
+			fs.Bool(s.flag, v, usage)
+		case []string:
+			fs.StringSlice(s.flag, v, usage)
+		}
+	}
+}
+
+// Load builds the configuration from the defaults, then the file at path if
+// it is not empty, then environment variables, then the flags of fs that were
+// set on the command line. fs may be nil. Every invalid value is reported.
+// The result is not validated.
+func Load(path string, fs *pflag.FlagSet) (*Config, error) {
+	c := Default()
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings(c) {
+		if value := os.Getenv(s.env); value != "" {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", s.env, value, err))
+			}
+		}
+		if fs == nil {
+			continue
+		}
+		if f := fs.Lookup(s.flag); f != nil && f.Changed {
+			value := f.Value.String()
+			if list, ok := f.Value.(pflag.SliceValue); ok {
+				value = strings.Join(list.GetSlice(), ",")
+			}
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: invalid value %q: %w", s.flag, value, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// loadFile overlays the settings in the YAML (.yaml, .yml) or TOML (.toml)
+// file at path onto c. Unknown keys are errors, so typos are not ignored.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, k := range undecoded {
+				keys[i] = k.String()
+			}
+			return fmt.Errorf("failed to parse config file %s: unknown keys %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return fmt.Errorf("unsupported config file extension %q: must be .yaml, .yml or .toml", ext)
+	}
+	return nil
+}
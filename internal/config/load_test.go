@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a config file named name with content to a temporary
+// directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+database:
+  host: file-host
+  port: 6432
+  user: file-user
+limits:
+  job_workers: 2
+`)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("DB_PORT", "7432")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--db-host", "flag-host"}))
+
+	c, err := Load(path, fs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "flag-host", c.Database.Host, "flags override the environment")
+	assert.Equal(t, 7432, c.Database.Port, "the environment overrides the file")
+	assert.Equal(t, "file-user", c.Database.User, "the file overrides defaults")
+	assert.Equal(t, 2, c.Limits.JobWorkers)
+	assert.Equal(t, "go_api_template", c.Database.Name, "unset settings keep their defaults")
+}
+
+func TestLoad_UnchangedFlagsDoNotOverride(t *testing.T) {
+	t.Setenv("DB_HOST", "env-host")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(fs)
+	require.NoError(t, fs.Parse(nil))
+
+	c, err := Load("", fs)
+	require.NoError(t, err)
+	assert.Equal(t, "env-host", c.Database.Host)
+}
+
+func TestLoad_Files(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+server:
+  http_addr: ":9091"
+  drain_timeout: 1m
+otel:
+  service_name: from-file
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `
+[server]
+http_addr = ":9091"
+drain_timeout = "1m"
+
+[otel]
+service_name = "from-file"
+`,
+		},
+		{
+			name:    "unknown yaml key",
+			file:    "config.yml",
+			content: "server:\n  htp_addr: \":9091\"\n",
+			wantErr: "field htp_addr not found",
+		},
+		{
+			name:    "unknown toml key",
+			file:    "config.toml",
+			content: "[server]\nhtp_addr = \":9091\"\n",
+			wantErr: "unknown keys server.htp_addr",
+		},
+		{
+			name:    "unsupported extension",
+			file:    "config.json",
+			content: "{}",
+			wantErr: `unsupported config file extension ".json"`,
+		},
+		{
+			name:    "empty yaml file",
+			file:    "config.yaml",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Load(writeFile(t, tt.file, tt.content), nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.content == "" {
+				assert.Equal(t, Default(), c)
+				return
+			}
+			assert.Equal(t, ":9091", c.Server.HTTPAddr)
+			assert.Equal(t, time.Minute, c.Server.DrainTimeout)
+			assert.Equal(t, "from-file", c.OTel.ServiceName)
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), nil)
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+func TestLoad_InvalidEnvironment(t *testing.T) {
+	t.Setenv("DB_PORT", "five")
+	t.Setenv("MIGRATE_ON_START", "maybe")
+	t.Setenv("DRAIN_TIMEOUT", "30")
+
+	_, err := Load("", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `DB_PORT: invalid value "five": must be an integer`)
+	assert.Contains(t, err.Error(), `MIGRATE_ON_START: invalid value "maybe": must be a boolean`)
+	assert.Contains(t, err.Error(), `DRAIN_TIMEOUT: invalid value "30": must be a duration`)
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(fs, "database", "server.migrations_dir")
+
+	assert.NotNil(t, fs.Lookup("db-host"))
+	assert.NotNil(t, fs.Lookup("migrations-dir"))
+	assert.Nil(t, fs.Lookup("grpc-addr"))
+	assert.Nil(t, fs.Lookup("job-workers"))
+	assert.Equal(t, "Database host [$DB_HOST]", fs.Lookup("db-host").Usage)
+	assert.Equal(t, "5432", fs.Lookup("db-port").DefValue)
+}
+
+func TestLoad_Lists(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  cors_origins:
+    - https://file.example.com
+features:
+  new_search: true
+`)
+	c, err := Load(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://file.example.com"}, c.Server.CORSOrigins)
+	assert.True(t, c.Feature("new_search"))
+	assert.False(t, c.Feature("unknown"))
+
+	t.Setenv("CORS_ORIGINS", "https://a.example.com, https://b.example.com,")
+	c, err = Load(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, c.Server.CORSOrigins)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(fs, "server.cors_origins")
+	require.NoError(t, fs.Parse([]string{"--cors-origins", "https://c.example.com,https://d.example.com"}))
+	c, err = Load(path, fs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://c.example.com", "https://d.example.com"}, c.Server.CORSOrigins)
+	assert.Contains(t, c.Environment(), "CORS_ORIGINS=https://c.example.com,https://d.example.com")
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// reloadDelay is how long the Reloader waits after the file last changed
+// before reloading it, so a file written in several steps is read once
+const reloadDelay = 100 * time.Millisecond
+
+// Reloader holds the configuration of a running server and reloads it from
+// the same file, environment and flags when the file changes or the process
+// receives SIGHUP.
+//
+// Only settings tagged reload:"true" and the feature flags take effect; the
+// rest keep the values the server started with, and changes to them are
+// logged as rejected. Each reload that changes something replaces the
+// configuration as a whole, so Current never returns a mix of old and new
+// values, and increments its version.
+type Reloader struct {
+	path   string
+	flags  *pflag.FlagSet
+	logger *slog.Logger
+	delay  time.Duration
+
+	mu        sync.Mutex
+	current   atomic.Pointer[Config]
+	version   atomic.Int64
+	listeners []func(*Config)
+	reloads   metric.Int64Counter
+}
+
+// NewReloader creates a reloader of the configuration loaded from path and
+// fs, starting at version 1 with initial. Its metrics are reported through
+// the global MeterProvider.
+func NewReloader(path string, fs *pflag.FlagSet, initial *Config, logger *slog.Logger) (*Reloader, error) {
+	return newReloader(path, fs, initial, logger, otel.GetMeterProvider())
+}
+
+func newReloader(path string, fs *pflag.FlagSet, initial *Config, logger *slog.Logger, mp metric.MeterProvider) (*Reloader, error) {
+	r := &Reloader{path: path, flags: fs, logger: logger, delay: reloadDelay}
+	r.current.Store(initial)
+	r.version.Store(1)
+	if err := r.registerMetrics(mp.Meter("github.com/zcking/go-api-template/internal/config")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// registerMetrics creates the reloader's instruments:
+//
+//   - config.version: the version of the configuration in use
+//   - config.reloads: reload attempts, by outcome (applied, unchanged or failed)
+func (r *Reloader) registerMetrics(meter metric.Meter) error {
+	var err error
+	r.reloads, err = meter.Int64Counter("config.reloads",
+		metric.WithDescription("Configuration reload attempts by outcome"),
+		metric.WithUnit("{reload}"))
+	if err != nil {
+		return err
+	}
+	_, err = meter.Int64ObservableGauge("config.version",
+		metric.WithDescription("Version of the configuration in use, incremented by each reload that changes it"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(r.version.Load())
+			return nil
+		}))
+	return err
+}
+
+// Current returns the configuration in use. It must not be modified.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Version returns the version of the configuration in use
+func (r *Reloader) Version() int64 {
+	return r.version.Load()
+}
+
+// OnReload registers fn to be called with the new configuration after each
+// reload that changes it. Listeners must be registered before Watch.
+func (r *Reloader) OnReload(fn func(*Config)) {
+	r.listeners = append(r.listeners, fn)
+}
+
+// Reload loads and validates the configuration, then applies the reloadable
+// settings that changed. An invalid configuration is not applied at all.
+func (r *Reloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.path, r.flags)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		r.reloads.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "failed")))
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+
+	current := r.Current()
+	applied, changed, rejected := current.merge(next)
+	if len(rejected) > 0 {
+		r.logger.Warn("rejected configuration changes that require a restart", "settings", rejected)
+	}
+	if len(changed) == 0 {
+		r.reloads.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "unchanged")))
+		return nil
+	}
+	r.current.Store(applied)
+	version := r.version.Add(1)
+	for _, fn := range r.listeners {
+		fn(applied)
+	}
+	r.reloads.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "applied")))
+	r.logger.Info("reloaded configuration", "version", version, "settings", changed)
+	return nil
+}
+
+// merge returns a copy of c with the reloadable settings and feature flags of
+// next, the paths of those that changed, and the paths of the settings that
+// changed but cannot be reloaded
+func (c *Config) merge(next *Config) (applied *Config, changed, rejected []string) {
+	applied = c.clone()
+	nextSettings := settings(next)
+	for i, s := range settings(applied) {
+		n := nextSettings[i]
+		if s.String() == n.String() {
+			continue
+		}
+		if !s.reload {
+			rejected = append(rejected, s.path)
+			continue
+		}
+		s.value.Set(n.value)
+		changed = append(changed, s.path)
+	}
+	if !maps.Equal(c.Features, next.Features) {
+		applied.Features = maps.Clone(next.Features)
+		changed = append(changed, "features")
+	}
+	return applied, changed, rejected
+}
+
+// clone returns a copy of c that shares no lists or maps with it
+func (c *Config) clone() *Config {
+	clone := *c
+	clone.Server.CORSOrigins = append([]string(nil), c.Server.CORSOrigins...)
+	clone.Features = maps.Clone(c.Features)
+	return &clone
+}
+
+// Watch reloads the configuration whenever the process receives SIGHUP or
+// the configuration file changes, until ctx is done. Failed reloads are
+// logged and leave the configuration in use unchanged.
+func (r *Reloader) Watch(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+	)
+	if r.path != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to watch config file: %w", err)
+		}
+		defer watcher.Close()
+		// Watch the directory rather than the file: editors and Kubernetes
+		// replace the file instead of writing to it, which ends a watch on it
+		if err := watcher.Add(filepath.Dir(r.path)); err != nil {
+			return fmt.Errorf("failed to watch config file: %w", err)
+		}
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	timer := time.NewTimer(r.delay)
+	timer.Stop()
+	reload := func(trigger string) {
+		r.logger.Info("reloading configuration", "trigger", trigger, "path", r.path)
+		if err := r.Reload(ctx); err != nil {
+			r.logger.Error("keeping the current configuration", "error", err)
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-hup:
+			reload("signal")
+		case event := <-events:
+			if r.affects(event) {
+				timer.Reset(r.delay)
+			}
+		case err := <-errs:
+			r.logger.Warn("config file watch failed", "error", err)
+		case <-timer.C:
+			reload("file")
+		}
+	}
+}
+
+// affects reports whether event may have changed the configuration file. A
+// Kubernetes ConfigMap volume swaps its ..data symlink rather than the file.
+func (r *Reloader) affects(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	return filepath.Clean(event.Name) == filepath.Clean(r.path) || filepath.Base(event.Name) == "..data"
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const reloadBase = `
+server:
+  log_level: info
+auth:
+  keyring_file: keyring.json
+`
+
+// newTestReloader returns a reloader of the configuration in a file with
+// content, and the path of the file
+func newTestReloader(t *testing.T, content string) (*Reloader, string, *sdkmetric.ManualReader) {
+	t.Helper()
+	path := writeFile(t, "config.yaml", content)
+	initial, err := Load(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, initial.Validate())
+
+	reader := sdkmetric.NewManualReader()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r, err := newReloader(path, nil, initial, logger, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+	return r, path, reader
+}
+
+// rewrite replaces the content of the file at path
+func rewrite(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReloader_Reload(t *testing.T) {
+	r, path, _ := newTestReloader(t, reloadBase)
+	initial := r.Current()
+	var notified *Config
+	r.OnReload(func(c *Config) { notified = c })
+
+	rewrite(t, path, `
+server:
+  log_level: debug
+  grpc_addr: ":9090"
+  cors_origins: [https://app.example.com]
+auth:
+  keyring_file: keyring.json
+limits:
+  requests_per_second: 100
+features:
+  new_search: true
+`)
+	require.NoError(t, r.Reload(t.Context()))
+
+	c := r.Current()
+	assert.Equal(t, int64(2), r.Version())
+	assert.Same(t, c, notified)
+	assert.Equal(t, "debug", c.Server.LogLevel)
+	assert.Equal(t, []string{"https://app.example.com"}, c.Server.CORSOrigins)
+	assert.Equal(t, 100, c.Limits.RequestsPerSecond)
+	assert.True(t, c.Feature("new_search"))
+	assert.Equal(t, ":8080", c.Server.GRPCAddr, "settings that are not reloadable keep their values")
+	assert.Equal(t, "info", initial.Server.LogLevel, "the previous configuration is not changed")
+}
+
+func TestReloader_Reload_Unchanged(t *testing.T) {
+	r, path, _ := newTestReloader(t, reloadBase)
+	r.OnReload(func(c *Config) { t.Error("listeners are not called when nothing changes") })
+
+	require.NoError(t, r.Reload(t.Context()))
+	rewrite(t, path, reloadBase+"database:\n  host: elsewhere\n")
+	require.NoError(t, r.Reload(t.Context()))
+
+	assert.Equal(t, int64(1), r.Version())
+	assert.Equal(t, "localhost", r.Current().Database.Host)
+}
+
+func TestReloader_Reload_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unparsable",
+			content: "server: [",
+			wantErr: "failed to parse config file",
+		},
+		{
+			name:    "invalid",
+			content: reloadBase + "limits:\n  requests_per_second: -1\n",
+			wantErr: "limits.requests_per_second: must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, path, _ := newTestReloader(t, reloadBase)
+			initial := r.Current()
+
+			rewrite(t, path, tt.content)
+			err := r.Reload(t.Context())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Same(t, initial, r.Current())
+			assert.Equal(t, int64(1), r.Version())
+		})
+	}
+}
+
+func TestReloader_Metrics(t *testing.T) {
+	r, path, reader := newTestReloader(t, reloadBase)
+	rewrite(t, path, reloadBase+"features:\n  new_search: true\n")
+	require.NoError(t, r.Reload(t.Context()))
+	rewrite(t, path, "server: [")
+	require.Error(t, r.Reload(t.Context()))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	version := metrics["config.version"].(metricdata.Gauge[int64])
+	require.Len(t, version.DataPoints, 1)
+	assert.Equal(t, int64(2), version.DataPoints[0].Value)
+
+	reloads := metrics["config.reloads"].(metricdata.Sum[int64])
+	byOutcome := make(map[string]int64)
+	for _, dp := range reloads.DataPoints {
+		outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+		byOutcome[outcome.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"applied": 1, "failed": 1}, byOutcome)
+}
+
+func TestReloader_Watch(t *testing.T) {
+	r, path, _ := newTestReloader(t, reloadBase)
+	r.delay = 10 * time.Millisecond
+	go func() { assert.NoError(t, r.Watch(t.Context())) }()
+
+	// The watch may not have started yet, so keep changing the file
+	assert.Eventually(t, func() bool {
+		rewrite(t, path, reloadBase+"limits:\n  request_burst: 10\n")
+		return r.Current().Limits.RequestBurst == 10
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int64(2), r.Version())
+}
+
+func TestConfig_Merge(t *testing.T) {
+	current := Default()
+	next := Default()
+	next.Server.LogLevel = "warn"
+	next.Server.HTTPAddr = ":9091"
+	next.Database.Password = "changed"
+	next.Features = map[string]bool{"beta": true}
+
+	applied, changed, rejected := current.merge(next)
+
+	assert.Equal(t, []string{"server.log_level", "features"}, changed)
+	assert.Equal(t, []string{"server.http_addr", "database.password"}, rejected)
+	assert.Equal(t, "warn", applied.Server.LogLevel)
+	assert.Equal(t, ":8081", applied.Server.HTTPAddr)
+	assert.Equal(t, "info", current.Server.LogLevel)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/zcking/go-api-template/internal/secrets"
+)
+
+// DatabasePassword returns the provider of the database password: its
+// command, its file or the password itself, whichever is set
+func (c *Config) DatabasePassword() secrets.Provider {
+	return c.secret(c.Database.Password, c.Database.PasswordFile, c.Database.PasswordCommand)
+}
+
+// SCIMBearerToken returns the provider of the SCIM bearer token: its
+// command, its file or the token itself, whichever is set
+func (c *Config) SCIMBearerToken() secrets.Provider {
+	return c.secret(c.Auth.SCIMBearerToken, c.Auth.SCIMBearerTokenFile, c.Auth.SCIMBearerTokenCommand)
+}
+
+// SCIMEnabled reports whether a SCIM bearer token is configured
+func (a AuthConfig) SCIMEnabled() bool {
+	return a.SCIMBearerToken != "" || a.SCIMBearerTokenFile != "" || a.SCIMBearerTokenCommand != ""
+}
+
+// secret returns the provider of a secret set as a value, a file or a
+// shell command. Validate ensures at most one is set.
+func (c *Config) secret(value, file, command string) secrets.Provider {
+	switch {
+	case command != "":
+		return secrets.Command{Args: []string{"sh", "-c", command}, Timeout: c.Secrets.CommandTimeout}
+	case file != "":
+		return secrets.File(file)
+	default:
+		return secrets.Static(value)
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zcking/go-api-template/internal/secrets"
+)
+
+func TestConfig_DatabasePassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(d *DatabaseConfig)
+		want   secrets.Provider
+	}{
+		{
+			name:   "none",
+			modify: func(d *DatabaseConfig) {},
+			want:   secrets.Static(""),
+		},
+		{
+			name:   "value",
+			modify: func(d *DatabaseConfig) { d.Password = "hunter2" },
+			want:   secrets.Static("hunter2"),
+		},
+		{
+			name:   "file",
+			modify: func(d *DatabaseConfig) { d.PasswordFile = "/run/secrets/db_password" },
+			want:   secrets.File("/run/secrets/db_password"),
+		},
+		{
+			name:   "command",
+			modify: func(d *DatabaseConfig) { d.PasswordCommand = "vault kv get -field=password secret/db" },
+			want:   secrets.Command{Args: []string{"sh", "-c", "vault kv get -field=password secret/db"}, Timeout: 10 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.modify(&c.Database)
+			assert.Equal(t, tt.want, c.DatabasePassword())
+		})
+	}
+}
+
+func TestConfig_SCIMBearerToken(t *testing.T) {
+	c := Default()
+	assert.False(t, c.Auth.SCIMEnabled())
+
+	c.Auth.SCIMBearerTokenFile = "/run/secrets/scim_token"
+	assert.True(t, c.Auth.SCIMEnabled())
+	assert.Equal(t, secrets.File("/run/secrets/scim_token"), c.SCIMBearerToken())
+}
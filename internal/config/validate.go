@@ -0,0 +1,226 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/zcking/go-api-template/internal/database"
+)
+
+// sslModes are the SSL modes Postgres clients accept
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Validate reports every invalid setting of c at once
+func (c *Config) Validate() error {
+	var v validator
+	c.Server.validate(&v)
+	c.Database.validate(&v)
+	c.OTel.validate(&v)
+	c.Auth.validate(&v)
+	c.Limits.validate(&v)
+	c.Secrets.validate(&v)
+	return v.err()
+}
+
+// ValidateDatabase reports every invalid database setting of c, for commands
+// that only connect to the database
+func (c *Config) ValidateDatabase() error {
+	var v validator
+	c.Database.validate(&v)
+	c.Secrets.validate(&v)
+	return v.err()
+}
+
+func (s ServerConfig) validate(v *validator) {
+	v.addr("server.grpc_addr", s.GRPCAddr)
+	v.addr("server.http_addr", s.HTTPAddr)
+	if s.GRPCAddr == s.HTTPAddr {
+		v.fail("server.http_addr", "must differ from server.grpc_addr")
+	}
+	v.positive("server.shutdown_timeout", s.ShutdownTimeout)
+	v.positive("server.drain_timeout", s.DrainTimeout)
+	v.required("server.permissions_config", s.PermissionsConfig)
+	if s.InvitationAcceptURL != "" {
+		v.absoluteURL("server.invitation_accept_url", s.InvitationAcceptURL)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s.LogLevel)); err != nil {
+		v.fail("server.log_level", fmt.Sprintf("must be debug, info, warn or error, got %q", s.LogLevel))
+	}
+	for _, origin := range s.CORSOrigins {
+		v.origin("server.cors_origins", origin)
+	}
+}
+
+func (d DatabaseConfig) validate(v *validator) {
+	if d.URL != "" {
+		// The error never includes the connection string, which may hold
+		// a password
+		if _, err := database.ParseDSN(d.URL); err != nil {
+			v.fail("database.url", err.Error())
+		}
+	} else {
+		v.required("database.host", d.Host)
+		if d.Port < 1 || d.Port > 65535 {
+			v.fail("database.port", fmt.Sprintf("must be between 1 and 65535, got %d", d.Port))
+		}
+		v.required("database.user", d.User)
+		v.required("database.name", d.Name)
+		if !slices.Contains(sslModes, d.SSLMode) {
+			v.fail("database.ssl_mode", fmt.Sprintf("must be one of %v, got %q", sslModes, d.SSLMode))
+		}
+	}
+	v.oneSecret("database.password", d.Password, d.PasswordFile, d.PasswordCommand)
+	v.notNegative("database.max_open_conns", d.MaxOpenConns)
+	v.notNegative("database.max_idle_conns", d.MaxIdleConns)
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		v.fail("database.max_idle_conns", fmt.Sprintf("must not exceed database.max_open_conns (%d), got %d", d.MaxOpenConns, d.MaxIdleConns))
+	}
+	v.notNegativeDuration("database.conn_max_lifetime", d.ConnMaxLifetime)
+	v.notNegativeDuration("database.conn_max_idle_time", d.ConnMaxIdleTime)
+	for i, replica := range d.ReplicaURLs {
+		// As for the url, errors never include the connection string
+		key := fmt.Sprintf("database.replica_urls[%d]", i)
+		if dsn, err := database.ParseDSN(replica); err != nil {
+			v.fail(key, err.Error())
+		} else if len(dsn.Hosts) != 1 {
+			v.fail(key, fmt.Sprintf("must name one host, got %d", len(dsn.Hosts)))
+		}
+	}
+	v.positive("database.replica_check_interval", d.ReplicaCheckInterval)
+	v.positive("database.startup_timeout", d.StartupTimeout)
+	v.positive("database.startup_max_backoff", d.StartupMaxBackoff)
+}
+
+func (o OTelConfig) validate(v *validator) {
+	v.required("otel.service_name", o.ServiceName)
+	v.positive("otel.shutdown_timeout", o.ShutdownTimeout)
+}
+
+func (a AuthConfig) validate(v *validator) {
+	v.required("auth.keyring_file", a.KeyringFile)
+	v.required("auth.actor_header", a.ActorHeader)
+	v.oneSecret("auth.scim_bearer_token", a.SCIMBearerToken, a.SCIMBearerTokenFile, a.SCIMBearerTokenCommand)
+	if a.SCIMBaseURL != "" {
+		if _, err := url.Parse(a.SCIMBaseURL); err != nil {
+			v.fail("auth.scim_base_url", "must be a URL")
+		}
+	}
+	if a.OIDCIssuer != "" {
+		v.absoluteURL("auth.oidc_issuer", a.OIDCIssuer)
+		v.required("auth.oidc_user_header", a.OIDCUserHeader)
+	}
+	if a.OIDCLoginURL != "" {
+		if _, err := url.Parse(a.OIDCLoginURL); err != nil {
+			v.fail("auth.oidc_login_url", "must be a URL")
+		}
+	}
+}
+
+func (l LimitsConfig) validate(v *validator) {
+	v.atLeastOne("limits.operation_workers", l.OperationWorkers)
+	v.atLeastOne("limits.job_workers", l.JobWorkers)
+	v.notNegative("limits.requests_per_second", l.RequestsPerSecond)
+	v.notNegative("limits.request_burst", l.RequestBurst)
+}
+
+func (s SecretsConfig) validate(v *validator) {
+	v.positive("secrets.refresh_interval", s.RefreshInterval)
+	v.positive("secrets.command_timeout", s.CommandTimeout)
+}
+
+// validator collects validation errors
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(path, problem string) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", path, problem))
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(v.errs...))
+}
+
+func (v *validator) required(path, value string) {
+	if value == "" {
+		v.fail(path, "is required")
+	}
+}
+
+func (v *validator) positive(path string, d time.Duration) {
+	if d <= 0 {
+		v.fail(path, fmt.Sprintf("must be positive, got %s", d))
+	}
+}
+
+func (v *validator) atLeastOne(path string, n int) {
+	if n < 1 {
+		v.fail(path, fmt.Sprintf("must be at least 1, got %d", n))
+	}
+}
+
+// oneSecret checks that a secret is set as at most one of a value, a file
+// and a command
+func (v *validator) oneSecret(path, value, file, command string) {
+	set := 0
+	for _, s := range []string{value, file, command} {
+		if s != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		v.fail(path, fmt.Sprintf("set only one of %s, %s_file and %s_command", path, path, path))
+	}
+}
+
+func (v *validator) notNegative(path string, n int) {
+	if n < 0 {
+		v.fail(path, fmt.Sprintf("must not be negative, got %d", n))
+	}
+}
+
+func (v *validator) notNegativeDuration(path string, d time.Duration) {
+	if d < 0 {
+		v.fail(path, fmt.Sprintf("must not be negative, got %s", d))
+	}
+}
+
+func (v *validator) addr(path, addr string) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		v.fail(path, fmt.Sprintf("must be host:port or :port, got %q", addr))
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		v.fail(path, fmt.Sprintf("must have a port between 0 and 65535, got %q", port))
+	}
+}
+
+func (v *validator) absoluteURL(path, value string) {
+	u, err := url.Parse(value)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		v.fail(path, fmt.Sprintf("must be an absolute URL, got %q", value))
+	}
+}
+
+// origin checks that value is * or a scheme and host, as browsers send them
+// in the Origin header
+func (v *validator) origin(path, value string) {
+	if value == "*" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		v.fail(path, fmt.Sprintf("must be * or an origin such as https://app.example.com, got %q", value))
+	}
+}
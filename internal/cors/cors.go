@@ -0,0 +1,65 @@
+// Package cors lets browsers call the REST gateway from other origins.
+package cors
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// allowedMethods are the methods preflight requests may ask for, those the
+// gateway routes
+const allowedMethods = "GET, POST, PUT, PATCH, DELETE"
+
+// allowedHeaders are the request headers, beyond the CORS-safelisted ones,
+// that preflight requests may ask for: credentials, JSON bodies and the
+// session LSN token. Headers set by the authenticating proxy, such as the
+// actor header, are deliberately left out, so scripts on other origins
+// cannot send them.
+const allowedHeaders = "Authorization, Content-Type, X-Db-Lsn"
+
+// exposedHeaders are the response headers, beyond the CORS-safelisted ones,
+// that browser scripts may read: the session LSN token of read replica
+// routing
+const exposedHeaders = "X-Db-Lsn"
+
+// maxAge is how long, in seconds, browsers may cache a preflight response
+const maxAge = "600"
+
+// Handler returns a handler that adds CORS headers to the responses of next
+// for requests from the origins returned by origins, and answers their
+// preflight requests itself. origins is called for every request, so the
+// allowed origins can change while the server runs; "*" allows any origin.
+// Requests from other origins are passed to next without CORS headers, which
+// makes browsers block their responses.
+func Handler(next http.Handler, origins func() []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := origins()
+		if origin == "" || len(allowed) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+		if !slices.ContainsFunc(allowed, func(a string) bool { return a == "*" || strings.TrimSuffix(a, "/") == origin }) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if !preflight {
+			w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+		w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+		w.Header().Set("Access-Control-Max-Age", maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,137 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		method      string
+		headers     map[string]string
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+		wantHeaders string
+		wantExposed string
+	}{
+		{
+			name:       "no origin configured",
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "same-origin request",
+			origins:    []string{"https://app.example.com"},
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "allowed origin",
+			origins:     []string{"https://app.example.com/"},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://app.example.com"},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://app.example.com",
+			wantExposed: "X-Db-Lsn",
+		},
+		{
+			name:        "any origin",
+			origins:     []string{"*"},
+			method:      http.MethodPost,
+			headers:     map[string]string{"Origin": "http://localhost:3000"},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "http://localhost:3000",
+			wantExposed: "X-Db-Lsn",
+		},
+		{
+			name:       "other origin",
+			origins:    []string{"https://app.example.com"},
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://evil.example.com"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "preflight",
+			origins: []string{"https://app.example.com"},
+			method:  http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "DELETE",
+				"Access-Control-Request-Headers": "content-type",
+			},
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://app.example.com",
+			wantMethods: allowedMethods,
+			wantHeaders: allowedHeaders,
+		},
+		{
+			name:    "preflight asking for the actor header",
+			origins: []string{"https://app.example.com"},
+			method:  http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type, x-authenticated-user-id",
+			},
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://app.example.com",
+			wantMethods: allowedMethods,
+			wantHeaders: allowedHeaders,
+		},
+		{
+			name:    "preflight from other origin",
+			origins: []string{"https://app.example.com"},
+			method:  http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.example.com",
+				"Access-Control-Request-Method": "DELETE",
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := Handler(next, func() []string { return tt.origins })
+
+			req := httptest.NewRequest(tt.method, "/v1/users", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.wantMethods, rec.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, tt.wantHeaders, rec.Header().Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, tt.wantExposed, rec.Header().Get("Access-Control-Expose-Headers"))
+		})
+	}
+}
+
+func TestHandler_OriginsChange(t *testing.T) {
+	origins := []string{"https://old.example.com"}
+	handler := Handler(http.NotFoundHandler(), func() []string { return origins })
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+	req.Header.Set("Origin", "https://new.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	origins = []string{"https://new.example.com"}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "https://new.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lib/pq"
+
+	"github.com/zcking/go-api-template/internal/secrets"
+)
+
+// ErrSessionAttrs is returned by Connect for hosts that are up but do not
+// accept sessions matching target_session_attrs, as during a failover
+var ErrSessionAttrs = errors.New("session does not match target_session_attrs")
+
+// Connector opens connections to the first host of a DSN that accepts one
+// matching its target_session_attrs, as libpq does, with the current
+// password. It is a database/sql driver.Connector.
+type Connector struct {
+	dsn         *DSN
+	credentials *secrets.Secret
+	connect     func(ctx context.Context, connString string) (driver.Conn, error)
+}
+
+// NewConnector creates a connector for the connection string dsn (see
+// ParseDSN). When credentials is not nil and not empty, its current value
+// replaces the DSN's password, so new connections pick up rotated
+// credentials.
+func NewConnector(dsn string, credentials *secrets.Secret) (*Connector, error) {
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &Connector{dsn: d, credentials: credentials, connect: connectPQ}, nil
+}
+
+// connectPQ opens a connection with lib/pq
+func connectPQ(ctx context.Context, connString string) (driver.Conn, error) {
+	c, err := pq.NewConnector(connString)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(ctx)
+}
+
+// DSN returns the parsed connection string
+func (c *Connector) DSN() *DSN {
+	return c.dsn
+}
+
+// Driver returns the Postgres driver
+func (c *Connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// Connect opens a connection to the first host that accepts one matching
+// target_session_attrs. With prefer-standby, a primary is used only if no
+// standby is available.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var password string
+	if c.credentials != nil {
+		password = c.credentials.Value()
+	}
+	attrs := c.dsn.TargetSessionAttrs
+	passes := []string{attrs}
+	if attrs == "prefer-standby" {
+		passes = []string{"standby", "any"}
+	}
+
+	var errs []error
+	for _, want := range passes {
+		for _, host := range c.dsn.Hosts {
+			conn, err := c.connect(ctx, c.dsn.connString(host, password))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s:%s: %w", host.Name, host.Port, err))
+				continue
+			}
+			ok, err := matches(ctx, conn, want)
+			if err == nil && ok {
+				return conn, nil
+			}
+			conn.Close()
+			if err == nil {
+				err = fmt.Errorf("%w %s", ErrSessionAttrs, want)
+			}
+			errs = append(errs, fmt.Errorf("%s:%s: %w", host.Name, host.Port, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Ping opens a connection and closes it, to check the database is reachable
+func (c *Connector) Ping(ctx context.Context) error {
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// matches reports whether the session on conn has the attributes want, a
+// value of target_session_attrs other than prefer-standby
+func matches(ctx context.Context, conn driver.Conn, want string) (bool, error) {
+	switch want {
+	case "read-write", "read-only":
+		readOnly, err := queryBool(ctx, conn, "SHOW transaction_read_only")
+		if err != nil {
+			return false, err
+		}
+		return readOnly == (want == "read-only"), nil
+	case "primary", "standby":
+		inRecovery, err := queryBool(ctx, conn, "SELECT pg_is_in_recovery()")
+		if err != nil {
+			return false, err
+		}
+		return inRecovery == (want == "standby"), nil
+	default:
+		return true, nil
+	}
+}
+
+// queryBool runs a query returning one boolean, or "on" or "off", on conn
+func queryBool(ctx context.Context, conn driver.Conn, query string) (bool, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return false, errors.New("driver connection cannot run queries")
+	}
+	rows, err := queryer.QueryContext(ctx, query, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session attributes: %w", err)
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("no rows")
+		}
+		return false, fmt.Errorf("failed to check session attributes: %w", err)
+	}
+	switch v := dest[0].(type) {
+	case bool:
+		return v, nil
+	case []byte:
+		return string(v) == "on", nil
+	case string:
+		return v == "on", nil
+	default:
+		return false, fmt.Errorf("failed to check session attributes: unexpected value %v", v)
+	}
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+
+	"github.com/zcking/go-api-template/internal/secrets"
+)
+
+// listenBackoff is how long Listen waits before reconnecting
+var listenBackoff = Backoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second}
+
+// listenConn is the part of *pgx.Conn a Listener uses
+type listenConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// Listener receives Postgres notifications, sent with NOTIFY or
+// pg_notify, on a dedicated connection. LISTEN belongs to a session, so it
+// cannot use a pooled connection: the pool would hand the session to other
+// requests, and notifications arriving while none is waiting for them would
+// be lost.
+type Listener struct {
+	connect func(ctx context.Context) (listenConn, error)
+	backoff Backoff
+	logger  *slog.Logger
+}
+
+// NewListener creates a listener that connects to dsn, a URL or
+// keyword=value pairs that may list several hosts (see ParseDSN). When
+// credentials is set, each connection uses its current value as the
+// password. It does not connect until Listen is called.
+func NewListener(dsn string, credentials *secrets.Secret, logger *slog.Logger) (*Listener, error) {
+	parsed, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	config, err := pgx.ParseConfig(parsed.keywords())
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	config.Tracer = newTracer(parsed, otel.GetTracerProvider())
+	return &Listener{
+		connect: func(ctx context.Context) (listenConn, error) {
+			cc := config.Copy()
+			if credentials != nil {
+				cc.Password = credentials.Value()
+			}
+			return pgx.ConnectConfig(ctx, cc)
+		},
+		backoff: listenBackoff,
+		logger:  logger,
+	}, nil
+}
+
+// Listen calls fn with the payload of each notification sent on channel
+// until ctx is done. When the connection fails it is reopened, with
+// backoff, and notifications sent while it was down are missed, so fn
+// should not rely on seeing every one. It returns nil once ctx is done, or
+// the error of a database that is reachable but refuses the connection.
+func (l *Listener) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	attempt := 0
+	for {
+		err := l.listen(ctx, channel, fn, func() { attempt = 0 })
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !Unreachable(err) {
+			return err
+		}
+		attempt++
+		wait := l.backoff.Wait(attempt)
+		l.logger.Warn("lost database notifications connection; reconnecting",
+			"channel", channel,
+			"attempt", attempt,
+			"retry_in", wait.Round(time.Millisecond).String(),
+			"error", err,
+		)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// listen connects, listens on channel and delivers notifications until the
+// connection fails or ctx is done. It calls listening once LISTEN succeeds.
+func (l *Listener) listen(ctx context.Context, channel string, fn func(payload string), listening func()) error {
+	conn, err := l.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// ctx may be done already; closing gets a short grace period
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	listening()
+	l.logger.Debug("listening for database notifications", "channel", channel)
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(n.Payload)
+	}
+}
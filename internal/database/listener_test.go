@@ -0,0 +1,133 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeListenConn delivers the payloads queued on notifications, then fails
+// with err, or blocks until ctx is done if err is nil
+type fakeListenConn struct {
+	notifications chan string
+	err           error
+
+	mu       sync.Mutex
+	executed []string
+	closed   bool
+}
+
+func (c *fakeListenConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.executed = append(c.executed, sql)
+	return pgconn.NewCommandTag("LISTEN"), nil
+}
+
+func (c *fakeListenConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case payload, ok := <-c.notifications:
+		if !ok {
+			if c.err != nil {
+				return nil, c.err
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &pgconn.Notification{Channel: "users_changed", Payload: payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeListenConn) Close(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+// newTestListener returns a listener whose connects return conns in turn,
+// then errNext
+func newTestListener(logs io.Writer, errNext error, conns ...*fakeListenConn) *Listener {
+	var mu sync.Mutex
+	return &Listener{
+		connect: func(ctx context.Context) (listenConn, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(conns) == 0 {
+				return nil, errNext
+			}
+			conn := conns[0]
+			conns = conns[1:]
+			return conn, nil
+		},
+		backoff: testBackoff,
+		logger:  slog.New(slog.NewTextHandler(logs, nil)),
+	}
+}
+
+func TestListener_Listen(t *testing.T) {
+	dropped := &fakeListenConn{notifications: make(chan string, 2), err: io.ErrUnexpectedEOF}
+	dropped.notifications <- "one"
+	close(dropped.notifications)
+	second := &fakeListenConn{notifications: make(chan string, 2)}
+	second.notifications <- "two"
+	var logs bytes.Buffer
+	l := newTestListener(&logs, errors.New("unused"), dropped, second)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var payloads []string
+	err := l.Listen(ctx, "users_changed", func(payload string) {
+		payloads = append(payloads, payload)
+		if len(payloads) == 2 {
+			cancel()
+		}
+	})
+
+	require.NoError(t, err, "Listen returns nil once ctx is done")
+	assert.Equal(t, []string{"one", "two"}, payloads, "notifications keep arriving after a reconnect")
+	assert.Equal(t, []string{`LISTEN "users_changed"`}, dropped.executed)
+	assert.Equal(t, []string{`LISTEN "users_changed"`}, second.executed, "the new connection listens again")
+	assert.True(t, dropped.closed)
+	assert.True(t, second.closed)
+	assert.Contains(t, logs.String(), "lost database notifications connection; reconnecting")
+}
+
+func TestListener_Listen_Refused(t *testing.T) {
+	l := newTestListener(io.Discard, &pgconn.PgError{Code: "28P01", Message: "password authentication failed"})
+
+	err := l.Listen(t.Context(), "users_changed", func(string) {})
+
+	assert.ErrorContains(t, err, "password authentication failed", "errors from a reachable database are returned")
+}
+
+func TestListener_Listen_Unreachable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	var logs bytes.Buffer
+	l := newTestListener(&logs, io.ErrUnexpectedEOF)
+
+	err := l.Listen(ctx, "users_changed", func(string) {})
+
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "attempt=2", "connecting is retried until ctx is done")
+}
+
+func TestNewListener(t *testing.T) {
+	l, err := NewListener("postgres://api@db1,db2/app", nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	assert.NotNil(t, l.connect)
+
+	_, err = NewListener("host=db1 target_session_attrs=nearest", nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.ErrorContains(t, err, "invalid target_session_attrs")
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LSN is a position in the Postgres write-ahead log
+type LSN uint64
+
+// ParseLSN parses the text form of a pg_lsn, two hexadecimal numbers such as
+// 16/B374D848
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	return LSN(h<<32 | l), nil
+}
+
+// String returns the text form of l, as Postgres prints it
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLSN(t *testing.T) {
+	lsn, err := ParseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, LSN(0x16_B374D848), lsn)
+	assert.Equal(t, "16/B374D848", lsn.String())
+	assert.Equal(t, "0/0", LSN(0).String())
+
+	later, err := ParseLSN("17/0")
+	require.NoError(t, err)
+	assert.Greater(t, later, lsn, "positions compare in log order")
+
+	for _, invalid := range []string{"", "16", "16/", "G/1", "1/100000000"} {
+		_, err := ParseLSN(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zcking/go-api-template/internal/secrets"
+)
+
+// OpenPool opens a pgx connection pool for dsn, a URL or keyword=value
+// pairs that may list several hosts (see ParseDSN), with the same settings
+// as Open. Queries, batches, copies and connects are traced (see Tracer) and
+// the pool statistics are reported as metrics. When credentials is set, each
+// new connection uses its current value as the password. It does not
+// connect; callers should ping the pool.
+func OpenPool(dsn string, credentials *secrets.Secret, pool Pool) (*pgxpool.Pool, error) {
+	return openPool(dsn, credentials, pool, otel.GetTracerProvider(), otel.GetMeterProvider())
+}
+
+// openPool opens a pgx pool whose spans and metrics are recorded with tp and mp
+func openPool(dsn string, credentials *secrets.Secret, pool Pool, tp trace.TracerProvider, mp metric.MeterProvider) (*pgxpool.Pool, error) {
+	parsed, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	config, err := poolConfig(parsed, credentials, pool)
+	if err != nil {
+		return nil, err
+	}
+	config.ConnConfig.Tracer = newTracer(parsed, tp)
+	p, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection pool: %w", err)
+	}
+	if err := registerPoolMetrics(p, parsed, mp); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// poolConfig returns the pgxpool configuration of dsn. database/sql's
+// MaxIdleConns has no pgxpool equivalent: idle connections are closed after
+// ConnMaxIdleTime instead.
+func poolConfig(dsn *DSN, credentials *secrets.Secret, pool Pool) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(dsn.keywords())
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	if pool.MaxOpenConns > 0 {
+		config.MaxConns = int32(pool.MaxOpenConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		config.MaxConnLifetime = pool.ConnMaxLifetime
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		config.MaxConnIdleTime = pool.ConnMaxIdleTime
+	}
+	if credentials != nil {
+		config.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+			cc.Password = credentials.Value()
+			return nil
+		}
+	}
+	return config, nil
+}
+
+// registerPoolMetrics reports the statistics of p as gauges
+func registerPoolMetrics(p *pgxpool.Pool, dsn *DSN, mp metric.MeterProvider) error {
+	meter := mp.Meter("github.com/zcking/go-api-template/internal/database")
+	attrs := metric.WithAttributes(dsn.attributes()...)
+	gauges := []struct {
+		name, description string
+		value             func(*pgxpool.Stat) int64
+	}{
+		{"db.pgx.pool.max", "Maximum number of connections in the pool", func(s *pgxpool.Stat) int64 { return int64(s.MaxConns()) }},
+		{"db.pgx.pool.total", "Number of open connections in the pool", func(s *pgxpool.Stat) int64 { return int64(s.TotalConns()) }},
+		{"db.pgx.pool.acquired", "Number of connections in use", func(s *pgxpool.Stat) int64 { return int64(s.AcquiredConns()) }},
+		{"db.pgx.pool.idle", "Number of idle connections", func(s *pgxpool.Stat) int64 { return int64(s.IdleConns()) }},
+		{"db.pgx.pool.empty_acquires", "Number of acquires that waited for a connection", func(s *pgxpool.Stat) int64 { return s.EmptyAcquireCount() }},
+	}
+	instruments := make([]metric.Observable, 0, len(gauges))
+	observers := make([]metric.Int64ObservableGauge, 0, len(gauges))
+	for _, g := range gauges {
+		gauge, err := meter.Int64ObservableGauge(g.name, metric.WithDescription(g.description))
+		if err != nil {
+			return fmt.Errorf("failed to register connection pool metrics: %w", err)
+		}
+		instruments = append(instruments, gauge)
+		observers = append(observers, gauge)
+	}
+	_, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := p.Stat()
+		for i, g := range gauges {
+			o.ObserveInt64(observers[i], g.value(stat), attrs)
+		}
+		return nil
+	}, instruments...)
+	if err != nil {
+		return fmt.Errorf("failed to register connection pool metrics: %w", err)
+	}
+	return nil
+}
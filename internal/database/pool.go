@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+// Pool holds the connection pool settings. Zero values keep the
+// database/sql defaults.
+type Pool struct {
+	// MaxOpenConns is the maximum number of open connections; 0 is unlimited
+	MaxOpenConns int
+	// MaxIdleConns is the maximum number of idle connections; 0 keeps the
+	// default of 2
+	MaxIdleConns int
+	// ConnMaxLifetime is how long a connection may be reused; 0 is forever
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime is how long a connection may be idle; 0 is forever
+	ConnMaxIdleTime time.Duration
+}
+
+// IdleConns returns the maximum number of idle connections the pool keeps
+func (p Pool) IdleConns() int {
+	if p.MaxIdleConns == 0 {
+		return 2
+	}
+	return p.MaxIdleConns
+}
+
+// Split divides p's limit on open connections between two pools to the same
+// database, e.g. a pgx pool and a database/sql pool, so together they open no
+// more than p allows. The first gets the larger half and each gets at least
+// one connection. The other settings apply to both.
+func (p Pool) Split() (Pool, Pool) {
+	first, second := p, p
+	if p.MaxOpenConns > 0 {
+		first.MaxOpenConns = max(1, (p.MaxOpenConns+1)/2)
+		second.MaxOpenConns = max(1, p.MaxOpenConns/2)
+	}
+	return first, second
+}
+
+// Open opens a connection pool with OpenTelemetry tracing and pool
+// statistics metrics. It does not connect; callers should ping the pool.
+func Open(connector *Connector, pool Pool) (*sql.DB, error) {
+	return open(connector, pool, otel.GetMeterProvider())
+}
+
+// open opens a connection pool whose metrics are recorded with mp
+func open(connector *Connector, pool Pool, mp metric.MeterProvider) (*sql.DB, error) {
+	attrs := otelsql.WithAttributes(connector.dsn.attributes()...)
+	db := otelsql.OpenDB(connector, attrs)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.IdleConns())
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	if err := otelsql.RegisterDBStatsMetrics(db, attrs, otelsql.WithMeterProvider(mp)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to register connection pool metrics: %w", err)
+	}
+	return db, nil
+}
+
+// attributes returns the span and metric attributes for connections. The
+// peer is the first host, which is the primary in most deployments.
+func (d *DSN) attributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.name", d.Params["dbname"]),
+		attribute.String("db.user", d.Params["user"]),
+		attribute.String("net.peer.name", d.Hosts[0].Name),
+		attribute.String("net.peer.port", d.Hosts[0].Port),
+	}
+}
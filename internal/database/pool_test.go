@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestOpen(t *testing.T) {
+	connector, err := NewConnector("postgres://api@db1:6432,db2/app", nil)
+	require.NoError(t, err)
+	reader := sdkmetric.NewManualReader()
+	pool := Pool{MaxOpenConns: 20, MaxIdleConns: 10, ConnMaxLifetime: 30 * time.Minute, ConnMaxIdleTime: 5 * time.Minute}
+
+	db, err := open(connector, pool, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, 20, db.Stats().MaxOpenConnections)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	require.Contains(t, metrics, "db.sql.connection.max_open")
+	maxOpen := metrics["db.sql.connection.max_open"].(metricdata.Gauge[int64]).DataPoints[0]
+	assert.Equal(t, int64(20), maxOpen.Value)
+	peer, _ := maxOpen.Attributes.Value("net.peer.name")
+	assert.Equal(t, "db1", peer.AsString(), "pool metrics identify the first host")
+	assert.Contains(t, metrics, "db.sql.connection.open")
+	assert.Contains(t, metrics, "db.sql.connection.wait")
+}
+
+func TestPool_IdleConns(t *testing.T) {
+	assert.Equal(t, 2, Pool{}.IdleConns(), "the database/sql default")
+	assert.Equal(t, 10, Pool{MaxIdleConns: 10}.IdleConns())
+}
+
+func TestPool_Split(t *testing.T) {
+	pool := Pool{MaxOpenConns: 21, MaxIdleConns: 10, ConnMaxLifetime: 30 * time.Minute}
+	first, second := pool.Split()
+	assert.Equal(t, Pool{MaxOpenConns: 11, MaxIdleConns: 10, ConnMaxLifetime: 30 * time.Minute}, first)
+	assert.Equal(t, Pool{MaxOpenConns: 10, MaxIdleConns: 10, ConnMaxLifetime: 30 * time.Minute}, second)
+
+	first, second = Pool{MaxOpenConns: 1}.Split()
+	assert.Equal(t, 1, first.MaxOpenConns)
+	assert.Equal(t, 1, second.MaxOpenConns, "each pool needs a connection")
+
+	first, second = Pool{}.Split()
+	assert.Zero(t, first.MaxOpenConns, "unlimited stays unlimited")
+	assert.Zero(t, second.MaxOpenConns)
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// Backoff configures how long Retry waits between attempts
+type Backoff struct {
+	// Initial is the wait after the first failure, doubled after each one
+	Initial time.Duration
+	// Max is the longest wait
+	Max time.Duration
+}
+
+// Wait returns how long to wait after the attempt'th failure, with jitter so
+// replicas starting together do not retry together
+func (b Backoff) Wait(attempt int) time.Duration {
+	d := b.Max
+	if attempt < 32 {
+		d = min(b.Initial<<(attempt-1), b.Max)
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// Retry calls fn until it succeeds, fails with an error that does not mean
+// the database is unreachable (see Unreachable), or ctx is done, which
+// bounds the total time. Each failure is logged with the time spent so far.
+func Retry(ctx context.Context, what string, b Backoff, logger *slog.Logger, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("database reachable", "step", what, "attempts", attempt, "elapsed", time.Since(start).Round(time.Millisecond).String())
+			}
+			return nil
+		}
+		if !Unreachable(err) {
+			return err
+		}
+
+		wait := b.Wait(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("database still unreachable after %d attempts in %s: %w", attempt, time.Since(start).Round(time.Millisecond), err)
+		}
+		logger.Warn("database unreachable; retrying",
+			"step", what,
+			"attempt", attempt,
+			"retry_in", wait.Round(time.Millisecond).String(),
+			"elapsed", time.Since(start).Round(time.Millisecond).String(),
+			"error", err,
+		)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("stopped waiting for the database after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// unreachableCodes are the Postgres error codes of a server that is
+// running but not yet accepting connections, or has none to spare
+var unreachableCodes = map[string]bool{
+	"57P03": true, // cannot_connect_now: starting up, shutting down or in recovery
+	"53300": true, // too_many_connections
+}
+
+// Unreachable reports whether err means the database could not be reached,
+// or is not ready yet, so the operation may succeed if retried. Errors from
+// a reachable database, such as a failed migration, are not.
+func Unreachable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Class() == "08" || unreachableCodes[string(pqErr.Code)]
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || unreachableCodes[pgErr.Code]
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, ErrSessionAttrs) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
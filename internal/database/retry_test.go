@@ -0,0 +1,106 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBackoff = Backoff{Initial: time.Millisecond, Max: 4 * time.Millisecond}
+
+func TestUnreachable(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection refused", err: fmt.Errorf("failed to ping database: %w", refused), want: true},
+		{name: "unknown host", err: &net.DNSError{Err: "no such host", Name: "db", IsNotFound: true}, want: true},
+		{name: "every host down", err: errors.Join(fmt.Errorf("db1:5432: %w", refused), fmt.Errorf("db2:5432: %w", refused)), want: true},
+		{name: "no primary yet", err: fmt.Errorf("db1:5432: %w read-write", ErrSessionAttrs), want: true},
+		{name: "server starting up", err: &pq.Error{Code: "57P03"}, want: true},
+		{name: "too many connections", err: &pq.Error{Code: "53300"}, want: true},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, want: true},
+		{name: "connection closed", err: io.ErrUnexpectedEOF, want: true},
+		{name: "pgx server starting up", err: &pgconn.PgError{Code: "57P03"}, want: true},
+		{name: "pgx connection failure", err: fmt.Errorf("failed to connect: %w", &pgconn.PgError{Code: "08001"}), want: true},
+		{name: "pgx wrong password", err: &pgconn.PgError{Code: "28P01"}},
+		{name: "wrong password", err: &pq.Error{Code: "28P01"}},
+		{name: "failed migration", err: &pq.Error{Code: "42601"}},
+		{name: "dirty schema", err: errors.New("database schema is dirty at version 3")},
+		{name: "deadline", err: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Unreachable(tt.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	calls := 0
+	err := Retry(t.Context(), "migrate", testBackoff, logger, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return &pq.Error{Code: "57P03", Message: "the database system is starting up"}
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, strings.Count(logs.String(), "database unreachable; retrying"), "each failure is logged")
+	assert.Contains(t, logs.String(), "attempt=2")
+	assert.Contains(t, logs.String(), "database reachable")
+}
+
+func TestRetry_StopsOnOtherErrors(t *testing.T) {
+	calls := 0
+	err := Retry(t.Context(), "migrate", testBackoff, slog.New(slog.NewTextHandler(io.Discard, nil)), func(ctx context.Context) error {
+		calls++
+		return &pq.Error{Code: "28P01", Message: "password authentication failed"}
+	})
+
+	assert.ErrorContains(t, err, "password authentication failed")
+	assert.Equal(t, 1, calls, "errors from a reachable database are not retried")
+}
+
+func TestRetry_Deadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+
+	start := time.Now()
+	err := Retry(ctx, "connect", testBackoff, slog.New(slog.NewTextHandler(io.Discard, nil)), func(ctx context.Context) error {
+		return refused
+	})
+
+	assert.ErrorIs(t, err, refused)
+	assert.ErrorContains(t, err, "database still unreachable after")
+	assert.Less(t, time.Since(start), time.Second, "retries stop at the deadline")
+}
+
+func TestBackoff_Wait(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 5: time.Second, 40: time.Second} {
+		wait := b.Wait(attempt)
+		assert.GreaterOrEqual(t, wait, want/2, "attempt %d", attempt)
+		assert.LessOrEqual(t, wait, want, "attempt %d", attempt)
+	}
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// LSNMetadataKey is the gRPC metadata key of the session LSN token: the
+// primary's write-ahead log position after a mutating RPC, returned in its
+// response header. A client that sends the token back with a read-only RPC
+// reads its own writes, from a replica that has replayed that position or
+// else from the primary.
+const LSNMetadataKey = "x-db-lsn"
+
+// Conn is the part of *pgxpool.Pool a Router routes between
+type Conn interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Replica is a read replica of the primary
+type Replica struct {
+	// Name identifies the replica in logs, such as its host
+	Name string
+	Conn Conn
+}
+
+// replica is a Replica and the result of its last health check
+type replica struct {
+	Replica
+	healthy atomic.Bool
+	// replayed is the LSN the replica had replayed at its last check
+	replayed atomic.Uint64
+}
+
+// Router sends the queries of read-only calls (see WithReadOnly) to read
+// replicas, in turn, and everything else to the primary. Replicas are
+// checked periodically by Run; one that fails a check, or has not replayed
+// the call's session LSN, is skipped, and when none is left the primary
+// serves the read. Transactions and Exec always use the primary.
+type Router struct {
+	primary  Conn
+	replicas []*replica
+	next     atomic.Uint64
+	logger   *slog.Logger
+}
+
+// NewRouter creates a router between primary and replicas. Replicas are
+// not used until Run has checked them.
+func NewRouter(primary Conn, replicas []Replica, logger *slog.Logger) *Router {
+	r := &Router{primary: primary, logger: logger}
+	for _, rep := range replicas {
+		r.replicas = append(r.replicas, &replica{Replica: rep})
+	}
+	return r
+}
+
+// readKey is the context key of a read-only call
+type readKey struct{}
+
+// WithReadOnly marks ctx as the context of a read-only call, whose queries
+// may be served by a replica that has replayed minLSN
+func WithReadOnly(ctx context.Context, minLSN LSN) context.Context {
+	return context.WithValue(ctx, readKey{}, minLSN)
+}
+
+// conn returns the connection for the queries of ctx
+func (r *Router) conn(ctx context.Context) Conn {
+	minLSN, ok := ctx.Value(readKey{}).(LSN)
+	if !ok || len(r.replicas) == 0 {
+		return r.primary
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() && LSN(rep.replayed.Load()) >= minLSN {
+			return rep.Conn
+		}
+	}
+	return r.primary
+}
+
+// Query runs a query on a replica for read-only calls, or else the primary
+func (r *Router) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return r.conn(ctx).Query(ctx, sql, args...)
+}
+
+// QueryRow runs a query on a replica for read-only calls, or else the primary
+func (r *Router) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return r.conn(ctx).QueryRow(ctx, sql, args...)
+}
+
+// Exec runs a statement on the primary
+func (r *Router) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return r.primary.Exec(ctx, sql, args...)
+}
+
+// Begin starts a transaction on the primary
+func (r *Router) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.primary.Begin(ctx)
+}
+
+// Run checks the replicas every interval until ctx is done
+func (r *Router) Run(ctx context.Context, interval time.Duration) {
+	if len(r.replicas) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, rep := range r.replicas {
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			r.check(checkCtx, rep)
+			cancel()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check records whether rep answers and how far it has replayed. A replica
+// that was promoted is no longer in recovery and reports its own position.
+func (r *Router) check(ctx context.Context, rep *replica) {
+	var text string
+	err := rep.Conn.QueryRow(ctx, "SELECT COALESCE(pg_last_wal_replay_lsn(), pg_current_wal_lsn())::text").Scan(&text)
+	var lsn LSN
+	if err == nil {
+		lsn, err = ParseLSN(text)
+	}
+	if err != nil {
+		if rep.healthy.Swap(false) {
+			r.logger.Warn("read replica failed its health check; reading from the others or the primary",
+				"replica", rep.Name, "error", err)
+		}
+		return
+	}
+	rep.replayed.Store(uint64(lsn))
+	if !rep.healthy.Swap(true) {
+		r.logger.Info("read replica is healthy", "replica", rep.Name, "replayed_lsn", lsn.String())
+	}
+}
+
+// CurrentLSN returns the primary's current write-ahead log position
+func (r *Router) CurrentLSN(ctx context.Context) (LSN, error) {
+	var text string
+	if err := r.primary.QueryRow(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&text); err != nil {
+		return 0, err
+	}
+	return ParseLSN(text)
+}
+
+// UnaryServerInterceptor returns an interceptor that marks read-only RPCs,
+// declared in proto with idempotency_level = NO_SIDE_EFFECTS, so their
+// queries go to replicas, and returns the session LSN token (see
+// LSNMetadataKey) in the header of the others that succeed. Without
+// replicas it does nothing.
+func (r *Router) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if len(r.replicas) == 0 {
+			return handler(ctx, req)
+		}
+		if readOnlyMethod(info.FullMethod) {
+			minLSN, err := sessionLSN(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(WithReadOnly(ctx, minLSN), req)
+		}
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			lsn, lsnErr := r.CurrentLSN(ctx)
+			if lsnErr == nil {
+				lsnErr = grpc.SetHeader(ctx, metadata.Pairs(LSNMetadataKey, lsn.String()))
+			}
+			if lsnErr != nil {
+				r.logger.WarnContext(ctx, "failed to return the session LSN token",
+					"error", lsnErr, "method", info.FullMethod)
+			}
+		}
+		return resp, err
+	}
+}
+
+// readOnlyMethod reports whether the method named fullMethod
+// (/package.Service/Method) is declared with no side effects
+func readOnlyMethod(fullMethod string) bool {
+	name := strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", ".")
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return false
+	}
+	method, ok := d.(protoreflect.MethodDescriptor)
+	if !ok {
+		return false
+	}
+	opts, ok := method.Options().(*descriptorpb.MethodOptions)
+	return ok && opts.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS
+}
+
+// sessionLSN returns the session LSN token of the incoming call, or 0 if it
+// has none
+func sessionLSN(ctx context.Context) (LSN, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(LSNMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	lsn, err := ParseLSN(values[0])
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %s: %v", LSNMetadataKey, err)
+	}
+	return lsn, nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	_ "github.com/zcking/go-api-template/gen/go/users/v1"
+)
+
+// selectReplayed matches a replica health check
+const selectReplayed = `SELECT COALESCE\(pg_last_wal_replay_lsn\(\), pg_current_wal_lsn\(\)\)::text`
+
+// newTestRouter returns a router between mock pools: a primary and two
+// replicas, replica-1 and replica-2
+func newTestRouter(t *testing.T, logs io.Writer) (*Router, pgxmock.PgxPoolIface, []pgxmock.PgxPoolIface) {
+	t.Helper()
+	newPool := func() pgxmock.PgxPoolIface {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		t.Cleanup(mock.Close)
+		return mock
+	}
+	primary, replicas := newPool(), []pgxmock.PgxPoolIface{newPool(), newPool()}
+	return NewRouter(primary, []Replica{{Name: "replica-1", Conn: replicas[0]}, {Name: "replica-2", Conn: replicas[1]}},
+		slog.New(slog.NewTextHandler(logs, nil))), primary, replicas
+}
+
+// expectCheck expects a health check of mock reporting lsn
+func expectCheck(mock pgxmock.PgxPoolIface, lsn string) {
+	mock.ExpectQuery(selectReplayed).WillReturnRows(pgxmock.NewRows([]string{"lsn"}).AddRow(lsn))
+}
+
+func TestRouter_Conn(t *testing.T) {
+	router, primary, replicas := newTestRouter(t, io.Discard)
+	read := WithReadOnly(t.Context(), 0)
+
+	assert.Equal(t, primary, router.conn(read), "replicas are not used until they are checked")
+
+	expectCheck(replicas[0], "1/100")
+	expectCheck(replicas[1], "1/200")
+	for _, rep := range router.replicas {
+		router.check(t.Context(), rep)
+	}
+	first, second := router.conn(read), router.conn(read)
+	assert.ElementsMatch(t, []Conn{replicas[0], replicas[1]}, []Conn{first, second}, "reads take turns between replicas")
+	assert.Equal(t, primary, router.conn(t.Context()), "calls that are not read-only use the primary")
+
+	caughtUp := WithReadOnly(t.Context(), LSN(0x1_00000150))
+	for range 3 {
+		assert.Equal(t, replicas[1], router.conn(caughtUp), "only replicas that replayed the session LSN serve it")
+	}
+	assert.Equal(t, primary, router.conn(WithReadOnly(t.Context(), LSN(0x1_00000300))), "the primary serves reads no replica has caught up with")
+}
+
+func TestRouter_QueriesAndTransactions(t *testing.T) {
+	router, primary, replicas := newTestRouter(t, io.Discard)
+	expectCheck(replicas[0], "0/10")
+	router.check(t.Context(), router.replicas[0])
+	read := WithReadOnly(t.Context(), 0)
+
+	replicas[0].ExpectQuery("SELECT id FROM users").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	var id int64
+	require.NoError(t, router.QueryRow(read, "SELECT id FROM users").Scan(&id))
+	primary.ExpectExec("UPDATE users").WithArgs("Ada").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	_, err := router.Exec(read, "UPDATE users SET name = $1", "Ada")
+	require.NoError(t, err)
+	primary.ExpectBegin()
+	_, err = router.Begin(read)
+	require.NoError(t, err)
+
+	assert.NoError(t, primary.ExpectationsWereMet(), "writes and transactions use the primary")
+	assert.NoError(t, replicas[0].ExpectationsWereMet())
+}
+
+func TestRouter_Check(t *testing.T) {
+	var logs bytes.Buffer
+	router, primary, replicas := newTestRouter(t, &logs)
+	rep := router.replicas[0]
+
+	expectCheck(replicas[0], "0/10")
+	router.check(t.Context(), rep)
+	assert.True(t, rep.healthy.Load())
+	assert.Equal(t, uint64(0x10), rep.replayed.Load())
+	assert.Contains(t, logs.String(), "read replica is healthy")
+
+	replicas[0].ExpectQuery(selectReplayed).WillReturnError(errors.New("connection refused"))
+	router.check(t.Context(), rep)
+	assert.False(t, rep.healthy.Load())
+	assert.Contains(t, logs.String(), "read replica failed its health check")
+	assert.Contains(t, logs.String(), "replica=replica-1")
+	assert.Equal(t, primary, router.conn(WithReadOnly(t.Context(), 0)), "unhealthy replicas are skipped")
+}
+
+func TestRouter_Run(t *testing.T) {
+	router, _, replicas := newTestRouter(t, io.Discard)
+	expectCheck(replicas[0], "0/10")
+	expectCheck(replicas[1], "0/20")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		router.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return router.replicas[0].healthy.Load() && router.replicas[1].healthy.Load()
+	}, time.Second, time.Millisecond, "replicas are checked as soon as Run starts")
+	cancel()
+	<-done
+}
+
+// headerStream records the headers a handler sets
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRouter_UnaryServerInterceptor(t *testing.T) {
+	router, primary, replicas := newTestRouter(t, io.Discard)
+	interceptor := router.UnaryServerInterceptor()
+	var handled context.Context
+	handler := func(ctx context.Context, req any) (any, error) {
+		handled = ctx
+		return "ok", nil
+	}
+
+	t.Run("read-only RPCs use replicas", func(t *testing.T) {
+		expectCheck(replicas[0], "1/0")
+		router.check(t.Context(), router.replicas[0])
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(LSNMetadataKey, "0/FF"))
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/ListUsers"}, handler)
+		require.NoError(t, err)
+		assert.Equal(t, LSN(0xFF), handled.Value(readKey{}), "the session LSN is carried to the router")
+		assert.Equal(t, replicas[0], router.conn(handled))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(LSNMetadataKey, "latest"))
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/ListUsers"}, handler)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("mutating RPCs return the session LSN", func(t *testing.T) {
+		primary.ExpectQuery(`SELECT pg_current_wal_lsn\(\)::text`).WillReturnRows(pgxmock.NewRows([]string{"lsn"}).AddRow("1/2A"))
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(t.Context(), stream)
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/CreateUser"}, handler)
+		require.NoError(t, err)
+		assert.Nil(t, handled.Value(readKey{}), "mutating RPCs read from the primary")
+		assert.Equal(t, []string{"1/2A"}, stream.header.Get(LSNMetadataKey))
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+
+	t.Run("failed RPCs", func(t *testing.T) {
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(t.Context(), stream)
+		failing := func(context.Context, any) (any, error) { return nil, status.Error(codes.NotFound, "no user") }
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/CreateUser"}, failing)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Empty(t, stream.header, "the token is only returned after a successful change")
+	})
+}
+
+func TestRouter_UnaryServerInterceptor_NoReplicas(t *testing.T) {
+	primary, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer primary.Close()
+	router := NewRouter(primary, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	resp, err := router.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/CreateUser"},
+		func(context.Context, any) (any, error) { return "ok", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.NoError(t, primary.ExpectationsWereMet(), "no token is read without replicas")
+}
+
+func TestReadOnlyMethod(t *testing.T) {
+	assert.True(t, readOnlyMethod("/users.v1.UserService/ListUsers"))
+	assert.True(t, readOnlyMethod("/users.v1.UserService/GetUserActivity"))
+	assert.False(t, readOnlyMethod("/users.v1.UserService/CreateUser"))
+	assert.False(t, readOnlyMethod("/users.v1.UserService/Unknown"))
+	assert.False(t, readOnlyMethod("/users.v1.CreateUserRequest/name"))
+}
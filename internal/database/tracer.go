@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer traces pgx queries, batches, copies and connects with
+// OpenTelemetry, as otelsql does for database/sql. Spans carry the SQL but
+// not the arguments, which may hold personal data.
+type Tracer struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+var (
+	_ pgx.QueryTracer    = (*Tracer)(nil)
+	_ pgx.BatchTracer    = (*Tracer)(nil)
+	_ pgx.CopyFromTracer = (*Tracer)(nil)
+	_ pgx.ConnectTracer  = (*Tracer)(nil)
+)
+
+// newTracer creates a tracer for connections to dsn whose spans are
+// recorded with tp
+func newTracer(dsn *DSN, tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: tp.Tracer("github.com/zcking/go-api-template/internal/database"),
+		attrs:  dsn.attributes(),
+	}
+}
+
+// start starts a client span with the connection attributes and attrs
+func (t *Tracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	ctx, _ = t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+// end ends the span of ctx, marking it failed if err is set
+func end(ctx context.Context, err error, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attrs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceQueryStart starts the span of a Query, QueryRow or Exec call
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, "pgx.query", statement(data.SQL)...)
+}
+
+// TraceQueryEnd ends the span of a query
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	end(ctx, data.Err, attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+// TraceBatchStart starts the span of a SendBatch call
+func (t *Tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, "pgx.batch", attribute.Int("db.batch.size", data.Batch.Len()))
+}
+
+// TraceBatchQuery adds an event for each query of a batch to its span
+func (t *Tracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	attrs := append(statement(data.SQL), attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	if data.Err != nil {
+		attrs = append(attrs, attribute.String("error", data.Err.Error()))
+	}
+	trace.SpanFromContext(ctx).AddEvent("query", trace.WithAttributes(attrs...))
+}
+
+// TraceBatchEnd ends the span of a batch
+func (t *Tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	end(ctx, data.Err)
+}
+
+// TraceCopyFromStart starts the span of a CopyFrom call
+func (t *Tracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return t.start(ctx, "pgx.copy_from",
+		attribute.String("db.operation", "COPY"),
+		attribute.String("db.sql.table", data.TableName.Sanitize()),
+		attribute.StringSlice("db.copy.columns", data.ColumnNames),
+	)
+}
+
+// TraceCopyFromEnd ends the span of a copy
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	end(ctx, data.Err, attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+// TraceConnectStart starts the span of a new connection
+func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	return t.start(ctx, "pgx.connect", attribute.String("server.address", data.ConnConfig.Host))
+}
+
+// TraceConnectEnd ends the span of a connection
+func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	end(ctx, data.Err)
+}
+
+// statement returns the attributes of a SQL statement. The operation is
+// its first word after any leading comment lines, such as the
+// "-- name: GetUser :one" of generated queries.
+func statement(sql string) []attribute.KeyValue {
+	body := strings.TrimSpace(sql)
+	for strings.HasPrefix(body, "--") {
+		_, body, _ = strings.Cut(body, "\n")
+		body = strings.TrimSpace(body)
+	}
+	operation, _, _ := strings.Cut(body, " ")
+	return []attribute.KeyValue{
+		attribute.String("db.statement", sql),
+		attribute.String("db.operation", strings.ToUpper(operation)),
+	}
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testTracer returns a tracer for a two-host DSN and the recorder of its spans
+func testTracer(t *testing.T) (*Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+	dsn, err := ParseDSN("postgres://api@db1,db2/app")
+	require.NoError(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	return newTracer(dsn, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))), recorder
+}
+
+// attrs returns the attributes of span by key
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracer_Query(t *testing.T) {
+	tracer, recorder := testTracer(t)
+
+	ctx := tracer.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "DELETE FROM users WHERE id = $1", Args: []any{42}})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("DELETE 1")})
+	ctx = tracer.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "select broken"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("syntax error")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "pgx.query", spans[0].Name())
+	got := attrs(spans[0])
+	assert.Equal(t, "DELETE FROM users WHERE id = $1", got["db.statement"].AsString())
+	assert.Equal(t, "DELETE", got["db.operation"].AsString())
+	assert.Equal(t, int64(1), got["db.rows_affected"].AsInt64())
+	assert.Equal(t, "db1", got["net.peer.name"].AsString())
+	assert.Equal(t, "postgresql", got["db.system"].AsString())
+	for _, kv := range spans[0].Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "42", "arguments are not recorded")
+	}
+
+	assert.Equal(t, "SELECT", attrs(spans[1])["db.operation"].AsString())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "syntax error", spans[1].Status().Description)
+}
+
+func TestStatement(t *testing.T) {
+	got := statement("-- name: GetUser :one\nSELECT id FROM users WHERE id = $1\n")
+
+	assert.Equal(t, attribute.String("db.operation", "SELECT"), got[1], "leading comments are skipped")
+	assert.Equal(t, attribute.String("db.operation", "UPDATE"), statement("update users SET name = $2")[1])
+	assert.Equal(t, attribute.String("db.operation", ""), statement("-- nothing")[1])
+}
+
+func TestTracer_Batch(t *testing.T) {
+	tracer, recorder := testTracer(t)
+	batch := &pgx.Batch{}
+	batch.Queue("UPDATE users SET key_version = $2 WHERE id = $1", 1, "v2")
+	batch.Queue("UPDATE users SET key_version = $2 WHERE id = $1", 2, "v2")
+
+	ctx := tracer.TraceBatchStart(t.Context(), nil, pgx.TraceBatchStartData{Batch: batch})
+	for _, q := range batch.QueuedQueries {
+		tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: q.SQL, CommandTag: pgconn.NewCommandTag("UPDATE 1")})
+	}
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "pgx.batch", spans[0].Name())
+	assert.Equal(t, int64(2), attrs(spans[0])["db.batch.size"].AsInt64())
+	assert.Len(t, spans[0].Events(), 2, "each query of the batch is an event")
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
+
+func TestTracer_CopyFrom(t *testing.T) {
+	tracer, recorder := testTracer(t)
+
+	ctx := tracer.TraceCopyFromStart(t.Context(), nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"users"}, ColumnNames: []string{"email", "name"}})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 3")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "pgx.copy_from", spans[0].Name())
+	got := attrs(spans[0])
+	assert.Equal(t, `"users"`, got["db.sql.table"].AsString())
+	assert.Equal(t, int64(3), got["db.rows_affected"].AsInt64())
+}
+
+func TestTracer_Connect(t *testing.T) {
+	tracer, recorder := testTracer(t)
+	config, err := pgx.ParseConfig("postgres://api@db2/app")
+	require.NoError(t, err)
+
+	ctx := tracer.TraceConnectStart(context.Background(), pgx.TraceConnectStartData{ConnConfig: config})
+	tracer.TraceConnectEnd(ctx, pgx.TraceConnectEndData{Err: errors.New("connection refused")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "pgx.connect", spans[0].Name())
+	assert.Equal(t, "db2", attrs(spans[0])["server.address"].AsString())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
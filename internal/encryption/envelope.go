@@ -0,0 +1,140 @@
+package encryption
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Envelope is a set of values encrypted with one data key. The data key is
+// stored wrapped by the key-encryption key of KeyVersion. The data key and
+// each value are bound to Owner, e.g. the id of the row they are stored in,
+// so an envelope copied to another row does not open. Each value is also
+// bound to its position, so values cannot be swapped between fields.
+//
+// Envelopes sealed before owners were introduced have an empty Owner.
+type Envelope struct {
+	KeyVersion string
+	Owner      string
+	DataKey    []byte
+	Values     [][]byte
+}
+
+// Seal encrypts values for owner under a new data key wrapped by the primary
+// key
+func (k *Keyring) Seal(owner string, values ...string) (*Envelope, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{KeyVersion: k.primary, Owner: owner, Values: make([][]byte, len(values))}
+	for i, v := range values {
+		if e.Values[i], err = seal(aead, []byte(v), valueAD(i, owner)); err != nil {
+			return nil, err
+		}
+	}
+	if e.DataKey, err = seal(k.keks[k.primary], dataKey, dataKeyAD(k.primary, owner)); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Open decrypts the values of an envelope
+func (k *Keyring) Open(e *Envelope) ([]string, error) {
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(e.Values))
+	for i, ciphertext := range e.Values {
+		plaintext, err := open(aead, ciphertext, valueAD(i, e.Owner))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt value %d: %w", i, err)
+		}
+		values[i] = string(plaintext)
+	}
+	return values, nil
+}
+
+// Rewrap wraps the data key of an envelope with the primary key, still bound
+// to the envelope's owner. The values are unchanged.
+func (k *Keyring) Rewrap(e *Envelope) error {
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return err
+	}
+	wrapped, err := seal(k.keks[k.primary], dataKey, dataKeyAD(k.primary, e.Owner))
+	if err != nil {
+		return err
+	}
+	e.KeyVersion, e.DataKey = k.primary, wrapped
+	return nil
+}
+
+// BlindIndex returns a keyed hash of value for equality lookups on an
+// encrypted column. Callers normalize value (e.g. lower-case an email)
+// before indexing and before looking it up.
+func (k *Keyring) BlindIndex(value string) []byte {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// unwrap decrypts the data key of an envelope
+func (k *Keyring) unwrap(e *Envelope) ([]byte, error) {
+	kek, ok := k.keks[e.KeyVersion]
+	if !ok {
+		return nil, fmt.Errorf("key version %q is not in the keyring", e.KeyVersion)
+	}
+	dataKey, err := open(kek, e.DataKey, dataKeyAD(e.KeyVersion, e.Owner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// dataKeyAD is the additional data binding a wrapped data key to its key
+// version and owner. An empty owner gives the additional data used before
+// owners were introduced.
+func dataKeyAD(version, owner string) []byte {
+	if owner == "" {
+		return []byte(version)
+	}
+	return append([]byte(version+"\x00"), owner...)
+}
+
+// valueAD is the additional data binding a value to its position and owner
+func valueAD(i int, owner string) []byte {
+	return append([]byte{byte(i)}, owner...)
+}
+
+// seal encrypts plaintext, prefixing the ciphertext with a random nonce
+func seal(aead cipher.AEAD, plaintext, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+// open decrypts a ciphertext produced by seal
+func open(aead cipher.AEAD, ciphertext, ad []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, ad)
+}
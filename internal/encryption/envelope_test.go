@@ -0,0 +1,99 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_SealOpen(t *testing.T) {
+	k, err := NewKeyring("1", map[string][]byte{"1": testKey(1)}, testKey(9))
+	require.NoError(t, err)
+
+	e, err := k.Seal("1", "ada@example.com", "Ada Lovelace")
+	require.NoError(t, err)
+	assert.Equal(t, "1", e.KeyVersion)
+	assert.Equal(t, "1", e.Owner)
+	assert.NotContains(t, string(e.Values[0]), "ada@example.com")
+
+	values, err := k.Open(e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ada@example.com", "Ada Lovelace"}, values)
+
+	t.Run("each seal uses a new data key", func(t *testing.T) {
+		other, err := k.Seal("1", "ada@example.com", "Ada Lovelace")
+		require.NoError(t, err)
+		assert.NotEqual(t, e.DataKey, other.DataKey)
+		assert.NotEqual(t, e.Values[0], other.Values[0])
+	})
+
+	t.Run("error - values swapped", func(t *testing.T) {
+		swapped := &Envelope{KeyVersion: e.KeyVersion, Owner: e.Owner, DataKey: e.DataKey, Values: [][]byte{e.Values[1], e.Values[0]}}
+		_, err := k.Open(swapped)
+		assert.ErrorContains(t, err, "failed to decrypt value 0")
+	})
+
+	t.Run("error - copied to another owner", func(t *testing.T) {
+		copied := &Envelope{KeyVersion: e.KeyVersion, Owner: "2", DataKey: e.DataKey, Values: e.Values}
+		_, err := k.Open(copied)
+		assert.ErrorContains(t, err, "failed to unwrap data key")
+	})
+
+	t.Run("error - copied without an owner", func(t *testing.T) {
+		copied := &Envelope{KeyVersion: e.KeyVersion, DataKey: e.DataKey, Values: e.Values}
+		_, err := k.Open(copied)
+		assert.ErrorContains(t, err, "failed to unwrap data key")
+	})
+
+	t.Run("error - tampered value", func(t *testing.T) {
+		tampered := &Envelope{KeyVersion: e.KeyVersion, Owner: e.Owner, DataKey: e.DataKey, Values: [][]byte{append([]byte{}, e.Values[0]...)}}
+		tampered.Values[0][len(tampered.Values[0])-1] ^= 1
+		_, err := k.Open(tampered)
+		assert.Error(t, err)
+	})
+
+	t.Run("error - unknown key version", func(t *testing.T) {
+		_, err := k.Open(&Envelope{KeyVersion: "0", DataKey: e.DataKey})
+		assert.ErrorContains(t, err, `key version "0" is not in the keyring`)
+	})
+
+	t.Run("error - data key wrapped under another version", func(t *testing.T) {
+		_, err := k.Open(&Envelope{KeyVersion: "1", DataKey: []byte("short")})
+		assert.ErrorContains(t, err, "failed to unwrap data key")
+	})
+}
+
+func TestKeyring_Rewrap(t *testing.T) {
+	old, err := NewKeyring("1", map[string][]byte{"1": testKey(1)}, testKey(9))
+	require.NoError(t, err)
+	e, err := old.Seal("1", "ada@example.com")
+	require.NoError(t, err)
+
+	rotated, err := NewKeyring("2", map[string][]byte{"1": testKey(1), "2": testKey(2)}, testKey(9))
+	require.NoError(t, err)
+	values := e.Values
+	require.NoError(t, rotated.Rewrap(e))
+	assert.Equal(t, "2", e.KeyVersion)
+	assert.Equal(t, "1", e.Owner)
+	assert.Equal(t, values, e.Values)
+
+	// Once rewrapped, the old key is no longer needed
+	retired, err := NewKeyring("2", map[string][]byte{"2": testKey(2)}, testKey(9))
+	require.NoError(t, err)
+	opened, err := retired.Open(e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ada@example.com"}, opened)
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	k, err := NewKeyring("1", map[string][]byte{"1": testKey(1)}, testKey(9))
+	require.NoError(t, err)
+	other, err := NewKeyring("1", map[string][]byte{"1": testKey(1)}, testKey(8))
+	require.NoError(t, err)
+
+	assert.Equal(t, k.BlindIndex("ada@example.com"), k.BlindIndex("ada@example.com"))
+	assert.NotEqual(t, k.BlindIndex("ada@example.com"), k.BlindIndex("bob@example.com"))
+	assert.NotEqual(t, k.BlindIndex("ada@example.com"), other.BlindIndex("ada@example.com"))
+	assert.Len(t, k.BlindIndex(""), 32)
+}
@@ -0,0 +1,117 @@
+// Package encryption implements envelope encryption of personal data stored
+// in the database. Values are encrypted with AES-GCM under a random data key
+// per row; the data key is stored next to them, wrapped by a versioned
+// key-encryption key (KEK) from a keyring. Rotating the KEK only requires
+// re-wrapping data keys, not re-encrypting values.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// keySize is the size of KEKs, data keys and the blind index key (AES-256)
+const keySize = 32
+
+// Keyring holds the key-encryption keys by version and the key used to
+// compute blind indexes. New data keys are wrapped by the primary version;
+// the other versions are only used to unwrap existing ones.
+type Keyring struct {
+	primary  string
+	keks     map[string]cipher.AEAD
+	indexKey []byte
+	dev      bool
+}
+
+// keyringFile is the JSON format of a keyring file. Keys are base64-encoded
+// 32-byte values. Dev marks a keyring whose keys are public, such as the one
+// checked in for local development.
+type keyringFile struct {
+	Primary       string            `json:"primary"`
+	Keys          map[string]string `json:"keys"`
+	BlindIndexKey string            `json:"blind_index_key"`
+	Dev           bool              `json:"dev"`
+}
+
+// LoadKeyring reads a keyring file
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring %s: %w", path, err)
+	}
+
+	keys := make(map[string][]byte, len(f.Keys))
+	for version, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("keyring %s: key %q is not base64: %w", path, version, err)
+		}
+		keys[version] = key
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(f.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("keyring %s: blind_index_key is not base64: %w", path, err)
+	}
+
+	k, err := NewKeyring(f.Primary, keys, indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("keyring %s: %w", path, err)
+	}
+	k.dev = f.Dev
+	return k, nil
+}
+
+// NewKeyring creates a keyring from raw keys. primary must be one of the
+// versions in keys.
+func NewKeyring(primary string, keys map[string][]byte, blindIndexKey []byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key version %q is not in the keyring", primary)
+	}
+	if len(blindIndexKey) != keySize {
+		return nil, errors.New("blind index key must be 32 bytes")
+	}
+
+	keks := make(map[string]cipher.AEAD, len(keys))
+	for version, key := range keys {
+		if version == "" {
+			return nil, errors.New("key version must not be empty")
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %q must be 32 bytes", version)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		keks[version] = aead
+	}
+	return &Keyring{primary: primary, keks: keks, indexKey: blindIndexKey}, nil
+}
+
+// Primary returns the version of the key that wraps new data keys
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Dev reports whether the keyring file was marked as a development keyring
+func (k *Keyring) Dev() bool {
+	return k.dev
+}
+
+// newAEAD creates an AES-GCM cipher
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,88 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey returns a 32-byte key filled with b
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(testKey(1))
+	tests := []struct {
+		name          string
+		content       string
+		errorContains string
+	}{
+		{
+			name:    "success",
+			content: `{"primary": "2", "keys": {"1": "` + key + `", "2": "` + key + `"}, "blind_index_key": "` + key + `"}`,
+		},
+		{
+			name:          "error - primary missing",
+			content:       `{"primary": "3", "keys": {"1": "` + key + `"}, "blind_index_key": "` + key + `"}`,
+			errorContains: `primary key version "3" is not in the keyring`,
+		},
+		{
+			name:          "error - short key",
+			content:       `{"primary": "1", "keys": {"1": "AAAA"}, "blind_index_key": "` + key + `"}`,
+			errorContains: `key "1" must be 32 bytes`,
+		},
+		{
+			name:          "error - not base64",
+			content:       `{"primary": "1", "keys": {"1": "!"}, "blind_index_key": "` + key + `"}`,
+			errorContains: "not base64",
+		},
+		{
+			name:          "error - missing blind index key",
+			content:       `{"primary": "1", "keys": {"1": "` + key + `"}}`,
+			errorContains: "blind index key must be 32 bytes",
+		},
+		{
+			name:          "error - invalid JSON",
+			content:       `{`,
+			errorContains: "failed to parse keyring",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keyring.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			k, err := LoadKeyring(path)
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "2", k.Primary())
+			assert.Len(t, k.keks, 2)
+			assert.False(t, k.Dev())
+		})
+	}
+
+	t.Run("dev keyring", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		content := `{"primary": "1", "keys": {"1": "` + key + `"}, "blind_index_key": "` + key + `", "dev": true}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		k, err := LoadKeyring(path)
+		require.NoError(t, err)
+		assert.True(t, k.Dev())
+	})
+
+	t.Run("error - missing file", func(t *testing.T) {
+		_, err := LoadKeyring(filepath.Join(t.TempDir(), "missing.json"))
+		assert.ErrorContains(t, err, "failed to read keyring")
+	})
+}
@@ -0,0 +1,132 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/txn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// nestingLockKey is the transaction-level advisory lock taken while nesting
+// groups. Serializing nesting changes keeps two concurrent AddMember calls
+// (A into B and B into A) from each passing the cycle check.
+const nestingLockKey int64 = 0x67726f757073 // "groups"
+
+// descendantsQuery reports whether $2 is reachable from group $1 by following
+// nested group memberships downwards.
+const descendantsQuery = `WITH RECURSIVE descendants (id) AS (
+	SELECT member_group_id FROM group_members WHERE group_id = $1 AND member_group_id IS NOT NULL
+	UNION
+	SELECT gm.member_group_id FROM group_members gm
+	JOIN descendants d ON gm.group_id = d.id
+	WHERE gm.member_group_id IS NOT NULL
+)
+SELECT EXISTS (SELECT 1 FROM descendants WHERE id = $2)`
+
+// AddMember adds a user or a nested group as a direct member of a group
+func (s *Service) AddMember(ctx context.Context, req *groupspb.AddMemberRequest) (_ *groupspb.AddMemberResponse, err error) {
+	member := req.GetMember()
+	switch m := member.GetMember().(type) {
+	case *groupspb.Member_UserId:
+	case *groupspb.Member_GroupId:
+		if m.GroupId == req.GetGroupId() {
+			return nil, status.Error(codes.InvalidArgument, "a group cannot be a member of itself")
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "member must set user_id or group_id")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	switch m := member.GetMember().(type) {
+	case *groupspb.Member_UserId:
+		err = addUserMember(ctx, tx, req.GetGroupId(), m.UserId)
+	case *groupspb.Member_GroupId:
+		err = addGroupMember(ctx, tx, req.GetGroupId(), m.GroupId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, memberResource(req.GetGroupId(), member)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &groupspb.AddMemberResponse{Member: member}, nil
+}
+
+// AddUserMemberTx adds a user as a direct member of a group within the
+// transaction carried by ctx (see txn.Manager), for callers that grant
+// membership as part of a larger atomic change (e.g. accepting an invitation).
+func (s *Service) AddUserMemberTx(ctx context.Context, groupID, userID int64) error {
+	return addUserMember(ctx, txn.Conn(ctx, s.db), groupID, userID)
+}
+
+func addUserMember(ctx context.Context, db execer, groupID, userID int64) error {
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO group_members (group_id, user_id) VALUES ($1, $2)", groupID, userID)
+	return membershipInsertError(err, groupID)
+}
+
+func addGroupMember(ctx context.Context, tx *sql.Tx, groupID, memberGroupID int64) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", nestingLockKey); err != nil {
+		return err
+	}
+
+	// Nesting memberGroupID under groupID creates a cycle if groupID is
+	// already reachable from memberGroupID.
+	cycle, err := isDescendant(ctx, tx, memberGroupID, groupID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return status.Errorf(codes.FailedPrecondition,
+			"adding group %d to group %d would create a membership cycle", memberGroupID, groupID)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO group_members (group_id, member_group_id) VALUES ($1, $2)", groupID, memberGroupID)
+	return membershipInsertError(err, groupID)
+}
+
+// memberResource returns the audit resource name of a membership
+func memberResource(groupID int64, member *groupspb.Member) string {
+	if id, ok := member.GetMember().(*groupspb.Member_GroupId); ok {
+		return fmt.Sprintf("groups/%d/members/groups/%d", groupID, id.GroupId)
+	}
+	return fmt.Sprintf("groups/%d/members/users/%d", groupID, member.GetUserId())
+}
+
+// isDescendant reports whether descendantID is nested, at any depth, under ancestorID
+func isDescendant(ctx context.Context, tx *sql.Tx, ancestorID, descendantID int64) (bool, error) {
+	var found bool
+	if err := tx.QueryRowContext(ctx, descendantsQuery, ancestorID, descendantID).Scan(&found); err != nil {
+		return false, fmt.Errorf("failed to check for membership cycle: %w", err)
+	}
+	return found, nil
+}
+
+// membershipInsertError translates constraint violations from inserting into group_members
+func membershipInsertError(err error, groupID int64) error {
+	switch pgErrorCode(err) {
+	case "":
+		return err
+	case pgForeignKeyViolation:
+		return status.Errorf(codes.NotFound, "group %d or member not found", groupID)
+	case pgUniqueViolation:
+		return status.Errorf(codes.AlreadyExists, "already a member of group %d", groupID)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,146 @@
+package groups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/txn"
+	"google.golang.org/grpc/codes"
+)
+
+func userMember(id int64) *groupspb.Member {
+	return &groupspb.Member{Member: &groupspb.Member_UserId{UserId: id}}
+}
+
+func groupMember(id int64) *groupspb.Member {
+	return &groupspb.Member{Member: &groupspb.Member_GroupId{GroupId: id}}
+}
+
+func TestService_AddMember(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *groupspb.AddMemberRequest
+		mockSetup    func(sqlmock.Sqlmock)
+		expectedErr  bool
+		expectedCode codes.Code
+	}{
+		{
+			name: "success - add user",
+			req:  &groupspb.AddMemberRequest{GroupId: 1, Member: userMember(10)},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO group_members \(group_id, user_id\) VALUES \(\$1, \$2\)`).
+					WithArgs(int64(1), int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error - user or group does not exist",
+			req:  &groupspb.AddMemberRequest{GroupId: 1, Member: userMember(10)},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO group_members`).
+					WillReturnError(&pq.Error{Code: pgForeignKeyViolation})
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "error - user already a member",
+			req:  &groupspb.AddMemberRequest{GroupId: 1, Member: userMember(10)},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO group_members`).
+					WillReturnError(&pq.Error{Code: pgUniqueViolation})
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name: "success - nest group",
+			req:  &groupspb.AddMemberRequest{GroupId: 1, Member: groupMember(2)},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).
+					WithArgs(nestingLockKey).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`WITH RECURSIVE descendants`).
+					WithArgs(int64(2), int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(`INSERT INTO group_members \(group_id, member_group_id\) VALUES \(\$1, \$2\)`).
+					WithArgs(int64(1), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error - nesting would create a cycle",
+			req:  &groupspb.AddMemberRequest{GroupId: 1, Member: groupMember(2)},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT pg_advisory_xact_lock`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`WITH RECURSIVE descendants`).
+					WithArgs(int64(2), int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name:         "error - group nested in itself",
+			req:          &groupspb.AddMemberRequest{GroupId: 1, Member: groupMember(1)},
+			mockSetup:    func(sqlmock.Sqlmock) {},
+			expectedErr:  true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "error - member not set",
+			req:          &groupspb.AddMemberRequest{GroupId: 1},
+			mockSetup:    func(sqlmock.Sqlmock) {},
+			expectedErr:  true,
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			resp, err := service.AddMember(context.Background(), tt.req)
+
+			if tt.expectedErr {
+				assertStatusCode(t, err, tt.expectedCode)
+				assert.Nil(t, resp)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.req.Member, resp.Member)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_AddUserMemberTx(t *testing.T) {
+	service, mock := newMockService(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO group_members \(group_id, user_id\) VALUES \(\$1, \$2\)`).
+		WithArgs(int64(1), int64(10)).
+		WillReturnError(&pq.Error{Code: pgUniqueViolation})
+	mock.ExpectRollback()
+
+	err := txn.NewManager(service.db, service.logger).Do(context.Background(), func(ctx context.Context) error {
+		return service.AddUserMemberTx(ctx, 1, 10)
+	})
+	assertStatusCode(t, err, codes.AlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
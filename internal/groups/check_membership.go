@@ -0,0 +1,38 @@
+package groups
+
+import (
+	"context"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+)
+
+// CheckMembership reports whether a user is a member of a group, directly or transitively
+func (s *Service) CheckMembership(ctx context.Context, req *groupspb.CheckMembershipRequest) (*groupspb.CheckMembershipResponse, error) {
+	isMember, direct, err := s.checkMembership(ctx, req.GetGroupId(), req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupspb.CheckMembershipResponse{IsMember: isMember, Direct: direct}, nil
+}
+
+// IsMember reports whether userID is transitively a member of groupID. It is
+// intended for other services (e.g. authorization checks) that need the
+// answer without going through the RPC layer.
+func (s *Service) IsMember(ctx context.Context, groupID, userID int64) (bool, error) {
+	isMember, _, err := s.checkMembership(ctx, groupID, userID)
+	return isMember, err
+}
+
+// checkMembership only walks upwards from the user, so its cost is bounded
+// by the number of groups the user belongs to rather than the size of the group.
+func (s *Service) checkMembership(ctx context.Context, groupID, userID int64) (isMember, direct bool, err error) {
+	row := s.db.QueryRowContext(ctx, userGroupsCTE+`SELECT
+		EXISTS (SELECT 1 FROM user_groups WHERE id = $2),
+		EXISTS (SELECT 1 FROM group_members WHERE user_id = $1 AND group_id = $2)`,
+		userID, groupID)
+	if err := row.Scan(&isMember, &direct); err != nil {
+		return false, false, err
+	}
+	return isMember, direct, nil
+}
@@ -0,0 +1,49 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+)
+
+func TestService_CheckMembership(t *testing.T) {
+	tests := []struct {
+		name     string
+		isMember bool
+		direct   bool
+	}{
+		{name: "direct member", isMember: true, direct: true},
+		{name: "transitive member", isMember: true, direct: false},
+		{name: "not a member", isMember: false, direct: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			mock.ExpectQuery(`WITH RECURSIVE user_groups .* SELECT\s+EXISTS`).
+				WithArgs(int64(10), int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"is_member", "direct"}).AddRow(tt.isMember, tt.direct))
+
+			resp, err := service.CheckMembership(context.Background(), &groupspb.CheckMembershipRequest{GroupId: 1, UserId: 10})
+			require.NoError(t, err)
+			assert.Equal(t, tt.isMember, resp.IsMember)
+			assert.Equal(t, tt.direct, resp.Direct)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_IsMember(t *testing.T) {
+	service, mock := newMockService(t)
+	mock.ExpectQuery(`WITH RECURSIVE user_groups`).
+		WithArgs(int64(10), int64(1)).
+		WillReturnError(errors.New("database connection failed"))
+
+	_, err := service.IsMember(context.Background(), 1, 10)
+	assert.ErrorContains(t, err, "database connection failed")
+}
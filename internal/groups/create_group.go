@@ -0,0 +1,43 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateGroup creates a new group in the database
+func (s *Service) CreateGroup(ctx context.Context, req *groupspb.CreateGroupRequest) (_ *groupspb.CreateGroupResponse, err error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	row := tx.QueryRowContext(ctx,
+		"INSERT INTO groups (name, description) VALUES ($1, $2) RETURNING id, name, description, created_at;",
+		req.GetName(), req.GetDescription())
+	group, err := scanGroup(row)
+	if err != nil {
+		if pgErrorCode(err) == pgUniqueViolation {
+			return nil, status.Errorf(codes.AlreadyExists, "group %q already exists", req.GetName())
+		}
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, fmt.Sprintf("groups/%d", group.Id)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &groupspb.CreateGroupResponse{Group: group}, nil
+}
@@ -0,0 +1,91 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_CreateGroup(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name         string
+		req          *groupspb.CreateGroupRequest
+		mockSetup    func(sqlmock.Sqlmock)
+		expectedCode codes.Code
+		expectedErr  bool
+	}{
+		{
+			name: "success - valid group creation",
+			req:  &groupspb.CreateGroupRequest{Name: "engineering", Description: "All engineers"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
+					AddRow(1, "engineering", "All engineers", createdAt)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO groups \(name, description\) VALUES \(\$1, \$2\) RETURNING id, name, description, created_at`).
+					WithArgs("engineering", "All engineers").
+					WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:         "error - missing name",
+			req:          &groupspb.CreateGroupRequest{},
+			mockSetup:    func(sqlmock.Sqlmock) {},
+			expectedErr:  true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "error - duplicate name",
+			req:  &groupspb.CreateGroupRequest{Name: "engineering"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO groups`).
+					WillReturnError(&pq.Error{Code: pgUniqueViolation})
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name: "error - database error during insert",
+			req:  &groupspb.CreateGroupRequest{Name: "engineering"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO groups`).
+					WillReturnError(errors.New("database connection failed"))
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			resp, err := service.CreateGroup(context.Background(), tt.req)
+
+			if tt.expectedErr {
+				assertStatusCode(t, err, tt.expectedCode)
+				assert.Nil(t, resp)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), resp.Group.Id)
+				assert.Equal(t, "engineering", resp.Group.Name)
+				assert.Equal(t, "All engineers", resp.Group.Description)
+				assert.Equal(t, createdAt, resp.Group.CreateTime.AsTime())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteGroup deletes a group. Its memberships, both as a parent and as a
+// nested member of other groups, are removed by ON DELETE CASCADE.
+func (s *Service) DeleteGroup(ctx context.Context, req *groupspb.DeleteGroupRequest) (_ *groupspb.DeleteGroupResponse, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM groups WHERE id = $1", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, status.Errorf(codes.NotFound, "group %d not found", req.GetId())
+	}
+	if err = audit.Record(ctx, tx, fmt.Sprintf("groups/%d", req.GetId())); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &groupspb.DeleteGroupResponse{}, nil
+}
@@ -0,0 +1,72 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_DeleteGroup(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockSetup    func(sqlmock.Sqlmock)
+		expectedErr  bool
+		expectedCode codes.Code
+	}{
+		{
+			name: "success - group deleted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM groups WHERE id = \$1`).
+					WithArgs(int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error - not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM groups WHERE id = \$1`).
+					WithArgs(int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "error - database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM groups`).WillReturnError(errors.New("database connection failed"))
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			resp, err := service.DeleteGroup(context.Background(), &groupspb.DeleteGroupRequest{Id: 5})
+
+			if tt.expectedErr {
+				assertStatusCode(t, err, tt.expectedCode)
+				assert.Nil(t, resp)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, resp)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
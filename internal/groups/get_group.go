@@ -0,0 +1,26 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetGroup retrieves a single group by id
+func (s *Service) GetGroup(ctx context.Context, req *groupspb.GetGroupRequest) (*groupspb.GetGroupResponse, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT id, name, description, created_at FROM groups WHERE id = $1", req.GetId())
+	group, err := scanGroup(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "group %d not found", req.GetId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupspb.GetGroupResponse{Group: group}, nil
+}
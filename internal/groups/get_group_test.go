@@ -0,0 +1,42 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_GetGroup(t *testing.T) {
+	t.Run("success - returns group", func(t *testing.T) {
+		service, mock := newMockService(t)
+		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
+			AddRow(7, "ops", "", time.Now())
+		mock.ExpectQuery(`SELECT id, name, description, created_at FROM groups WHERE id = \$1`).
+			WithArgs(int64(7)).
+			WillReturnRows(rows)
+
+		resp, err := service.GetGroup(context.Background(), &groupspb.GetGroupRequest{Id: 7})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), resp.Group.Id)
+		assert.Equal(t, "ops", resp.Group.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(`SELECT id, name, description, created_at FROM groups WHERE id = \$1`).
+			WithArgs(int64(7)).
+			WillReturnError(sql.ErrNoRows)
+
+		resp, err := service.GetGroup(context.Background(), &groupspb.GetGroupRequest{Id: 7})
+		assertStatusCode(t, err, codes.NotFound)
+		assert.Nil(t, resp)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
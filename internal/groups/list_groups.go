@@ -0,0 +1,40 @@
+package groups
+
+import (
+	"context"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListGroups retrieves a page of groups ordered by id
+func (s *Service) ListGroups(ctx context.Context, req *groupspb.ListGroupsRequest) (*groupspb.ListGroupsResponse, error) {
+	afterID, err := pagination.DecodeToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+
+	// Fetch one extra row to find out whether there is a next page
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, description, created_at FROM groups WHERE id > $1 ORDER BY id LIMIT $2",
+		afterID, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups, err := scanGroups(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &groupspb.ListGroupsResponse{Groups: groups}
+	if len(groups) > pageSize {
+		resp.Groups = groups[:pageSize]
+		resp.NextPageToken = pagination.NextToken(len(groups), pageSize, groups[pageSize-1].Id)
+	}
+	return resp, nil
+}
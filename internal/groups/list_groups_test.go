@@ -0,0 +1,71 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_ListGroups(t *testing.T) {
+	columns := []string{"id", "name", "description", "created_at"}
+
+	t.Run("success - first page with next page token", func(t *testing.T) {
+		service, mock := newMockService(t)
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "a", "", time.Now()).
+			AddRow(2, "b", "", time.Now()).
+			AddRow(3, "c", "", time.Now())
+		mock.ExpectQuery(`SELECT id, name, description, created_at FROM groups WHERE id > \$1 ORDER BY id LIMIT \$2`).
+			WithArgs(int64(0), 3).
+			WillReturnRows(rows)
+
+		resp, err := service.ListGroups(context.Background(), &groupspb.ListGroupsRequest{PageSize: 2})
+		require.NoError(t, err)
+		require.Len(t, resp.Groups, 2)
+		assert.Equal(t, int64(2), resp.Groups[1].Id)
+		assert.Equal(t, pagination.EncodeToken(2), resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - last page", func(t *testing.T) {
+		service, mock := newMockService(t)
+		rows := sqlmock.NewRows(columns).AddRow(3, "c", "", time.Now())
+		mock.ExpectQuery(`SELECT .* FROM groups WHERE id > \$1`).
+			WithArgs(int64(2), 3).
+			WillReturnRows(rows)
+
+		resp, err := service.ListGroups(context.Background(), &groupspb.ListGroupsRequest{
+			PageSize:  2,
+			PageToken: pagination.EncodeToken(2),
+		})
+		require.NoError(t, err)
+		assert.Len(t, resp.Groups, 1)
+		assert.Empty(t, resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invalid page token", func(t *testing.T) {
+		service, mock := newMockService(t)
+
+		_, err := service.ListGroups(context.Background(), &groupspb.ListGroupsRequest{PageToken: "!"})
+		assertStatusCode(t, err, codes.InvalidArgument)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - database query fails", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(`SELECT .* FROM groups`).WillReturnError(errors.New("failed to query database"))
+
+		resp, err := service.ListGroups(context.Background(), &groupspb.ListGroupsRequest{})
+		assert.ErrorContains(t, err, "failed to query database")
+		assert.Nil(t, resp)
+	})
+}
@@ -0,0 +1,64 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListMembers retrieves a page of the direct members of a group
+func (s *Service) ListMembers(ctx context.Context, req *groupspb.ListMembersRequest) (*groupspb.ListMembersResponse, error) {
+	afterID, err := pagination.DecodeToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+
+	// Pages are keyed on the membership row id, so users and nested groups
+	// are returned in the order they were added.
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, user_id, member_group_id FROM group_members
+		WHERE group_id = $1 AND id > $2 ORDER BY id LIMIT $3`,
+		req.GetGroupId(), afterID, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		members []*groupspb.Member
+		lastIDs []int64
+	)
+	for rows.Next() {
+		var (
+			id            int64
+			userID        sql.NullInt64
+			memberGroupID sql.NullInt64
+		)
+		if err := rows.Scan(&id, &userID, &memberGroupID); err != nil {
+			return nil, err
+		}
+		member := &groupspb.Member{}
+		if userID.Valid {
+			member.Member = &groupspb.Member_UserId{UserId: userID.Int64}
+		} else {
+			member.Member = &groupspb.Member_GroupId{GroupId: memberGroupID.Int64}
+		}
+		members = append(members, member)
+		lastIDs = append(lastIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	resp := &groupspb.ListMembersResponse{Members: members}
+	if len(members) > pageSize {
+		resp.Members = members[:pageSize]
+		resp.NextPageToken = pagination.NextToken(len(members), pageSize, lastIDs[pageSize-1])
+	}
+	return resp, nil
+}
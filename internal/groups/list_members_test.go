@@ -0,0 +1,41 @@
+package groups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_ListMembers(t *testing.T) {
+	t.Run("success - users and nested groups", func(t *testing.T) {
+		service, mock := newMockService(t)
+		rows := sqlmock.NewRows([]string{"id", "user_id", "member_group_id"}).
+			AddRow(4, 10, nil).
+			AddRow(6, nil, 2).
+			AddRow(9, 11, nil)
+		mock.ExpectQuery(`SELECT id, user_id, member_group_id FROM group_members`).
+			WithArgs(int64(1), int64(0), 3).
+			WillReturnRows(rows)
+
+		resp, err := service.ListMembers(context.Background(), &groupspb.ListMembersRequest{GroupId: 1, PageSize: 2})
+		require.NoError(t, err)
+		require.Len(t, resp.Members, 2)
+		assert.Equal(t, int64(10), resp.Members[0].GetUserId())
+		assert.Equal(t, int64(2), resp.Members[1].GetGroupId())
+		assert.Equal(t, pagination.EncodeToken(6), resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invalid page token", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.ListMembers(context.Background(), &groupspb.ListMembersRequest{GroupId: 1, PageToken: "%"})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+}
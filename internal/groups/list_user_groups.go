@@ -0,0 +1,58 @@
+package groups
+
+import (
+	"context"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userGroupsCTE walks memberships upwards from user $1, yielding the id of
+// every group the user belongs to directly or through nested groups. UNION
+// (rather than UNION ALL) discards groups that were already visited, so the
+// walk terminates even if the data somehow contains a cycle.
+const userGroupsCTE = `WITH RECURSIVE user_groups (id) AS (
+	SELECT group_id FROM group_members WHERE user_id = $1
+	UNION
+	SELECT gm.group_id FROM group_members gm
+	JOIN user_groups ug ON gm.member_group_id = ug.id
+)
+`
+
+// ListUserGroups retrieves a page of the groups a user belongs to
+func (s *Service) ListUserGroups(ctx context.Context, req *groupspb.ListUserGroupsRequest) (*groupspb.ListUserGroupsResponse, error) {
+	afterID, err := pagination.DecodeToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+
+	query := `SELECT g.id, g.name, g.description, g.created_at FROM groups g
+		JOIN group_members gm ON gm.group_id = g.id
+		WHERE gm.user_id = $1 AND g.id > $2 ORDER BY g.id LIMIT $3`
+	if req.GetTransitive() {
+		query = userGroupsCTE + `SELECT g.id, g.name, g.description, g.created_at FROM groups g
+		JOIN user_groups ug ON ug.id = g.id
+		WHERE g.id > $2 ORDER BY g.id LIMIT $3`
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, req.GetUserId(), afterID, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups, err := scanGroups(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &groupspb.ListUserGroupsResponse{Groups: groups}
+	if len(groups) > pageSize {
+		resp.Groups = groups[:pageSize]
+		resp.NextPageToken = pagination.NextToken(len(groups), pageSize, groups[pageSize-1].Id)
+	}
+	return resp, nil
+}
@@ -0,0 +1,44 @@
+package groups
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+)
+
+func TestService_ListUserGroups(t *testing.T) {
+	columns := []string{"id", "name", "description", "created_at"}
+
+	t.Run("success - direct memberships", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(`SELECT g.id, g.name, g.description, g.created_at FROM groups g\s+JOIN group_members gm`).
+			WithArgs(int64(10), int64(0), 51).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "engineering", "", time.Now()))
+
+		resp, err := service.ListUserGroups(context.Background(), &groupspb.ListUserGroupsRequest{UserId: 10})
+		require.NoError(t, err)
+		require.Len(t, resp.Groups, 1)
+		assert.Equal(t, "engineering", resp.Groups[0].Name)
+		assert.Empty(t, resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - transitive memberships", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(`WITH RECURSIVE user_groups .* JOIN user_groups ug ON ug.id = g.id`).
+			WithArgs(int64(10), int64(0), 51).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "engineering", "", time.Now()).
+				AddRow(2, "everyone", "", time.Now()))
+
+		resp, err := service.ListUserGroups(context.Background(), &groupspb.ListUserGroupsRequest{UserId: 10, Transitive: true})
+		require.NoError(t, err)
+		assert.Len(t, resp.Groups, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,50 @@
+package groups
+
+import (
+	"context"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RemoveMember removes a direct user or nested group member from a group
+func (s *Service) RemoveMember(ctx context.Context, req *groupspb.RemoveMemberRequest) (_ *groupspb.RemoveMemberResponse, err error) {
+	var query string
+	var memberID int64
+	switch m := req.GetMember().GetMember().(type) {
+	case *groupspb.Member_UserId:
+		query, memberID = "DELETE FROM group_members WHERE group_id = $1 AND user_id = $2", m.UserId
+	case *groupspb.Member_GroupId:
+		query, memberID = "DELETE FROM group_members WHERE group_id = $1 AND member_group_id = $2", m.GroupId
+	default:
+		return nil, status.Error(codes.InvalidArgument, "member must set user_id or group_id")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	result, err := tx.ExecContext(ctx, query, req.GetGroupId(), memberID)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, status.Errorf(codes.NotFound, "member is not a direct member of group %d", req.GetGroupId())
+	}
+	if err = audit.Record(ctx, tx, memberResource(req.GetGroupId(), req.GetMember())); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &groupspb.RemoveMemberResponse{}, nil
+}
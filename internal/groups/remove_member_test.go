@@ -0,0 +1,57 @@
+package groups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_RemoveMember(t *testing.T) {
+	t.Run("success - remove user", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM group_members WHERE group_id = \$1 AND user_id = \$2`).
+			WithArgs(int64(1), int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := service.RemoveMember(context.Background(), &groupspb.RemoveMemberRequest{GroupId: 1, Member: userMember(10)})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - remove nested group", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM group_members WHERE group_id = \$1 AND member_group_id = \$2`).
+			WithArgs(int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := service.RemoveMember(context.Background(), &groupspb.RemoveMemberRequest{GroupId: 1, Member: groupMember(2)})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - not a member", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM group_members`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		_, err := service.RemoveMember(context.Background(), &groupspb.RemoveMemberRequest{GroupId: 1, Member: userMember(10)})
+		assertStatusCode(t, err, codes.NotFound)
+	})
+
+	t.Run("error - member not set", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.RemoveMember(context.Background(), &groupspb.RemoveMemberRequest{GroupId: 1})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+}
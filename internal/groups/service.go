@@ -0,0 +1,87 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Postgres error codes the group RPCs translate into gRPC status codes
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
+// Service handles gRPC requests for group and group membership operations
+type Service struct {
+	groupspb.UnimplementedGroupServiceServer
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewService creates a new group service using an existing database connection
+func NewService(db *sql.DB, logger *slog.Logger) *Service {
+	return &Service{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanGroup scans a row of (id, name, description, created_at) into a Group
+func scanGroup(row rowScanner) (*groupspb.Group, error) {
+	var (
+		group     groupspb.Group
+		createdAt time.Time
+	)
+	if err := row.Scan(&group.Id, &group.Name, &group.Description, &createdAt); err != nil {
+		return nil, err
+	}
+	group.CreateTime = timestamppb.New(createdAt)
+	return &group, nil
+}
+
+// scanGroups scans every remaining row of a group query
+func scanGroups(rows *sql.Rows) ([]*groupspb.Group, error) {
+	groups := make([]*groupspb.Group, 0)
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// rollback rolls back tx if err is set. It is meant to be deferred with a
+// pointer to the caller's named error result.
+func rollback(tx *sql.Tx, err *error) {
+	if *err != nil {
+		_ = tx.Rollback()
+	}
+}
+
+// pgErrorCode returns the Postgres SQLSTATE of err, or "" if err did not come from Postgres
+func pgErrorCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
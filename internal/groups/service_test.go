@@ -0,0 +1,39 @@
+package groups
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newMockService creates a Service backed by go-sqlmock
+func newMockService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	return NewService(db, logger), mock
+}
+
+// assertStatusCode asserts err is a gRPC status error with the given code
+func assertStatusCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestPgErrorCode(t *testing.T) {
+	assert.Equal(t, pgUniqueViolation, pgErrorCode(&pq.Error{Code: pgUniqueViolation}))
+	assert.Equal(t, "", pgErrorCode(errors.New("boom")))
+	assert.Equal(t, "", pgErrorCode(nil))
+}
@@ -0,0 +1,71 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateGroup updates the fields of a group selected by the update mask
+func (s *Service) UpdateGroup(ctx context.Context, req *groupspb.UpdateGroupRequest) (_ *groupspb.UpdateGroupResponse, err error) {
+	group := req.GetGroup()
+	if group == nil {
+		return nil, status.Error(codes.InvalidArgument, "group is required")
+	}
+
+	// An empty mask means a full update of every mutable field
+	updateName, updateDescription := true, true
+	if paths := req.GetUpdateMask().GetPaths(); len(paths) > 0 {
+		updateName, updateDescription = false, false
+		for _, path := range paths {
+			switch path {
+			case "name":
+				updateName = true
+			case "description":
+				updateDescription = true
+			default:
+				return nil, status.Errorf(codes.InvalidArgument, "unsupported update_mask path %q", path)
+			}
+		}
+	}
+	if updateName && group.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name cannot be empty")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	row := tx.QueryRowContext(ctx, `UPDATE groups SET
+		name = CASE WHEN $2 THEN $3 ELSE name END,
+		description = CASE WHEN $4 THEN $5 ELSE description END
+		WHERE id = $1
+		RETURNING id, name, description, created_at`,
+		group.GetId(), updateName, group.GetName(), updateDescription, group.GetDescription())
+	updated, err := scanGroup(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "group %d not found", group.GetId())
+	}
+	if err != nil {
+		if pgErrorCode(err) == pgUniqueViolation {
+			return nil, status.Errorf(codes.AlreadyExists, "group %q already exists", group.GetName())
+		}
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, fmt.Sprintf("groups/%d", updated.Id)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &groupspb.UpdateGroupResponse{Group: updated}, nil
+}
@@ -0,0 +1,85 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	groupspb "github.com/zcking/go-api-template/gen/go/groups/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestService_UpdateGroup(t *testing.T) {
+	columns := []string{"id", "name", "description", "created_at"}
+
+	t.Run("success - description only", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE groups SET`).
+			WithArgs(int64(3), false, "", true, "new description").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "ops", "new description", time.Now()))
+		mock.ExpectCommit()
+
+		resp, err := service.UpdateGroup(context.Background(), &groupspb.UpdateGroupRequest{
+			Group:      &groupspb.Group{Id: 3, Description: "new description"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"description"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "ops", resp.Group.Name)
+		assert.Equal(t, "new description", resp.Group.Description)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - empty mask updates all fields", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE groups SET`).
+			WithArgs(int64(3), true, "platform", true, "").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "platform", "", time.Now()))
+		mock.ExpectCommit()
+
+		resp, err := service.UpdateGroup(context.Background(), &groupspb.UpdateGroupRequest{
+			Group: &groupspb.Group{Id: 3, Name: "platform"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "platform", resp.Group.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - unsupported mask path", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.UpdateGroup(context.Background(), &groupspb.UpdateGroupRequest{
+			Group:      &groupspb.Group{Id: 3},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"id"}},
+		})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+
+	t.Run("error - empty name", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.UpdateGroup(context.Background(), &groupspb.UpdateGroupRequest{
+			Group:      &groupspb.Group{Id: 3},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE groups SET`).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := service.UpdateGroup(context.Background(), &groupspb.UpdateGroupRequest{
+			Group: &groupspb.Group{Id: 3, Name: "platform"},
+		})
+		assertStatusCode(t, err, codes.NotFound)
+	})
+}
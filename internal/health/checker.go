@@ -0,0 +1,118 @@
+// Package health serves the liveness and readiness endpoints that
+// orchestrators such as Kubernetes probe.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout bounds each readiness check
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency is usable
+type Check func(ctx context.Context) error
+
+// Checker reports the server live as soon as it runs, and ready once it has
+// started and every check passes
+type Checker struct {
+	logger  *slog.Logger
+	started atomic.Bool
+
+	mu     sync.Mutex
+	checks map[string]Check
+}
+
+// NewChecker creates a checker that reports not ready until Started is called
+func NewChecker(logger *slog.Logger) *Checker {
+	return &Checker{logger: logger, checks: make(map[string]Check)}
+}
+
+// AddCheck adds a readiness check named name
+func (c *Checker) AddCheck(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Started marks startup complete
+func (c *Checker) Started() {
+	c.started.Store(true)
+}
+
+// Register serves GET /healthz, liveness, and GET /readyz, readiness, on mux
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", c.serveReady)
+}
+
+// serveReady answers 200 when the server is ready and 503 with the failing
+// checks when it is not
+func (c *Checker) serveReady(w http.ResponseWriter, r *http.Request) {
+	if !c.started.Load() {
+		writeStatus(w, http.StatusServiceUnavailable, map[string]string{"status": "starting"})
+		return
+	}
+	if failed := c.failing(r.Context()); len(failed) > 0 {
+		c.logger.Warn("not ready", "checks", failed)
+		writeStatus(w, http.StatusServiceUnavailable, map[string]any{"status": "unavailable", "checks": failed})
+		return
+	}
+	writeStatus(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// failing runs the checks concurrently and returns the errors of those that
+// fail by name
+func (c *Checker) failing(ctx context.Context) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = make(map[string]string)
+	)
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := check(ctx); err != nil {
+				mu.Lock()
+				failed[name] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return failed
+}
+
+// Gate answers 503 to requests until the server has started, then passes
+// them to next, so clients retry rather than reach handlers that are not
+// registered yet
+func (c *Checker) Gate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.started.Load() {
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "server is starting", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeStatus writes body as JSON with code
+func writeStatus(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// get requests path from handler and returns the status code and body
+func get(handler http.Handler, path string) (int, string) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestChecker(t *testing.T) {
+	c := NewChecker(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var dbErr error
+	c.AddCheck("database", func(ctx context.Context) error { return dbErr })
+	mux := http.NewServeMux()
+	c.Register(mux)
+	mux.Handle("/", c.Gate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("api"))
+	})))
+
+	code, body := get(mux, "/healthz")
+	assert.Equal(t, http.StatusOK, code, "the server is live while it starts")
+	assert.JSONEq(t, `{"status":"ok"}`, body)
+	code, body = get(mux, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"status":"starting"}`, body)
+	code, _ = get(mux, "/v1/users")
+	assert.Equal(t, http.StatusServiceUnavailable, code, "API requests are refused until the server has started")
+
+	c.Started()
+	code, body = get(mux, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"status":"ok"}`, body)
+	code, body = get(mux, "/v1/users")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "api", body)
+
+	dbErr = errors.New("connection refused")
+	code, body = get(mux, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"status":"unavailable","checks":{"database":"connection refused"}}`, body)
+	code, _ = get(mux, "/healthz")
+	assert.Equal(t, http.StatusOK, code, "the server stays live while a dependency is down")
+}
@@ -0,0 +1,72 @@
+package invitations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/txn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AcceptInvitation accepts a pending invitation. The user is created through
+// the same path as UserService.CreateUser and added to the invitation's
+// groups in a single transaction, so either all of it happens or none of it.
+func (s *Service) AcceptInvitation(ctx context.Context, req *invitationspb.AcceptInvitationRequest) (*invitationspb.AcceptInvitationResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	var (
+		inv  *invitationspb.Invitation
+		user *userspb.User
+	)
+	err := s.txns.Do(ctx, func(ctx context.Context) (err error) {
+		tx, _ := txn.Tx(ctx)
+		row := tx.QueryRowContext(ctx,
+			"SELECT "+invitationColumns+" FROM invitations WHERE token_hash = $1 FOR UPDATE",
+			hashToken(req.GetToken()))
+		inv, err = s.scanInvitation(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Error(codes.NotFound, "invitation not found")
+		}
+		if err != nil {
+			return err
+		}
+		if inv.State != invitationspb.Invitation_STATE_PENDING {
+			return status.Errorf(codes.FailedPrecondition, "invitation is %s", stateName(inv.State))
+		}
+
+		name := req.GetName()
+		if name == "" {
+			name = inv.Name
+		}
+		if user, err = s.users.CreateUserTx(ctx, &userspb.CreateUserRequest{Email: inv.Email, Name: name}); err != nil {
+			return err
+		}
+		for _, groupID := range inv.GroupIds {
+			if err = s.groups.AddUserMemberTx(ctx, groupID, user.Id); err != nil {
+				return err
+			}
+		}
+
+		row = tx.QueryRowContext(ctx,
+			"UPDATE invitations SET accepted_at = $2, accepted_user_id = $3 WHERE id = $1 RETURNING "+invitationColumns,
+			inv.Id, s.now(), user.Id)
+		if inv, err = s.scanInvitation(row); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, fmt.Sprintf("invitations/%d", inv.Id), fmt.Sprintf("users/%d", user.Id))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "invitation accepted", "invitation_id", inv.Id, "user_id", user.Id)
+	return &invitationspb.AcceptInvitationResponse{Invitation: inv, UserId: user.Id}, nil
+}
@@ -0,0 +1,127 @@
+package invitations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_AcceptInvitation(t *testing.T) {
+	lockQuery := `SELECT .* FROM invitations WHERE token_hash = \$1 FOR UPDATE`
+
+	t.Run("success - user created and added to groups atomically", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		row := pendingRow(1)
+		row.groupIDs = "{7,8}"
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(hashToken("secret")).WillReturnRows(row.rows())
+		inserted := &insertedUser{}
+		expectInsertUser(mock, 42, inserted)
+		mock.ExpectExec(`INSERT INTO group_members \(group_id, user_id\)`).
+			WithArgs(int64(7), int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO group_members \(group_id, user_id\)`).
+			WithArgs(int64(8), int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		accepted := row
+		accepted.acceptedAt, accepted.acceptedUserID = testNow, 42
+		mock.ExpectQuery(`UPDATE invitations SET accepted_at = \$2, accepted_user_id = \$3 WHERE id = \$1`).
+			WithArgs(int64(1), testNow, int64(42)).
+			WillReturnRows(accepted.rows())
+		mock.ExpectCommit()
+
+		resp, err := service.AcceptInvitation(context.Background(), &invitationspb.AcceptInvitationRequest{
+			Token: "secret",
+			Name:  "Johnny",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), resp.UserId)
+		assert.Equal(t, invitationspb.Invitation_STATE_ACCEPTED, resp.Invitation.State)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		email, name := inserted.open(t)
+		assert.Equal(t, "jdoe@example.com", email)
+		assert.Equal(t, "Johnny", name)
+	})
+
+	t.Run("success - retried after a serialization failure", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		row := pendingRow(1)
+		row.groupIDs = "{7}"
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(row.rows())
+		expectInsertUser(mock, 42, nil)
+		mock.ExpectExec(`INSERT INTO group_members`).
+			WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(row.rows())
+		expectInsertUser(mock, 43, nil)
+		mock.ExpectExec(`INSERT INTO group_members`).
+			WithArgs(int64(7), int64(43)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		accepted := row
+		accepted.acceptedAt, accepted.acceptedUserID = testNow, 43
+		mock.ExpectQuery(`UPDATE invitations SET accepted_at`).
+			WithArgs(int64(1), testNow, int64(43)).
+			WillReturnRows(accepted.rows())
+		mock.ExpectCommit()
+
+		resp, err := service.AcceptInvitation(context.Background(), &invitationspb.AcceptInvitationRequest{Token: "secret"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(43), resp.UserId)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - group membership fails and nothing is committed", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		row := pendingRow(1)
+		row.groupIDs = "{7}"
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(row.rows())
+		expectInsertUser(mock, 42, nil)
+		mock.ExpectExec(`INSERT INTO group_members`).
+			WillReturnError(&pq.Error{Code: "23503"})
+		mock.ExpectRollback()
+
+		_, err := service.AcceptInvitation(context.Background(), &invitationspb.AcceptInvitationRequest{Token: "secret"})
+		assertStatusCode(t, err, codes.NotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - expired", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		row := pendingRow(1)
+		row.expiresAt = testNow.Add(-1)
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(row.rows())
+		mock.ExpectRollback()
+
+		_, err := service.AcceptInvitation(context.Background(), &invitationspb.AcceptInvitationRequest{Token: "secret"})
+		assertStatusCode(t, err, codes.FailedPrecondition)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - unknown token", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		_, err := service.AcceptInvitation(context.Background(), &invitationspb.AcceptInvitationRequest{Token: "secret"})
+		assertStatusCode(t, err, codes.NotFound)
+	})
+
+	t.Run("error - missing token", func(t *testing.T) {
+		service, _ := newMockService(t, Config{})
+
+		_, err := service.AcceptInvitation(context.Background(), &invitationspb.AcceptInvitationRequest{})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+}
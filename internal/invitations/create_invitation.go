@@ -0,0 +1,65 @@
+package invitations
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+
+	"github.com/lib/pq"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateInvitation invites an email address and returns the invitation token
+func (s *Service) CreateInvitation(ctx context.Context, req *invitationspb.CreateInvitationRequest) (_ *invitationspb.CreateInvitationResponse, err error) {
+	if _, err := mail.ParseAddress(req.GetEmail()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid email: %v", err)
+	}
+
+	groupIDs := slices.Compact(slices.Sorted(slices.Values(req.GetGroupIds())))
+	if len(groupIDs) > 0 {
+		var found int
+		err := s.db.QueryRowContext(ctx, "SELECT count(*) FROM groups WHERE id = ANY($1)", pq.Array(groupIDs)).Scan(&found)
+		if err != nil {
+			return nil, err
+		}
+		if found != len(groupIDs) {
+			return nil, status.Error(codes.NotFound, "one or more groups not found")
+		}
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO invitations (email, name, group_ids, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING `+invitationColumns,
+		req.GetEmail(), req.GetName(), pq.Array(groupIDs), hash, s.now().Add(s.config.TTL))
+	inv, err := s.scanInvitation(row)
+	if err != nil {
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, fmt.Sprintf("invitations/%d", inv.Id)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "invitation created", "invitation_id", inv.Id)
+	return &invitationspb.CreateInvitationResponse{
+		Invitation: inv,
+		Token:      token,
+		AcceptUrl:  s.acceptURL(token),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package invitations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_CreateInvitation(t *testing.T) {
+	t.Run("success - invitation with groups", func(t *testing.T) {
+		service, mock := newMockService(t, Config{AcceptURL: "https://app.example.com/invite"})
+		mock.ExpectQuery(`SELECT count\(\*\) FROM groups WHERE id = ANY\(\$1\)`).
+			WithArgs("{1,2}").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		row := pendingRow(1)
+		row.groupIDs = "{1,2}"
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO invitations \(email, name, group_ids, token_hash, expires_at\)`).
+			WithArgs("jdoe@example.com", "John Doe", "{1,2}", anyBytes{}, testNow.Add(DefaultTTL)).
+			WillReturnRows(row.rows())
+		mock.ExpectCommit()
+
+		resp, err := service.CreateInvitation(context.Background(), &invitationspb.CreateInvitationRequest{
+			Email:    "jdoe@example.com",
+			Name:     "John Doe",
+			GroupIds: []int64{2, 1, 2},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), resp.Invitation.Id)
+		assert.Equal(t, []int64{1, 2}, resp.Invitation.GroupIds)
+		assert.Equal(t, invitationspb.Invitation_STATE_PENDING, resp.Invitation.State)
+		assert.NotEmpty(t, resp.Token)
+		assert.Equal(t, "https://app.example.com/invite?token="+resp.Token, resp.AcceptUrl)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invalid email", func(t *testing.T) {
+		service, _ := newMockService(t, Config{})
+
+		_, err := service.CreateInvitation(context.Background(), &invitationspb.CreateInvitationRequest{Email: "not-an-email"})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+
+	t.Run("error - unknown group", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		mock.ExpectQuery(`SELECT count\(\*\) FROM groups`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		_, err := service.CreateInvitation(context.Background(), &invitationspb.CreateInvitationRequest{
+			Email:    "jdoe@example.com",
+			GroupIds: []int64{1, 2},
+		})
+		assertStatusCode(t, err, codes.NotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,46 @@
+package invitations
+
+import (
+	"context"
+
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListInvitations retrieves a page of invitations ordered by id
+func (s *Service) ListInvitations(ctx context.Context, req *invitationspb.ListInvitationsRequest) (*invitationspb.ListInvitationsResponse, error) {
+	afterID, err := pagination.DecodeToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+invitationColumns+" FROM invitations WHERE id > $1 ORDER BY id LIMIT $2",
+		afterID, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]*invitationspb.Invitation, 0)
+	for rows.Next() {
+		inv, err := s.scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	resp := &invitationspb.ListInvitationsResponse{Invitations: invitations}
+	if len(invitations) > pageSize {
+		resp.Invitations = invitations[:pageSize]
+		resp.NextPageToken = pagination.NextToken(len(invitations), pageSize, invitations[pageSize-1].Id)
+	}
+	return resp, nil
+}
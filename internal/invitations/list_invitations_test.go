@@ -0,0 +1,48 @@
+package invitations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_ListInvitations(t *testing.T) {
+	t.Run("success - returns a page", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		rows := pendingRow(5).rows()
+		mock.ExpectQuery(`SELECT .* FROM invitations WHERE id > \$1 ORDER BY id LIMIT \$2`).
+			WithArgs(int64(4), 11).
+			WillReturnRows(rows)
+
+		resp, err := service.ListInvitations(context.Background(), &invitationspb.ListInvitationsRequest{
+			PageSize:  10,
+			PageToken: pagination.EncodeToken(4),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Invitations, 1)
+		assert.Equal(t, int64(5), resp.Invitations[0].Id)
+		assert.Empty(t, resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invalid page token", func(t *testing.T) {
+		service, _ := newMockService(t, Config{})
+
+		_, err := service.ListInvitations(context.Background(), &invitationspb.ListInvitationsRequest{PageToken: "?"})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+
+	t.Run("error - database query fails", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		mock.ExpectQuery(`SELECT .* FROM invitations`).WillReturnError(errors.New("failed to query database"))
+
+		_, err := service.ListInvitations(context.Background(), &invitationspb.ListInvitationsRequest{})
+		assert.ErrorContains(t, err, "failed to query database")
+	})
+}
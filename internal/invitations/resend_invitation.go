@@ -0,0 +1,65 @@
+package invitations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResendInvitation issues a new token for an invitation and extends its
+// expiry. Accepted and revoked invitations cannot be resent, and resends are
+// limited by Config.MaxSends and Config.ResendCooldown.
+func (s *Service) ResendInvitation(ctx context.Context, req *invitationspb.ResendInvitationRequest) (_ *invitationspb.ResendInvitationResponse, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	inv, err := s.lockInvitation(ctx, tx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	switch inv.State {
+	case invitationspb.Invitation_STATE_ACCEPTED, invitationspb.Invitation_STATE_REVOKED:
+		return nil, status.Errorf(codes.FailedPrecondition, "invitation %d is %s", inv.Id, stateName(inv.State))
+	}
+	if int(inv.SendCount) >= s.config.MaxSends {
+		return nil, status.Errorf(codes.ResourceExhausted, "invitation %d has already been sent %d times", inv.Id, inv.SendCount)
+	}
+
+	now := s.now()
+	if wait := s.config.ResendCooldown - now.Sub(inv.LastSendTime.AsTime()); wait > 0 {
+		return nil, status.Errorf(codes.ResourceExhausted, "invitation %d was sent recently, retry in %s", inv.Id, wait.Round(time.Second))
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	row := tx.QueryRowContext(ctx, `UPDATE invitations
+		SET token_hash = $2, send_count = send_count + 1, last_sent_at = $3, expires_at = $4
+		WHERE id = $1 RETURNING `+invitationColumns,
+		inv.Id, hash, now, now.Add(s.config.TTL))
+	if inv, err = s.scanInvitation(row); err != nil {
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, fmt.Sprintf("invitations/%d", inv.Id)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "invitation resent", "invitation_id", inv.Id, "send_count", inv.SendCount)
+	return &invitationspb.ResendInvitationResponse{
+		Invitation: inv,
+		Token:      token,
+		AcceptUrl:  s.acceptURL(token),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package invitations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_ResendInvitation(t *testing.T) {
+	lockQuery := `SELECT .* FROM invitations WHERE id = \$1 FOR UPDATE`
+
+	t.Run("success - new token and expiry", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(int64(1)).WillReturnRows(pendingRow(1).rows())
+		resent := pendingRow(1)
+		resent.sendCount, resent.lastSentAt, resent.expiresAt = 2, testNow, testNow.Add(DefaultTTL)
+		mock.ExpectQuery(`UPDATE invitations\s+SET token_hash = \$2, send_count = send_count \+ 1`).
+			WithArgs(int64(1), anyBytes{}, testNow, testNow.Add(DefaultTTL)).
+			WillReturnRows(resent.rows())
+		mock.ExpectCommit()
+
+		resp, err := service.ResendInvitation(context.Background(), &invitationspb.ResendInvitationRequest{Id: 1})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Token)
+		assert.Equal(t, int32(2), resp.Invitation.SendCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	tests := []struct {
+		name string
+		row  func(r invitationRow) invitationRow
+		code codes.Code
+	}{
+		{
+			name: "error - already accepted",
+			row:  func(r invitationRow) invitationRow { r.acceptedAt = testNow; return r },
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "error - revoked",
+			row:  func(r invitationRow) invitationRow { r.revokedAt = testNow; return r },
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "error - send limit reached",
+			row:  func(r invitationRow) invitationRow { r.sendCount = DefaultMaxSends; return r },
+			code: codes.ResourceExhausted,
+		},
+		{
+			name: "error - within cooldown",
+			row:  func(r invitationRow) invitationRow { r.lastSentAt = testNow.Add(-10 * time.Second); return r },
+			code: codes.ResourceExhausted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t, Config{})
+			mock.ExpectBegin()
+			mock.ExpectQuery(lockQuery).WillReturnRows(tt.row(pendingRow(1)).rows())
+			mock.ExpectRollback()
+
+			_, err := service.ResendInvitation(context.Background(), &invitationspb.ResendInvitationRequest{Id: 1})
+			assertStatusCode(t, err, tt.code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("error - not found", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		_, err := service.ResendInvitation(context.Background(), &invitationspb.ResendInvitationRequest{Id: 1})
+		assertStatusCode(t, err, codes.NotFound)
+	})
+}
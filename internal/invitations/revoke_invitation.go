@@ -0,0 +1,45 @@
+package invitations
+
+import (
+	"context"
+	"fmt"
+
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeInvitation revokes an invitation that has not been accepted yet
+func (s *Service) RevokeInvitation(ctx context.Context, req *invitationspb.RevokeInvitationRequest) (_ *invitationspb.RevokeInvitationResponse, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	inv, err := s.lockInvitation(ctx, tx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	switch inv.State {
+	case invitationspb.Invitation_STATE_ACCEPTED, invitationspb.Invitation_STATE_REVOKED:
+		return nil, status.Errorf(codes.FailedPrecondition, "invitation %d is %s", inv.Id, stateName(inv.State))
+	}
+
+	row := tx.QueryRowContext(ctx,
+		"UPDATE invitations SET revoked_at = $2 WHERE id = $1 RETURNING "+invitationColumns,
+		inv.Id, s.now())
+	if inv, err = s.scanInvitation(row); err != nil {
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, fmt.Sprintf("invitations/%d", inv.Id)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "invitation revoked", "invitation_id", inv.Id)
+	return &invitationspb.RevokeInvitationResponse{Invitation: inv}, nil
+}
@@ -0,0 +1,45 @@
+package invitations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_RevokeInvitation(t *testing.T) {
+	t.Run("success - pending invitation revoked", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM invitations WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(pendingRow(1).rows())
+		revoked := pendingRow(1)
+		revoked.revokedAt = testNow
+		mock.ExpectQuery(`UPDATE invitations SET revoked_at = \$2 WHERE id = \$1`).
+			WithArgs(int64(1), testNow).
+			WillReturnRows(revoked.rows())
+		mock.ExpectCommit()
+
+		resp, err := service.RevokeInvitation(context.Background(), &invitationspb.RevokeInvitationRequest{Id: 1})
+		require.NoError(t, err)
+		assert.Equal(t, invitationspb.Invitation_STATE_REVOKED, resp.Invitation.State)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - already accepted", func(t *testing.T) {
+		service, mock := newMockService(t, Config{})
+		accepted := pendingRow(1)
+		accepted.acceptedAt = testNow
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM invitations`).WillReturnRows(accepted.rows())
+		mock.ExpectRollback()
+
+		_, err := service.RevokeInvitation(context.Background(), &invitationspb.RevokeInvitationRequest{Id: 1})
+		assertStatusCode(t, err, codes.FailedPrecondition)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,176 @@
+package invitations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/groups"
+	"github.com/zcking/go-api-template/internal/txn"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Defaults applied to a zero Config
+const (
+	DefaultTTL            = 7 * 24 * time.Hour
+	DefaultMaxSends       = 5
+	DefaultResendCooldown = time.Minute
+)
+
+// invitationColumns is the column list scanned by scanInvitation
+const invitationColumns = "id, email, name, group_ids, send_count, last_sent_at, created_at, expires_at, accepted_at, accepted_user_id, revoked_at"
+
+// Config holds configuration for invitations
+type Config struct {
+	// AcceptURL is the page that accepts invitations. When set, issued
+	// invitations include a link with the token in its "token" query parameter.
+	AcceptURL string
+	// TTL is how long an invitation can be accepted after it was last sent
+	TTL time.Duration
+	// MaxSends is the maximum number of times an invitation can be sent, including the first
+	MaxSends int
+	// ResendCooldown is the minimum time between two sends of the same invitation
+	ResendCooldown time.Duration
+}
+
+// Service handles gRPC requests for invitation operations
+type Service struct {
+	invitationspb.UnimplementedInvitationServiceServer
+	db     *sql.DB
+	txns   *txn.Manager
+	users  *users.Service
+	groups *groups.Service
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new invitation service. Accepted invitations create
+// users through usersService and memberships through groupsService.
+func NewService(db *sql.DB, config Config, usersService *users.Service, groupsService *groups.Service, logger *slog.Logger) *Service {
+	if config.TTL == 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.MaxSends == 0 {
+		config.MaxSends = DefaultMaxSends
+	}
+	if config.ResendCooldown == 0 {
+		config.ResendCooldown = DefaultResendCooldown
+	}
+
+	return &Service{
+		db:     db,
+		txns:   txn.NewManager(db, logger),
+		users:  usersService,
+		groups: groupsService,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// newToken generates a random invitation token and the hash that is stored for it
+func newToken() (token string, hash []byte, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the SHA-256 hash under which a token is stored. Tokens
+// carry 256 bits of entropy, so an unsalted hash is sufficient.
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// acceptURL returns the link for accepting an invitation with the given token
+func (s *Service) acceptURL(token string) string {
+	if s.config.AcceptURL == "" {
+		return ""
+	}
+	u, err := url.Parse(s.config.AcceptURL)
+	if err != nil {
+		s.logger.Warn("invalid invitation accept URL", "error", err)
+		return ""
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanInvitation scans a row of invitationColumns into an Invitation
+func (s *Service) scanInvitation(row rowScanner) (*invitationspb.Invitation, error) {
+	var (
+		inv                   invitationspb.Invitation
+		lastSentAt, createdAt time.Time
+		expiresAt             time.Time
+		acceptedAt, revokedAt sql.NullTime
+		acceptedUserID        sql.NullInt64
+	)
+	err := row.Scan(&inv.Id, &inv.Email, &inv.Name, pq.Array(&inv.GroupIds), &inv.SendCount,
+		&lastSentAt, &createdAt, &expiresAt, &acceptedAt, &acceptedUserID, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	inv.LastSendTime = timestamppb.New(lastSentAt)
+	inv.CreateTime = timestamppb.New(createdAt)
+	inv.ExpireTime = timestamppb.New(expiresAt)
+	inv.AcceptedUserId = acceptedUserID.Int64
+	switch {
+	case acceptedAt.Valid:
+		inv.State = invitationspb.Invitation_STATE_ACCEPTED
+	case revokedAt.Valid:
+		inv.State = invitationspb.Invitation_STATE_REVOKED
+	case !s.now().Before(expiresAt):
+		inv.State = invitationspb.Invitation_STATE_EXPIRED
+	default:
+		inv.State = invitationspb.Invitation_STATE_PENDING
+	}
+	return &inv, nil
+}
+
+// lockInvitation reads an invitation by id within tx, locking its row until the transaction ends
+func (s *Service) lockInvitation(ctx context.Context, tx *sql.Tx, id int64) (*invitationspb.Invitation, error) {
+	row := tx.QueryRowContext(ctx, "SELECT "+invitationColumns+" FROM invitations WHERE id = $1 FOR UPDATE", id)
+	inv, err := s.scanInvitation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "invitation %d not found", id)
+	}
+	return inv, err
+}
+
+// rollback rolls back tx if err is set. It is meant to be deferred with a
+// pointer to the caller's named error result.
+func rollback(tx *sql.Tx, err *error) {
+	if *err != nil {
+		_ = tx.Rollback()
+	}
+}
+
+// stateName returns a human readable name for an invitation state, for error messages
+func stateName(state invitationspb.Invitation_State) string {
+	return strings.ToLower(strings.TrimPrefix(state.String(), "STATE_"))
+}
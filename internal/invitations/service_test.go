@@ -0,0 +1,186 @@
+package invitations
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/groups"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testKeyring encrypts the users created by services under test
+var testKeyring = func() *encryption.Keyring {
+	k, err := encryption.NewKeyring("1", map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return k
+}()
+
+// newMockService creates a Service backed by go-sqlmock with a fixed clock
+func newMockService(t *testing.T, config Config) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	service := NewService(db, config, users.NewServiceFromDB(db, testKeyring, logger), groups.NewService(db, logger), logger)
+	service.now = func() time.Time { return testNow }
+	return service, mock
+}
+
+// invitationRow is a row of invitationColumns, with the fields tests care about exposed
+type invitationRow struct {
+	id             int64
+	groupIDs       string
+	sendCount      int
+	lastSentAt     time.Time
+	expiresAt      time.Time
+	acceptedAt     any
+	acceptedUserID any
+	revokedAt      any
+}
+
+// pendingRow returns a row for a pending invitation sent an hour ago
+func pendingRow(id int64) invitationRow {
+	return invitationRow{
+		id:         id,
+		groupIDs:   "{}",
+		sendCount:  1,
+		lastSentAt: testNow.Add(-time.Hour),
+		expiresAt:  testNow.Add(DefaultTTL - time.Hour),
+	}
+}
+
+func (r invitationRow) rows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "email", "name", "group_ids", "send_count", "last_sent_at",
+		"created_at", "expires_at", "accepted_at", "accepted_user_id", "revoked_at"}).
+		AddRow(r.id, "jdoe@example.com", "John Doe", r.groupIDs, r.sendCount, r.lastSentAt,
+			r.lastSentAt, r.expiresAt, r.acceptedAt, r.acceptedUserID, r.revokedAt)
+}
+
+// anyBytes matches any []byte argument, such as a token hash
+type anyBytes struct{}
+
+func (anyBytes) Match(v driver.Value) bool {
+	_, ok := v.([]byte)
+	return ok
+}
+
+// assertStatusCode asserts err is a gRPC status error with the given code
+func assertStatusCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestNewService_Defaults(t *testing.T) {
+	service, _ := newMockService(t, Config{})
+	assert.Equal(t, DefaultTTL, service.config.TTL)
+	assert.Equal(t, DefaultMaxSends, service.config.MaxSends)
+	assert.Equal(t, DefaultResendCooldown, service.config.ResendCooldown)
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := newToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Equal(t, hashToken(token), hash)
+
+	other, _, err := newToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestService_AcceptURL(t *testing.T) {
+	service, _ := newMockService(t, Config{})
+	assert.Empty(t, service.acceptURL("abc"))
+
+	service.config.AcceptURL = "https://app.example.com/invite?source=email"
+	assert.Equal(t, "https://app.example.com/invite?source=email&token=abc", service.acceptURL("abc"))
+}
+
+func TestService_ScanInvitation_State(t *testing.T) {
+	service, mock := newMockService(t, Config{})
+
+	tests := []struct {
+		name  string
+		row   func(r invitationRow) invitationRow
+		state invitationspb.Invitation_State
+	}{
+		{name: "pending", row: func(r invitationRow) invitationRow { return r }, state: invitationspb.Invitation_STATE_PENDING},
+		{name: "accepted", row: func(r invitationRow) invitationRow { r.acceptedAt, r.acceptedUserID = testNow, 3; return r }, state: invitationspb.Invitation_STATE_ACCEPTED},
+		{name: "revoked", row: func(r invitationRow) invitationRow { r.revokedAt = testNow; return r }, state: invitationspb.Invitation_STATE_REVOKED},
+		{name: "expired", row: func(r invitationRow) invitationRow { r.expiresAt = testNow; return r }, state: invitationspb.Invitation_STATE_EXPIRED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery("SELECT").WillReturnRows(tt.row(pendingRow(1)).rows())
+			inv, err := service.scanInvitation(service.db.QueryRow("SELECT"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.state, inv.State)
+		})
+	}
+}
+
+// capturedArg records the value of a query argument
+type capturedArg struct{ value *driver.Value }
+
+func (c capturedArg) Match(v driver.Value) bool {
+	*c.value = v
+	return true
+}
+
+// insertedUser captures the id and the values of users.SealedColumns
+// written when a user is created
+type insertedUser struct{ values [9]driver.Value }
+
+// args returns matchers capturing the insert's arguments
+func (u *insertedUser) args() []driver.Value {
+	args := make([]driver.Value, len(u.values))
+	for i := range u.values {
+		args[i] = capturedArg{&u.values[i]}
+	}
+	return args
+}
+
+// open decrypts the captured email and name
+func (u *insertedUser) open(t *testing.T) (email, name string) {
+	t.Helper()
+	values, err := testKeyring.Open(&encryption.Envelope{
+		KeyVersion: u.values[7].(string),
+		Owner:      strconv.FormatInt(u.values[0].(int64), 10),
+		DataKey:    u.values[6].([]byte),
+		Values:     [][]byte{u.values[3].([]byte), u.values[4].([]byte)},
+	})
+	require.NoError(t, err)
+	return values[0], values[1]
+}
+
+// expectInsertUser expects user id to be created, capturing the inserted
+// values in u unless it is nil
+func expectInsertUser(mock sqlmock.Sqlmock, id int64, u *insertedUser) {
+	mock.ExpectQuery(`SELECT nextval\('seq_users_id'\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	insert := mock.ExpectExec(`INSERT INTO users`)
+	if u != nil {
+		insert.WithArgs(u.args()...)
+	}
+	insert.WillReturnResult(sqlmock.NewResult(0, 1))
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrDuplicate is returned by Enqueue when a pending or running job of the
+// same kind already has the unique key
+var ErrDuplicate = errors.New("a job with this unique key is already queued")
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// EnqueueOptions control when and how often a job runs
+type EnqueueOptions struct {
+	// RunAt is the earliest time the job runs; zero means now
+	RunAt time.Time
+	// UniqueKey, if set, prevents enqueueing the job while another job of the
+	// same kind with the same key is pending or running
+	UniqueKey string
+	// MaxAttempts overrides Config.MaxAttempts for this job
+	MaxAttempts int
+}
+
+// Enqueue adds a job of kind with args to the queue and returns its id. q may
+// be a transaction so the job is only enqueued if the change that needs it
+// commits.
+func Enqueue[T any](ctx context.Context, queue *Queue, q queryer, kind Kind[T], args T, opts EnqueueOptions) (int64, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return 0, err
+	}
+	now := queue.now()
+	runAt := opts.RunAt
+	if runAt.IsZero() {
+		runAt = now
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = queue.config.MaxAttempts
+	}
+	var uniqueKey sql.NullString
+	if opts.UniqueKey != "" {
+		uniqueKey = sql.NullString{String: opts.UniqueKey, Valid: true}
+	}
+
+	var id int64
+	err = q.QueryRowContext(ctx, `INSERT INTO jobs (kind, payload, unique_key, run_at, max_attempts, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND state IN ('pending', 'running') DO NOTHING
+		RETURNING id`,
+		string(kind), payload, uniqueKey, runAt, maxAttempts, now).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrDuplicate
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
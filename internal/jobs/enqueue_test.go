@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueue(t *testing.T) {
+	insert := regexp.QuoteMeta("INSERT INTO jobs (kind, payload, unique_key, run_at, max_attempts, created_at, updated_at)")
+	later := testNow.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		opts      EnqueueOptions
+		mockSetup func(sqlmock.Sqlmock)
+		wantID    int64
+		wantErr   error
+	}{
+		{
+			name: "defaults",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(insert).
+					WithArgs("test.send_email", []byte(`{"user_id":42}`), sql.NullString{}, testNow, DefaultMaxAttempts, testNow).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			},
+			wantID: 7,
+		},
+		{
+			name: "scheduled and unique",
+			opts: EnqueueOptions{RunAt: later, UniqueKey: "welcome:42", MaxAttempts: 1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(insert).
+					WithArgs("test.send_email", []byte(`{"user_id":42}`), sql.NullString{String: "welcome:42", Valid: true}, later, 1, testNow).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+			},
+			wantID: 8,
+		},
+		{
+			name: "error - duplicate unique key",
+			opts: EnqueueOptions{UniqueKey: "welcome:42"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(insert).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantErr: ErrDuplicate,
+		},
+		{
+			name: "error - database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(insert).WillReturnError(errors.New("database error"))
+			},
+			wantErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, mock, _ := newMockQueue(t, Config{})
+			tt.mockSetup(mock)
+
+			id, err := Enqueue(t.Context(), q, q.db, testKind, testArgs{UserID: 42}, tt.opts)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantID, id)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
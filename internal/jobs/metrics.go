@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// registerMetrics creates the queue's instruments:
+//
+//   - jobs.queue.depth: jobs in the table by kind and state, read from the
+//     database whenever metrics are collected
+//   - jobs.queue.latency: how long ready jobs waited before a worker claimed them
+//   - jobs.duration: how long jobs ran, by kind and outcome
+func (q *Queue) registerMetrics(meter metric.Meter) error {
+	var err error
+	q.latency, err = meter.Float64Histogram("jobs.queue.latency",
+		metric.WithDescription("Time from a job's run_at until a worker claimed it"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	q.duration, err = meter.Float64Histogram("jobs.duration",
+		metric.WithDescription("Time spent running jobs"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	_, err = meter.Int64ObservableGauge("jobs.queue.depth",
+		metric.WithDescription("Jobs in the queue by kind and state"),
+		metric.WithUnit("{job}"),
+		metric.WithInt64Callback(q.observeDepth))
+	return err
+}
+
+// observeDepth reports the number of jobs by kind and state
+func (q *Queue) observeDepth(ctx context.Context, o metric.Int64Observer) error {
+	rows, err := q.db.QueryContext(ctx, "SELECT kind, state, count(*) FROM jobs GROUP BY kind, state")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind, state string
+		var n int64
+		if err := rows.Scan(&kind, &state, &n); err != nil {
+			return err
+		}
+		o.Observe(n, metric.WithAttributes(attribute.String("kind", kind), attribute.String("state", state)))
+	}
+	return rows.Err()
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collect returns the metrics the queue reported, by name
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestQueue_Metrics(t *testing.T) {
+	q, mock, reader := newMockQueue(t, Config{})
+	Register(q, testKind, func(ctx context.Context, args testArgs) error { return nil })
+	mock.ExpectQuery(claimJob).
+		WillReturnRows(sqlmock.NewRows(jobColumns).AddRow(1, "test.send_email", []byte(`{}`), 1, 3, testNow.Add(-30*time.Second)))
+	mock.ExpectExec(deleteJob).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err := q.runNext(t.Context(), []string{"test.send_email"})
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT kind, state, count\\(\\*\\) FROM jobs GROUP BY kind, state").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "state", "count"}).
+			AddRow("test.send_email", "pending", 3).
+			AddRow("test.send_email", "failed", 1))
+	metrics := collect(t, reader)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	depth := metrics["jobs.queue.depth"].(metricdata.Gauge[int64])
+	byState := make(map[string]int64)
+	for _, dp := range depth.DataPoints {
+		state, _ := dp.Attributes.Value(attribute.Key("state"))
+		byState[state.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"pending": 3, "failed": 1}, byState)
+
+	latency := metrics["jobs.queue.latency"].(metricdata.Histogram[float64])
+	require.Len(t, latency.DataPoints, 1)
+	assert.Equal(t, 30.0, latency.DataPoints[0].Sum)
+
+	duration := metrics["jobs.duration"].(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 1)
+	outcome, _ := duration.DataPoints[0].Attributes.Value(attribute.Key("outcome"))
+	assert.Equal(t, "succeeded", outcome.AsString())
+}
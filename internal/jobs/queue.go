@@ -0,0 +1,272 @@
+// Package jobs is a background job queue stored in Postgres. Jobs are
+// enqueued, usually in the transaction of the change that needs them, and
+// run by a pool of workers that claim them with SELECT ... FOR UPDATE SKIP
+// LOCKED, so any number of instances can share the queue. Failed jobs are
+// retried with exponential backoff until they run out of attempts.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// DefaultWorkers is used when Config.Workers is not set
+	DefaultWorkers = 4
+	// DefaultPollInterval is used when Config.PollInterval is not set
+	DefaultPollInterval = time.Second
+	// DefaultJobTimeout is used when Config.JobTimeout is not set
+	DefaultJobTimeout = 15 * time.Minute
+	// DefaultMaxAttempts is used when neither Config.MaxAttempts nor
+	// EnqueueOptions.MaxAttempts is set
+	DefaultMaxAttempts = 5
+
+	minBackoff = 10 * time.Second
+	maxBackoff = time.Hour
+)
+
+// Config configures the workers of a Queue
+type Config struct {
+	// Workers is the number of jobs run concurrently by this instance
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for jobs again
+	PollInterval time.Duration
+	// JobTimeout bounds how long a job may run. A job still marked running
+	// after this long, because its worker died, is claimed again.
+	JobTimeout time.Duration
+	// MaxAttempts is the number of times a job is tried before it is marked failed
+	MaxAttempts int
+}
+
+// Kind names a type of job whose arguments are T. Arguments are stored as
+// JSON, so T must round-trip through encoding/json.
+type Kind[T any] string
+
+// Queue enqueues jobs and runs them on a pool of workers
+type Queue struct {
+	db       *sql.DB
+	config   Config
+	logger   *slog.Logger
+	now      func() time.Time
+	backoff  func(attempt int) time.Duration
+	handlers map[string]func(ctx context.Context, payload []byte) error
+
+	latency  metric.Float64Histogram
+	duration metric.Float64Histogram
+
+	stop    chan struct{}
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// NewQueue creates a queue using an existing database connection. Its
+// metrics are reported through the global MeterProvider.
+func NewQueue(db *sql.DB, config Config, logger *slog.Logger) (*Queue, error) {
+	return newQueue(db, config, logger, otel.GetMeterProvider())
+}
+
+func newQueue(db *sql.DB, config Config, logger *slog.Logger, mp metric.MeterProvider) (*Queue, error) {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.JobTimeout <= 0 {
+		config.JobTimeout = DefaultJobTimeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	q := &Queue{
+		db:       db,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+		backoff:  backoff,
+		handlers: make(map[string]func(ctx context.Context, payload []byte) error),
+	}
+	if err := q.registerMetrics(mp.Meter("github.com/zcking/go-api-template/internal/jobs")); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Register sets the handler that runs jobs of kind. Handlers must be
+// registered before Start; an instance only claims the kinds it handles.
+func Register[T any](q *Queue, kind Kind[T], handler func(ctx context.Context, args T) error) {
+	q.handlers[string(kind)] = func(ctx context.Context, payload []byte) error {
+		var args T
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return fmt.Errorf("failed to decode job arguments: %w", err)
+		}
+		return handler(ctx, args)
+	}
+}
+
+// Start starts the workers. It does nothing if no handlers are registered.
+func (q *Queue) Start() {
+	if len(q.handlers) == 0 {
+		return
+	}
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	var ctx context.Context
+	ctx, q.cancel = context.WithCancel(context.Background())
+	q.stop = make(chan struct{})
+	for range q.config.Workers {
+		q.workers.Add(1)
+		go q.work(ctx, kinds)
+	}
+}
+
+// Shutdown stops the workers from claiming jobs and waits for the jobs they
+// are running to finish. If ctx ends first, running jobs are canceled and
+// will be retried; Shutdown still waits for them to return.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	if q.stop == nil {
+		return nil
+	}
+	close(q.stop)
+	drained := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+// work runs jobs until the queue is stopped, polling when there are none
+func (q *Queue) work(ctx context.Context, kinds []string) {
+	defer q.workers.Done()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-timer.C:
+		}
+		ran, err := q.runNext(ctx, kinds)
+		if err != nil {
+			q.logger.Error("failed to run job", "error", err)
+		}
+		if ran && err == nil {
+			timer.Reset(0)
+		} else {
+			timer.Reset(q.config.PollInterval)
+		}
+	}
+}
+
+// job is a claimed row of the jobs table
+type job struct {
+	id          int64
+	kind        string
+	payload     []byte
+	attempts    int
+	maxAttempts int
+	runAt       time.Time
+}
+
+// runNext claims the next ready job of one of kinds, runs it and records the
+// outcome. It reports whether there was a job to run.
+func (q *Queue) runNext(ctx context.Context, kinds []string) (bool, error) {
+	now := q.now()
+	var j job
+	err := q.db.QueryRowContext(context.WithoutCancel(ctx), `UPDATE jobs SET state = 'running', attempts = attempts + 1, locked_at = $1, updated_at = $1
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE kind = ANY($2) AND run_at <= $1
+			AND (state = 'pending' OR (state = 'running' AND locked_at < $3))
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, kind, payload, attempts, max_attempts, run_at`,
+		now, pq.Array(kinds), now.Add(-q.config.JobTimeout)).
+		Scan(&j.id, &j.kind, &j.payload, &j.attempts, &j.maxAttempts, &j.runAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	kind := attribute.String("kind", j.kind)
+	q.latency.Record(ctx, now.Sub(j.runAt).Seconds(), metric.WithAttributes(kind))
+
+	jobCtx, cancel := context.WithTimeout(ctx, q.config.JobTimeout)
+	jobErr := q.call(jobCtx, j)
+	cancel()
+
+	outcome, err := q.settle(context.WithoutCancel(ctx), j, jobErr)
+	q.duration.Record(ctx, q.now().Sub(now).Seconds(), metric.WithAttributes(kind, attribute.String("outcome", outcome)))
+	return true, err
+}
+
+// call runs the handler of j, turning a panic into an error
+func (q *Queue) call(ctx context.Context, j job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return q.handlers[j.kind](ctx, j.payload)
+}
+
+// settle records the outcome of running j: it is deleted if it succeeded,
+// scheduled again if it failed with attempts left, and marked failed
+// otherwise. Updates are fenced by the attempt number so a job that was
+// claimed again after timing out is left to its new worker.
+func (q *Queue) settle(ctx context.Context, j job, jobErr error) (string, error) {
+	if jobErr == nil {
+		_, err := q.db.ExecContext(ctx, "DELETE FROM jobs WHERE id = $1 AND attempts = $2", j.id, j.attempts)
+		return "succeeded", err
+	}
+	now := q.now()
+	if j.attempts < j.maxAttempts {
+		q.logger.Warn("job failed, will retry", "job_id", j.id, "kind", j.kind, "attempt", j.attempts, "error", jobErr)
+		_, err := q.db.ExecContext(ctx,
+			"UPDATE jobs SET state = 'pending', run_at = $3, last_error = $4, locked_at = NULL, updated_at = $5 WHERE id = $1 AND attempts = $2",
+			j.id, j.attempts, now.Add(q.backoff(j.attempts)), jobErr.Error(), now)
+		return "retried", err
+	}
+	q.logger.Error("job failed", "job_id", j.id, "kind", j.kind, "attempts", j.attempts, "error", jobErr)
+	_, err := q.db.ExecContext(ctx,
+		"UPDATE jobs SET state = 'failed', last_error = $3, locked_at = NULL, updated_at = $4 WHERE id = $1 AND attempts = $2",
+		j.id, j.attempts, jobErr.Error(), now)
+	return "failed", err
+}
+
+// backoff returns how long to wait before retrying a job that failed its
+// attempt'th attempt: exponential from minBackoff up to maxBackoff, with
+// jitter so jobs that failed together do not retry together
+func backoff(attempt int) time.Duration {
+	d := maxBackoff
+	if attempt < 32 {
+		d = min(minBackoff<<(attempt-1), maxBackoff)
+	}
+	return d/2 + rand.N(d/2)
+}
@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testArgs are the arguments of testKind jobs
+type testArgs struct {
+	UserID int64 `json:"user_id"`
+}
+
+const testKind Kind[testArgs] = "test.send_email"
+
+// newMockQueue creates a Queue backed by go-sqlmock whose clock is fixed at
+// testNow, whose retries back off by exactly one minute and whose metrics
+// are collected by the returned reader
+func newMockQueue(t *testing.T, config Config) (*Queue, sqlmock.Sqlmock, *sdkmetric.ManualReader) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	reader := sdkmetric.NewManualReader()
+	q, err := newQueue(db, config, slog.New(slog.NewJSONHandler(io.Discard, nil)), sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+	q.now = func() time.Time { return testNow }
+	q.backoff = func(int) time.Duration { return time.Minute }
+	return q, mock, reader
+}
+
+var (
+	claimJob   = regexp.QuoteMeta("UPDATE jobs SET state = 'running'")
+	deleteJob  = regexp.QuoteMeta("DELETE FROM jobs WHERE id = $1 AND attempts = $2")
+	retryJob   = regexp.QuoteMeta("UPDATE jobs SET state = 'pending', run_at = $3, last_error = $4, locked_at = NULL, updated_at = $5 WHERE id = $1 AND attempts = $2")
+	failJob    = regexp.QuoteMeta("UPDATE jobs SET state = 'failed', last_error = $3, locked_at = NULL, updated_at = $4 WHERE id = $1 AND attempts = $2")
+	jobColumns = []string{"id", "kind", "payload", "attempts", "max_attempts", "run_at"}
+)
+
+func TestQueue_RunNext(t *testing.T) {
+	handlerErr := errors.New("smtp unavailable")
+	tests := []struct {
+		name      string
+		attempts  int
+		handler   func(ctx context.Context, args testArgs) error
+		mockSetup func(sqlmock.Sqlmock)
+	}{
+		{
+			name:     "success deletes the job",
+			attempts: 1,
+			handler: func(ctx context.Context, args testArgs) error {
+				if args.UserID != 42 {
+					return errors.New("wrong arguments")
+				}
+				return nil
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(deleteJob).WithArgs(int64(1), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "failure with attempts left is retried",
+			attempts: 2,
+			handler:  func(ctx context.Context, args testArgs) error { return handlerErr },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(retryJob).
+					WithArgs(int64(1), 2, testNow.Add(time.Minute), "smtp unavailable", testNow).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "failure on the last attempt marks the job failed",
+			attempts: 3,
+			handler:  func(ctx context.Context, args testArgs) error { return handlerErr },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(failJob).
+					WithArgs(int64(1), 3, "smtp unavailable", testNow).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "panic is a failure",
+			attempts: 1,
+			handler:  func(ctx context.Context, args testArgs) error { panic("boom") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(retryJob).
+					WithArgs(int64(1), 1, testNow.Add(time.Minute), "job panicked: boom", testNow).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, mock, _ := newMockQueue(t, Config{})
+			Register(q, testKind, tt.handler)
+			mock.ExpectQuery(claimJob).
+				WithArgs(testNow, pq.Array([]string{"test.send_email"}), testNow.Add(-DefaultJobTimeout)).
+				WillReturnRows(sqlmock.NewRows(jobColumns).AddRow(1, "test.send_email", []byte(`{"user_id": 42}`), tt.attempts, 3, testNow))
+			tt.mockSetup(mock)
+
+			ran, err := q.runNext(t.Context(), []string{"test.send_email"})
+			require.NoError(t, err)
+			assert.True(t, ran)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("no ready job", func(t *testing.T) {
+		q, mock, _ := newMockQueue(t, Config{})
+		mock.ExpectQuery(claimJob).WillReturnRows(sqlmock.NewRows(jobColumns))
+
+		ran, err := q.runNext(t.Context(), []string{"test.send_email"})
+		require.NoError(t, err)
+		assert.False(t, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - claim fails", func(t *testing.T) {
+		q, mock, _ := newMockQueue(t, Config{})
+		mock.ExpectQuery(claimJob).WillReturnError(errors.New("database error"))
+
+		ran, err := q.runNext(t.Context(), []string{"test.send_email"})
+		assert.Error(t, err)
+		assert.False(t, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestQueue_Shutdown(t *testing.T) {
+	t.Run("waits for running jobs", func(t *testing.T) {
+		q, mock, _ := newMockQueue(t, Config{Workers: 1, PollInterval: time.Hour})
+		started, finished := make(chan struct{}), make(chan struct{})
+		Register(q, testKind, func(ctx context.Context, args testArgs) error {
+			close(started)
+			time.Sleep(10 * time.Millisecond)
+			close(finished)
+			return nil
+		})
+		mock.ExpectQuery(claimJob).WillReturnRows(sqlmock.NewRows(jobColumns).AddRow(1, "test.send_email", []byte(`{}`), 1, 3, testNow))
+		mock.ExpectExec(deleteJob).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(claimJob).WillReturnRows(sqlmock.NewRows(jobColumns))
+
+		q.Start()
+		<-started
+		require.NoError(t, q.Shutdown(t.Context()))
+		select {
+		case <-finished:
+		default:
+			t.Fatal("Shutdown returned before the running job finished")
+		}
+	})
+
+	t.Run("cancels running jobs when the deadline passes", func(t *testing.T) {
+		q, mock, _ := newMockQueue(t, Config{Workers: 1, PollInterval: time.Hour})
+		started := make(chan struct{})
+		Register(q, testKind, func(ctx context.Context, args testArgs) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		mock.ExpectQuery(claimJob).WillReturnRows(sqlmock.NewRows(jobColumns).AddRow(1, "test.send_email", []byte(`{}`), 1, 3, testNow))
+		mock.ExpectExec(retryJob).WithArgs(int64(1), 1, testNow.Add(time.Minute), "context canceled", testNow).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		q.Start()
+		<-started
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, q.Shutdown(ctx), context.DeadlineExceeded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("without handlers", func(t *testing.T) {
+		q, mock, _ := newMockQueue(t, Config{})
+		q.Start()
+		require.NoError(t, q.Shutdown(t.Context()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Second, 3: 40 * time.Second, 20: time.Hour, 100: time.Hour} {
+		d := backoff(attempt)
+		assert.GreaterOrEqual(t, d, want/2, "attempt %d", attempt)
+		assert.Less(t, d, want, "attempt %d", attempt)
+	}
+}
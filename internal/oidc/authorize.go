@@ -0,0 +1,129 @@
+package oidc
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pkceMethod is the only accepted PKCE code challenge method. The plain
+// method offers no protection if the authorization request leaks.
+const pkceMethod = "S256"
+
+// handleAuthorize serves the authorization endpoint (OpenID Connect Core
+// section 3.1.2). It signs the user in through the Authenticator and
+// redirects back to the client with a single-use authorization code.
+func (p *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		p.writeError(w, r, newOAuthError(http.StatusBadRequest, "invalid_request", "malformed request"))
+		return
+	}
+	params := r.Form
+
+	// Until the client and redirect URI are known to be valid, errors are
+	// shown to the user rather than redirected, to avoid open redirects.
+	client, err := p.lookupClient(r.Context(), params.Get("client_id"))
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+	if client == nil {
+		p.writeError(w, r, newOAuthError(http.StatusBadRequest, "invalid_request", "unknown client_id"))
+		return
+	}
+	redirectURI := params.Get("redirect_uri")
+	if !client.allowsRedirect(redirectURI) {
+		p.writeError(w, r, newOAuthError(http.StatusBadRequest, "invalid_request", "redirect_uri is not registered for this client"))
+		return
+	}
+
+	state := params.Get("state")
+	fail := func(code, description string) {
+		p.redirect(w, r, redirectURI, url.Values{"error": {code}, "error_description": {description}, "state": {state}})
+	}
+
+	if params.Get("response_type") != "code" {
+		fail("unsupported_response_type", "only the code response type is supported")
+		return
+	}
+	scope := filterScopes(params.Get("scope"))
+	if !hasScope(scope, ScopeOpenID) {
+		fail("invalid_scope", "the openid scope is required")
+		return
+	}
+	challenge := params.Get("code_challenge")
+	if challenge == "" {
+		fail("invalid_request", "code_challenge is required")
+		return
+	}
+	if params.Get("code_challenge_method") != pkceMethod {
+		fail("invalid_request", "code_challenge_method must be S256")
+		return
+	}
+
+	userID, ok := p.auth.Authenticate(w, r)
+	if !ok {
+		return
+	}
+	active, err := p.userActive(r.Context(), userID)
+	if err != nil && status.Code(err) != codes.NotFound {
+		p.logger.ErrorContext(r.Context(), "failed to look up user", "error", err, "user_id", userID)
+		fail("server_error", "failed to look up the user")
+		return
+	}
+	if !active {
+		fail("access_denied", "the user does not exist or is deactivated")
+		return
+	}
+
+	code, codeHash, err := newSecret()
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+	now := p.now()
+	_, err = p.db.ExecContext(r.Context(),
+		`INSERT INTO oidc_authorization_codes
+		(code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		codeHash, client.ID, userID, redirectURI, scope, params.Get("nonce"), challenge, now, now.Add(p.config.CodeTTL))
+	if err != nil {
+		p.logger.ErrorContext(r.Context(), "failed to store authorization code", "error", err, "client_id", client.ID)
+		fail("server_error", "failed to issue an authorization code")
+		return
+	}
+
+	p.redirect(w, r, redirectURI, url.Values{"code": {code}, "state": {state}})
+}
+
+// redirect sends an authorization response to the client's redirect URI.
+// The issuer is included so clients can detect mix-up attacks (RFC 9207).
+func (p *Provider) redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	if params.Get("state") == "" {
+		params.Del("state")
+	}
+	params.Set("iss", p.config.Issuer)
+
+	target, _ := url.Parse(redirectURI)
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// filterScopes drops unsupported and duplicate scopes from a scope list
+func filterScopes(scope string) string {
+	var kept []string
+	for _, s := range strings.Fields(scope) {
+		if slices.Contains(supportedScopes, s) && !slices.Contains(kept, s) {
+			kept = append(kept, s)
+		}
+	}
+	return strings.Join(kept, " ")
+}
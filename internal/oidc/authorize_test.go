@@ -0,0 +1,177 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_Authorize(t *testing.T) {
+	const redirectURI = "https://app.example.com/cb"
+	insert := regexp.QuoteMeta("INSERT INTO oidc_authorization_codes")
+
+	valid := func() url.Values {
+		return url.Values{
+			"client_id":             {"app"},
+			"redirect_uri":          {redirectURI},
+			"response_type":         {"code"},
+			"scope":                 {"openid email"},
+			"state":                 {"xyz"},
+			"nonce":                 {"n-0S6"},
+			"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+			"code_challenge_method": {"S256"},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(params url.Values)
+		userID    string
+		mockSetup func(mock sqlmock.Sqlmock)
+		// wantStatus is set for errors shown to the user instead of redirected
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:   "issues code",
+			userID: "7",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectClient(mock, "app", []byte("hash"), "{"+redirectURI+"}")
+				expectActive(mock, 7, true)
+				mock.ExpectExec(insert).
+					WithArgs(sqlmock.AnyArg(), "app", int64(7), redirectURI, "openid email", "n-0S6",
+						"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", testNow, testNow.Add(DefaultCodeTTL)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:       "unknown client",
+			modify:     func(params url.Values) { params.Set("client_id", "nope") },
+			userID:     "7",
+			mockSetup:  func(mock sqlmock.Sqlmock) { mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(nil)) },
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "unregistered redirect URI",
+			modify:     func(params url.Values) { params.Set("redirect_uri", "https://evil.example.com/cb") },
+			userID:     "7",
+			mockSetup:  func(mock sqlmock.Sqlmock) { expectClient(mock, "app", nil, "{"+redirectURI+"}") },
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_request",
+		},
+		{
+			name:      "unsupported response type",
+			modify:    func(params url.Values) { params.Set("response_type", "token") },
+			userID:    "7",
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", nil, "{"+redirectURI+"}") },
+			wantError: "unsupported_response_type",
+		},
+		{
+			name:      "missing openid scope",
+			modify:    func(params url.Values) { params.Set("scope", "email") },
+			userID:    "7",
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", nil, "{"+redirectURI+"}") },
+			wantError: "invalid_scope",
+		},
+		{
+			name:      "missing code challenge",
+			modify:    func(params url.Values) { params.Del("code_challenge") },
+			userID:    "7",
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", nil, "{"+redirectURI+"}") },
+			wantError: "invalid_request",
+		},
+		{
+			name:      "plain code challenge method",
+			modify:    func(params url.Values) { params.Set("code_challenge_method", "plain") },
+			userID:    "7",
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", nil, "{"+redirectURI+"}") },
+			wantError: "invalid_request",
+		},
+		{
+			name:       "not signed in",
+			mockSetup:  func(mock sqlmock.Sqlmock) { expectClient(mock, "app", nil, "{"+redirectURI+"}") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "database error",
+			userID: "7",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectClient(mock, "app", nil, "{"+redirectURI+"}")
+				expectActive(mock, 7, true)
+				mock.ExpectExec(insert).WillReturnError(errors.New("database error"))
+			},
+			wantError: "server_error",
+		},
+		{
+			name:   "deactivated user",
+			userID: "7",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectClient(mock, "app", nil, "{"+redirectURI+"}")
+				expectActive(mock, 7, false)
+			},
+			wantError: "access_denied",
+		},
+		{
+			name:   "unknown user",
+			userID: "7",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectClient(mock, "app", nil, "{"+redirectURI+"}")
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT active FROM users")).WillReturnRows(sqlmock.NewRows(nil))
+			},
+			wantError: "access_denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mock := newMockProvider(t, testIssuer)
+			tt.mockSetup(mock)
+
+			params := valid()
+			if tt.modify != nil {
+				tt.modify(params)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/authorize?"+params.Encode(), nil)
+			if tt.userID != "" {
+				req.Header.Set("X-User-Id", tt.userID)
+			}
+			rec := serve(p, req)
+
+			if tt.wantStatus != 0 {
+				assert.Equal(t, tt.wantStatus, rec.Code)
+				if tt.wantError != "" {
+					var body map[string]string
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+					assert.Equal(t, tt.wantError, body["error"])
+				}
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+
+			require.Equal(t, http.StatusFound, rec.Code)
+			location, err := url.Parse(rec.Header().Get("Location"))
+			require.NoError(t, err)
+			assert.Equal(t, redirectURI, location.Scheme+"://"+location.Host+location.Path)
+			query := location.Query()
+			assert.Equal(t, "xyz", query.Get("state"))
+			assert.Equal(t, testIssuer, query.Get("iss"))
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, query.Get("error"))
+				assert.Empty(t, query.Get("code"))
+			} else {
+				assert.Empty(t, query.Get("error"))
+				assert.Len(t, query.Get("code"), 43)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+// Client is an application registered to sign users in with this provider
+type Client struct {
+	ID   string
+	Name string
+	// RedirectURIs are the exact URIs authorization responses may be sent to
+	RedirectURIs []string
+	// Public clients (e.g. single-page and native apps) have no secret and
+	// authenticate with PKCE only
+	Public bool
+
+	secretHash []byte
+}
+
+// RegisterClient stores a new client and returns its secret, which is empty
+// for public clients. Only a hash of the secret is stored. A client id is
+// generated when client.ID is empty.
+func (p *Provider) RegisterClient(ctx context.Context, client Client) (*Client, string, error) {
+	if client.Name == "" {
+		return nil, "", fmt.Errorf("client name is required")
+	}
+	if len(client.RedirectURIs) == 0 {
+		return nil, "", fmt.Errorf("at least one redirect URI is required")
+	}
+	for _, uri := range client.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return nil, "", err
+		}
+	}
+	if client.ID == "" {
+		id, _, err := newSecret()
+		if err != nil {
+			return nil, "", err
+		}
+		client.ID = id[:22]
+	}
+
+	var secret string
+	if !client.Public {
+		var err error
+		if secret, client.secretHash, err = newSecret(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// A NULL secret hash marks the client as public
+	secretHash := sql.Null[[]byte]{V: client.secretHash, Valid: client.secretHash != nil}
+	_, err := p.db.ExecContext(ctx,
+		"INSERT INTO oidc_clients (id, name, secret_hash, redirect_uris) VALUES ($1, $2, $3, $4)",
+		client.ID, client.Name, secretHash, pq.Array(client.RedirectURIs))
+	if err != nil {
+		return nil, "", err
+	}
+	return &client, secret, nil
+}
+
+// validateRedirectURI checks that uri is an absolute URI without a fragment (RFC 6749 section 3.1.2)
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("redirect URI %q must be an absolute URI", uri)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not have a fragment", uri)
+	}
+	return nil
+}
+
+// lookupClient fetches a registered client. It returns nil if there is no such client.
+func (p *Provider) lookupClient(ctx context.Context, id string) (*Client, error) {
+	if id == "" {
+		return nil, nil
+	}
+	client := Client{ID: id}
+	err := p.db.QueryRowContext(ctx,
+		"SELECT name, secret_hash, redirect_uris FROM oidc_clients WHERE id = $1", id).
+		Scan(&client.Name, &client.secretHash, pq.Array(&client.RedirectURIs))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.Public = client.secretHash == nil
+	return &client, nil
+}
+
+// allowsRedirect reports whether uri is one of the client's registered redirect URIs
+func (c *Client) allowsRedirect(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// authenticateClient identifies the client calling the token endpoint, from
+// HTTP basic authentication (client_secret_basic) or the client_id and
+// client_secret form parameters (client_secret_post). Public clients send
+// only their client_id.
+func (p *Provider) authenticateClient(r *http.Request) (*Client, error) {
+	id, secret, basic := r.BasicAuth()
+	if basic {
+		// RFC 6749 section 2.3.1: credentials are form-encoded before basic encoding
+		var err error
+		if id, err = url.QueryUnescape(id); err != nil {
+			return nil, invalidClient()
+		}
+		if secret, err = url.QueryUnescape(secret); err != nil {
+			return nil, invalidClient()
+		}
+	} else {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	client, err := p.lookupClient(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, invalidClient()
+	}
+	if client.Public {
+		if secret != "" {
+			return nil, invalidClient()
+		}
+		return client, nil
+	}
+	if secret == "" || subtle.ConstantTimeCompare(hashSecret(secret), client.secretHash) != 1 {
+		return nil, invalidClient()
+	}
+	return client, nil
+}
+
+// invalidClient returns the error for a failed client authentication
+func invalidClient() error {
+	return newOAuthError(http.StatusUnauthorized, "invalid_client", "client authentication failed")
+}
@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_RegisterClient(t *testing.T) {
+	insert := regexp.QuoteMeta("INSERT INTO oidc_clients (id, name, secret_hash, redirect_uris) VALUES ($1, $2, $3, $4)")
+
+	tests := []struct {
+		name       string
+		client     Client
+		mockSetup  func(mock sqlmock.Sqlmock)
+		wantSecret bool
+		wantErr    bool
+	}{
+		{
+			name:   "confidential client",
+			client: Client{ID: "app", Name: "App", RedirectURIs: []string{"https://app.example.com/cb"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insert).
+					WithArgs("app", "App", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantSecret: true,
+		},
+		{
+			name:   "public client with generated id",
+			client: Client{Name: "SPA", RedirectURIs: []string{"http://localhost:3000/cb"}, Public: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insert).
+					WithArgs(sqlmock.AnyArg(), "SPA", nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:      "missing redirect URIs",
+			client:    Client{Name: "App"},
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+		{
+			name:      "relative redirect URI",
+			client:    Client{Name: "App", RedirectURIs: []string{"/cb"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+		{
+			name:      "redirect URI with fragment",
+			client:    Client{Name: "App", RedirectURIs: []string{"https://app.example.com/cb#x"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+		{
+			name:   "database error",
+			client: Client{ID: "app", Name: "App", RedirectURIs: []string{"https://app.example.com/cb"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(insert).WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mock := newMockProvider(t, testIssuer)
+			tt.mockSetup(mock)
+
+			client, secret, err := p.RegisterClient(t.Context(), tt.client)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotEmpty(t, client.ID)
+				assert.Equal(t, tt.wantSecret, secret != "")
+				if tt.wantSecret {
+					assert.Equal(t, hashSecret(secret), client.secretHash)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProvider_AuthenticateClient(t *testing.T) {
+	secret, secretHash, err := newSecret()
+	require.NoError(t, err)
+	lookup := regexp.QuoteMeta("SELECT name, secret_hash, redirect_uris FROM oidc_clients WHERE id = $1")
+
+	tests := []struct {
+		name      string
+		setupReq  func(req *http.Request)
+		form      url.Values
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantID    string
+		wantErr   bool
+	}{
+		{
+			name:      "client_secret_basic",
+			setupReq:  func(req *http.Request) { req.SetBasicAuth("app", secret) },
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", secretHash, "{}") },
+			wantID:    "app",
+		},
+		{
+			name:      "client_secret_basic with form-encoded id",
+			setupReq:  func(req *http.Request) { req.SetBasicAuth("my%3Aapp", secret) },
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "my:app", secretHash, "{}") },
+			wantID:    "my:app",
+		},
+		{
+			name:      "client_secret_post",
+			form:      url.Values{"client_id": {"app"}, "client_secret": {secret}},
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", secretHash, "{}") },
+			wantID:    "app",
+		},
+		{
+			name:      "public client",
+			form:      url.Values{"client_id": {"spa"}},
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "spa", nil, "{}") },
+			wantID:    "spa",
+		},
+		{
+			name:      "public client sending a secret",
+			form:      url.Values{"client_id": {"spa"}, "client_secret": {"guess"}},
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "spa", nil, "{}") },
+			wantErr:   true,
+		},
+		{
+			name:      "wrong secret",
+			setupReq:  func(req *http.Request) { req.SetBasicAuth("app", "guess") },
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", secretHash, "{}") },
+			wantErr:   true,
+		},
+		{
+			name:      "confidential client without secret",
+			form:      url.Values{"client_id": {"app"}},
+			mockSetup: func(mock sqlmock.Sqlmock) { expectClient(mock, "app", secretHash, "{}") },
+			wantErr:   true,
+		},
+		{
+			name: "unknown client",
+			form: url.Values{"client_id": {"nope"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lookup).WithArgs("nope").WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+		},
+		{
+			name:      "no credentials",
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mock := newMockProvider(t, testIssuer)
+			tt.mockSetup(mock)
+
+			req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.setupReq != nil {
+				tt.setupReq(req)
+			}
+			require.NoError(t, req.ParseForm())
+
+			client, err := p.authenticateClient(req)
+			if tt.wantErr {
+				var oauthErr *oauthError
+				require.ErrorAs(t, err, &oauthErr)
+				assert.Equal(t, "invalid_client", oauthErr.Code)
+				assert.Equal(t, http.StatusUnauthorized, oauthErr.status)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantID, client.ID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
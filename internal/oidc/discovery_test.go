@@ -0,0 +1,27 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_Discovery(t *testing.T) {
+	p, _ := newMockProvider(t, "https://example.com/oidc")
+
+	rec := serve(p, httptest.NewRequest(http.MethodGet, "/oidc/.well-known/openid-configuration", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc discoveryDocument
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "https://example.com/oidc", doc.Issuer)
+	assert.Equal(t, "https://example.com/oidc/authorize", doc.AuthorizationEndpoint)
+	assert.Equal(t, "https://example.com/oidc/token", doc.TokenEndpoint)
+	assert.Equal(t, "https://example.com/oidc/userinfo", doc.UserInfoEndpoint)
+	assert.Equal(t, "https://example.com/oidc/jwks.json", doc.JWKSURI)
+	assert.Equal(t, []string{"S256"}, doc.CodeChallengeMethodsSupported)
+}
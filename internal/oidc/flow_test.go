@@ -0,0 +1,284 @@
+package oidc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturedHash records the code hash stored by the authorization endpoint
+// so later queries can check they look up the same code
+type capturedHash struct{ value []byte }
+
+func (c *capturedHash) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	if c.value == nil {
+		c.value = b
+		return true
+	}
+	return bytes.Equal(c.value, b)
+}
+
+// rpClient is a minimal relying party that drives the provider over HTTP
+// the way a third-party OIDC client library would
+type rpClient struct {
+	t            *testing.T
+	http         *http.Client
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	config       discoveryDocument
+	keys         jose.JSONWebKeySet
+}
+
+func (c *rpClient) getJSON(target string, v any) {
+	c.t.Helper()
+	resp, err := c.http.Get(target)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	require.Equal(c.t, http.StatusOK, resp.StatusCode)
+	require.NoError(c.t, json.NewDecoder(resp.Body).Decode(v))
+}
+
+// discover fetches the provider metadata and key set
+func (c *rpClient) discover() {
+	c.t.Helper()
+	c.getJSON(c.issuer+"/.well-known/openid-configuration", &c.config)
+	// OpenID Connect Discovery section 4.3: the issuer must match exactly
+	require.Equal(c.t, c.issuer, c.config.Issuer)
+	c.getJSON(c.config.JWKSURI, &c.keys)
+}
+
+// authorize runs an authorization request as the signed-in user and
+// returns the code from the redirect
+func (c *rpClient) authorize(userID, state, nonce, challenge string) url.Values {
+	c.t.Helper()
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.clientID},
+		"redirect_uri":          {c.redirectURI},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	req, err := http.NewRequest(http.MethodGet, c.config.AuthorizationEndpoint+"?"+params.Encode(), nil)
+	require.NoError(c.t, err)
+	req.Header.Set("X-User-Id", userID)
+	resp, err := c.http.Do(req)
+	require.NoError(c.t, err)
+	resp.Body.Close()
+	require.Equal(c.t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(c.t, err)
+	require.True(c.t, strings.HasPrefix(location.String(), c.redirectURI+"?"))
+	return location.Query()
+}
+
+// exchange redeems a code at the token endpoint
+func (c *rpClient) exchange(code, verifier string) (*http.Response, map[string]any) {
+	c.t.Helper()
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {verifier},
+		"redirect_uri":  {c.redirectURI},
+	}
+	req, err := http.NewRequest(http.MethodPost, c.config.TokenEndpoint, strings.NewReader(form.Encode()))
+	require.NoError(c.t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	resp, err := c.http.Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	require.NoError(c.t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, body
+}
+
+// verifyIDToken validates an ID token against the published key set
+// (OpenID Connect Core section 3.1.3.7)
+func (c *rpClient) verifyIDToken(raw, nonce string, now time.Time) idTokenClaims {
+	c.t.Helper()
+	token, err := jwt.ParseSigned(raw, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(c.t, err)
+	keys := c.keys.Key(token.Headers[0].KeyID)
+	require.Len(c.t, keys, 1, "ID token must be signed by a published key")
+
+	var claims idTokenClaims
+	require.NoError(c.t, token.Claims(keys[0].Key, &claims))
+	require.NoError(c.t, claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      c.issuer,
+		AnyAudience: jwt.Audience{c.clientID},
+		Time:        now,
+	}, 0))
+	require.Equal(c.t, nonce, claims.Nonce)
+	return claims
+}
+
+func newVerifier(t *testing.T) (verifier, challenge string) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// TestAuthorizationCodeFlow runs the OpenID Connect authorization code flow
+// with PKCE end to end over HTTP: discovery, key set, authorization, token
+// exchange, ID token validation and UserInfo, plus the checks a conformant
+// provider must make on replayed codes and wrong verifiers.
+func TestAuthorizationCodeFlow(t *testing.T) {
+	const redirectURI = "https://app.example.com/cb"
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p, mock := newMockProvider(t, srv.URL)
+	p.Register(mux)
+
+	secret, secretHash, err := newSecret()
+	require.NoError(t, err)
+	client := &rpClient{
+		t: t,
+		http: &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}},
+		issuer:       srv.URL,
+		clientID:     "web:app",
+		clientSecret: secret,
+		redirectURI:  redirectURI,
+	}
+	selectCode := regexp.QuoteMeta("SELECT client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at, used_at")
+	codeColumns := []string{"client_id", "user_id", "redirect_uri", "scope", "nonce", "code_challenge", "auth_time", "expires_at", "used_at"}
+	userRow := userRows(t, 7, "ada@example.com", "Ada")
+
+	// Discovery and key set
+	expectKeys(t, mock)
+	client.discover()
+	assert.Equal(t, srv.URL+"/authorize", client.config.AuthorizationEndpoint)
+	require.Len(t, client.keys.Keys, 1)
+
+	// Authorization request
+	verifier, challenge := newVerifier(t)
+	codeHash := &capturedHash{}
+	expectClient(mock, client.clientID, secretHash, "{"+redirectURI+"}")
+	expectActive(mock, 7, true)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO oidc_authorization_codes")).
+		WithArgs(codeHash, client.clientID, int64(7), redirectURI, "openid email profile", "n-123", challenge, testNow, testNow.Add(DefaultCodeTTL)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result := client.authorize("7", "s-456", "n-123", challenge)
+	assert.Equal(t, "s-456", result.Get("state"))
+	assert.Equal(t, srv.URL, result.Get("iss"))
+	code := result.Get("code")
+	require.NotEmpty(t, code)
+	assert.Equal(t, hashSecret(code), codeHash.value, "only the code hash may be stored")
+
+	// Token exchange
+	expectClient(mock, client.clientID, secretHash, "{"+redirectURI+"}")
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCode).
+		WithArgs(codeHash).
+		WillReturnRows(sqlmock.NewRows(codeColumns).
+			AddRow(client.clientID, int64(7), redirectURI, "openid email profile", "n-123", challenge, testNow, testNow.Add(DefaultCodeTTL), nil))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE oidc_authorization_codes SET used_at")).
+		WithArgs(codeHash, testNow).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectActive(mock, 7, true)
+	mock.ExpectQuery(selectUserByID).WillReturnRows(userRow)
+
+	resp, tokens := client.exchange(code, verifier)
+	require.Equal(t, http.StatusOK, resp.StatusCode, tokens)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "Bearer", tokens["token_type"])
+
+	claims := client.verifyIDToken(tokens["id_token"].(string), "n-123", testNow)
+	assert.Equal(t, "7", claims.Subject)
+	assert.Equal(t, "ada@example.com", claims.Email)
+	assert.Equal(t, "Ada", claims.Name)
+
+	// UserInfo
+	expectActive(mock, 7, true)
+	mock.ExpectQuery(selectUserByID).
+		WillReturnRows(userRows(t, 7, "ada@example.com", "Ada Lovelace"))
+
+	req, err := http.NewRequest(http.MethodGet, client.config.UserInfoEndpoint, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+tokens["access_token"].(string))
+	infoResp, err := client.http.Do(req)
+	require.NoError(t, err)
+	defer infoResp.Body.Close()
+	require.Equal(t, http.StatusOK, infoResp.StatusCode)
+	var info userInfo
+	require.NoError(t, json.NewDecoder(infoResp.Body).Decode(&info))
+	assert.Equal(t, userInfo{Subject: "7", Email: "ada@example.com", Name: "Ada Lovelace"}, info)
+
+	// The ID token is not an access token
+	req.Header.Set("Authorization", "Bearer "+tokens["id_token"].(string))
+	rejected, err := client.http.Do(req)
+	require.NoError(t, err)
+	rejected.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, rejected.StatusCode)
+
+	// A replayed code is rejected
+	expectClient(mock, client.clientID, secretHash, "{"+redirectURI+"}")
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCode).
+		WithArgs(codeHash).
+		WillReturnRows(sqlmock.NewRows(codeColumns).
+			AddRow(client.clientID, int64(7), redirectURI, "openid email profile", "n-123", challenge, testNow, testNow.Add(DefaultCodeTTL), testNow))
+	mock.ExpectRollback()
+
+	resp, body := client.exchange(code, verifier)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	// A code cannot be redeemed without the verifier it was issued for
+	otherVerifier, _ := newVerifier(t)
+	expectClient(mock, client.clientID, secretHash, "{"+redirectURI+"}")
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCode).
+		WillReturnRows(sqlmock.NewRows(codeColumns).
+			AddRow(client.clientID, int64(7), redirectURI, "openid email profile", "n-123", challenge, testNow, testNow.Add(DefaultCodeTTL), nil))
+	mock.ExpectRollback()
+
+	resp, body = client.exchange(code, otherVerifier)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	// A wrong client secret is rejected
+	client.clientSecret = "wrong"
+	expectClient(mock, client.clientID, secretHash, "{"+redirectURI+"}")
+
+	resp, body = client.exchange(code, verifier)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "invalid_client", body["error"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,206 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// keyBits is the size of generated RSA signing keys
+const keyBits = 2048
+
+// keyCacheTTL is how long loaded signing keys are reused before they are
+// reloaded, so that replicas pick up keys rotated by one another
+const keyCacheTTL = time.Minute
+
+// keyRotationLockKey is the transaction-level advisory lock taken while
+// rotating keys, so that concurrent replicas create a single new key
+const keyRotationLockKey int64 = 0x6f6964636b657973 // "oidckeys"
+
+// signingKey is an RSA key used to sign tokens
+type signingKey struct {
+	kid       string
+	key       *rsa.PrivateKey
+	createdAt time.Time
+}
+
+// keyCache holds the published signing keys, newest first
+type keyCache struct {
+	mu       sync.Mutex
+	keys     []signingKey
+	loadedAt time.Time
+}
+
+// signingKeys returns the published signing keys, newest first. The first
+// key signs new tokens. A new key is created once the newest one is older
+// than the rotation period; retired keys stay published until every token
+// they signed has expired.
+func (p *Provider) signingKeys(ctx context.Context) ([]signingKey, error) {
+	p.keys.mu.Lock()
+	defer p.keys.mu.Unlock()
+
+	now := p.now()
+	if len(p.keys.keys) > 0 && now.Sub(p.keys.loadedAt) < keyCacheTTL && !p.rotationDue(p.keys.keys, now) {
+		return p.keys.keys, nil
+	}
+
+	keys, err := p.loadKeys(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	if p.rotationDue(keys, now) {
+		if err := p.rotateKeys(ctx, now); err != nil {
+			return nil, err
+		}
+		if keys, err = p.loadKeys(ctx, now); err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("no signing key available after rotation")
+		}
+	}
+
+	p.keys.keys, p.keys.loadedAt = keys, now
+	return keys, nil
+}
+
+// rotationDue reports whether a new signing key must be created
+func (p *Provider) rotationDue(keys []signingKey, now time.Time) bool {
+	return len(keys) == 0 || now.Sub(keys[0].createdAt) >= p.config.KeyRotationPeriod
+}
+
+// retentionCutoff is the creation time before which keys are no longer
+// published. A key signs tokens for at most the rotation period plus
+// keyCacheTTL (see activeKey), and its tokens live for TokenTTL after that.
+func (p *Provider) retentionCutoff(now time.Time) time.Time {
+	return now.Add(-(p.config.KeyRotationPeriod + keyCacheTTL + p.config.TokenTTL))
+}
+
+// loadKeys reads the published signing keys, newest first
+func (p *Provider) loadKeys(ctx context.Context, now time.Time) ([]signingKey, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT kid, private_key, created_at FROM oidc_signing_keys WHERE created_at > $1 ORDER BY created_at DESC",
+		p.retentionCutoff(now))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]signingKey, 0)
+	for rows.Next() {
+		var (
+			key signingKey
+			der []byte
+		)
+		if err := rows.Scan(&key.kid, &der, &key.createdAt); err != nil {
+			return nil, err
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %w", key.kid, err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not an RSA key", key.kid)
+		}
+		key.key = rsaKey
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// rotateKeys creates a new signing key and deletes keys past retention,
+// unless another replica has just rotated
+func (p *Provider) rotateKeys(ctx context.Context, now time.Time) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", keyRotationLockKey); err != nil {
+		return err
+	}
+
+	var newest sql.NullTime
+	if err = tx.QueryRowContext(ctx, "SELECT max(created_at) FROM oidc_signing_keys").Scan(&newest); err != nil {
+		return err
+	}
+	if newest.Valid && now.Sub(newest.Time) < p.config.KeyRotationPeriod {
+		return tx.Commit()
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	kid, err := keyID(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		"INSERT INTO oidc_signing_keys (kid, private_key, created_at) VALUES ($1, $2, $3)", kid, der, now); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx,
+		"DELETE FROM oidc_signing_keys WHERE created_at <= $1", p.retentionCutoff(now)); err != nil {
+		return err
+	}
+
+	p.logger.InfoContext(ctx, "rotated OIDC signing key", "kid", kid)
+	return tx.Commit()
+}
+
+// keyID returns the RFC 7638 thumbprint of a public key, used as its kid
+func keyID(key *rsa.PublicKey) (string, error) {
+	thumbprint, err := (&jose.JSONWebKey{Key: key}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute key thumbprint: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+// publicJWK returns the public JSON Web Key of a signing key
+func (k signingKey) publicJWK() jose.JSONWebKey {
+	return jose.JSONWebKey{
+		Key:       &k.key.PublicKey,
+		KeyID:     k.kid,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}
+}
+
+// handleJWKS serves the published signing keys as a JSON Web Key Set
+func (p *Provider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	keys, err := p.signingKeys(r.Context())
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+
+	set := jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, len(keys))}
+	for i, key := range keys {
+		set.Keys[i] = key.publicJWK()
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(keyCacheTTL.Seconds())))
+	p.writeJSON(w, r, http.StatusOK, set)
+}
@@ -0,0 +1,122 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_SigningKeys(t *testing.T) {
+	t.Run("cached", func(t *testing.T) {
+		p, mock := newMockProvider(t, testIssuer)
+		key := expectKeys(t, mock)
+
+		for range 3 {
+			keys, err := p.signingKeys(t.Context())
+			require.NoError(t, err)
+			require.Len(t, keys, 1)
+			assert.Equal(t, key.kid, keys[0].kid)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reloaded after cache TTL", func(t *testing.T) {
+		p, mock := newMockProvider(t, testIssuer)
+		expectKeys(t, mock)
+		expectKeys(t, mock)
+
+		_, err := p.signingKeys(t.Context())
+		require.NoError(t, err)
+		p.now = func() time.Time { return testNow.Add(keyCacheTTL) }
+		_, err = p.signingKeys(t.Context())
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rotates when no key exists", func(t *testing.T) {
+		p, mock := newMockProvider(t, testIssuer)
+		loadKeys := regexp.QuoteMeta("SELECT kid, private_key, created_at FROM oidc_signing_keys WHERE created_at > $1")
+		mock.ExpectQuery(loadKeys).
+			WithArgs(testNow.Add(-(DefaultKeyRotationPeriod + keyCacheTTL + DefaultTokenTTL))).
+			WillReturnRows(sqlmock.NewRows([]string{"kid", "private_key", "created_at"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+			WithArgs(keyRotationLockKey).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT max(created_at) FROM oidc_signing_keys")).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO oidc_signing_keys (kid, private_key, created_at) VALUES ($1, $2, $3)")).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), testNow).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM oidc_signing_keys WHERE created_at <= $1")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		expectKeys(t, mock)
+
+		keys, err := p.signingKeys(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips rotation done by another replica", func(t *testing.T) {
+		p, mock := newMockProvider(t, testIssuer)
+		key, der := testSigningKey(t)
+		expired := testNow.Add(-DefaultKeyRotationPeriod)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT kid, private_key, created_at FROM oidc_signing_keys")).
+			WillReturnRows(sqlmock.NewRows([]string{"kid", "private_key", "created_at"}).AddRow(key.kid, der, expired))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT max(created_at) FROM oidc_signing_keys")).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(testNow.Add(-time.Second)))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT kid, private_key, created_at FROM oidc_signing_keys")).
+			WillReturnRows(sqlmock.NewRows([]string{"kid", "private_key", "created_at"}).
+				AddRow("new", der, testNow.Add(-time.Second)).
+				AddRow(key.kid, der, expired))
+
+		keys, err := p.signingKeys(t.Context())
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "new", keys[0].kid)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestActiveKey(t *testing.T) {
+	fresh := signingKey{kid: "fresh", createdAt: testNow.Add(-time.Second)}
+	published := signingKey{kid: "published", createdAt: testNow.Add(-keyCacheTTL)}
+	old := signingKey{kid: "old", createdAt: testNow.Add(-time.Hour)}
+
+	assert.Equal(t, "published", activeKey([]signingKey{fresh, published, old}, testNow).kid)
+	assert.Equal(t, "old", activeKey([]signingKey{fresh, old}, testNow).kid)
+	assert.Equal(t, "fresh", activeKey([]signingKey{fresh}, testNow).kid)
+}
+
+func TestProvider_JWKS(t *testing.T) {
+	p, mock := newMockProvider(t, testIssuer)
+	key := expectKeys(t, mock)
+
+	rec := serve(p, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "public")
+
+	var set jose.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	jwk := set.Keys[0]
+	assert.Equal(t, key.kid, jwk.KeyID)
+	assert.Equal(t, "RS256", jwk.Algorithm)
+	assert.Equal(t, "sig", jwk.Use)
+	assert.True(t, jwk.IsPublic(), "private key material must not be published")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,244 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults applied to a zero Config
+const (
+	DefaultTokenTTL          = time.Hour
+	DefaultCodeTTL           = time.Minute
+	DefaultKeyRotationPeriod = 30 * 24 * time.Hour
+)
+
+// Supported scopes. openid is required on every authorization request.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+var supportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+
+// Config holds configuration for the OpenID Connect provider
+type Config struct {
+	// Issuer is the provider's issuer identifier, e.g. https://id.example.com.
+	// Endpoints are served under its path.
+	Issuer string
+	// TokenTTL is the lifetime of issued ID and access tokens
+	TokenTTL time.Duration
+	// CodeTTL is how long an authorization code can be exchanged
+	CodeTTL time.Duration
+	// KeyRotationPeriod is how long a signing key signs tokens before it is
+	// replaced by a new one
+	KeyRotationPeriod time.Duration
+}
+
+// Provider is an OpenID Connect provider that issues identity for the users
+// owned by this service. It implements the authorization code flow with
+// PKCE; clients, authorization codes and signing keys are stored in Postgres.
+type Provider struct {
+	db     *sql.DB
+	users  *users.Service
+	auth   Authenticator
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+	path   string
+	keys   keyCache
+}
+
+// NewProvider creates an OpenID Connect provider. End users are identified
+// by auth and looked up through usersService.
+func NewProvider(db *sql.DB, usersService *users.Service, auth Authenticator, config Config, logger *slog.Logger) (*Provider, error) {
+	issuer, err := url.Parse(config.Issuer)
+	if err != nil || issuer.Host == "" || (issuer.Scheme != "https" && issuer.Scheme != "http") {
+		return nil, fmt.Errorf("invalid OIDC issuer %q: must be an absolute http(s) URL", config.Issuer)
+	}
+	if issuer.RawQuery != "" || issuer.Fragment != "" {
+		return nil, fmt.Errorf("invalid OIDC issuer %q: must not have a query or fragment", config.Issuer)
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = DefaultTokenTTL
+	}
+	if config.CodeTTL <= 0 {
+		config.CodeTTL = DefaultCodeTTL
+	}
+	if config.KeyRotationPeriod <= 0 {
+		config.KeyRotationPeriod = DefaultKeyRotationPeriod
+	}
+
+	return &Provider{
+		db:     db,
+		users:  usersService,
+		auth:   auth,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+		path:   strings.TrimSuffix(issuer.Path, "/"),
+	}, nil
+}
+
+// Register adds the provider's endpoints to mux
+func (p *Provider) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+p.path+"/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("GET "+p.path+"/jwks.json", p.handleJWKS)
+	mux.HandleFunc("GET "+p.path+"/authorize", p.handleAuthorize)
+	mux.HandleFunc("POST "+p.path+"/authorize", p.handleAuthorize)
+	mux.HandleFunc("POST "+p.path+"/token", p.handleToken)
+	mux.HandleFunc("GET "+p.path+"/userinfo", p.handleUserInfo)
+	mux.HandleFunc("POST "+p.path+"/userinfo", p.handleUserInfo)
+}
+
+// userActive reports whether the user id may sign in: users deactivated
+// through SCIM may not. It returns a NotFound status if the user does not
+// exist.
+func (p *Provider) userActive(ctx context.Context, id int64) (bool, error) {
+	var active bool
+	err := p.db.QueryRowContext(ctx, "SELECT active FROM users WHERE id = $1", id).Scan(&active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, status.Errorf(codes.NotFound, "user %d not found", id)
+	}
+	return active, err
+}
+
+// endpoint returns the absolute URL of an endpoint
+func (p *Provider) endpoint(path string) string {
+	return p.config.Issuer + path
+}
+
+// PurgeExpiredCodes deletes authorization codes that expired without being
+// exchanged. Exchanged codes are kept as the users' sign-in history. It is
+// meant to be called periodically.
+func (p *Provider) PurgeExpiredCodes(ctx context.Context) (int64, error) {
+	result, err := p.db.ExecContext(ctx, "DELETE FROM oidc_authorization_codes WHERE expires_at < $1 AND used_at IS NULL", p.now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Authenticator identifies the end user at the authorization endpoint. When
+// the user is not signed in it writes a response itself (e.g. a redirect to
+// a login page) and returns ok == false.
+type Authenticator interface {
+	Authenticate(w http.ResponseWriter, r *http.Request) (userID int64, ok bool)
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator
+type AuthenticatorFunc func(w http.ResponseWriter, r *http.Request) (int64, bool)
+
+// Authenticate calls f(w, r)
+func (f AuthenticatorFunc) Authenticate(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	return f(w, r)
+}
+
+// HeaderAuthenticator trusts a user id set in a request header by an
+// authenticating reverse proxy. The proxy must strip the header from
+// incoming requests, otherwise anyone can sign in as any user.
+type HeaderAuthenticator struct {
+	// Header carries the signed-in user's id
+	Header string
+	// LoginURL is where users without the header are sent to sign in. The
+	// authorization request URL is passed in its return_to query parameter.
+	// When empty, such requests are rejected.
+	LoginURL string
+}
+
+// Authenticate implements Authenticator
+func (a HeaderAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if id, err := strconv.ParseInt(r.Header.Get(a.Header), 10, 64); err == nil && id > 0 {
+		return id, true
+	}
+	if a.LoginURL == "" {
+		http.Error(w, "sign in required", http.StatusUnauthorized)
+		return 0, false
+	}
+	http.Redirect(w, r, a.LoginURL+"?"+url.Values{"return_to": {r.URL.RequestURI()}}.Encode(), http.StatusFound)
+	return 0, false
+}
+
+// newSecret returns a random URL-safe secret and its SHA-256 hash
+func newSecret() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	return secret, hashSecret(secret), nil
+}
+
+// hashSecret returns the SHA-256 hash stored for a secret
+func hashSecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// writeJSON writes v as a JSON response. Unless the handler has set its own
+// Cache-Control header the response is not cacheable, since most responses
+// carry tokens or user data.
+func (p *Provider) writeJSON(w http.ResponseWriter, r *http.Request, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		p.logger.ErrorContext(r.Context(), "failed to write OIDC response", "error", err)
+	}
+}
+
+// oauthError is an OAuth 2.0 error response (RFC 6749 section 5.2)
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	status      int
+}
+
+func (e *oauthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// newOAuthError creates an oauthError returned with the given HTTP status
+func newOAuthError(status int, code, format string, args ...any) *oauthError {
+	return &oauthError{Code: code, Description: fmt.Sprintf(format, args...), status: status}
+}
+
+// writeError writes err as an OAuth error response. Errors that are not an
+// *oauthError are logged and reported as server_error.
+func (p *Provider) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	oauthErr, ok := err.(*oauthError)
+	if !ok {
+		p.logger.ErrorContext(r.Context(), "OIDC request failed", "error", err, "path", r.URL.Path)
+		oauthErr = newOAuthError(http.StatusInternalServerError, "server_error", "internal error")
+	}
+	p.writeJSON(w, r, oauthErr.status, oauthErr)
+}
+
+// hasScope reports whether a space-separated scope list contains scope
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+const testIssuer = "https://id.example.com"
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testKeyring decrypts the users read by the provider
+var testKeyring = func() *encryption.Keyring {
+	k, err := encryption.NewKeyring("1", map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return k
+}()
+
+// selectUserByID is the query the users service runs to load a user
+var selectUserByID = regexp.QuoteMeta("SELECT id, " + users.PIIColumns + " FROM users WHERE id = $1")
+
+// expectActive expects the check that user id may sign in
+func expectActive(mock sqlmock.Sqlmock, id int64, active bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT active FROM users WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"active"}).AddRow(active))
+}
+
+// userRows returns users rows whose email and name are sealed with testKeyring
+func userRows(t *testing.T, id int64, email, name string) *sqlmock.Rows {
+	t.Helper()
+	sealed, err := testKeyring.Seal(strconv.FormatInt(id, 10), email, name)
+	require.NoError(t, err)
+	return sqlmock.NewRows([]string{"id", "email", "name", "email_ciphertext", "name_ciphertext", "data_key", "key_version", "pii_bound"}).
+		AddRow(id, nil, nil, sealed.Values[0], sealed.Values[1], sealed.DataKey, sealed.KeyVersion, true)
+}
+
+var (
+	testKeyOnce sync.Once
+	testKey     signingKey
+	testKeyDER  []byte
+)
+
+// testSigningKey returns an RSA signing key shared by the tests, created two
+// hours before testNow
+func testSigningKey(t *testing.T) (signingKey, []byte) {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, keyBits)
+		require.NoError(t, err)
+		kid, err := keyID(&key.PublicKey)
+		require.NoError(t, err)
+		testKeyDER, err = x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		testKey = signingKey{kid: kid, key: key, createdAt: testNow.Add(-2 * time.Hour)}
+	})
+	return testKey, testKeyDER
+}
+
+// newMockProvider creates a Provider backed by go-sqlmock whose clock is
+// fixed at testNow. Users are authenticated by the X-User-Id header.
+func newMockProvider(t *testing.T, issuer string) (*Provider, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	p, err := NewProvider(db, users.NewServiceFromDB(db, testKeyring, logger), HeaderAuthenticator{Header: "X-User-Id"}, Config{Issuer: issuer}, logger)
+	require.NoError(t, err)
+	p.now = func() time.Time { return testNow }
+	return p, mock
+}
+
+// expectKeys expects the signing keys to be loaded and returns the test key
+func expectKeys(t *testing.T, mock sqlmock.Sqlmock) signingKey {
+	t.Helper()
+	key, der := testSigningKey(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT kid, private_key, created_at FROM oidc_signing_keys WHERE created_at > $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"kid", "private_key", "created_at"}).AddRow(key.kid, der, key.createdAt))
+	return key
+}
+
+// expectClient expects a client lookup. A nil secret hash makes the client public.
+func expectClient(mock sqlmock.Sqlmock, id string, secretHash []byte, redirectURIs string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, secret_hash, redirect_uris FROM oidc_clients WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "secret_hash", "redirect_uris"}).AddRow("Test app", secretHash, redirectURIs))
+}
+
+// serve sends a request through the provider's endpoints
+func serve(p *Provider, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	p.Register(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNewProvider(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	tests := []struct {
+		name     string
+		issuer   string
+		wantErr  bool
+		wantPath string
+	}{
+		{name: "host only", issuer: "https://id.example.com"},
+		{name: "trailing slash", issuer: "https://id.example.com/"},
+		{name: "with path", issuer: "https://example.com/oidc", wantPath: "/oidc"},
+		{name: "empty", issuer: "", wantErr: true},
+		{name: "relative", issuer: "/oidc", wantErr: true},
+		{name: "unsupported scheme", issuer: "ftp://id.example.com", wantErr: true},
+		{name: "query", issuer: "https://id.example.com?tenant=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProvider(nil, nil, nil, Config{Issuer: tt.issuer}, logger)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, p.path)
+			assert.NotEqual(t, '/', p.config.Issuer[len(p.config.Issuer)-1])
+			assert.Equal(t, DefaultTokenTTL, p.config.TokenTTL)
+			assert.Equal(t, DefaultCodeTTL, p.config.CodeTTL)
+			assert.Equal(t, DefaultKeyRotationPeriod, p.config.KeyRotationPeriod)
+		})
+	}
+}
+
+func TestHeaderAuthenticator(t *testing.T) {
+	t.Run("signed in", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/authorize", nil)
+		req.Header.Set("X-User-Id", "42")
+		id, ok := HeaderAuthenticator{Header: "X-User-Id"}.Authenticate(httptest.NewRecorder(), req)
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), id)
+	})
+
+	t.Run("redirects to login", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/authorize?client_id=app", nil)
+		rec := httptest.NewRecorder()
+		_, ok := HeaderAuthenticator{Header: "X-User-Id", LoginURL: "https://login.example.com"}.Authenticate(rec, req)
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://login.example.com?return_to=%2Fauthorize%3Fclient_id%3Dapp", rec.Header().Get("Location"))
+	})
+
+	t.Run("rejects without login URL", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/authorize", nil)
+		req.Header.Set("X-User-Id", "not-a-number")
+		rec := httptest.NewRecorder()
+		_, ok := HeaderAuthenticator{Header: "X-User-Id"}.Authenticate(rec, req)
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestFilterScopes(t *testing.T) {
+	assert.Equal(t, "openid email", filterScopes("openid email offline_access openid"))
+	assert.Equal(t, "", filterScopes("admin"))
+	assert.True(t, hasScope("openid profile", ScopeProfile))
+	assert.False(t, hasScope("openid profile", ScopeEmail))
+}
+
+func TestPurgeExpiredCodes(t *testing.T) {
+	p, mock := newMockProvider(t, testIssuer)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM oidc_authorization_codes WHERE expires_at < $1 AND used_at IS NULL")).
+		WithArgs(testNow).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := p.PurgeExpiredCodes(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
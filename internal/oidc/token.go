@@ -0,0 +1,265 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// accessTokenType is the JWT typ header of access tokens (RFC 9068). It
+// keeps ID tokens from being accepted as access tokens and vice versa.
+const accessTokenType = "at+jwt"
+
+// idTokenClaims are the claims of an ID token
+type idTokenClaims struct {
+	jwt.Claims
+	AuthTime int64  `json:"auth_time"`
+	Nonce    string `json:"nonce,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// accessTokenClaims are the claims of an access token
+type accessTokenClaims struct {
+	jwt.Claims
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+}
+
+// tokenResponse is a successful token endpoint response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// authorizationCode is a stored authorization code
+type authorizationCode struct {
+	clientID      string
+	userID        int64
+	redirectURI   string
+	scope         string
+	nonce         string
+	codeChallenge string
+	authTime      time.Time
+	expiresAt     time.Time
+	usedAt        sql.NullTime
+}
+
+// handleToken serves the token endpoint (OpenID Connect Core section 3.1.3),
+// exchanging an authorization code and its PKCE verifier for tokens
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	resp, err := p.exchangeCode(r)
+	if err != nil {
+		if oauthErr, ok := err.(*oauthError); ok && oauthErr.status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Basic realm="token"`)
+		}
+		p.writeError(w, r, err)
+		return
+	}
+	p.writeJSON(w, r, http.StatusOK, resp)
+}
+
+func (p *Provider) exchangeCode(r *http.Request) (*tokenResponse, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, newOAuthError(http.StatusBadRequest, "invalid_request", "malformed request")
+	}
+	if grantType := r.PostForm.Get("grant_type"); grantType != "authorization_code" {
+		return nil, newOAuthError(http.StatusBadRequest, "unsupported_grant_type", "grant_type %q is not supported", grantType)
+	}
+	client, err := p.authenticateClient(r)
+	if err != nil {
+		return nil, err
+	}
+	code, verifier := r.PostForm.Get("code"), r.PostForm.Get("code_verifier")
+	if code == "" || verifier == "" {
+		return nil, newOAuthError(http.StatusBadRequest, "invalid_request", "code and code_verifier are required")
+	}
+
+	authCode, err := p.redeemCode(r.Context(), code, client.ID, r.PostForm.Get("redirect_uri"), verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := p.userActive(r.Context(), authCode.userID)
+	if status.Code(err) == codes.NotFound {
+		return nil, invalidGrant("the user no longer exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, invalidGrant("the user is deactivated")
+	}
+	user, err := p.users.GetUserByID(r.Context(), authCode.userID)
+	if status.Code(err) == codes.NotFound {
+		return nil, invalidGrant("the user no longer exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	expiry := now.Add(p.config.TokenTTL)
+	subject := strconv.FormatInt(user.GetId(), 10)
+
+	idClaims := idTokenClaims{
+		Claims: jwt.Claims{
+			Issuer:   p.config.Issuer,
+			Subject:  subject,
+			Audience: jwt.Audience{client.ID},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(expiry),
+		},
+		AuthTime: authCode.authTime.Unix(),
+		Nonce:    authCode.nonce,
+	}
+	if hasScope(authCode.scope, ScopeEmail) {
+		idClaims.Email = user.GetEmail()
+	}
+	if hasScope(authCode.scope, ScopeProfile) {
+		idClaims.Name = user.GetName()
+	}
+
+	tokenID, _, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	accessClaims := accessTokenClaims{
+		Claims: jwt.Claims{
+			Issuer:   p.config.Issuer,
+			Subject:  subject,
+			Audience: jwt.Audience{p.config.Issuer},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(expiry),
+			ID:       tokenID,
+		},
+		ClientID: client.ID,
+		Scope:    authCode.scope,
+	}
+
+	idToken, err := p.sign(r.Context(), "JWT", idClaims)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := p.sign(r.Context(), accessTokenType, accessClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(p.config.TokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       authCode.scope,
+	}, nil
+}
+
+// redeemCode validates an authorization code and marks it used. Codes are
+// single use: a second exchange fails even if the first one did not
+// complete.
+func (p *Provider) redeemCode(ctx context.Context, code, clientID, redirectURI, verifier string) (_ *authorizationCode, err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var c authorizationCode
+	codeHash := hashSecret(code)
+	err = tx.QueryRowContext(ctx,
+		`SELECT client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at, used_at
+		FROM oidc_authorization_codes WHERE code_hash = $1 FOR UPDATE`, codeHash).
+		Scan(&c.clientID, &c.userID, &c.redirectURI, &c.scope, &c.nonce, &c.codeChallenge, &c.authTime, &c.expiresAt, &c.usedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, invalidGrant("unknown authorization code")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c.usedAt.Valid:
+		return nil, invalidGrant("the authorization code has already been used")
+	case !p.now().Before(c.expiresAt):
+		return nil, invalidGrant("the authorization code has expired")
+	case c.clientID != clientID:
+		return nil, invalidGrant("the authorization code was issued to another client")
+	case c.redirectURI != redirectURI:
+		return nil, invalidGrant("redirect_uri does not match the authorization request")
+	case !verifyPKCE(verifier, c.codeChallenge):
+		return nil, invalidGrant("code_verifier does not match the code challenge")
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		"UPDATE oidc_authorization_codes SET used_at = $2 WHERE code_hash = $1", codeHash, p.now()); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// verifyPKCE checks a code verifier against an S256 code challenge (RFC 7636 section 4.6)
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// invalidGrant returns the error for an authorization code that cannot be exchanged
+func invalidGrant(description string) error {
+	return newOAuthError(http.StatusBadRequest, "invalid_grant", "%s", description)
+}
+
+// sign signs claims as a JWT with the active signing key
+func (p *Provider) sign(ctx context.Context, typ string, claims any) (string, error) {
+	keys, err := p.signingKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+	key := activeKey(keys, p.now())
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key.key, KeyID: key.kid}},
+		(&jose.SignerOptions{}).WithType(jose.ContentType(typ)),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create signer: %w", err)
+	}
+	return jwt.Signed(signer).Claims(claims).Serialize()
+}
+
+// activeKey picks the key that signs new tokens: the newest key that has
+// been published for at least keyCacheTTL, so relying parties that cached
+// the key set have seen it, or the newest key if none has.
+func activeKey(keys []signingKey, now time.Time) signingKey {
+	for _, key := range keys {
+		if now.Sub(key.createdAt) >= keyCacheTTL {
+			return key
+		}
+	}
+	return keys[0]
+}
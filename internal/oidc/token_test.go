@@ -0,0 +1,224 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PKCE example from RFC 7636 appendix B
+const (
+	testVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	assert.True(t, verifyPKCE(testVerifier, testChallenge))
+	assert.False(t, verifyPKCE(testVerifier+"x", testChallenge))
+	assert.False(t, verifyPKCE("short", testChallenge))
+	assert.False(t, verifyPKCE(strings.Repeat("a", 129), testChallenge))
+}
+
+func TestProvider_Token(t *testing.T) {
+	const redirectURI = "https://app.example.com/cb"
+	selectCode := regexp.QuoteMeta("SELECT client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at, used_at")
+	codeColumns := []string{"client_id", "user_id", "redirect_uri", "scope", "nonce", "code_challenge", "auth_time", "expires_at", "used_at"}
+	authTime := testNow.Add(-10 * time.Second)
+	expiresAt := testNow.Add(50 * time.Second)
+
+	valid := func() url.Values {
+		return url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {"spa"},
+			"code":          {"the-code"},
+			"code_verifier": {testVerifier},
+			"redirect_uri":  {redirectURI},
+		}
+	}
+	// expectCode expects the client lookup and the locked authorization code row
+	expectCode := func(mock sqlmock.Sqlmock, clientID string, expiresAt time.Time, usedAt any) {
+		expectClient(mock, "spa", nil, "{"+redirectURI+"}")
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectCode).
+			WithArgs(hashSecret("the-code")).
+			WillReturnRows(sqlmock.NewRows(codeColumns).
+				AddRow(clientID, int64(7), redirectURI, "openid email profile", "n-0S6", testChallenge, authTime, expiresAt, usedAt))
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(form url.Values)
+		mockSetup   func(mock sqlmock.Sqlmock)
+		wantStatus  int
+		wantError   string
+		wantSuccess bool
+	}{
+		{
+			name: "exchanges code",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "spa", expiresAt, nil)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE oidc_authorization_codes SET used_at = $2 WHERE code_hash = $1")).
+					WithArgs(hashSecret("the-code"), testNow).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectActive(mock, 7, true)
+				mock.ExpectQuery(selectUserByID).
+					WithArgs(int64(7)).
+					WillReturnRows(userRows(t, 7, "ada@example.com", "Ada"))
+				expectKeys(t, mock)
+			},
+			wantStatus:  http.StatusOK,
+			wantSuccess: true,
+		},
+		{
+			name:       "unsupported grant type",
+			modify:     func(form url.Values) { form.Set("grant_type", "password") },
+			mockSetup:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "unsupported_grant_type",
+		},
+		{
+			name:       "unknown client",
+			modify:     func(form url.Values) { form.Set("client_id", "") },
+			mockSetup:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "invalid_client",
+		},
+		{
+			name:       "missing code verifier",
+			modify:     func(form url.Values) { form.Del("code_verifier") },
+			mockSetup:  func(mock sqlmock.Sqlmock) { expectClient(mock, "spa", nil, "{"+redirectURI+"}") },
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_request",
+		},
+		{
+			name: "unknown code",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectClient(mock, "spa", nil, "{"+redirectURI+"}")
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectCode).WillReturnRows(sqlmock.NewRows(codeColumns))
+				mock.ExpectRollback()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+		{
+			name: "code already used",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "spa", expiresAt, authTime)
+				mock.ExpectRollback()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+		{
+			name: "code expired",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "spa", testNow, nil)
+				mock.ExpectRollback()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+		{
+			name: "code issued to another client",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "other", expiresAt, nil)
+				mock.ExpectRollback()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+		{
+			name: "user deactivated",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "spa", expiresAt, nil)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE oidc_authorization_codes SET used_at")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				expectActive(mock, 7, false)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+		{
+			name:   "redirect URI mismatch",
+			modify: func(form url.Values) { form.Set("redirect_uri", "https://app.example.com/other") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "spa", expiresAt, nil)
+				mock.ExpectRollback()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+		{
+			name:   "wrong code verifier",
+			modify: func(form url.Values) { form.Set("code_verifier", strings.Repeat("a", 43)) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectCode(mock, "spa", expiresAt, nil)
+				mock.ExpectRollback()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mock := newMockProvider(t, testIssuer)
+			tt.mockSetup(mock)
+
+			form := valid()
+			if tt.modify != nil {
+				tt.modify(form)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := serve(p, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+
+			if tt.wantSuccess {
+				var resp tokenResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, "Bearer", resp.TokenType)
+				assert.Equal(t, int64(3600), resp.ExpiresIn)
+				assert.Equal(t, "openid email profile", resp.Scope)
+
+				key, _ := testSigningKey(t)
+				idToken, err := jwt.ParseSigned(resp.IDToken, []jose.SignatureAlgorithm{jose.RS256})
+				require.NoError(t, err)
+				assert.Equal(t, key.kid, idToken.Headers[0].KeyID)
+				var claims idTokenClaims
+				require.NoError(t, idToken.Claims(&key.key.PublicKey, &claims))
+				assert.Equal(t, testIssuer, claims.Issuer)
+				assert.Equal(t, "7", claims.Subject)
+				assert.Equal(t, jwt.Audience{"spa"}, claims.Audience)
+				assert.Equal(t, "n-0S6", claims.Nonce)
+				assert.Equal(t, authTime.Unix(), claims.AuthTime)
+				assert.Equal(t, "ada@example.com", claims.Email)
+				assert.Equal(t, "Ada", claims.Name)
+			} else {
+				var body map[string]string
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userInfo is a UserInfo response (OpenID Connect Core section 5.3.2)
+type userInfo struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+// handleUserInfo serves the UserInfo endpoint. Claims are read from the
+// users table on every request, so they reflect changes made after the
+// access token was issued; a user deactivated since is refused.
+func (p *Provider) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		p.writeError(w, r, newOAuthError(http.StatusUnauthorized, "invalid_token", "a bearer access token is required"))
+		return
+	}
+
+	claims, err := p.verifyAccessToken(r.Context(), strings.TrimSpace(token))
+	if err != nil {
+		p.writeTokenError(w, r, err)
+		return
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		p.writeTokenError(w, r, newOAuthError(http.StatusUnauthorized, "invalid_token", "invalid subject"))
+		return
+	}
+
+	active, err := p.userActive(r.Context(), userID)
+	if status.Code(err) == codes.NotFound {
+		p.writeTokenError(w, r, newOAuthError(http.StatusUnauthorized, "invalid_token", "the user no longer exists"))
+		return
+	}
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+	if !active {
+		p.writeTokenError(w, r, newOAuthError(http.StatusUnauthorized, "invalid_token", "the user is deactivated"))
+		return
+	}
+	user, err := p.users.GetUserByID(r.Context(), userID)
+	if status.Code(err) == codes.NotFound {
+		p.writeTokenError(w, r, newOAuthError(http.StatusUnauthorized, "invalid_token", "the user no longer exists"))
+		return
+	}
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+
+	info := userInfo{Subject: claims.Subject}
+	if hasScope(claims.Scope, ScopeEmail) {
+		info.Email = user.GetEmail()
+	}
+	if hasScope(claims.Scope, ScopeProfile) {
+		info.Name = user.GetName()
+	}
+	p.writeJSON(w, r, http.StatusOK, info)
+}
+
+// writeTokenError writes an invalid access token error with its
+// WWW-Authenticate challenge (RFC 6750 section 3)
+func (p *Provider) writeTokenError(w http.ResponseWriter, r *http.Request, err error) {
+	if oauthErr, ok := err.(*oauthError); ok {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error=%q, error_description=%q`, oauthErr.Code, oauthErr.Description))
+	}
+	p.writeError(w, r, err)
+}
+
+// verifyAccessToken checks the signature, type, issuer, audience and
+// expiry of an access token and returns its claims
+func (p *Provider) verifyAccessToken(ctx context.Context, token string) (*accessTokenClaims, error) {
+	invalid := func(description string) error {
+		return newOAuthError(http.StatusUnauthorized, "invalid_token", "%s", description)
+	}
+
+	parsed, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	if err != nil || len(parsed.Headers) != 1 {
+		return nil, invalid("malformed access token")
+	}
+	header := parsed.Headers[0]
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); !strings.EqualFold(typ, accessTokenType) {
+		return nil, invalid("not an access token")
+	}
+
+	keys, err := p.signingKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var claims accessTokenClaims
+	verified := false
+	for _, key := range keys {
+		if key.kid == header.KeyID {
+			if err := parsed.Claims(&key.key.PublicKey, &claims); err != nil {
+				return nil, invalid("invalid signature")
+			}
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, invalid("unknown signing key")
+	}
+
+	err = claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      p.config.Issuer,
+		AnyAudience: jwt.Audience{p.config.Issuer},
+		Time:        p.now(),
+	}, 0)
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+	return &claims, nil
+}
@@ -0,0 +1,157 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_UserInfo(t *testing.T) {
+
+	accessToken := func(modify func(claims *accessTokenClaims)) accessTokenClaims {
+		claims := accessTokenClaims{
+			Claims: jwt.Claims{
+				Issuer:   testIssuer,
+				Subject:  "7",
+				Audience: jwt.Audience{testIssuer},
+				IssuedAt: jwt.NewNumericDate(testNow.Add(-time.Minute)),
+				Expiry:   jwt.NewNumericDate(testNow.Add(time.Hour)),
+			},
+			ClientID: "spa",
+			Scope:    "openid email",
+		}
+		if modify != nil {
+			modify(&claims)
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name          string
+		typ           string
+		claims        accessTokenClaims
+		authorization string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		wantStatus    int
+		wantInfo      userInfo
+	}{
+		{
+			name:   "returns scoped claims",
+			typ:    accessTokenType,
+			claims: accessToken(nil),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectActive(mock, 7, true)
+				mock.ExpectQuery(selectUserByID).
+					WithArgs(int64(7)).
+					WillReturnRows(userRows(t, 7, "ada@example.com", "Ada"))
+			},
+			wantStatus: http.StatusOK,
+			wantInfo:   userInfo{Subject: "7", Email: "ada@example.com"},
+		},
+		{
+			name:          "missing token",
+			authorization: "Basic abc",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "malformed token",
+			authorization: "Bearer not.a.jwt",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:       "ID token used as access token",
+			typ:        "JWT",
+			claims:     accessToken(nil),
+			mockSetup:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			typ:  accessTokenType,
+			claims: accessToken(func(c *accessTokenClaims) {
+				c.Expiry = jwt.NewNumericDate(testNow.Add(-time.Second))
+			}),
+			mockSetup:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong audience",
+			typ:  accessTokenType,
+			claims: accessToken(func(c *accessTokenClaims) {
+				c.Audience = jwt.Audience{"spa"}
+			}),
+			mockSetup:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong issuer",
+			typ:  accessTokenType,
+			claims: accessToken(func(c *accessTokenClaims) {
+				c.Issuer = "https://other.example.com"
+			}),
+			mockSetup:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "user deleted",
+			typ:    accessTokenType,
+			claims: accessToken(nil),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT active FROM users")).WillReturnRows(sqlmock.NewRows(nil))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "user deactivated",
+			typ:    accessTokenType,
+			claims: accessToken(nil),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectActive(mock, 7, false)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mock := newMockProvider(t, testIssuer)
+			expectKeys(t, mock)
+
+			authorization := tt.authorization
+			if authorization == "" {
+				token, err := p.sign(t.Context(), tt.typ, tt.claims)
+				require.NoError(t, err)
+				authorization = "Bearer " + token
+			} else {
+				// the keys are loaded while signing in the other cases
+				_, err := p.signingKeys(t.Context())
+				require.NoError(t, err)
+			}
+			tt.mockSetup(mock)
+
+			req := httptest.NewRequest(http.MethodGet, "/userinfo", nil)
+			req.Header.Set("Authorization", authorization)
+			rec := serve(p, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				var info userInfo
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+				assert.Equal(t, tt.wantInfo, info)
+			} else {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
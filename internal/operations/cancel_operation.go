@@ -0,0 +1,63 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CancelOperation marks an operation done with a CANCELLED error and stops
+// its work if it is queued or running on this instance. Work running on
+// another instance is not interrupted, but its result is discarded.
+// Canceling an operation that is already done has no effect.
+func (s *Service) CancelOperation(ctx context.Context, req *longrunningpb.CancelOperationRequest) (_ *emptypb.Empty, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var b []byte
+	var done bool
+	err = tx.QueryRowContext(ctx, "SELECT operation, done FROM operations WHERE name = $1 FOR UPDATE", req.GetName()).Scan(&b, &done)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "operation %q not found", req.GetName())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return &emptypb.Empty{}, tx.Commit()
+	}
+
+	var op longrunningpb.Operation
+	if err := proto.Unmarshal(b, &op); err != nil {
+		return nil, err
+	}
+	op.Done = true
+	op.Result = &longrunningpb.Operation_Error{Error: status.New(codes.Canceled, "operation was canceled").Proto()}
+	if b, err = proto.Marshal(&op); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx,
+		"UPDATE operations SET done = true, operation = $2, updated_at = $3 WHERE name = $1",
+		op.Name, b, s.now()); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.cancel(op.Name)
+	return &emptypb.Empty{}, nil
+}
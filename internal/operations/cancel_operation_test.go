@@ -0,0 +1,138 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestService_CancelOperation(t *testing.T) {
+	selectOp := regexp.QuoteMeta("SELECT operation, done FROM operations WHERE name = $1 FOR UPDATE")
+	update := regexp.QuoteMeta("UPDATE operations SET done = true, operation = $2, updated_at = $3 WHERE name = $1")
+	pending, err := proto.Marshal(&longrunningpb.Operation{Name: "operations/1"})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		service, mock := newMockService(t)
+		stored := &capturedOperation{}
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectOp).
+			WithArgs("operations/1").
+			WillReturnRows(sqlmock.NewRows([]string{"operation", "done"}).AddRow(pending, false))
+		mock.ExpectExec(update).
+			WithArgs("operations/1", stored, testNow).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := service.CancelOperation(t.Context(), &longrunningpb.CancelOperationRequest{Name: "operations/1"})
+		require.NoError(t, err)
+		require.NotNil(t, stored.op)
+		assert.True(t, stored.op.Done)
+		assert.Equal(t, int32(codes.Canceled), stored.op.GetError().GetCode())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stops running work", func(t *testing.T) {
+		service, mock := newMockService(t)
+		started := make(chan struct{})
+		var workErr error
+		service.Run(t.Context(), &longrunningpb.Operation{Name: "operations/1"}, func(ctx context.Context) (proto.Message, error) {
+			close(started)
+			<-ctx.Done()
+			workErr = ctx.Err()
+			return nil, workErr
+		})
+		<-started
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectOp).WillReturnRows(sqlmock.NewRows([]string{"operation", "done"}).AddRow(pending, false))
+		mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		// The canceled result is kept, so storing the work's result changes nothing
+		mock.ExpectExec(regexp.QuoteMeta("AND NOT done")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		_, err := service.CancelOperation(t.Context(), &longrunningpb.CancelOperationRequest{Name: "operations/1"})
+		require.NoError(t, err)
+		require.NoError(t, service.Wait(t.Context()))
+		assert.ErrorIs(t, workErr, context.Canceled)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already done", func(t *testing.T) {
+		service, mock := newMockService(t)
+		done, err := proto.Marshal(&longrunningpb.Operation{Name: "operations/1", Done: true})
+		require.NoError(t, err)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectOp).WillReturnRows(sqlmock.NewRows([]string{"operation", "done"}).AddRow(done, true))
+		mock.ExpectCommit()
+
+		_, err = service.CancelOperation(t.Context(), &longrunningpb.CancelOperationRequest{Name: "operations/1"})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectOp).WillReturnRows(sqlmock.NewRows([]string{"operation", "done"}))
+		mock.ExpectRollback()
+
+		_, err := service.CancelOperation(t.Context(), &longrunningpb.CancelOperationRequest{Name: "operations/1"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update fails", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectOp).WillReturnRows(sqlmock.NewRows([]string{"operation", "done"}).AddRow(pending, false))
+		mock.ExpectExec(update).WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := service.CancelOperation(t.Context(), &longrunningpb.CancelOperationRequest{Name: "operations/1"})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("queued work never starts", func(t *testing.T) {
+		service, mock := newMockService(t)
+		started, release := make(chan struct{}), make(chan struct{})
+		service.Run(t.Context(), &longrunningpb.Operation{Name: "operations/0"}, func(ctx context.Context) (proto.Message, error) {
+			close(started)
+			<-release
+			return wrapperspb.Bool(true), nil
+		})
+		// The service has one worker, which operations/0 now occupies
+		<-started
+		ran := false
+		service.Run(t.Context(), &longrunningpb.Operation{Name: "operations/1"}, func(ctx context.Context) (proto.Message, error) {
+			ran = true
+			return wrapperspb.Bool(true), nil
+		})
+
+		mock.MatchExpectationsInOrder(false)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectOp).WillReturnRows(sqlmock.NewRows([]string{"operation", "done"}).AddRow(pending, false))
+		mock.ExpectExec(update).WithArgs("operations/1", sqlmock.AnyArg(), testNow).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(regexp.QuoteMeta("AND NOT done")).WithArgs("operations/1", sqlmock.AnyArg(), testNow).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("AND NOT done")).WithArgs("operations/0", sqlmock.AnyArg(), testNow).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := service.CancelOperation(t.Context(), &longrunningpb.CancelOperationRequest{Name: "operations/1"})
+		require.NoError(t, err)
+		close(release)
+		require.NoError(t, service.Wait(t.Context()))
+		assert.False(t, ran, "canceled work must not start")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
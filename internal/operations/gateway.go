@@ -0,0 +1,119 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// gatewayRoute is a REST route of the Operations service. call decodes the
+// HTTP request into the RPC's request and invokes it.
+type gatewayRoute struct {
+	method string
+	path   string
+	rpc    string
+	call   func(ctx context.Context, client longrunningpb.OperationsClient, marshaler runtime.Marshaler, req *http.Request, pathParams map[string]string, opts ...grpc.CallOption) (proto.Message, error)
+}
+
+// gatewayRoutes are the AIP-151 routes of the Operations service
+var gatewayRoutes = []gatewayRoute{
+	{
+		method: http.MethodGet,
+		path:   "/api/v1/operations",
+		rpc:    "ListOperations",
+		call: func(ctx context.Context, client longrunningpb.OperationsClient, _ runtime.Marshaler, req *http.Request, _ map[string]string, opts ...grpc.CallOption) (proto.Message, error) {
+			protoReq := &longrunningpb.ListOperationsRequest{}
+			if err := req.ParseForm(); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			if err := runtime.PopulateQueryParameters(protoReq, req.Form, utilities.NewDoubleArray(nil)); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			protoReq.Name = "operations"
+			return client.ListOperations(ctx, protoReq, opts...)
+		},
+	},
+	{
+		method: http.MethodGet,
+		path:   "/api/v1/operations/{id}",
+		rpc:    "GetOperation",
+		call: func(ctx context.Context, client longrunningpb.OperationsClient, _ runtime.Marshaler, _ *http.Request, pathParams map[string]string, opts ...grpc.CallOption) (proto.Message, error) {
+			return client.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: "operations/" + pathParams["id"]}, opts...)
+		},
+	},
+	{
+		method: http.MethodPost,
+		path:   "/api/v1/operations/{id}:cancel",
+		rpc:    "CancelOperation",
+		call: func(ctx context.Context, client longrunningpb.OperationsClient, marshaler runtime.Marshaler, req *http.Request, pathParams map[string]string, opts ...grpc.CallOption) (proto.Message, error) {
+			protoReq := &longrunningpb.CancelOperationRequest{}
+			if err := decodeBody(marshaler, req, protoReq); err != nil {
+				return nil, err
+			}
+			protoReq.Name = "operations/" + pathParams["id"]
+			return client.CancelOperation(ctx, protoReq, opts...)
+		},
+	},
+	{
+		method: http.MethodPost,
+		path:   "/api/v1/operations/{id}:wait",
+		rpc:    "WaitOperation",
+		call: func(ctx context.Context, client longrunningpb.OperationsClient, marshaler runtime.Marshaler, req *http.Request, pathParams map[string]string, opts ...grpc.CallOption) (proto.Message, error) {
+			protoReq := &longrunningpb.WaitOperationRequest{}
+			if err := decodeBody(marshaler, req, protoReq); err != nil {
+				return nil, err
+			}
+			protoReq.Name = "operations/" + pathParams["id"]
+			return client.WaitOperation(ctx, protoReq, opts...)
+		},
+	},
+}
+
+// decodeBody decodes the request body into msg; an empty body leaves msg unset
+func decodeBody(marshaler runtime.Marshaler, req *http.Request, msg proto.Message) error {
+	if err := marshaler.NewDecoder(req.Body).Decode(msg); err != nil && !errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// RegisterGatewayHandler serves the Operations service on the gateway,
+// forwarding to it through conn. google.longrunning ships without gateway
+// handlers, so the routes are registered by hand the way the generated
+// handlers do it.
+func RegisterGatewayHandler(ctx context.Context, mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	client := longrunningpb.NewOperationsClient(conn)
+	for _, route := range gatewayRoutes {
+		if err := mux.HandlePath(route.method, route.path, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
+			ctx, cancel := context.WithCancel(req.Context())
+			defer cancel()
+			inboundMarshaler, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
+			annotatedContext, err := runtime.AnnotateContext(ctx, mux, req, "/google.longrunning.Operations/"+route.rpc, runtime.WithHTTPPathPattern(route.path))
+			if err != nil {
+				runtime.HTTPError(ctx, mux, outboundMarshaler, w, req, err)
+				return
+			}
+			var md runtime.ServerMetadata
+			resp, err := route.call(annotatedContext, client, inboundMarshaler, req, pathParams,
+				grpc.Header(&md.HeaderMD), grpc.Trailer(&md.TrailerMD))
+			annotatedContext = runtime.NewServerMetadataContext(annotatedContext, md)
+			if err != nil {
+				runtime.HTTPError(annotatedContext, mux, outboundMarshaler, w, req, err)
+				return
+			}
+			runtime.ForwardResponseMessage(annotatedContext, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
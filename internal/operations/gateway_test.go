@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// fakeConn answers Operations calls from a map of operations by name and
+// records the last request it received
+type fakeConn struct {
+	grpc.ClientConnInterface
+	operations map[string]*longrunningpb.Operation
+	last       proto.Message
+}
+
+func (c *fakeConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	c.last = args.(proto.Message)
+	switch req := args.(type) {
+	case *longrunningpb.ListOperationsRequest:
+		for _, op := range c.operations {
+			reply.(*longrunningpb.ListOperationsResponse).Operations = append(reply.(*longrunningpb.ListOperationsResponse).Operations, op)
+		}
+		return nil
+	case interface{ GetName() string }:
+		op, ok := c.operations[req.GetName()]
+		if !ok {
+			return status.Error(codes.NotFound, "operation not found")
+		}
+		if r, ok := reply.(*longrunningpb.Operation); ok {
+			proto.Merge(r, op)
+		}
+		return nil
+	}
+	return status.Error(codes.Unimplemented, method)
+}
+
+func TestRegisterGatewayHandler(t *testing.T) {
+	mux := runtime.NewServeMux()
+	conn := &fakeConn{operations: map[string]*longrunningpb.Operation{
+		"operations/abc": {Name: "operations/abc", Done: true},
+	}}
+	require.NoError(t, RegisterGatewayHandler(t.Context(), mux, conn))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+		wantReq  proto.Message
+	}{
+		{
+			name:     "get",
+			method:   http.MethodGet,
+			path:     "/api/v1/operations/abc",
+			wantCode: http.StatusOK,
+			wantBody: `{"name": "operations/abc", "done": true, "metadata": null}`,
+			wantReq:  &longrunningpb.GetOperationRequest{Name: "operations/abc"},
+		},
+		{
+			name:     "get not found",
+			method:   http.MethodGet,
+			path:     "/api/v1/operations/missing",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "list",
+			method:   http.MethodGet,
+			path:     "/api/v1/operations?filter=done%20%3D%20true&page_size=10&page_token=MQ",
+			wantCode: http.StatusOK,
+			wantBody: `{"operations": [{"name": "operations/abc", "done": true, "metadata": null}], "nextPageToken": "", "unreachable": []}`,
+			wantReq:  &longrunningpb.ListOperationsRequest{Name: "operations", Filter: "done = true", PageSize: 10, PageToken: "MQ"},
+		},
+		{
+			name:     "cancel",
+			method:   http.MethodPost,
+			path:     "/api/v1/operations/abc:cancel",
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+			wantReq:  &longrunningpb.CancelOperationRequest{Name: "operations/abc"},
+		},
+		{
+			name:     "wait",
+			method:   http.MethodPost,
+			path:     "/api/v1/operations/abc:wait",
+			body:     `{"timeout": "30s"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"name": "operations/abc", "done": true, "metadata": null}`,
+			wantReq:  &longrunningpb.WaitOperationRequest{Name: "operations/abc", Timeout: durationpb.New(30 * time.Second)},
+		},
+		{
+			name:     "wait with invalid body",
+			method:   http.MethodPost,
+			path:     "/api/v1/operations/abc:wait",
+			body:     `{"timeout": 30}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn.last = nil
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+			if tt.wantReq != nil {
+				assert.True(t, proto.Equal(tt.wantReq, conn.last), "got request %v", conn.last)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetOperation returns the latest state of an operation
+func (s *Service) GetOperation(ctx context.Context, req *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	var b []byte
+	err := s.db.QueryRowContext(ctx, "SELECT operation FROM operations WHERE name = $1", req.GetName()).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "operation %q not found", req.GetName())
+	}
+	if err != nil {
+		return nil, err
+	}
+	var op longrunningpb.Operation
+	if err := proto.Unmarshal(b, &op); err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
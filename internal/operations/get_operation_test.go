@@ -0,0 +1,66 @@
+package operations
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestService_GetOperation(t *testing.T) {
+	query := `SELECT operation FROM operations WHERE name = \$1`
+	stored := &longrunningpb.Operation{Name: "operations/1", Done: true}
+	b, err := proto.Marshal(stored)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantCode  codes.Code
+	}{
+		{
+			name: "found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).
+					WithArgs("operations/1").
+					WillReturnRows(sqlmock.NewRows([]string{"operation"}).AddRow(b))
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"operation"}))
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+			},
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			op, err := service.GetOperation(t.Context(), &longrunningpb.GetOperationRequest{Name: "operations/1"})
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				assert.True(t, proto.Equal(stored, op))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
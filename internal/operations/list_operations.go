@@ -0,0 +1,75 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// doneFilter matches the only filters ListOperations supports
+var doneFilter = regexp.MustCompile(`^\s*done\s*=\s*(true|false)\s*$`)
+
+// ListOperations lists operations in the order they were created. The filter
+// may be empty, "done = true" or "done = false".
+func (s *Service) ListOperations(ctx context.Context, req *longrunningpb.ListOperationsRequest) (*longrunningpb.ListOperationsResponse, error) {
+	if name := req.GetName(); name != "" && name != "operations" {
+		return nil, status.Errorf(codes.InvalidArgument, "name must be %q", "operations")
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+	afterID, err := pagination.DecodeToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	query := "SELECT id, operation FROM operations WHERE id > $1"
+	args := []any{afterID}
+	if req.GetFilter() != "" {
+		m := doneFilter.FindStringSubmatch(req.GetFilter())
+		if m == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported filter %q; use done = true or done = false", req.GetFilter())
+		}
+		args = append(args, m[1] == "true")
+		query += fmt.Sprintf(" AND done = $%d", len(args))
+	}
+	// Fetch one extra row to find out whether there is a next page
+	args = append(args, pageSize+1)
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var operations []*longrunningpb.Operation
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var b []byte
+		if err := rows.Scan(&id, &b); err != nil {
+			return nil, err
+		}
+		var op longrunningpb.Operation
+		if err := proto.Unmarshal(b, &op); err != nil {
+			return nil, err
+		}
+		operations = append(operations, &op)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	resp := &longrunningpb.ListOperationsResponse{Operations: operations}
+	if len(operations) > pageSize {
+		resp.Operations = operations[:pageSize]
+		resp.NextPageToken = pagination.NextToken(len(operations), pageSize, ids[pageSize-1])
+	}
+	return resp, nil
+}
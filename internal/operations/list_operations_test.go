@@ -0,0 +1,106 @@
+package operations
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// operationRows returns rows of operations with the given ids, named after them
+func operationRows(t *testing.T, ids ...int64) *sqlmock.Rows {
+	t.Helper()
+	rows := sqlmock.NewRows([]string{"id", "operation"})
+	for _, id := range ids {
+		b, err := proto.Marshal(&longrunningpb.Operation{Name: "operations/" + string(rune('a'+id))})
+		require.NoError(t, err)
+		rows.AddRow(id, b)
+	}
+	return rows
+}
+
+func TestService_ListOperations(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *longrunningpb.ListOperationsRequest
+		mockSetup     func(*testing.T, sqlmock.Sqlmock)
+		wantNames     []string
+		wantNextToken string
+		wantCode      codes.Code
+	}{
+		{
+			name: "first page",
+			req:  &longrunningpb.ListOperationsRequest{Name: "operations", PageSize: 2},
+			mockSetup: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, operation FROM operations WHERE id > \$1 ORDER BY id LIMIT \$2`).
+					WithArgs(int64(0), 3).
+					WillReturnRows(operationRows(t, 1, 2, 3))
+			},
+			wantNames:     []string{"operations/b", "operations/c"},
+			wantNextToken: pagination.EncodeToken(2),
+		},
+		{
+			name: "last page with done filter",
+			req:  &longrunningpb.ListOperationsRequest{Filter: "done = false", PageToken: pagination.EncodeToken(2)},
+			mockSetup: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, operation FROM operations WHERE id > \$1 AND done = \$2 ORDER BY id LIMIT \$3`).
+					WithArgs(int64(2), false, pagination.DefaultPageSize+1).
+					WillReturnRows(operationRows(t, 3))
+			},
+			wantNames: []string{"operations/d"},
+		},
+		{
+			name:     "error - unsupported filter",
+			req:      &longrunningpb.ListOperationsRequest{Filter: `name = "operations/a"`},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "error - invalid page token",
+			req:      &longrunningpb.ListOperationsRequest{PageToken: "!"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "error - other collection",
+			req:      &longrunningpb.ListOperationsRequest{Name: "jobs"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "error - database error",
+			req:  &longrunningpb.ListOperationsRequest{},
+			mockSetup: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id, operation FROM operations").WillReturnError(errors.New("database error"))
+			},
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(t, mock)
+			}
+
+			resp, err := service.ListOperations(t.Context(), tt.req)
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				var names []string
+				for _, op := range resp.Operations {
+					names = append(names, op.Name)
+				}
+				assert.Equal(t, tt.wantNames, names)
+				assert.Equal(t, tt.wantNextToken, resp.NextPageToken)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// Package operations tracks long-running operations (AIP-151). RPCs that take
+// longer than a client should wait create an operation, queue its work on a
+// bounded pool of workers and return it straight away; clients poll it with
+// google.longrunning.Operations.GetOperation or block on WaitOperation until
+// it is done, and may cancel it with CancelOperation.
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// DefaultWorkers is the number of operations run concurrently when
+// NewService is given no worker count
+const DefaultWorkers = 4
+
+// heartbeatInterval is how often an instance touches the operations it is
+// running, and abandonedAfter how long an operation may go untouched before
+// FailAbandoned takes its instance for gone
+const (
+	heartbeatInterval = 30 * time.Second
+	abandonedAfter    = 4 * heartbeatInterval
+)
+
+// Service stores operations and serves google.longrunning.Operations
+type Service struct {
+	longrunningpb.UnimplementedOperationsServer
+	db           *sql.DB
+	logger       *slog.Logger
+	now          func() time.Time
+	pollInterval time.Duration
+	heartbeat    time.Duration
+	workers      chan struct{}
+	running      sync.WaitGroup
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// NewService creates a new operations service using an existing database
+// connection. At most workers operations run at once; the rest wait in line.
+func NewService(db *sql.DB, workers int, logger *slog.Logger) *Service {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	return &Service{
+		db:           db,
+		logger:       logger,
+		now:          time.Now,
+		pollInterval: time.Second,
+		heartbeat:    heartbeatInterval,
+		workers:      make(chan struct{}, workers),
+		cancels:      make(map[string]context.CancelFunc),
+	}
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Create stores a new operation that is not done yet. q may be a transaction
+// so the operation is only created if the request that starts it commits.
+func (s *Service) Create(ctx context.Context, q execer, metadata proto.Message) (*longrunningpb.Operation, error) {
+	md, err := anypb.New(metadata)
+	if err != nil {
+		return nil, err
+	}
+	op := &longrunningpb.Operation{
+		Name:     "operations/" + uuid.NewString(),
+		Metadata: md,
+	}
+	b, err := proto.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if _, err := q.ExecContext(ctx,
+		"INSERT INTO operations (name, done, operation, created_at, updated_at) VALUES ($1, false, $2, $3, $3)",
+		op.Name, b, now); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// Run queues the work of a created operation on the worker pool and stores
+// its result: the response work returns, or its error as a status. The work
+// is not canceled when ctx is, so it outlives the request that started it,
+// but it is when the operation is canceled with CancelOperation. While the
+// work is queued or running its operation is touched every heartbeat, so
+// FailAbandoned can tell it from one whose instance stopped.
+func (s *Service) Run(ctx context.Context, op *longrunningpb.Operation, work func(ctx context.Context) (proto.Message, error)) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.mu.Lock()
+	s.cancels[op.Name] = cancel
+	s.mu.Unlock()
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.cancels, op.Name)
+			s.mu.Unlock()
+			cancel()
+		}()
+
+		stopHeartbeat := s.keepAlive(ctx, op.Name)
+		var resp proto.Message
+		var err error
+		select {
+		case s.workers <- struct{}{}:
+			resp, err = work(ctx)
+			<-s.workers
+			if err != nil && ctx.Err() != nil {
+				err = status.FromContextError(ctx.Err()).Err()
+			}
+		case <-ctx.Done():
+			err = status.FromContextError(ctx.Err()).Err()
+		}
+		stopHeartbeat()
+		if err := s.finish(context.WithoutCancel(ctx), op, resp, err); err != nil {
+			s.logger.ErrorContext(ctx, "failed to store operation result", "operation", op.Name, "error", err)
+		}
+	}()
+}
+
+// cancel stops the work of op if it is queued or running on this instance
+func (s *Service) cancel(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[name]; ok {
+		cancel()
+	}
+}
+
+// keepAlive touches the operation name every heartbeat until the returned
+// func is called
+func (s *Service) keepAlive(ctx context.Context, name string) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := s.db.ExecContext(ctx,
+				"UPDATE operations SET updated_at = $2 WHERE name = $1 AND NOT done",
+				name, s.now()); err != nil && ctx.Err() == nil {
+				s.logger.WarnContext(ctx, "failed to touch operation", "operation", name, "error", err)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+// Wait blocks until the work of every running operation has finished. If
+// ctx ends first, the work still queued or running is canceled, which
+// stores a CANCELLED error as its result; Wait still waits for it to return.
+func (s *Service) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for _, cancel := range s.cancels {
+			cancel()
+		}
+		s.mu.Unlock()
+		<-finished
+		return ctx.Err()
+	}
+}
+
+// FailAbandoned marks done, with an ABORTED error, the operations that are
+// not done and have not been touched for abandonedAfter: their work was
+// lost when the instance running it stopped without finishing it. It
+// returns how many operations it failed.
+func (s *Service) FailAbandoned(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-abandonedAfter)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT operation FROM operations WHERE NOT done AND updated_at < $1", cutoff)
+	if err != nil {
+		return 0, err
+	}
+	var abandoned []*longrunningpb.Operation
+	for rows.Next() {
+		var b []byte
+		if err := rows.Scan(&b); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		op := &longrunningpb.Operation{}
+		if err := proto.Unmarshal(b, op); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		abandoned = append(abandoned, op)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for _, op := range abandoned {
+		op.Done = true
+		op.Result = &longrunningpb.Operation_Error{
+			Error: status.New(codes.Aborted, "the instance running the operation stopped before it finished").Proto(),
+		}
+		b, err := proto.Marshal(op)
+		if err != nil {
+			return failed, err
+		}
+		// Skip an operation touched since it was read
+		res, err := s.db.ExecContext(ctx,
+			"UPDATE operations SET done = true, operation = $2, updated_at = $3 WHERE name = $1 AND NOT done AND updated_at < $4",
+			op.Name, b, s.now(), cutoff)
+		if err != nil {
+			return failed, err
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// finish marks op done with either resp or workErr as its result. An
+// operation that was canceled meanwhile keeps its canceled result.
+func (s *Service) finish(ctx context.Context, op *longrunningpb.Operation, resp proto.Message, workErr error) error {
+	done := proto.Clone(op).(*longrunningpb.Operation)
+	done.Done = true
+	if workErr != nil {
+		s.logger.WarnContext(ctx, "operation failed", "operation", op.Name, "error", workErr)
+		done.Result = &longrunningpb.Operation_Error{Error: status.Convert(workErr).Proto()}
+	} else {
+		response, err := anypb.New(resp)
+		if err != nil {
+			return err
+		}
+		done.Result = &longrunningpb.Operation_Response{Response: response}
+	}
+	b, err := proto.Marshal(done)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		"UPDATE operations SET done = true, operation = $2, updated_at = $3 WHERE name = $1 AND NOT done",
+		op.Name, b, s.now())
+	return err
+}
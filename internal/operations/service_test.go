@@ -0,0 +1,249 @@
+package operations
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newMockService creates a Service backed by go-sqlmock whose clock is fixed at testNow
+func newMockService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	service := NewService(db, 1, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	service.now = func() time.Time { return testNow }
+	return service, mock
+}
+
+// capturedOperation decodes the serialized operation passed as a query argument
+type capturedOperation struct{ op *longrunningpb.Operation }
+
+func (c *capturedOperation) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	c.op = &longrunningpb.Operation{}
+	return proto.Unmarshal(b, c.op) == nil
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		service, mock := newMockService(t)
+		stored := &capturedOperation{}
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO operations (name, done, operation, created_at, updated_at) VALUES ($1, false, $2, $3, $3)")).
+			WithArgs(sqlmock.AnyArg(), stored, testNow).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		op, err := service.Create(t.Context(), service.db, wrapperspb.String("users/42"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(op.Name, "operations/"))
+		assert.False(t, op.Done)
+		assert.True(t, proto.Equal(op, stored.op))
+
+		var md wrapperspb.StringValue
+		require.NoError(t, op.Metadata.UnmarshalTo(&md))
+		assert.Equal(t, "users/42", md.Value)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectExec("INSERT INTO operations").WillReturnError(errors.New("database error"))
+
+		_, err := service.Create(t.Context(), service.db, wrapperspb.String("users/42"))
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestService_Run(t *testing.T) {
+	update := regexp.QuoteMeta("UPDATE operations SET done = true, operation = $2, updated_at = $3 WHERE name = $1 AND NOT done")
+	op := &longrunningpb.Operation{Name: "operations/1"}
+
+	t.Run("stores the response", func(t *testing.T) {
+		service, mock := newMockService(t)
+		stored := &capturedOperation{}
+		mock.ExpectExec(update).
+			WithArgs("operations/1", stored, testNow).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		service.Run(t.Context(), op, func(ctx context.Context) (proto.Message, error) {
+			return wrapperspb.Int64(7), nil
+		})
+		require.NoError(t, service.Wait(t.Context()))
+
+		require.NotNil(t, stored.op)
+		assert.True(t, stored.op.Done)
+		var resp wrapperspb.Int64Value
+		require.NoError(t, stored.op.GetResponse().UnmarshalTo(&resp))
+		assert.Equal(t, int64(7), resp.Value)
+		assert.False(t, op.Done, "the caller's operation must not be modified")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stores the error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		stored := &capturedOperation{}
+		mock.ExpectExec(update).
+			WithArgs("operations/1", stored, testNow).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		service.Run(t.Context(), op, func(ctx context.Context) (proto.Message, error) {
+			return nil, status.Error(codes.NotFound, "user 42 not found")
+		})
+		require.NoError(t, service.Wait(t.Context()))
+
+		require.NotNil(t, stored.op)
+		assert.True(t, stored.op.Done)
+		assert.Equal(t, int32(codes.NotFound), stored.op.GetError().GetCode())
+		assert.Equal(t, "user 42 not found", stored.op.GetError().GetMessage())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("outlives the request", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		service.Run(ctx, op, func(ctx context.Context) (proto.Message, error) {
+			return wrapperspb.Int64(7), ctx.Err()
+		})
+		require.NoError(t, service.Wait(t.Context()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// touched is closed once the heartbeat of operations/1 is matched
+type touched chan struct{}
+
+func (c touched) Match(v driver.Value) bool {
+	select {
+	case <-c:
+	default:
+		close(c)
+	}
+	return v == "operations/1"
+}
+
+func TestService_Run_Heartbeat(t *testing.T) {
+	service, mock := newMockService(t)
+	service.heartbeat = 10 * time.Millisecond
+	heartbeat := make(touched)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE operations SET updated_at = $2 WHERE name = $1 AND NOT done")).
+		WithArgs(heartbeat, testNow).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE operations SET done = true")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	service.Run(t.Context(), &longrunningpb.Operation{Name: "operations/1"}, func(ctx context.Context) (proto.Message, error) {
+		<-heartbeat
+		return wrapperspb.Int64(7), nil
+	})
+	require.NoError(t, service.Wait(t.Context()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_Wait(t *testing.T) {
+	t.Run("cancels work still running when ctx ends", func(t *testing.T) {
+		service, mock := newMockService(t)
+		stored := &capturedOperation{}
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE operations SET done = true")).
+			WithArgs("operations/1", stored, testNow).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		started := make(chan struct{})
+		service.Run(t.Context(), &longrunningpb.Operation{Name: "operations/1"}, func(ctx context.Context) (proto.Message, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, service.Wait(ctx), context.DeadlineExceeded)
+		require.NotNil(t, stored.op, "the canceled work must have returned")
+		assert.Equal(t, int32(codes.Canceled), stored.op.GetError().GetCode())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing running", func(t *testing.T) {
+		service, _ := newMockService(t)
+		assert.NoError(t, service.Wait(t.Context()))
+	})
+}
+
+func TestService_FailAbandoned(t *testing.T) {
+	selectQuery := regexp.QuoteMeta("SELECT operation FROM operations WHERE NOT done AND updated_at < $1")
+	update := regexp.QuoteMeta("UPDATE operations SET done = true, operation = $2, updated_at = $3 WHERE name = $1 AND NOT done AND updated_at < $4")
+	cutoff := testNow.Add(-abandonedAfter)
+	stale := func(name string) []byte {
+		b, err := proto.Marshal(&longrunningpb.Operation{Name: name})
+		require.NoError(t, err)
+		return b
+	}
+
+	t.Run("fails operations not touched since the cutoff", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(selectQuery).
+			WithArgs(cutoff).
+			WillReturnRows(sqlmock.NewRows([]string{"operation"}).
+				AddRow(stale("operations/1")).
+				AddRow(stale("operations/2")))
+		stored := &capturedOperation{}
+		mock.ExpectExec(update).
+			WithArgs("operations/1", stored, testNow, cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// Touched by its instance after it was read
+		mock.ExpectExec(update).
+			WithArgs("operations/2", sqlmock.AnyArg(), testNow, cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		n, err := service.FailAbandoned(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.True(t, stored.op.Done)
+		assert.Equal(t, int32(codes.Aborted), stored.op.GetError().GetCode())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("none abandoned", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(selectQuery).WillReturnRows(sqlmock.NewRows([]string{"operation"}))
+
+		n, err := service.FailAbandoned(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(selectQuery).WillReturnError(errors.New("database error"))
+
+		_, err := service.FailAbandoned(t.Context())
+		assert.Error(t, err)
+	})
+}
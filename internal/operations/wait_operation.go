@@ -0,0 +1,44 @@
+package operations
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxWaitTimeout caps how long WaitOperation blocks, and is used when a
+// request does not set a timeout
+const MaxWaitTimeout = time.Minute
+
+// WaitOperation blocks until an operation is done or the timeout elapses and
+// returns its latest state, which is not done if the timeout elapsed first
+func (s *Service) WaitOperation(ctx context.Context, req *longrunningpb.WaitOperationRequest) (*longrunningpb.Operation, error) {
+	timeout := MaxWaitTimeout
+	if req.GetTimeout() != nil {
+		if err := req.GetTimeout().CheckValid(); err != nil || req.GetTimeout().AsDuration() < 0 {
+			return nil, status.Error(codes.InvalidArgument, "timeout must be a non-negative duration")
+		}
+		timeout = min(req.GetTimeout().AsDuration(), MaxWaitTimeout)
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		op, err := s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: req.GetName()})
+		if err != nil || op.Done {
+			return op, err
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: req.GetName()})
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
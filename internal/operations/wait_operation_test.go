@@ -0,0 +1,84 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestService_WaitOperation(t *testing.T) {
+	query := `SELECT operation FROM operations WHERE name = \$1`
+	marshal := func(op *longrunningpb.Operation) []byte {
+		b, err := proto.Marshal(op)
+		require.NoError(t, err)
+		return b
+	}
+	pending := marshal(&longrunningpb.Operation{Name: "operations/1"})
+	done := marshal(&longrunningpb.Operation{Name: "operations/1", Done: true})
+
+	t.Run("returns once done", func(t *testing.T) {
+		service, mock := newMockService(t)
+		service.pollInterval = time.Millisecond
+		mock.ExpectQuery(query).WithArgs("operations/1").WillReturnRows(sqlmock.NewRows([]string{"operation"}).AddRow(pending))
+		mock.ExpectQuery(query).WithArgs("operations/1").WillReturnRows(sqlmock.NewRows([]string{"operation"}).AddRow(done))
+
+		op, err := service.WaitOperation(t.Context(), &longrunningpb.WaitOperationRequest{Name: "operations/1"})
+		require.NoError(t, err)
+		assert.True(t, op.Done)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns the latest state after the timeout", func(t *testing.T) {
+		service, mock := newMockService(t)
+		service.pollInterval = time.Hour
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"operation"}).AddRow(pending))
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"operation"}).AddRow(pending))
+
+		op, err := service.WaitOperation(t.Context(), &longrunningpb.WaitOperationRequest{
+			Name:    "operations/1",
+			Timeout: durationpb.New(time.Millisecond),
+		})
+		require.NoError(t, err)
+		assert.False(t, op.Done)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - caller gives up", func(t *testing.T) {
+		service, mock := newMockService(t)
+		service.pollInterval = time.Hour
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"operation"}).AddRow(pending))
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		_, err := service.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{Name: "operations/1"})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"operation"}))
+
+		_, err := service.WaitOperation(t.Context(), &longrunningpb.WaitOperationRequest{Name: "operations/1"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - negative timeout", func(t *testing.T) {
+		service, _ := newMockService(t)
+		_, err := service.WaitOperation(t.Context(), &longrunningpb.WaitOperationRequest{
+			Name:    "operations/1",
+			Timeout: durationpb.New(-time.Second),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
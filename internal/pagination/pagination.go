@@ -0,0 +1,66 @@
+// Package pagination implements the page_size/page_token conventions shared by
+// the List RPCs. Page tokens are opaque to clients and encode the id of the
+// last row on the previous page, so queries can use keyset pagination
+// (WHERE id > $last ORDER BY id LIMIT $n) instead of OFFSET.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+const (
+	// DefaultPageSize is used when a request does not set page_size
+	DefaultPageSize = 50
+	// MaxPageSize caps page_size so a single request cannot read a whole table
+	MaxPageSize = 1000
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// PageSize normalizes a requested page size into the range [1, MaxPageSize]
+func PageSize(requested int32) int {
+	switch {
+	case requested <= 0:
+		return DefaultPageSize
+	case requested > MaxPageSize:
+		return MaxPageSize
+	default:
+		return int(requested)
+	}
+}
+
+// EncodeToken returns the page token for a page that ended at lastID
+func EncodeToken(lastID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(lastID, 10)))
+}
+
+// DecodeToken returns the id encoded in a page token. An empty token decodes
+// to 0, which is before the first row.
+func DecodeToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id < 0 {
+		return 0, ErrInvalidPageToken
+	}
+	return id, nil
+}
+
+// NextToken returns the token for the page after a query that fetched rows
+// with a limit of pageSize+1. If more rows exist than pageSize,
+// the extra row is the first row of the next page and the token points at
+// the last row kept.
+func NextToken(fetched, pageSize int, lastKeptID int64) string {
+	if fetched <= pageSize {
+		return ""
+	}
+	return EncodeToken(lastKeptID)
+}
@@ -0,0 +1,37 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageSize(t *testing.T) {
+	assert.Equal(t, DefaultPageSize, PageSize(0))
+	assert.Equal(t, DefaultPageSize, PageSize(-5))
+	assert.Equal(t, 10, PageSize(10))
+	assert.Equal(t, MaxPageSize, PageSize(MaxPageSize+1))
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	id, err := DecodeToken("")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), id)
+
+	id, err = DecodeToken(EncodeToken(42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestDecodeToken_Invalid(t *testing.T) {
+	for _, token := range []string{"!!!", "bm90LWFuLWlk", EncodeToken(-1)} {
+		_, err := DecodeToken(token)
+		assert.ErrorIs(t, err, ErrInvalidPageToken, token)
+	}
+}
+
+func TestNextToken(t *testing.T) {
+	assert.Empty(t, NextToken(10, 10, 99))
+	assert.Equal(t, EncodeToken(99), NextToken(11, 10, 99))
+}
@@ -0,0 +1,232 @@
+package permissions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrMaxDepthExceeded is returned when a check needs more hops than the
+// service's depth limit, which usually means the relationships form a cycle
+var ErrMaxDepthExceeded = errors.New("maximum check depth exceeded")
+
+// Check reports whether a subject has a permission on a resource
+func (s *Service) Check(ctx context.Context, req *permissionspb.CheckRequest) (*permissionspb.CheckResponse, error) {
+	resource := objectFromProto(req.GetResource())
+	sub := subjectFromProto(req.GetSubject())
+	if err := s.validateRelation(resource, req.GetPermission()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.validateSubject(sub); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "subject: %v", err)
+	}
+
+	allowed, err := s.newChecker().check(ctx, resource, req.GetPermission(), sub, 0)
+	if err != nil {
+		return nil, checkError(err)
+	}
+
+	return &permissionspb.CheckResponse{Allowed: allowed}, nil
+}
+
+// checkError translates errors from the checker into gRPC status errors
+func checkError(err error) error {
+	if errors.Is(err, ErrMaxDepthExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
+
+// checkKey identifies a single (object, relation, subject) question
+type checkKey struct {
+	object   object
+	relation string
+	subject  subject
+}
+
+// checker evaluates permission checks. A checker is scoped to a single
+// request: its cache memoizes every sub-question answered so far, so
+// repeated branches of the rewrite tree (and repeated candidates in
+// LookupResources) hit the database only once, while never serving results
+// across requests that may have seen different relationships.
+type checker struct {
+	db         *sql.DB
+	namespaces *NamespaceConfig
+	maxDepth   int
+	cache      map[checkKey]bool
+}
+
+func (s *Service) newChecker() *checker {
+	return &checker{
+		db:         s.db,
+		namespaces: s.namespaces,
+		maxDepth:   s.maxDepth,
+		cache:      make(map[checkKey]bool),
+	}
+}
+
+// check reports whether sub has relation on obj
+func (c *checker) check(ctx context.Context, obj object, relation string, sub subject, depth int) (bool, error) {
+	if depth > c.maxDepth {
+		return false, fmt.Errorf("%w (%d) checking %s#%s", ErrMaxDepthExceeded, c.maxDepth, obj, relation)
+	}
+
+	// A userset trivially contains itself
+	if sub.Relation == relation && sub.object == obj {
+		return true, nil
+	}
+
+	key := checkKey{object: obj, relation: relation, subject: sub}
+	if allowed, ok := c.cache[key]; ok {
+		return allowed, nil
+	}
+
+	ns := c.namespaces.Namespace(obj.Type)
+	if ns == nil {
+		return false, nil
+	}
+	rel := ns.Relation(relation)
+	if rel == nil {
+		return false, nil
+	}
+
+	var (
+		allowed bool
+		err     error
+	)
+	if rel.Rewrite == nil {
+		allowed, err = c.checkDirect(ctx, obj, relation, sub, depth)
+	} else {
+		allowed, err = c.evaluate(ctx, rel.Rewrite, obj, relation, sub, depth)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	c.cache[key] = allowed
+	return allowed, nil
+}
+
+// evaluate applies a rewrite rule for relation on obj
+func (c *checker) evaluate(ctx context.Context, r *Rewrite, obj object, relation string, sub subject, depth int) (bool, error) {
+	switch {
+	case r.This != nil:
+		return c.checkDirect(ctx, obj, relation, sub, depth)
+
+	case r.ComputedUserset != "":
+		return c.check(ctx, obj, r.ComputedUserset, sub, depth+1)
+
+	case r.TupleToUserset != nil:
+		return c.checkTupleToUserset(ctx, r.TupleToUserset, obj, sub, depth)
+
+	case len(r.Union) > 0:
+		for _, child := range r.Union {
+			allowed, err := c.evaluate(ctx, child, obj, relation, sub, depth)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+		return false, nil
+
+	case len(r.Intersection) > 0:
+		for _, child := range r.Intersection {
+			allowed, err := c.evaluate(ctx, child, obj, relation, sub, depth)
+			if err != nil || !allowed {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case r.Exclusion != nil:
+		allowed, err := c.evaluate(ctx, r.Exclusion.Base, obj, relation, sub, depth)
+		if err != nil || !allowed {
+			return false, err
+		}
+		excluded, err := c.evaluate(ctx, r.Exclusion.Subtract, obj, relation, sub, depth)
+		if err != nil {
+			return false, err
+		}
+		return !excluded, nil
+	}
+
+	return false, fmt.Errorf("invalid rewrite for %s#%s", obj, relation)
+}
+
+// checkDirect matches relationships stored under relation on obj: either
+// sub itself, or a userset (e.g. group:eng#member) that contains sub
+func (c *checker) checkDirect(ctx context.Context, obj object, relation string, sub subject, depth int) (bool, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT subject_type, subject_id, subject_relation FROM relation_tuples
+		WHERE object_type = $1 AND object_id = $2 AND relation = $3
+		AND ((subject_type = $4 AND subject_id = $5 AND subject_relation = $6) OR subject_relation <> '')`,
+		obj.Type, obj.ID, relation, sub.Type, sub.ID, sub.Relation)
+	if err != nil {
+		return false, err
+	}
+
+	var usersets []subject
+	for rows.Next() {
+		var tuple subject
+		if err := rows.Scan(&tuple.Type, &tuple.ID, &tuple.Relation); err != nil {
+			rows.Close()
+			return false, err
+		}
+		if tuple == sub {
+			rows.Close()
+			return true, nil
+		}
+		if tuple.Relation != "" {
+			usersets = append(usersets, tuple)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	// Rows are fully read before recursing so only one connection is held at a time
+	for _, userset := range usersets {
+		allowed, err := c.check(ctx, userset.object, userset.Relation, sub, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
+
+// checkTupleToUserset follows the tupleset relation from obj to other objects
+// and checks the computed userset on each of them
+func (c *checker) checkTupleToUserset(ctx context.Context, ttu *TupleToUserset, obj object, sub subject, depth int) (bool, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT subject_type, subject_id FROM relation_tuples
+		WHERE object_type = $1 AND object_id = $2 AND relation = $3`,
+		obj.Type, obj.ID, ttu.Tupleset)
+	if err != nil {
+		return false, err
+	}
+
+	var related []object
+	for rows.Next() {
+		var o object
+		if err := rows.Scan(&o.Type, &o.ID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		related = append(related, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	for _, o := range related {
+		allowed, err := c.check(ctx, o, ttu.ComputedUserset, sub, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,198 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"google.golang.org/grpc/codes"
+)
+
+var user1 = subject{object: object{Type: "user", ID: "1"}}
+
+// expectDirect expects the direct relationship lookup for obj#relation made
+// while checking user1, returning the given (type, id, relation) subject rows
+func expectDirect(mock sqlmock.Sqlmock, obj object, relation string, subjects ...subject) {
+	rows := sqlmock.NewRows([]string{"subject_type", "subject_id", "subject_relation"})
+	for _, s := range subjects {
+		rows.AddRow(s.Type, s.ID, s.Relation)
+	}
+	mock.ExpectQuery(`SELECT subject_type, subject_id, subject_relation FROM relation_tuples`).
+		WithArgs(obj.Type, obj.ID, relation, user1.Type, user1.ID, user1.Relation).
+		WillReturnRows(rows)
+}
+
+// expectTupleset expects the tupleset lookup for obj#relation
+func expectTupleset(mock sqlmock.Sqlmock, obj object, relation string, related ...object) {
+	rows := sqlmock.NewRows([]string{"subject_type", "subject_id"})
+	for _, o := range related {
+		rows.AddRow(o.Type, o.ID)
+	}
+	mock.ExpectQuery(`SELECT subject_type, subject_id FROM relation_tuples`).
+		WithArgs(obj.Type, obj.ID, relation).
+		WillReturnRows(rows)
+}
+
+func TestService_Check(t *testing.T) {
+	doc := object{Type: "document", ID: "readme"}
+	folder := object{Type: "folder", ID: "docs"}
+	eng := object{Type: "group", ID: "eng"}
+
+	tests := []struct {
+		name       string
+		permission string
+		mockSetup  func(sqlmock.Sqlmock)
+		allowed    bool
+	}{
+		{
+			name:       "direct relationship",
+			permission: "owner",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "owner", user1)
+			},
+			allowed: true,
+		},
+		{
+			name:       "no relationship",
+			permission: "owner",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "owner")
+			},
+			allowed: false,
+		},
+		{
+			name:       "computed userset - owners are editors",
+			permission: "editor",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "editor")
+				expectDirect(mock, doc, "owner", user1)
+			},
+			allowed: true,
+		},
+		{
+			name:       "userset subject - owned by a group the user is a member of",
+			permission: "owner",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "owner", subject{object: eng, Relation: "member"})
+				expectDirect(mock, eng, "member", user1)
+			},
+			allowed: true,
+		},
+		{
+			name:       "tuple to userset - viewer of the parent folder",
+			permission: "viewer",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "viewer")
+				expectDirect(mock, doc, "editor")
+				expectDirect(mock, doc, "owner")
+				expectTupleset(mock, doc, "parent", folder)
+				expectDirect(mock, folder, "viewer", user1)
+			},
+			allowed: true,
+		},
+		{
+			name:       "exclusion - banned viewers cannot read",
+			permission: "reader",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "viewer", user1)
+				expectDirect(mock, doc, "banned", user1)
+			},
+			allowed: false,
+		},
+		{
+			name:       "exclusion - viewers can read",
+			permission: "reader",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectDirect(mock, doc, "viewer", user1)
+				expectDirect(mock, doc, "banned")
+			},
+			allowed: true,
+		},
+		{
+			name:       "intersection - owner is also an editor",
+			permission: "approver",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				// editor falls back to owner, which is answered from the cache
+				expectDirect(mock, doc, "owner", user1)
+				expectDirect(mock, doc, "editor")
+			},
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			resp, err := service.Check(context.Background(), &permissionspb.CheckRequest{
+				Resource:   &permissionspb.ObjectReference{ObjectType: doc.Type, ObjectId: doc.ID},
+				Permission: tt.permission,
+				Subject:    &permissionspb.SubjectReference{Object: &permissionspb.ObjectReference{ObjectType: "user", ObjectId: "1"}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, resp.Allowed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_Check_InvalidArgument(t *testing.T) {
+	service, _ := newMockService(t)
+	user := &permissionspb.SubjectReference{Object: &permissionspb.ObjectReference{ObjectType: "user", ObjectId: "1"}}
+
+	_, err := service.Check(context.Background(), &permissionspb.CheckRequest{
+		Resource:   &permissionspb.ObjectReference{ObjectType: "spaceship", ObjectId: "1"},
+		Permission: "pilot",
+		Subject:    user,
+	})
+	assertStatusCode(t, err, codes.InvalidArgument)
+
+	_, err = service.Check(context.Background(), &permissionspb.CheckRequest{
+		Resource:   &permissionspb.ObjectReference{ObjectType: "document", ObjectId: "1"},
+		Permission: "viewer",
+	})
+	assertStatusCode(t, err, codes.InvalidArgument)
+}
+
+func TestChecker_Cache(t *testing.T) {
+	service, mock := newMockService(t)
+	doc := object{Type: "document", ID: "readme"}
+	expectDirect(mock, doc, "owner", user1)
+
+	checker := service.newChecker()
+	for range 3 {
+		allowed, err := checker.check(context.Background(), doc, "owner", user1, 0)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	// Only the first check reached the database
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChecker_MaxDepth(t *testing.T) {
+	service, mock := newMockService(t)
+	service.maxDepth = 2
+
+	// group:a#member contains group:b#member contains group:a#member ...
+	a, b := object{Type: "group", ID: "a"}, object{Type: "group", ID: "b"}
+	expectDirect(mock, a, "member", subject{object: b, Relation: "member"})
+	expectDirect(mock, b, "member", subject{object: a, Relation: "member"})
+	expectDirect(mock, a, "member", subject{object: b, Relation: "member"})
+
+	_, err := service.newChecker().check(context.Background(), a, "member", user1, 0)
+	assert.ErrorIs(t, err, ErrMaxDepthExceeded)
+	assertStatusCode(t, checkError(err), codes.ResourceExhausted)
+}
+
+func TestChecker_DatabaseError(t *testing.T) {
+	service, mock := newMockService(t)
+	mock.ExpectQuery(`SELECT subject_type`).WillReturnError(errors.New("database connection failed"))
+
+	_, err := service.newChecker().check(context.Background(), object{Type: "document", ID: "x"}, "owner", user1, 0)
+	assert.ErrorContains(t, err, "database connection failed")
+}
@@ -0,0 +1,73 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteRelationships deletes every relationship matching the request filter
+func (s *Service) DeleteRelationships(ctx context.Context, req *permissionspb.DeleteRelationshipsRequest) (_ *permissionspb.DeleteRelationshipsResponse, err error) {
+	filter := req.GetFilter()
+	if filter.GetResourceType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filter.resource_type is required")
+	}
+
+	// Build the WHERE clause from the filter fields that are set
+	columns := []struct {
+		name  string
+		value string
+	}{
+		{"object_type", filter.GetResourceType()},
+		{"object_id", filter.GetResourceId()},
+		{"relation", filter.GetRelation()},
+		{"subject_type", filter.GetSubjectType()},
+		{"subject_id", filter.GetSubjectId()},
+		{"subject_relation", filter.GetSubjectRelation()},
+	}
+	var (
+		conditions []string
+		args       []any
+	)
+	for _, column := range columns {
+		if column.value == "" {
+			continue
+		}
+		args = append(args, column.value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column.name, len(args)))
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		"DELETE FROM relation_tuples WHERE "+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	resource := object{Type: filter.GetResourceType(), ID: filter.GetResourceId()}
+	if err = audit.Record(ctx, tx, auditResource(resource)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &permissionspb.DeleteRelationshipsResponse{DeletedCount: deleted}, nil
+}
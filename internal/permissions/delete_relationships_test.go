@@ -0,0 +1,39 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_DeleteRelationships(t *testing.T) {
+	t.Run("success - filter on set fields only", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM relation_tuples WHERE object_type = \$1 AND relation = \$2 AND subject_id = \$3$`).
+			WithArgs("document", "viewer", "1").
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectCommit()
+
+		resp, err := service.DeleteRelationships(context.Background(), &permissionspb.DeleteRelationshipsRequest{
+			Filter: &permissionspb.RelationshipFilter{ResourceType: "document", Relation: "viewer", SubjectId: "1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), resp.DeletedCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - resource type is required", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.DeleteRelationships(context.Background(), &permissionspb.DeleteRelationshipsRequest{
+			Filter: &permissionspb.RelationshipFilter{SubjectId: "1"},
+		})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+}
@@ -0,0 +1,113 @@
+package permissions
+
+import (
+	"context"
+	"encoding/base64"
+
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// lookupBatchSize is how many candidate resources are read per query
+	lookupBatchSize = 100
+	// maxLookupCandidates bounds how many candidates a single LookupResources
+	// request checks. When reached, a partial page is returned together with
+	// a page token to continue from.
+	maxLookupCandidates = 10 * pagination.MaxPageSize
+)
+
+// LookupResources lists the resources of a type on which the subject has a permission.
+//
+// Every resource of the requested type that appears in at least one
+// relationship is a candidate; candidates are checked in object_id order,
+// sharing one request-scoped check cache.
+func (s *Service) LookupResources(ctx context.Context, req *permissionspb.LookupResourcesRequest) (*permissionspb.LookupResourcesResponse, error) {
+	ns := s.namespaces.Namespace(req.GetResourceType())
+	if ns == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown resource type %q", req.GetResourceType())
+	}
+	if ns.Relation(req.GetPermission()) == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "resource type %q has no relation %q", req.GetResourceType(), req.GetPermission())
+	}
+	sub := subjectFromProto(req.GetSubject())
+	if err := s.validateSubject(sub); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "subject: %v", err)
+	}
+	after, err := decodeLookupToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+
+	var (
+		checker   = s.newChecker()
+		ids       = make([]string, 0)
+		scanned   int
+		exhausted bool
+	)
+scan:
+	for scanned < maxLookupCandidates {
+		candidates, err := s.lookupCandidates(ctx, req.GetResourceType(), after)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range candidates {
+			scanned++
+			after = id
+			allowed, err := checker.check(ctx, object{Type: req.GetResourceType(), ID: id}, req.GetPermission(), sub, 0)
+			if err != nil {
+				return nil, checkError(err)
+			}
+			if allowed {
+				ids = append(ids, id)
+				if len(ids) == pageSize {
+					break scan
+				}
+			}
+		}
+		if len(candidates) < lookupBatchSize {
+			exhausted = true
+			break
+		}
+	}
+
+	resp := &permissionspb.LookupResourcesResponse{ResourceIds: ids}
+	if !exhausted {
+		resp.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(after))
+	}
+	return resp, nil
+}
+
+// lookupCandidates returns the next batch of distinct object ids of a type after the given id
+func (s *Service) lookupCandidates(ctx context.Context, objectType, after string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT object_id FROM relation_tuples
+		WHERE object_type = $1 AND object_id > $2 ORDER BY object_id LIMIT $3`,
+		objectType, after, lookupBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// decodeLookupToken decodes a LookupResources page token, which holds the
+// last object id checked by the previous page
+func decodeLookupToken(token string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", pagination.ErrInvalidPageToken
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,79 @@
+package permissions
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_LookupResources(t *testing.T) {
+	user := &permissionspb.SubjectReference{Object: &permissionspb.ObjectReference{ObjectType: "user", ObjectId: "1"}}
+
+	t.Run("success - returns allowed candidates", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(`SELECT DISTINCT object_id FROM relation_tuples`).
+			WithArgs("document", "", lookupBatchSize).
+			WillReturnRows(sqlmock.NewRows([]string{"object_id"}).AddRow("a").AddRow("b"))
+		expectDirect(mock, object{Type: "document", ID: "a"}, "owner", user1)
+		expectDirect(mock, object{Type: "document", ID: "b"}, "owner")
+
+		resp, err := service.LookupResources(context.Background(), &permissionspb.LookupResourcesRequest{
+			ResourceType: "document",
+			Permission:   "owner",
+			Subject:      user,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, resp.ResourceIds)
+		assert.Empty(t, resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - full page returns a page token", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(`SELECT DISTINCT object_id FROM relation_tuples`).
+			WithArgs("document", "a", lookupBatchSize).
+			WillReturnRows(sqlmock.NewRows([]string{"object_id"}).AddRow("b").AddRow("c"))
+		expectDirect(mock, object{Type: "document", ID: "b"}, "owner", user1)
+
+		resp, err := service.LookupResources(context.Background(), &permissionspb.LookupResourcesRequest{
+			ResourceType: "document",
+			Permission:   "owner",
+			Subject:      user,
+			PageSize:     1,
+			PageToken:    base64.RawURLEncoding.EncodeToString([]byte("a")),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, resp.ResourceIds)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString([]byte("b")), resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - unknown permission", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.LookupResources(context.Background(), &permissionspb.LookupResourcesRequest{
+			ResourceType: "document",
+			Permission:   "pilot",
+			Subject:      user,
+		})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+
+	t.Run("error - invalid page token", func(t *testing.T) {
+		service, _ := newMockService(t)
+
+		_, err := service.LookupResources(context.Background(), &permissionspb.LookupResourcesRequest{
+			ResourceType: "document",
+			Permission:   "owner",
+			Subject:      user,
+			PageToken:    "%%",
+		})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+}
@@ -0,0 +1,199 @@
+package permissions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NamespaceConfig defines the object types (namespaces) known to the
+// permission service, the relations each of them has, and how relations are
+// rewritten in terms of other relations.
+type NamespaceConfig struct {
+	Namespaces []Namespace `yaml:"namespaces"`
+
+	byName map[string]*Namespace
+}
+
+// Namespace is an object type, e.g. "document" or "group"
+type Namespace struct {
+	Name      string     `yaml:"name"`
+	Relations []Relation `yaml:"relations"`
+
+	byName map[string]*Relation
+}
+
+// Relation is a named relation or permission on a namespace. A relation
+// without a rewrite is satisfied only by relationships stored directly
+// under its name.
+type Relation struct {
+	Name    string   `yaml:"name"`
+	Rewrite *Rewrite `yaml:"rewrite,omitempty"`
+}
+
+// Rewrite is a userset rewrite rule. Exactly one field must be set.
+type Rewrite struct {
+	// This matches relationships stored directly under the relation being evaluated
+	This *struct{} `yaml:"this,omitempty"`
+	// ComputedUserset matches subjects that have another relation on the same object
+	ComputedUserset string `yaml:"computed_userset,omitempty"`
+	// TupleToUserset follows relationships to other objects and evaluates a relation there
+	TupleToUserset *TupleToUserset `yaml:"tuple_to_userset,omitempty"`
+	// Union matches if any child matches
+	Union []*Rewrite `yaml:"union,omitempty"`
+	// Intersection matches if every child matches
+	Intersection []*Rewrite `yaml:"intersection,omitempty"`
+	// Exclusion matches if Base matches and Subtract does not
+	Exclusion *Exclusion `yaml:"exclusion,omitempty"`
+}
+
+// TupleToUserset reads the objects related through Tupleset (e.g. a
+// document's "parent" folder) and evaluates ComputedUserset on each of them
+// (e.g. the folder's "viewer" relation).
+type TupleToUserset struct {
+	Tupleset        string `yaml:"tupleset"`
+	ComputedUserset string `yaml:"computed_userset"`
+}
+
+// Exclusion subtracts one userset from another
+type Exclusion struct {
+	Base     *Rewrite `yaml:"base"`
+	Subtract *Rewrite `yaml:"subtract"`
+}
+
+// LoadNamespaceConfig reads and validates a YAML namespace configuration file
+func LoadNamespaceConfig(path string) (*NamespaceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read namespace config: %w", err)
+	}
+	return ParseNamespaceConfig(data)
+}
+
+// ParseNamespaceConfig parses and validates a YAML namespace configuration
+func ParseNamespaceConfig(data []byte) (*NamespaceConfig, error) {
+	var config NamespaceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse namespace config: %w", err)
+	}
+	if err := config.index(); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Namespace returns the namespace with the given name, or nil
+func (c *NamespaceConfig) Namespace(name string) *Namespace {
+	return c.byName[name]
+}
+
+// Relation returns the relation with the given name, or nil
+func (n *Namespace) Relation(name string) *Relation {
+	return n.byName[name]
+}
+
+func (c *NamespaceConfig) index() error {
+	c.byName = make(map[string]*Namespace, len(c.Namespaces))
+	for i := range c.Namespaces {
+		ns := &c.Namespaces[i]
+		if ns.Name == "" {
+			return errors.New("namespace name is required")
+		}
+		if _, ok := c.byName[ns.Name]; ok {
+			return fmt.Errorf("duplicate namespace %q", ns.Name)
+		}
+		c.byName[ns.Name] = ns
+
+		ns.byName = make(map[string]*Relation, len(ns.Relations))
+		for j := range ns.Relations {
+			rel := &ns.Relations[j]
+			if rel.Name == "" {
+				return fmt.Errorf("namespace %q: relation name is required", ns.Name)
+			}
+			if _, ok := ns.byName[rel.Name]; ok {
+				return fmt.Errorf("namespace %q: duplicate relation %q", ns.Name, rel.Name)
+			}
+			ns.byName[rel.Name] = rel
+		}
+	}
+	return nil
+}
+
+// validate checks that every rewrite is well formed and only references
+// relations that exist in its namespace
+func (c *NamespaceConfig) validate() error {
+	for i := range c.Namespaces {
+		ns := &c.Namespaces[i]
+		for j := range ns.Relations {
+			rel := &ns.Relations[j]
+			if rel.Rewrite == nil {
+				continue
+			}
+			if err := ns.validateRewrite(rel.Rewrite); err != nil {
+				return fmt.Errorf("namespace %q relation %q: %w", ns.Name, rel.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (n *Namespace) validateRewrite(r *Rewrite) error {
+	if r == nil {
+		return errors.New("empty rewrite")
+	}
+
+	set := 0
+	if r.This != nil {
+		set++
+	}
+	if r.ComputedUserset != "" {
+		set++
+		if n.Relation(r.ComputedUserset) == nil {
+			return fmt.Errorf("computed_userset references unknown relation %q", r.ComputedUserset)
+		}
+	}
+	if r.TupleToUserset != nil {
+		set++
+		if n.Relation(r.TupleToUserset.Tupleset) == nil {
+			return fmt.Errorf("tuple_to_userset references unknown tupleset relation %q", r.TupleToUserset.Tupleset)
+		}
+		// The computed userset is evaluated on whatever objects the tupleset
+		// points at, which may live in any namespace, so it is resolved at
+		// check time instead.
+		if r.TupleToUserset.ComputedUserset == "" {
+			return errors.New("tuple_to_userset requires computed_userset")
+		}
+	}
+	if len(r.Union) > 0 {
+		set++
+	}
+	if len(r.Intersection) > 0 {
+		set++
+	}
+	if r.Exclusion != nil {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("rewrite must set exactly one of this, computed_userset, tuple_to_userset, union, intersection or exclusion (got %d)", set)
+	}
+
+	for _, child := range append(r.Union, r.Intersection...) {
+		if err := n.validateRewrite(child); err != nil {
+			return err
+		}
+	}
+	if r.Exclusion != nil {
+		if err := n.validateRewrite(r.Exclusion.Base); err != nil {
+			return fmt.Errorf("exclusion base: %w", err)
+		}
+		if err := n.validateRewrite(r.Exclusion.Subtract); err != nil {
+			return fmt.Errorf("exclusion subtract: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package permissions
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNamespaceConfig(t *testing.T) {
+	config, err := ParseNamespaceConfig([]byte(testNamespaces))
+	require.NoError(t, err)
+
+	doc := config.Namespace("document")
+	require.NotNil(t, doc)
+	viewer := doc.Relation("viewer")
+	require.NotNil(t, viewer)
+	require.NotNil(t, viewer.Rewrite)
+	require.Len(t, viewer.Rewrite.Union, 3)
+	assert.NotNil(t, viewer.Rewrite.Union[0].This)
+	assert.Equal(t, "editor", viewer.Rewrite.Union[1].ComputedUserset)
+	assert.Equal(t, "parent", viewer.Rewrite.Union[2].TupleToUserset.Tupleset)
+
+	assert.Nil(t, config.Namespace("unknown"))
+	assert.Nil(t, doc.Relation("unknown"))
+}
+
+func TestParseNamespaceConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		errorContains string
+	}{
+		{
+			name:          "duplicate namespace",
+			yaml:          "namespaces: [{name: a}, {name: a}]",
+			errorContains: `duplicate namespace "a"`,
+		},
+		{
+			name:          "duplicate relation",
+			yaml:          "namespaces: [{name: a, relations: [{name: r}, {name: r}]}]",
+			errorContains: `duplicate relation "r"`,
+		},
+		{
+			name:          "unknown computed userset",
+			yaml:          "namespaces: [{name: a, relations: [{name: r, rewrite: {computed_userset: missing}}]}]",
+			errorContains: `unknown relation "missing"`,
+		},
+		{
+			name:          "unknown tupleset",
+			yaml:          "namespaces: [{name: a, relations: [{name: r, rewrite: {tuple_to_userset: {tupleset: missing, computed_userset: r}}}]}]",
+			errorContains: `unknown tupleset relation "missing"`,
+		},
+		{
+			name:          "more than one rewrite kind",
+			yaml:          "namespaces: [{name: a, relations: [{name: o}, {name: r, rewrite: {this: {}, computed_userset: o}}]}]",
+			errorContains: "exactly one",
+		},
+		{
+			name:          "invalid nested rewrite",
+			yaml:          "namespaces: [{name: a, relations: [{name: r, rewrite: {union: [{this: {}}, {computed_userset: nope}]}}]}]",
+			errorContains: `unknown relation "nope"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNamespaceConfig([]byte(tt.yaml))
+			assert.ErrorContains(t, err, tt.errorContains)
+		})
+	}
+}
+
+func TestLoadNamespaceConfig_ShippedConfig(t *testing.T) {
+	// The default configuration shipped with the service must always be valid
+	config, err := LoadNamespaceConfig("../../config/permissions.yaml")
+	require.NoError(t, err)
+	assert.NotNil(t, config.Namespace("document"))
+
+	_, err = LoadNamespaceConfig(os.DevNull + "/missing.yaml")
+	assert.Error(t, err)
+}
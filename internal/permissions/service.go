@@ -0,0 +1,121 @@
+package permissions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+)
+
+// DefaultMaxDepth bounds how many rewrite and userset hops a single check may follow
+const DefaultMaxDepth = 25
+
+// Service handles gRPC requests for relationship storage and permission checks
+type Service struct {
+	permissionspb.UnimplementedPermissionServiceServer
+	db         *sql.DB
+	namespaces *NamespaceConfig
+	maxDepth   int
+	logger     *slog.Logger
+}
+
+// NewService creates a new permission service that evaluates checks against
+// the given namespace configuration
+func NewService(db *sql.DB, namespaces *NamespaceConfig, logger *slog.Logger) *Service {
+	return &Service{
+		db:         db,
+		namespaces: namespaces,
+		maxDepth:   DefaultMaxDepth,
+		logger:     logger,
+	}
+}
+
+// object is an internal representation of an ObjectReference
+type object struct {
+	Type string
+	ID   string
+}
+
+func (o object) String() string {
+	return o.Type + ":" + o.ID
+}
+
+// subject is an internal representation of a SubjectReference. A non-empty
+// Relation makes it a userset, e.g. group:eng#member.
+type subject struct {
+	object
+	Relation string
+}
+
+func (s subject) String() string {
+	if s.Relation == "" {
+		return s.object.String()
+	}
+	return s.object.String() + "#" + s.Relation
+}
+
+// auditResource returns the audit resource name for the relationships of
+// the objects in objs: a single object, every object of a single type, or
+// all relationships
+func auditResource(objs ...object) string {
+	if len(objs) == 0 {
+		return "relationships"
+	}
+	first := objs[0]
+	for _, o := range objs[1:] {
+		if o.Type != first.Type {
+			return "relationships"
+		}
+		if o.ID != first.ID {
+			first.ID = ""
+		}
+	}
+	if first.ID == "" {
+		return "relationships/" + first.Type
+	}
+	return "relationships/" + first.String()
+}
+
+func objectFromProto(ref *permissionspb.ObjectReference) object {
+	return object{Type: ref.GetObjectType(), ID: ref.GetObjectId()}
+}
+
+func subjectFromProto(ref *permissionspb.SubjectReference) subject {
+	return subject{object: objectFromProto(ref.GetObject()), Relation: ref.GetOptionalRelation()}
+}
+
+// validateObject checks that o is fully specified and its type is a known namespace
+func (s *Service) validateObject(o object) (*Namespace, error) {
+	if o.Type == "" || o.ID == "" {
+		return nil, errors.New("object_type and object_id are required")
+	}
+	ns := s.namespaces.Namespace(o.Type)
+	if ns == nil {
+		return nil, fmt.Errorf("unknown object type %q", o.Type)
+	}
+	return ns, nil
+}
+
+// validateRelation checks that relation is defined on the namespace of o
+func (s *Service) validateRelation(o object, relation string) error {
+	ns, err := s.validateObject(o)
+	if err != nil {
+		return err
+	}
+	if ns.Relation(relation) == nil {
+		return fmt.Errorf("object type %q has no relation %q", o.Type, relation)
+	}
+	return nil
+}
+
+// validateSubject checks that sub refers to a known object and, for usersets,
+// a relation defined on that object's namespace
+func (s *Service) validateSubject(sub subject) error {
+	if sub.Relation == "" {
+		_, err := s.validateObject(sub.object)
+		return err
+	}
+	return s.validateRelation(sub.object, sub.Relation)
+}
@@ -0,0 +1,102 @@
+package permissions
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testNamespaces is the namespace configuration used by the tests
+const testNamespaces = `
+namespaces:
+  - name: user
+  - name: group
+    relations:
+      - name: member
+  - name: folder
+    relations:
+      - name: viewer
+  - name: document
+    relations:
+      - name: parent
+      - name: owner
+      - name: banned
+      - name: editor
+        rewrite:
+          union:
+            - this: {}
+            - computed_userset: owner
+      - name: viewer
+        rewrite:
+          union:
+            - this: {}
+            - computed_userset: editor
+            - tuple_to_userset:
+                tupleset: parent
+                computed_userset: viewer
+      - name: reader
+        rewrite:
+          exclusion:
+            base:
+              computed_userset: viewer
+            subtract:
+              computed_userset: banned
+      - name: approver
+        rewrite:
+          intersection:
+            - computed_userset: owner
+            - computed_userset: editor
+`
+
+// newMockService creates a Service backed by go-sqlmock using testNamespaces
+func newMockService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	config, err := ParseNamespaceConfig([]byte(testNamespaces))
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	return NewService(db, config, logger), mock
+}
+
+// assertStatusCode asserts err is a gRPC status error with the given code
+func assertStatusCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestSubject_String(t *testing.T) {
+	assert.Equal(t, "user:1", subject{object: object{Type: "user", ID: "1"}}.String())
+	assert.Equal(t, "group:eng#member", subject{object: object{Type: "group", ID: "eng"}, Relation: "member"}.String())
+}
+
+func TestAuditResource(t *testing.T) {
+	doc1, doc2 := object{Type: "document", ID: "1"}, object{Type: "document", ID: "2"}
+	assert.Equal(t, "relationships/document:1", auditResource(doc1, doc1))
+	assert.Equal(t, "relationships/document", auditResource(doc1, doc2))
+	assert.Equal(t, "relationships/document", auditResource(object{Type: "document"}))
+	assert.Equal(t, "relationships", auditResource(doc1, object{Type: "folder", ID: "1"}))
+}
+
+func TestService_Validate(t *testing.T) {
+	service, _ := newMockService(t)
+
+	assert.NoError(t, service.validateRelation(object{Type: "document", ID: "1"}, "viewer"))
+	assert.ErrorContains(t, service.validateRelation(object{Type: "document"}, "viewer"), "required")
+	assert.ErrorContains(t, service.validateRelation(object{Type: "spaceship", ID: "1"}, "viewer"), "unknown object type")
+	assert.ErrorContains(t, service.validateRelation(object{Type: "document", ID: "1"}, "pilot"), "no relation")
+
+	assert.NoError(t, service.validateSubject(subject{object: object{Type: "user", ID: "1"}}))
+	assert.NoError(t, service.validateSubject(subject{object: object{Type: "group", ID: "eng"}, Relation: "member"}))
+	assert.Error(t, service.validateSubject(subject{object: object{Type: "group", ID: "eng"}, Relation: "owner"}))
+}
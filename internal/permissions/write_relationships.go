@@ -0,0 +1,65 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WriteRelationships atomically stores a batch of relationships
+func (s *Service) WriteRelationships(ctx context.Context, req *permissionspb.WriteRelationshipsRequest) (_ *permissionspb.WriteRelationshipsResponse, err error) {
+	if len(req.GetRelationships()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one relationship is required")
+	}
+	for i, rel := range req.GetRelationships() {
+		if err := s.validateRelationship(rel); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "relationships[%d]: %v", i, err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	resources := make([]object, 0, len(req.GetRelationships()))
+	for _, rel := range req.GetRelationships() {
+		resource, sub := objectFromProto(rel.GetResource()), subjectFromProto(rel.GetSubject())
+		resources = append(resources, resource)
+		_, err = tx.ExecContext(ctx, `INSERT INTO relation_tuples
+			(object_type, object_id, relation, subject_type, subject_id, subject_relation)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT ON CONSTRAINT relation_tuples_unique DO NOTHING`,
+			resource.Type, resource.ID, rel.GetRelation(), sub.Type, sub.ID, sub.Relation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = audit.Record(ctx, tx, auditResource(resources...)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &permissionspb.WriteRelationshipsResponse{}, nil
+}
+
+func (s *Service) validateRelationship(rel *permissionspb.Relationship) error {
+	if err := s.validateRelation(objectFromProto(rel.GetResource()), rel.GetRelation()); err != nil {
+		return err
+	}
+	if err := s.validateSubject(subjectFromProto(rel.GetSubject())); err != nil {
+		return fmt.Errorf("subject: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	permissionspb "github.com/zcking/go-api-template/gen/go/permissions/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func relationship(resourceType, resourceID, relation, subjectType, subjectID, subjectRelation string) *permissionspb.Relationship {
+	return &permissionspb.Relationship{
+		Resource: &permissionspb.ObjectReference{ObjectType: resourceType, ObjectId: resourceID},
+		Relation: relation,
+		Subject: &permissionspb.SubjectReference{
+			Object:           &permissionspb.ObjectReference{ObjectType: subjectType, ObjectId: subjectID},
+			OptionalRelation: subjectRelation,
+		},
+	}
+}
+
+func TestService_WriteRelationships(t *testing.T) {
+	tests := []struct {
+		name          string
+		relationships []*permissionspb.Relationship
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedErr   bool
+		expectedCode  codes.Code
+	}{
+		{
+			name: "success - batch written in one transaction",
+			relationships: []*permissionspb.Relationship{
+				relationship("document", "readme", "owner", "user", "1", ""),
+				relationship("document", "readme", "viewer", "group", "eng", "member"),
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO relation_tuples .* ON CONFLICT ON CONSTRAINT relation_tuples_unique DO NOTHING`).
+					WithArgs("document", "readme", "owner", "user", "1", "").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO relation_tuples`).
+					WithArgs("document", "readme", "viewer", "group", "eng", "member").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error - insert fails and the batch is rolled back",
+			relationships: []*permissionspb.Relationship{
+				relationship("document", "readme", "owner", "user", "1", ""),
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO relation_tuples`).WillReturnError(errors.New("database connection failed"))
+				mock.ExpectRollback()
+			},
+			expectedErr:  true,
+			expectedCode: codes.Unknown,
+		},
+		{
+			name:          "error - empty batch",
+			mockSetup:     func(sqlmock.Sqlmock) {},
+			expectedErr:   true,
+			expectedCode:  codes.InvalidArgument,
+			relationships: nil,
+		},
+		{
+			name: "error - unknown relation",
+			relationships: []*permissionspb.Relationship{
+				relationship("document", "readme", "pilot", "user", "1", ""),
+			},
+			mockSetup:    func(sqlmock.Sqlmock) {},
+			expectedErr:  true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "error - unknown subject relation",
+			relationships: []*permissionspb.Relationship{
+				relationship("document", "readme", "owner", "group", "eng", "owner"),
+			},
+			mockSetup:    func(sqlmock.Sqlmock) {},
+			expectedErr:  true,
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			tt.mockSetup(mock)
+
+			resp, err := service.WriteRelationships(context.Background(), &permissionspb.WriteRelationshipsRequest{
+				Relationships: tt.relationships,
+			})
+
+			if tt.expectedErr {
+				assertStatusCode(t, err, tt.expectedCode)
+				assert.Nil(t, resp)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, resp)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package privacy
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportContentType is the content type of a downloaded export
+const exportContentType = "application/x-ndjson"
+
+// DownloadUserDataExport streams a finished export, one record per message.
+// Through the gateway the messages are newline-delimited, so the response
+// body is newline-delimited JSON.
+func (s *Service) DownloadUserDataExport(req *privacypb.DownloadUserDataExportRequest, stream grpc.ServerStreamingServer[httpbody.HttpBody]) error {
+	ctx := stream.Context()
+	var bundle []byte
+	err := s.db.QueryRowContext(ctx,
+		"SELECT bundle FROM user_data_exports WHERE id = $1 AND user_id = $2 AND expires_at > $3",
+		req.GetExportId(), req.GetUserId(), s.now()).Scan(&bundle)
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "export %d of user %d not found", req.GetExportId(), req.GetUserId())
+	}
+	if err != nil {
+		return err
+	}
+
+	for line := range bytes.Lines(bundle) {
+		if err := stream.Send(&httpbody.HttpBody{
+			ContentType: exportContentType,
+			Data:        bytes.TrimSuffix(line, []byte("\n")),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
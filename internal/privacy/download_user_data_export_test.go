@@ -0,0 +1,69 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream collects the messages sent on a server stream
+type fakeStream struct {
+	grpc.ServerStreamingServer[httpbody.HttpBody]
+	ctx  context.Context
+	sent []*httpbody.HttpBody
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) Send(m *httpbody.HttpBody) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestService_DownloadUserDataExport(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT bundle FROM user_data_exports WHERE id = $1 AND user_id = $2 AND expires_at > $3")
+	req := &privacypb.DownloadUserDataExportRequest{UserId: 42, ExportId: 7}
+
+	t.Run("success", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(query).
+			WithArgs(int64(7), int64(42), testNow).
+			WillReturnRows(sqlmock.NewRows([]string{"bundle"}).AddRow([]byte("{\"type\":\"user\"}\n{\"type\":\"sign_in\"}\n")))
+
+		stream := &fakeStream{ctx: t.Context()}
+		require.NoError(t, service.DownloadUserDataExport(req, stream))
+		require.Len(t, stream.sent, 2)
+		assert.Equal(t, `{"type":"user"}`, string(stream.sent[0].Data))
+		assert.Equal(t, `{"type":"sign_in"}`, string(stream.sent[1].Data))
+		assert.Equal(t, exportContentType, stream.sent[0].ContentType)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - not found or expired", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"bundle"}))
+
+		err := service.DownloadUserDataExport(req, &fakeStream{ctx: t.Context()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - database error", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+
+		err := service.DownloadUserDataExport(req, &fakeStream{ctx: t.Context()})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
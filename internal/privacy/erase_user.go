@@ -0,0 +1,74 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// EraseUser starts deleting a user's personal data. The user, their
+// memberships, relationships, invitations, sign-ins and exports are
+// deleted; their audit events are kept with personal fields redacted.
+func (s *Service) EraseUser(ctx context.Context, req *privacypb.EraseUserRequest) (*longrunningpb.Operation, error) {
+	userID := req.GetUserId()
+	return s.start(ctx, userID, "erase", func(ctx context.Context) (proto.Message, error) {
+		return s.eraseUser(ctx, userID)
+	})
+}
+
+// eraseUser deletes a user's personal data in one transaction
+func (s *Service) eraseUser(ctx context.Context, userID int64) (_ *privacypb.EraseUserResponse, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	// Memberships, sign-ins and exports are deleted with the user. The email
+	// is still needed to find the invitations sent to it.
+	var pii users.PII
+	err = tx.QueryRowContext(ctx, "DELETE FROM users WHERE id = $1 RETURNING "+users.PIIColumns, userID).Scan(pii.Dest()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "user %d not found", userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	email, _, err := s.users.OpenPII(userID, &pii)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatInt(userID, 10)
+	for _, stmt := range []struct {
+		query string
+		args  []any
+	}{
+		{"DELETE FROM relation_tuples WHERE (subject_type = 'user' AND subject_id = $1) OR (object_type = 'user' AND object_id = $1)", []any{id}},
+		{"DELETE FROM invitations WHERE accepted_user_id = $1 OR lower(email) = lower($2)", []any{userID, email}},
+	} {
+		if _, err = tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	redacted, err := audit.RedactUser(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "erased user", "user_id", userID, "redacted_audit_events", redacted)
+	return &privacypb.EraseUserResponse{RedactedAuditEventCount: redacted}, nil
+}
@@ -0,0 +1,81 @@
+package privacy
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+)
+
+func TestService_EraseUser(t *testing.T) {
+	deleteUser := regexp.QuoteMeta("DELETE FROM users WHERE id = $1 RETURNING " + users.PIIColumns)
+
+	t.Run("success", func(t *testing.T) {
+		service, mock := newMockService(t)
+		expectStart(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery(deleteUser).
+			WithArgs(int64(42)).
+			WillReturnRows(sqlmock.NewRows(piiColumnNames).AddRow(sealedPII(42, "ada@example.com", "Ada")...))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM relation_tuples")).
+			WithArgs("42").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM invitations WHERE accepted_user_id = $1 OR lower(email) = lower($2)")).
+			WithArgs(int64(42), "ada@example.com").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE audit_events")).
+			WithArgs("42", "users/42").
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectCommit()
+		finished := expectFinish(mock)
+
+		op, err := service.EraseUser(t.Context(), &privacypb.EraseUserRequest{UserId: 42})
+		require.NoError(t, err)
+		var md privacypb.UserDataOperationMetadata
+		require.NoError(t, op.Metadata.UnmarshalTo(&md))
+		assert.Equal(t, "erase", md.Verb)
+
+		require.NoError(t, service.operations.Wait(t.Context()))
+		require.NoError(t, mock.ExpectationsWereMet())
+		var resp privacypb.EraseUserResponse
+		require.NoError(t, finished.op.GetResponse().UnmarshalTo(&resp))
+		assert.Equal(t, int64(5), resp.RedactedAuditEventCount)
+	})
+
+	t.Run("error - already erased", func(t *testing.T) {
+		service, mock := newMockService(t)
+		expectStart(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery(deleteUser).WillReturnRows(sqlmock.NewRows(piiColumnNames))
+		mock.ExpectRollback()
+		finished := expectFinish(mock)
+
+		_, err := service.EraseUser(t.Context(), &privacypb.EraseUserRequest{UserId: 42})
+		require.NoError(t, err)
+		require.NoError(t, service.operations.Wait(t.Context()))
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, int32(codes.NotFound), finished.op.GetError().GetCode())
+	})
+
+	t.Run("error - delete fails and nothing is erased", func(t *testing.T) {
+		service, mock := newMockService(t)
+		expectStart(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery(deleteUser).WillReturnRows(sqlmock.NewRows(piiColumnNames).AddRow(sealedPII(42, "ada@example.com", "Ada")...))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM relation_tuples")).WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+		finished := expectFinish(mock)
+
+		_, err := service.EraseUser(t.Context(), &privacypb.EraseUserRequest{UserId: 42})
+		require.NoError(t, err)
+		require.NoError(t, service.operations.Wait(t.Context()))
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, int32(codes.Unknown), finished.op.GetError().GetCode())
+	})
+}
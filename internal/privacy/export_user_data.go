@@ -0,0 +1,208 @@
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"github.com/zcking/go-api-template/internal/jobs"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exportQueries select everything stored about a user besides the user
+// itself, one JSON object per row. Each query takes the user's id as $1;
+// byEmail queries also take the user's email as $2. Secrets (token and code
+// hashes) are not exported.
+var exportQueries = []struct {
+	recordType string
+	query      string
+	byEmail    bool
+}{
+	{"group_membership", `SELECT row_to_json(t) FROM (
+		SELECT m.group_id, g.name AS group_name, m.created_at
+		FROM group_members m JOIN groups g ON g.id = m.group_id
+		WHERE m.user_id = $1 ORDER BY m.id) t`, false},
+	{"relationship", `SELECT row_to_json(t) FROM (
+		SELECT object_type, object_id, relation, subject_type, subject_id, subject_relation, created_at
+		FROM relation_tuples
+		WHERE (subject_type = 'user' AND subject_id = $1) OR (object_type = 'user' AND object_id = $1)
+		ORDER BY id) t`, false},
+	{"invitation", `SELECT row_to_json(t) FROM (
+		SELECT id, email, name, group_ids, send_count, created_at, expires_at, accepted_at, revoked_at
+		FROM invitations
+		WHERE accepted_user_id = $1 OR lower(email) = lower($2)
+		ORDER BY id) t`, true},
+	{"sign_in", `SELECT row_to_json(t) FROM (
+		SELECT client_id, scope, auth_time FROM oidc_authorization_codes
+		WHERE user_id = $1 AND used_at IS NOT NULL ORDER BY auth_time) t`, false},
+	{"audit_event", `SELECT row_to_json(t) FROM (
+		SELECT id, created_at, actor, method, resource, outcome, source_ip FROM audit_events
+		WHERE actor = $1 OR resource = 'users/' || $1 OR resource LIKE '%/users/' || $1
+		ORDER BY id) t`, false},
+}
+
+// exportRecord is one line of an export
+type exportRecord struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// exportedUser is the user record of an export
+type exportedUser struct {
+	ID         int64     `json:"id"`
+	Email      string    `json:"email"`
+	Name       string    `json:"name"`
+	ExternalID *string   `json:"external_id"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ExportUserData starts collecting everything stored about a user into an
+// export that can be downloaded with DownloadUserDataExport
+func (s *Service) ExportUserData(ctx context.Context, req *privacypb.ExportUserDataRequest) (*longrunningpb.Operation, error) {
+	userID := req.GetUserId()
+	return s.start(ctx, userID, "export", func(ctx context.Context) (proto.Message, error) {
+		return s.exportUserData(ctx, userID)
+	})
+}
+
+// exportUserData builds and stores the export of a user. All rows are read
+// from one snapshot so the export is consistent.
+func (s *Service) exportUserData(ctx context.Context, userID int64) (_ *privacypb.ExportUserDataResponse, err error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	user, err := s.exportUser(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var bundle bytes.Buffer
+	if err = writeRecord(&bundle, "user", user); err != nil {
+		return nil, err
+	}
+	count := 1
+	id := strconv.FormatInt(userID, 10)
+	for _, q := range exportQueries {
+		args := []any{id}
+		if q.byEmail {
+			args = append(args, user.Email)
+		}
+		n, err := writeRecords(ctx, tx, &bundle, q.recordType, q.query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("export %s records: %w", q.recordType, err)
+		}
+		count += n
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	expires := now.Add(exportTTL)
+	var exportID int64
+	err = s.db.QueryRowContext(ctx,
+		`INSERT INTO user_data_exports (user_id, bundle, record_count, created_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		userID, bundle.Bytes(), count, now, expires).Scan(&exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "exported user data", "user_id", userID, "export_id", exportID, "records", count)
+	return &privacypb.ExportUserDataResponse{
+		Export:      fmt.Sprintf("%s/exports/%d", userResource(userID), exportID),
+		RecordCount: int64(count),
+		SizeBytes:   int64(bundle.Len()),
+		ExpireTime:  timestamppb.New(expires),
+	}, nil
+}
+
+// exportUser reads the user record of an export, decrypting the email and name
+func (s *Service) exportUser(ctx context.Context, tx *sql.Tx, userID int64) (*exportedUser, error) {
+	var user exportedUser
+	var externalID sql.NullString
+	var pii users.PII
+	dest := append([]any{&user.ID, &externalID, &user.Active, &user.CreatedAt, &user.UpdatedAt}, pii.Dest()...)
+	err := tx.QueryRowContext(ctx,
+		"SELECT id, external_id, active, created_at, updated_at, "+users.PIIColumns+" FROM users WHERE id = $1",
+		userID).Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "user %d not found", userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if externalID.Valid {
+		user.ExternalID = &externalID.String
+	}
+	if user.Email, user.Name, err = s.users.OpenPII(user.ID, &pii); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// writeRecord appends one line holding data to bundle
+func writeRecord(bundle *bytes.Buffer, recordType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(exportRecord{Type: recordType, Data: raw})
+	if err != nil {
+		return err
+	}
+	bundle.Write(line)
+	bundle.WriteByte('\n')
+	return nil
+}
+
+// writeRecords appends one line to bundle for each row of query
+func writeRecords(ctx context.Context, tx *sql.Tx, bundle *bytes.Buffer, recordType, query string, args ...any) (int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return 0, err
+		}
+		if err := writeRecord(bundle, recordType, json.RawMessage(data)); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// PurgeExpiredExportsJob is the background job that runs
+// PurgeExpiredExports. It takes no arguments.
+const PurgeExpiredExportsJob jobs.Kind[struct{}] = "privacy.purge_expired_exports"
+
+// PurgeExpiredExports deletes exports that can no longer be downloaded. It is
+// meant to be called periodically, through PurgeExpiredExportsJob.
+func (s *Service) PurgeExpiredExports(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM user_data_exports WHERE expires_at < $1", s.now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
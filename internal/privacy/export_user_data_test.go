@@ -0,0 +1,152 @@
+package privacy
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+)
+
+// capturedBundle records the bundle passed as a query argument
+type capturedBundle struct{ value []byte }
+
+func (c *capturedBundle) Match(v driver.Value) bool {
+	c.value, _ = v.([]byte)
+	return true
+}
+
+func TestService_ExportUserData(t *testing.T) {
+	records := map[string][]string{
+		"group_membership": {`{"group_id": 1, "group_name": "eng"}`, `{"group_id": 2, "group_name": "ops"}`},
+		"invitation":       {`{"id": 3, "email": "Ada@example.com"}`},
+		"sign_in":          {`{"client_id": "my-app"}`},
+	}
+	selectUser := regexp.QuoteMeta("SELECT id, external_id, active, created_at, updated_at, " + users.PIIColumns + " FROM users WHERE id = $1")
+	userColumns := append([]string{"id", "external_id", "active", "created_at", "updated_at"}, piiColumnNames...)
+	expectUser := func(mock sqlmock.Sqlmock) {
+		row := append([]driver.Value{42, "00u1", true, testNow, testNow}, sealedPII(42, "ada@example.com", "Ada")...)
+		mock.ExpectQuery(selectUser).
+			WithArgs(int64(42)).
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow(row...))
+	}
+	expectQueries := func(mock sqlmock.Sqlmock, records map[string][]string) {
+		for _, q := range exportQueries {
+			rows := sqlmock.NewRows([]string{"row_to_json"})
+			for _, r := range records[q.recordType] {
+				rows.AddRow([]byte(r))
+			}
+			args := []driver.Value{"42"}
+			if q.byEmail {
+				args = append(args, "ada@example.com")
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(q.query)).WithArgs(args...).WillReturnRows(rows)
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		service, mock := newMockService(t)
+		expectStart(mock)
+		mock.ExpectBegin()
+		expectUser(mock)
+		expectQueries(mock, records)
+		mock.ExpectCommit()
+		bundle := &capturedBundle{}
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO user_data_exports")).
+			WithArgs(int64(42), bundle, 5, testNow, testNow.Add(exportTTL)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		finished := expectFinish(mock)
+
+		op, err := service.ExportUserData(t.Context(), &privacypb.ExportUserDataRequest{UserId: 42})
+		require.NoError(t, err)
+		assert.False(t, op.Done)
+		var md privacypb.UserDataOperationMetadata
+		require.NoError(t, op.Metadata.UnmarshalTo(&md))
+		assert.Equal(t, "users/42", md.Target)
+		assert.Equal(t, "export", md.Verb)
+
+		require.NoError(t, service.operations.Wait(t.Context()))
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		var resp privacypb.ExportUserDataResponse
+		require.NoError(t, finished.op.GetResponse().UnmarshalTo(&resp))
+		assert.Equal(t, "users/42/exports/7", resp.Export)
+		assert.Equal(t, int64(5), resp.RecordCount)
+		assert.Equal(t, int64(len(bundle.value)), resp.SizeBytes)
+
+		// One JSON record per line, in query order
+		var records []exportRecord
+		scanner := bufio.NewScanner(bytes.NewReader(bundle.value))
+		for scanner.Scan() {
+			var record exportRecord
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			records = append(records, record)
+		}
+		require.Len(t, records, 5)
+		var types []string
+		for _, r := range records {
+			types = append(types, r.Type)
+		}
+		assert.Equal(t, []string{"user", "group_membership", "group_membership", "invitation", "sign_in"}, types)
+
+		// The user is exported decrypted
+		var user exportedUser
+		require.NoError(t, json.Unmarshal(records[0].Data, &user))
+		assert.Equal(t, int64(42), user.ID)
+		assert.Equal(t, "ada@example.com", user.Email)
+		assert.Equal(t, "Ada", user.Name)
+		assert.Equal(t, "00u1", *user.ExternalID)
+	})
+
+	t.Run("error - user deleted before the export ran", func(t *testing.T) {
+		service, mock := newMockService(t)
+		expectStart(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectUser).WillReturnRows(sqlmock.NewRows(userColumns))
+		mock.ExpectRollback()
+		finished := expectFinish(mock)
+
+		_, err := service.ExportUserData(t.Context(), &privacypb.ExportUserDataRequest{UserId: 42})
+		require.NoError(t, err)
+		require.NoError(t, service.operations.Wait(t.Context()))
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, int32(codes.NotFound), finished.op.GetError().GetCode())
+	})
+
+	t.Run("error - query fails", func(t *testing.T) {
+		service, mock := newMockService(t)
+		expectStart(mock)
+		mock.ExpectBegin()
+		expectUser(mock)
+		mock.ExpectQuery(regexp.QuoteMeta(exportQueries[0].query)).WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+		finished := expectFinish(mock)
+
+		_, err := service.ExportUserData(t.Context(), &privacypb.ExportUserDataRequest{UserId: 42})
+		require.NoError(t, err)
+		require.NoError(t, service.operations.Wait(t.Context()))
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Contains(t, finished.op.GetError().GetMessage(), "export group_membership records")
+	})
+}
+
+func TestService_PurgeExpiredExports(t *testing.T) {
+	service, mock := newMockService(t)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user_data_exports WHERE expires_at < $1")).
+		WithArgs(testNow).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := service.PurgeExpiredExports(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/operations"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exportTTL is how long a finished export can be downloaded
+const exportTTL = 7 * 24 * time.Hour
+
+// Service handles gRPC requests for data subject exports and erasure
+type Service struct {
+	privacypb.UnimplementedPrivacyServiceServer
+	db         *sql.DB
+	operations *operations.Service
+	users      *users.Service
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// NewService creates a new privacy service using an existing database
+// connection. Exports and erasures run as operations of ops; users' emails
+// and names are decrypted with usersService.
+func NewService(db *sql.DB, ops *operations.Service, usersService *users.Service, logger *slog.Logger) *Service {
+	return &Service{
+		db:         db,
+		operations: ops,
+		users:      usersService,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// userResource returns the resource name of a user
+func userResource(userID int64) string {
+	return fmt.Sprintf("users/%d", userID)
+}
+
+// start creates an operation acting on a user and runs work in the
+// background. The operation is committed together with the audit event of
+// the request that starts it.
+func (s *Service) start(ctx context.Context, userID int64, verb string, work func(ctx context.Context) (proto.Message, error)) (_ *longrunningpb.Operation, err error) {
+	if userID <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx, &err)
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = $1", userID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "user %d not found", userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	target := userResource(userID)
+	op, err := s.operations.Create(ctx, tx, &privacypb.UserDataOperationMetadata{
+		Target:     target,
+		Verb:       verb,
+		CreateTime: timestamppb.New(s.now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, target); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.operations.Run(ctx, op, work)
+	return op, nil
+}
+
+// rollback rolls back tx if err is set. It is meant to be deferred with a
+// pointer to the caller's named error result.
+func rollback(tx *sql.Tx, err *error) {
+	if *err != nil {
+		_ = tx.Rollback()
+	}
+}
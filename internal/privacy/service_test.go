@@ -0,0 +1,129 @@
+package privacy
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	privacypb "github.com/zcking/go-api-template/gen/go/privacy/v1"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/operations"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testKeyring encrypts the users of services under test
+var testKeyring = func() *encryption.Keyring {
+	k, err := encryption.NewKeyring("1", map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return k
+}()
+
+// piiColumnNames are the column names of users.PIIColumns
+var piiColumnNames = []string{"email", "name", "email_ciphertext", "name_ciphertext", "data_key", "key_version", "pii_bound"}
+
+// sealedPII returns the values of users.PIIColumns for encrypted user id
+func sealedPII(id int64, email, name string) []driver.Value {
+	e, err := testKeyring.Seal(strconv.FormatInt(id, 10), email, name)
+	if err != nil {
+		panic(err)
+	}
+	return []driver.Value{nil, nil, e.Values[0], e.Values[1], e.DataKey, e.KeyVersion, true}
+}
+
+// newMockService creates a Service backed by go-sqlmock whose clock is fixed
+// at testNow. Its operations share the mocked database.
+func newMockService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	service := NewService(db, operations.NewService(db, 1, logger), users.NewServiceFromDB(db, testKeyring, logger), logger)
+	service.now = func() time.Time { return testNow }
+	return service, mock
+}
+
+// expectStart expects an operation on user 42 to be created
+func expectStart(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM users WHERE id = $1")).
+		WithArgs(int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO operations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+// finishedOperation decodes the operation stored when an operation finishes
+type finishedOperation struct{ op *longrunningpb.Operation }
+
+func (f *finishedOperation) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	f.op = &longrunningpb.Operation{}
+	return proto.Unmarshal(b, f.op) == nil
+}
+
+// expectFinish expects an operation to finish and returns its stored result
+func expectFinish(mock sqlmock.Sqlmock) *finishedOperation {
+	finished := &finishedOperation{}
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE operations SET done = true")).
+		WithArgs(sqlmock.AnyArg(), finished, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	return finished
+}
+
+func TestService_Start(t *testing.T) {
+	t.Run("error - user not found", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM users WHERE id = $1")).
+			WillReturnRows(sqlmock.NewRows([]string{"?column?"}))
+		mock.ExpectRollback()
+
+		_, err := service.EraseUser(t.Context(), &privacypb.EraseUserRequest{UserId: 42})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - missing user id", func(t *testing.T) {
+		service, mock := newMockService(t)
+
+		_, err := service.ExportUserData(t.Context(), &privacypb.ExportUserDataRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - operation not created", func(t *testing.T) {
+		service, mock := newMockService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM users WHERE id = $1")).
+			WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO operations").WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := service.EraseUser(t.Context(), &privacypb.EraseUserRequest{UserId: 42})
+		assert.Error(t, err)
+		require.NoError(t, service.operations.Wait(t.Context()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
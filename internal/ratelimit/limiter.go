@@ -0,0 +1,68 @@
+// Package ratelimit bounds the rate of API requests the server accepts.
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limiter rejects calls beyond a rate shared by all clients with
+// ResourceExhausted. Its limit can change while the server runs.
+type Limiter struct {
+	limiter atomic.Pointer[rate.Limiter]
+}
+
+// NewLimiter creates a limiter accepting perSecond calls per second, and
+// burst calls at once above that. See SetLimit.
+func NewLimiter(perSecond, burst int) *Limiter {
+	l := &Limiter{}
+	l.SetLimit(perSecond, burst)
+	return l
+}
+
+// SetLimit replaces the limit. A perSecond of 0 accepts every call; a burst
+// of 0 is the same as perSecond. Calls already accepted are not counted
+// against the new limit.
+func (l *Limiter) SetLimit(perSecond, burst int) {
+	if perSecond <= 0 {
+		l.limiter.Store(nil)
+		return
+	}
+	if burst <= 0 {
+		burst = perSecond
+	}
+	l.limiter.Store(rate.NewLimiter(rate.Limit(perSecond), burst))
+}
+
+// allow reports whether a call may proceed now
+func (l *Limiter) allow() bool {
+	limiter := l.limiter.Load()
+	return limiter == nil || limiter.Allow()
+}
+
+// UnaryServerInterceptor returns an interceptor that rejects unary calls
+// beyond the limit
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !l.allow() {
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded; retry later")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that rejects streams beyond
+// the limit. A stream counts once, however many messages it carries.
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !l.allow() {
+			return status.Error(codes.ResourceExhausted, "rate limit exceeded; retry later")
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// call makes a unary call through the limiter's interceptor
+func call(l *Limiter) error {
+	_, err := l.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/ListUsers"},
+		func(ctx context.Context, req any) (any, error) { return "ok", nil })
+	return err
+}
+
+func TestLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		perSecond int
+		burst     int
+		accepted  int
+	}{
+		{name: "unlimited", perSecond: 0, burst: 0, accepted: 20},
+		{name: "burst", perSecond: 1, burst: 3, accepted: 3},
+		{name: "burst defaults to the rate", perSecond: 5, burst: 0, accepted: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.perSecond, tt.burst)
+			accepted := 0
+			for range 20 {
+				err := call(l)
+				if err == nil {
+					accepted++
+					continue
+				}
+				assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			}
+			assert.Equal(t, tt.accepted, accepted)
+		})
+	}
+}
+
+func TestLimiter_SetLimit(t *testing.T) {
+	l := NewLimiter(1, 1)
+	assert.NoError(t, call(l))
+	assert.Error(t, call(l))
+
+	l.SetLimit(0, 0)
+	assert.NoError(t, call(l), "removing the limit takes effect immediately")
+
+	l.SetLimit(1, 2)
+	assert.NoError(t, call(l))
+	assert.NoError(t, call(l))
+	assert.Error(t, call(l))
+}
+
+func TestLimiter_StreamServerInterceptor(t *testing.T) {
+	l := NewLimiter(1, 1)
+	interceptor := l.StreamServerInterceptor()
+	handler := func(srv any, stream grpc.ServerStream) error { return nil }
+
+	assert.NoError(t, interceptor(nil, nil, &grpc.StreamServerInfo{}, handler))
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
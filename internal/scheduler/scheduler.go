@@ -0,0 +1,218 @@
+// Package scheduler runs periodic tasks on cron schedules. Every instance of
+// the server runs the same schedules; at each scheduled time the instances
+// race for a Postgres advisory lock on the task, and the winner runs it and
+// records the run in task_runs. A run is recorded once per scheduled time,
+// so a task runs exactly once even if the instances' clocks disagree.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// historyRetention is how long runs are kept in task_runs
+const historyRetention = 30 * 24 * time.Hour
+
+// task is a registered task
+type task struct {
+	name     string
+	schedule cron.Schedule
+	run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered tasks on their schedules
+type Scheduler struct {
+	db       *sql.DB
+	logger   *slog.Logger
+	now      func() time.Time
+	instance string
+	tasks    []task
+
+	stop    chan struct{}
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler using an existing database connection
+func NewScheduler(db *sql.DB, logger *slog.Logger) *Scheduler {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &Scheduler{
+		db:       db,
+		logger:   logger,
+		now:      time.Now,
+		instance: instance,
+	}
+}
+
+// Register adds a task that runs on spec, a standard five-field cron
+// expression (minute hour day-of-month month day-of-week) or a descriptor
+// such as @hourly or @every 5m, evaluated in UTC unless it starts with
+// CRON_TZ=. An @every interval runs at multiples of the interval rather than
+// relative to when the instance started, so that every instance computes the
+// same scheduled times. Tasks must be registered before Start.
+func (s *Scheduler) Register(name, spec string, run func(ctx context.Context) error) error {
+	if !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = "CRON_TZ=UTC " + spec
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for task %q: %w", name, err)
+	}
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		schedule = alignedSchedule{every.Delay}
+	}
+	s.tasks = append(s.tasks, task{name: name, schedule: schedule, run: run})
+	return nil
+}
+
+// Start starts running the registered tasks
+func (s *Scheduler) Start() {
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.stop = make(chan struct{})
+	for _, t := range s.tasks {
+		s.running.Add(1)
+		go s.loop(ctx, t)
+	}
+}
+
+// Stop stops scheduling tasks and waits for running ones to finish. If ctx
+// ends first, running tasks are canceled; Stop still waits for them to return.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+	close(s.stop)
+	stopped := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+// loop runs t at each of its scheduled times until the scheduler is stopped.
+// Times that pass while t is running are skipped.
+func (s *Scheduler) loop(ctx context.Context, t task) {
+	defer s.running.Done()
+	for {
+		next := t.schedule.Next(s.now())
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := s.runOnce(ctx, t, next); err != nil {
+			s.logger.Error("failed to run scheduled task", "task", t.name, "scheduled_at", next, "error", err)
+		}
+	}
+}
+
+// alignedSchedule runs every delay, at multiples of delay since the zero
+// time. cron.ConstantDelaySchedule counts from the time it is given instead,
+// which differs between instances, so they would never agree on a run.
+type alignedSchedule struct {
+	delay time.Duration
+}
+
+// Next returns the first multiple of the delay after t
+func (a alignedSchedule) Next(t time.Time) time.Time {
+	return t.Truncate(a.delay).Add(a.delay)
+}
+
+// lockKey is the advisory lock key of the task named name
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + name))
+	return int64(h.Sum64())
+}
+
+// runOnce runs t for its scheduled time if this instance is elected to: it
+// must take the task's advisory lock and be the first to record a run for
+// scheduledAt. The lock is held on a dedicated connection until the run is
+// recorded as finished.
+func (s *Scheduler) runOnce(ctx context.Context, t task, scheduledAt time.Time) error {
+	dbCtx := context.WithoutCancel(ctx)
+	conn, err := s.db.Conn(dbCtx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := lockKey(t.name)
+	var locked bool
+	if err := conn.QueryRowContext(dbCtx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		s.logger.Debug("scheduled task is running on another instance", "task", t.name, "scheduled_at", scheduledAt)
+		return nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(dbCtx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			s.logger.Error("failed to release scheduled task lock", "task", t.name, "error", err)
+		}
+	}()
+
+	var runID int64
+	err = conn.QueryRowContext(dbCtx, `INSERT INTO task_runs (task, scheduled_at, started_at, status, instance)
+		VALUES ($1, $2, $3, 'running', $4)
+		ON CONFLICT (task, scheduled_at) DO NOTHING
+		RETURNING id`,
+		t.name, scheduledAt, s.now(), s.instance).Scan(&runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.Debug("scheduled task already ran", "task", t.name, "scheduled_at", scheduledAt)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	runErr := call(ctx, t.run)
+	status, errText := "succeeded", sql.NullString{}
+	if runErr != nil {
+		status, errText = "failed", sql.NullString{String: runErr.Error(), Valid: true}
+		s.logger.Error("scheduled task failed", "task", t.name, "scheduled_at", scheduledAt, "error", runErr)
+	}
+	if _, err := conn.ExecContext(dbCtx,
+		"UPDATE task_runs SET finished_at = $2, status = $3, error = $4 WHERE id = $1",
+		runID, s.now(), status, errText); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(dbCtx, "DELETE FROM task_runs WHERE task = $1 AND scheduled_at < $2",
+		t.name, scheduledAt.Add(-historyRetention))
+	return err
+}
+
+// call runs a task, turning a panic into an error
+func call(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newMockScheduler creates a Scheduler backed by go-sqlmock whose clock is
+// fixed at testNow
+func newMockScheduler(t *testing.T) (*Scheduler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	s := NewScheduler(db, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	s.now = func() time.Time { return testNow }
+	s.instance = "pod-1"
+	return s, mock
+}
+
+var (
+	tryLock   = regexp.QuoteMeta("SELECT pg_try_advisory_lock($1)")
+	unlock    = regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")
+	insertRun = regexp.QuoteMeta("INSERT INTO task_runs (task, scheduled_at, started_at, status, instance)")
+	finishRun = regexp.QuoteMeta("UPDATE task_runs SET finished_at = $2, status = $3, error = $4 WHERE id = $1")
+	purgeRuns = regexp.QuoteMeta("DELETE FROM task_runs WHERE task = $1 AND scheduled_at < $2")
+)
+
+func TestScheduler_RunOnce(t *testing.T) {
+	key := lockKey("purge")
+	scheduledAt := testNow.Add(-time.Second)
+
+	tests := []struct {
+		name      string
+		run       func(ctx context.Context) error
+		mockSetup func(sqlmock.Sqlmock)
+		wantRun   bool
+	}{
+		{
+			name: "success",
+			run:  func(ctx context.Context) error { return nil },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WithArgs(key).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(insertRun).
+					WithArgs("purge", scheduledAt, testNow, "pod-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectExec(finishRun).WithArgs(int64(5), testNow, "succeeded", sql.NullString{}).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(purgeRuns).WithArgs("purge", scheduledAt.Add(-historyRetention)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(unlock).WithArgs(key).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantRun: true,
+		},
+		{
+			name: "failure is recorded",
+			run:  func(ctx context.Context) error { return errors.New("purge failed") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(insertRun).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectExec(finishRun).
+					WithArgs(int64(5), testNow, "failed", sql.NullString{String: "purge failed", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(purgeRuns).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(unlock).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantRun: true,
+		},
+		{
+			name: "panic is recorded as a failure",
+			run:  func(ctx context.Context) error { panic("boom") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(insertRun).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectExec(finishRun).
+					WithArgs(int64(5), testNow, "failed", sql.NullString{String: "task panicked: boom", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(purgeRuns).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(unlock).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantRun: true,
+		},
+		{
+			name: "another instance holds the lock",
+			run:  func(ctx context.Context) error { return nil },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+			},
+		},
+		{
+			name: "another instance already ran it",
+			run:  func(ctx context.Context) error { return nil },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(insertRun).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec(unlock).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newMockScheduler(t)
+			tt.mockSetup(mock)
+
+			ran := false
+			err := s.runOnce(t.Context(), task{name: "purge", run: func(ctx context.Context) error {
+				ran = true
+				return tt.run(ctx)
+			}}, scheduledAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, ran)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("error - lock query fails", func(t *testing.T) {
+		s, mock := newMockScheduler(t)
+		mock.ExpectQuery(tryLock).WillReturnError(errors.New("database error"))
+
+		err := s.runOnce(t.Context(), task{name: "purge", run: func(ctx context.Context) error {
+			t.Fatal("the task must not run")
+			return nil
+		}}, scheduledAt)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s, _ := newMockScheduler(t)
+	require.NoError(t, s.Register("hourly", "0 * * * *", nil))
+	require.NoError(t, s.Register("every", "@every 5m", nil))
+	require.NoError(t, s.Register("zoned", "CRON_TZ=America/New_York 0 9 * * *", nil))
+	assert.Error(t, s.Register("invalid", "every hour", nil))
+
+	require.Len(t, s.tasks, 3)
+	assert.Equal(t, time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC), s.tasks[0].schedule.Next(testNow))
+	assert.Equal(t, testNow.Add(5*time.Minute), s.tasks[1].schedule.Next(testNow))
+	assert.Equal(t, testNow.Add(5*time.Minute), s.tasks[1].schedule.Next(testNow.Add(4*time.Minute+59*time.Second)))
+	assert.Equal(t, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), s.tasks[2].schedule.Next(testNow).UTC())
+}
+
+func TestScheduler_EveryAcrossInstances(t *testing.T) {
+	// Two instances started at different times agree on the scheduled times
+	// of an @every task, so the second finds the run already recorded
+	var scheduled []time.Time
+	for _, started := range []time.Time{testNow.Add(7 * time.Second), testNow.Add(2*time.Minute + 42*time.Second)} {
+		s, mock := newMockScheduler(t)
+		s.now = func() time.Time { return started }
+		require.NoError(t, s.Register("purge", "@every 5m", func(ctx context.Context) error { return nil }))
+
+		next := s.tasks[0].schedule.Next(s.now())
+		mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery(insertRun).
+			WithArgs("purge", testNow.Add(5*time.Minute), started, "pod-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec(unlock).WillReturnResult(sqlmock.NewResult(0, 0))
+		require.NoError(t, s.runOnce(t.Context(), s.tasks[0], next))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		scheduled = append(scheduled, next)
+	}
+	assert.Equal(t, scheduled[0], scheduled[1])
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	t.Run("runs tasks on schedule", func(t *testing.T) {
+		s, mock := newMockScheduler(t)
+		s.now = time.Now
+		ran := make(chan struct{})
+		require.NoError(t, s.Register("purge", "@every 1s", func(ctx context.Context) error {
+			close(ran)
+			return nil
+		}))
+		mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery(insertRun).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(finishRun).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(purgeRuns).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(unlock).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		s.Start()
+		select {
+		case <-ran:
+		case <-time.After(5 * time.Second):
+			t.Fatal("task did not run")
+		}
+		require.NoError(t, s.Stop(t.Context()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cancels running tasks when the deadline passes", func(t *testing.T) {
+		s, mock := newMockScheduler(t)
+		s.now = time.Now
+		started := make(chan struct{})
+		require.NoError(t, s.Register("purge", "@every 1s", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+		mock.ExpectQuery(tryLock).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery(insertRun).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(finishRun).
+			WithArgs(int64(5), sqlmock.AnyArg(), "failed", sql.NullString{String: "context canceled", Valid: true}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(purgeRuns).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(unlock).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		s.Start()
+		<-started
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
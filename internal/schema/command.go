@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// Usage describes the migrate subcommand
+const Usage = `usage: migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down N      roll back the last N migrations
+  goto V      migrate up or down to version V
+  force V     mark version V as applied and clear the dirty flag, without
+              running migrations (-1 marks no migration applied)
+  version     print the applied version
+  status      list migrations and whether each is applied`
+
+// Run runs the migrate subcommand described by args, writing its output to w
+func (m *Migrator) Run(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", Usage)
+	}
+	command, args := args[0], args[1:]
+	wantArgs := map[string]int{"up": 0, "down": 1, "goto": 1, "force": 1, "version": 0, "status": 0}
+	n, ok := wantArgs[command]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", command, Usage)
+	}
+	if len(args) != n {
+		return fmt.Errorf("%s takes %d argument(s), got %d\n%s", command, n, len(args), Usage)
+	}
+
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		steps, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid number of migrations %q", args[0])
+		}
+		return m.Down(ctx, steps)
+	case "goto":
+		version, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		if !slices.Contains(m.versions, uint(version)) {
+			return fmt.Errorf("no migration has version %d", version)
+		}
+		return m.Goto(ctx, uint(version))
+	case "force":
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		if version != -1 && !slices.Contains(m.versions, uint(version)) {
+			return fmt.Errorf("no migration has version %d", version)
+		}
+		return m.Force(ctx, version)
+	case "version":
+		version, dirty, err := m.Version()
+		if err != nil {
+			return err
+		}
+		if dirty {
+			fmt.Fprintf(w, "%d (dirty)\n", version)
+		} else {
+			fmt.Fprintln(w, version)
+		}
+		return nil
+	default:
+		return m.status(w)
+	}
+}
+
+// status prints each migration and whether it is applied
+func (m *Migrator) status(w io.Writer) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	for _, v := range m.versions {
+		state := "pending"
+		switch {
+		case v == version && dirty:
+			state = "dirty"
+		case v <= version:
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%6d  %s\n", v, state)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		engine      *fakeEngine
+		args        []string
+		locks       bool
+		wantCalls   []string
+		wantOutput  string
+		wantErr     string
+		wantVersion uint
+	}{
+		{name: "up", engine: &fakeEngine{version: 1}, args: []string{"up"}, locks: true, wantCalls: []string{"up"}, wantVersion: 3},
+		{name: "down", engine: &fakeEngine{version: 3}, args: []string{"down", "1"}, locks: true, wantCalls: []string{"steps"}, wantVersion: 2},
+		{name: "goto", engine: &fakeEngine{version: 3}, args: []string{"goto", "1"}, locks: true, wantCalls: []string{"migrate"}, wantVersion: 1},
+		{name: "force", engine: &fakeEngine{version: 2, dirty: true}, args: []string{"force", "2"}, locks: true, wantCalls: []string{"force"}, wantVersion: 2},
+		{name: "version", engine: &fakeEngine{version: 2}, args: []string{"version"}, wantOutput: "2\n", wantVersion: 2},
+		{name: "dirty version", engine: &fakeEngine{version: 2, dirty: true}, args: []string{"version"}, wantOutput: "2 (dirty)\n", wantVersion: 2},
+		{
+			name:        "status",
+			engine:      &fakeEngine{version: 2, dirty: true},
+			args:        []string{"status"},
+			wantOutput:  "     1  applied\n     2  dirty\n     3  pending\n",
+			wantVersion: 2,
+		},
+		{name: "error - no command", engine: &fakeEngine{}, wantErr: "missing command"},
+		{name: "error - unknown command", engine: &fakeEngine{}, args: []string{"drop"}, wantErr: `unknown command "drop"`},
+		{name: "error - down without count", engine: &fakeEngine{version: 3}, args: []string{"down"}, wantErr: "down takes 1 argument(s), got 0", wantVersion: 3},
+		{name: "error - invalid count", engine: &fakeEngine{version: 3}, args: []string{"down", "all"}, wantErr: `invalid number of migrations "all"`, wantVersion: 3},
+		{name: "error - unknown version", engine: &fakeEngine{version: 3}, args: []string{"goto", "9"}, wantErr: "no migration has version 9", wantVersion: 3},
+		{name: "error - invalid forced version", engine: &fakeEngine{version: 3}, args: []string{"force", "-2"}, wantErr: `invalid version "-2"`, wantVersion: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, mock := newMockMigrator(t, tt.engine)
+			if tt.locks {
+				expectLock(mock)
+			}
+
+			var out bytes.Buffer
+			err := m.Run(t.Context(), tt.args, &out)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, tt.engine.calls)
+			assert.Equal(t, tt.wantOutput, out.String())
+			assert.Equal(t, tt.wantVersion, tt.engine.version)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package schema applies the SQL migrations embedded from migrations/ to the
+// database.
+// Every change to the schema takes a Postgres advisory lock first, so
+// replicas starting together and migration jobs never migrate concurrently,
+// and a schema left dirty by a failed migration is never migrated further
+// until it has been repaired and forced to a version by hand.
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgmigrate "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/zcking/go-api-template/migrations"
+)
+
+// lockKey is the advisory lock key held while the schema is changed
+const lockKey int64 = 0x676f5f6170695f6d // "go_api_m"
+
+// ErrDirty is returned when the last migration failed part way. The
+// database must be repaired by hand and the version set with Force.
+type ErrDirty struct {
+	Version uint
+}
+
+func (e ErrDirty) Error() string {
+	return fmt.Sprintf("database schema is dirty at version %d: a migration failed part way; repair the database by hand, then run `migrate force V` with the last fully applied version V", e.Version)
+}
+
+// ErrBehind is returned by CheckCurrent when migrations are pending
+type ErrBehind struct {
+	Version uint
+	Latest  uint
+}
+
+func (e ErrBehind) Error() string {
+	return fmt.Sprintf("database schema is at version %d but this build needs version %d; run `migrate up`", e.Version, e.Latest)
+}
+
+// engine is the part of *migrate.Migrate the Migrator uses
+type engine interface {
+	Up() error
+	Steps(n int) error
+	Migrate(version uint) error
+	Force(version int) error
+	Version() (version uint, dirty bool, err error)
+	Close() (source error, database error)
+}
+
+// Migrator applies migrations from a source to a database
+type Migrator struct {
+	db       *sql.DB
+	engine   engine
+	versions []uint
+	logger   *slog.Logger
+}
+
+// Source opens the migrations embedded in the binary or, if dir is set, the
+// migrations in dir, which lets them be edited during development without
+// rebuilding
+func Source(dir string) (source.Driver, error) {
+	if dir == "" {
+		return iofs.New(migrations.FS, ".")
+	}
+	return iofs.New(os.DirFS(dir), ".")
+}
+
+// NewMigrator creates a migrator applying the migrations in src to the
+// database connector connects to. The migrator closes src.
+func NewMigrator(src source.Driver, connector driver.Connector, logger *slog.Logger) (*Migrator, error) {
+	versions, err := sourceVersions(src)
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	db := sql.OpenDB(connector)
+	drv, err := pgmigrate.WithInstance(sql.OpenDB(connector), &pgmigrate.Config{})
+	if err != nil {
+		src.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to create migration instance: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", drv)
+	if err != nil {
+		src.Close()
+		drv.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to create migration instance: %w", err)
+	}
+	return &Migrator{db: db, engine: m, versions: versions, logger: logger}, nil
+}
+
+// sourceVersions lists the versions of the migrations in src in order
+func sourceVersions(src source.Driver) ([]uint, error) {
+	var versions []uint
+	v, err := src.First()
+	for err == nil {
+		versions = append(versions, v)
+		v, err = src.Next(v)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Close closes the migrator's database connections
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.engine.Close()
+	return errors.Join(srcErr, dbErr, m.db.Close())
+}
+
+// Latest returns the version of the newest migration, or 0 if there are none
+func (m *Migrator) Latest() uint {
+	if len(m.versions) == 0 {
+		return 0
+	}
+	return m.versions[len(m.versions)-1]
+}
+
+// Version returns the applied version and whether it is dirty. A database
+// without migrations is at version 0.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.engine.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.change(ctx, "up", true, m.engine.Up)
+}
+
+// Down rolls back the last n migrations
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+	return m.change(ctx, fmt.Sprintf("down %d", n), true, func() error { return m.engine.Steps(-n) })
+}
+
+// Goto migrates up or down to version
+func (m *Migrator) Goto(ctx context.Context, version uint) error {
+	return m.change(ctx, fmt.Sprintf("goto %d", version), true, func() error { return m.engine.Migrate(version) })
+}
+
+// Force sets the applied version and clears the dirty flag without running
+// any migration. It is how a dirty schema is marked repaired; -1 means no
+// migration is applied.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	return m.change(ctx, fmt.Sprintf("force %d", version), false, func() error { return m.engine.Force(version) })
+}
+
+// CheckCurrent returns ErrDirty or ErrBehind unless all migrations of this
+// build are applied. A schema that is ahead, because a newer release has
+// already migrated it, is current.
+func (m *Migrator) CheckCurrent() error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return ErrDirty{Version: version}
+	}
+	if version < m.Latest() {
+		return ErrBehind{Version: version, Latest: m.Latest()}
+	}
+	return nil
+}
+
+// change runs a schema change while holding the advisory lock. Unless it is
+// forced, the change is refused if the schema is dirty.
+func (m *Migrator) change(ctx context.Context, name string, refuseDirty bool, apply func() error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	m.logger.InfoContext(ctx, "waiting for the migration lock", "command", name)
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	defer func() {
+		if _, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release the migration lock: %w", unlockErr))
+		}
+	}()
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty && refuseDirty {
+		return ErrDirty{Version: version}
+	}
+	err = apply()
+	if errors.Is(err, migrate.ErrNoChange) {
+		m.logger.InfoContext(ctx, "database schema is up to date", "version", version)
+		return nil
+	}
+	var dirtyErr migrate.ErrDirty
+	if errors.As(err, &dirtyErr) {
+		return ErrDirty{Version: uint(dirtyErr.Version)}
+	}
+	if err != nil {
+		return fmt.Errorf("migrate %s failed: %w", name, err)
+	}
+	version, _, err = m.Version()
+	if err != nil {
+		return err
+	}
+	m.logger.InfoContext(ctx, "database schema migrated", "command", name, "version", version)
+	return nil
+}
@@ -0,0 +1,220 @@
+package schema
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine records the migrations it is asked to run against a version
+type fakeEngine struct {
+	version uint
+	dirty   bool
+	applied bool
+	calls   []string
+	err     error
+}
+
+func (e *fakeEngine) record(call string, version uint) error {
+	e.calls = append(e.calls, call)
+	if e.err != nil {
+		return e.err
+	}
+	if e.dirty {
+		return migrate.ErrDirty{Version: int(e.version)}
+	}
+	if e.version == version {
+		return migrate.ErrNoChange
+	}
+	e.version, e.applied = version, true
+	return nil
+}
+
+func (e *fakeEngine) Up() error                  { return e.record("up", 3) }
+func (e *fakeEngine) Steps(n int) error          { return e.record("steps", uint(int(e.version)+n)) }
+func (e *fakeEngine) Migrate(version uint) error { return e.record("migrate", version) }
+func (e *fakeEngine) Force(version int) error {
+	e.calls = append(e.calls, "force")
+	e.version, e.dirty, e.applied = uint(version), false, true
+	return nil
+}
+func (e *fakeEngine) Close() (error, error) { return nil, nil }
+func (e *fakeEngine) Version() (uint, bool, error) {
+	if e.version == 0 {
+		return 0, false, migrate.ErrNilVersion
+	}
+	return e.version, e.dirty, nil
+}
+
+// newMockMigrator creates a Migrator with migrations 1 to 3 whose engine is
+// fake and whose lock connection is backed by go-sqlmock
+func newMockMigrator(t *testing.T, engine *fakeEngine) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return &Migrator{
+		db:       db,
+		engine:   engine,
+		versions: []uint{1, 2, 3},
+		logger:   slog.New(slog.NewJSONHandler(io.Discard, nil)),
+	}, mock
+}
+
+var (
+	lock   = regexp.QuoteMeta("SELECT pg_advisory_lock($1)")
+	unlock = regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")
+)
+
+// expectLock expects the migration lock to be taken and released
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(lock).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(unlock).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_Change(t *testing.T) {
+	tests := []struct {
+		name        string
+		engine      *fakeEngine
+		change      func(*Migrator) error
+		wantVersion uint
+		wantApplied bool
+		wantErr     error
+	}{
+		{
+			name:        "up applies pending migrations",
+			engine:      &fakeEngine{version: 1},
+			change:      func(m *Migrator) error { return m.Up(t.Context()) },
+			wantVersion: 3,
+			wantApplied: true,
+		},
+		{
+			name:        "up on a current schema is a no-op",
+			engine:      &fakeEngine{version: 3},
+			change:      func(m *Migrator) error { return m.Up(t.Context()) },
+			wantVersion: 3,
+		},
+		{
+			name:        "down rolls back",
+			engine:      &fakeEngine{version: 3},
+			change:      func(m *Migrator) error { return m.Down(t.Context(), 2) },
+			wantVersion: 1,
+			wantApplied: true,
+		},
+		{
+			name:        "goto",
+			engine:      &fakeEngine{version: 3},
+			change:      func(m *Migrator) error { return m.Goto(t.Context(), 2) },
+			wantVersion: 2,
+			wantApplied: true,
+		},
+		{
+			name:        "error - up refuses a dirty schema",
+			engine:      &fakeEngine{version: 2, dirty: true},
+			change:      func(m *Migrator) error { return m.Up(t.Context()) },
+			wantVersion: 2,
+			wantErr:     ErrDirty{Version: 2},
+		},
+		{
+			name:        "force clears a dirty schema",
+			engine:      &fakeEngine{version: 2, dirty: true},
+			change:      func(m *Migrator) error { return m.Force(t.Context(), 1) },
+			wantVersion: 1,
+			wantApplied: true,
+		},
+		{
+			name:        "error - migration fails",
+			engine:      &fakeEngine{version: 1, err: errors.New("syntax error")},
+			change:      func(m *Migrator) error { return m.Up(t.Context()) },
+			wantVersion: 1,
+			wantErr:     errors.New("migrate up failed: syntax error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, mock := newMockMigrator(t, tt.engine)
+			expectLock(mock)
+
+			err := tt.change(m)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantVersion, tt.engine.version)
+			assert.Equal(t, tt.wantApplied, tt.engine.applied)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("error - lock fails and nothing runs", func(t *testing.T) {
+		engine := &fakeEngine{version: 1}
+		m, mock := newMockMigrator(t, engine)
+		mock.ExpectExec(lock).WillReturnError(errors.New("connection refused"))
+
+		assert.Error(t, m.Up(t.Context()))
+		assert.Empty(t, engine.calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - down needs a positive count", func(t *testing.T) {
+		m, _ := newMockMigrator(t, &fakeEngine{version: 3})
+		assert.Error(t, m.Down(t.Context(), 0))
+	})
+}
+
+func TestMigrator_CheckCurrent(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  *fakeEngine
+		wantErr error
+	}{
+		{name: "current", engine: &fakeEngine{version: 3}},
+		{name: "ahead of this build", engine: &fakeEngine{version: 4}},
+		{name: "behind", engine: &fakeEngine{version: 2}, wantErr: ErrBehind{Version: 2, Latest: 3}},
+		{name: "empty database", engine: &fakeEngine{}, wantErr: ErrBehind{Version: 0, Latest: 3}},
+		{name: "dirty", engine: &fakeEngine{version: 3, dirty: true}, wantErr: ErrDirty{Version: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newMockMigrator(t, tt.engine)
+			err := m.CheckCurrent()
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	embedded, err := Source("")
+	require.NoError(t, err)
+	defer embedded.Close()
+	onDisk, err := Source("../../migrations")
+	require.NoError(t, err)
+	defer onDisk.Close()
+
+	versions, err := sourceVersions(embedded)
+	require.NoError(t, err)
+	require.NotEmpty(t, versions)
+	assert.Equal(t, uint(1), versions[0])
+	assert.IsIncreasing(t, versions)
+
+	diskVersions, err := sourceVersions(onDisk)
+	require.NoError(t, err)
+	assert.Equal(t, versions, diskVersions)
+
+	_, err = Source("does-not-exist")
+	assert.Error(t, err)
+}
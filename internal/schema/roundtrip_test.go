@@ -0,0 +1,156 @@
+//go:build integration
+
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zcking/go-api-template/internal/database"
+)
+
+// startPostgres starts a throwaway Postgres server for the test and returns
+// its URL. The server's binaries are downloaded on first use and cached in
+// ~/.embedded-postgres-go.
+func startPostgres(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := uint32(l.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, l.Close())
+
+	config := embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V15).
+		Port(port).
+		Database("migrations").
+		RuntimePath(t.TempDir()).
+		Logger(io.Discard)
+	if testing.Verbose() {
+		config = config.Logger(os.Stderr)
+	}
+	pg := embeddedpostgres.NewDatabase(config)
+	require.NoError(t, pg.Start())
+	t.Cleanup(func() {
+		if err := pg.Stop(); err != nil {
+			t.Errorf("failed to stop Postgres: %v", err)
+		}
+	})
+	return config.GetConnectionURL() + "?sslmode=disable"
+}
+
+// snapshotQueries describe the public schema, excluding golang-migrate's
+// own table. Each row becomes one line of the snapshot.
+var snapshotQueries = []struct {
+	name  string
+	query string
+}{
+	{"table", `SELECT table_name, table_type FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_name <> 'schema_migrations'`},
+	{"column", `SELECT table_name, column_name, data_type, is_nullable, coalesce(column_default, '')
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name <> 'schema_migrations'`},
+	{"constraint", `SELECT tc.table_name, tc.constraint_name, tc.constraint_type, coalesce(cc.check_clause, '')
+		FROM information_schema.table_constraints tc
+		LEFT JOIN information_schema.check_constraints cc
+			ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+		WHERE tc.table_schema = 'public' AND tc.table_name <> 'schema_migrations'`},
+	{"key", `SELECT table_name, constraint_name, column_name, ordinal_position::text
+		FROM information_schema.key_column_usage
+		WHERE table_schema = 'public' AND table_name <> 'schema_migrations'`},
+	{"index", `SELECT tablename, indexname, indexdef FROM pg_indexes
+		WHERE schemaname = 'public' AND tablename <> 'schema_migrations'`},
+	{"sequence", `SELECT sequence_name, data_type, start_value, increment
+		FROM information_schema.sequences WHERE sequence_schema = 'public'`},
+	{"routine", `SELECT routine_name, routine_type, coalesce(routine_definition, '')
+		FROM information_schema.routines WHERE routine_schema = 'public'`},
+	{"trigger", `SELECT event_object_table, trigger_name, event_manipulation, action_timing, action_statement
+		FROM information_schema.triggers WHERE trigger_schema = 'public'`},
+}
+
+// snapshot returns a sorted, line-per-object description of the schema
+func snapshot(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var lines []string
+	for _, q := range snapshotQueries {
+		rows, err := db.Query(q.query)
+		require.NoError(t, err, q.name)
+		columns, err := rows.Columns()
+		require.NoError(t, err)
+		for rows.Next() {
+			values := make([]sql.NullString, len(columns))
+			dest := make([]any, len(columns))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			require.NoError(t, rows.Scan(dest...))
+			fields := []string{q.name}
+			for _, v := range values {
+				fields = append(fields, v.String)
+			}
+			lines = append(lines, strings.Join(fields, " | "))
+		}
+		require.NoError(t, rows.Err())
+		rows.Close()
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// TestMigrations_RoundTrip applies every migration up, down and up again,
+// checking after each step that down restores the schema from before the
+// migration and that applying it again reproduces the same schema. Run it
+// with `make test/migrations`.
+func TestMigrations_RoundTrip(t *testing.T) {
+	databaseURL := startPostgres(t)
+	src, err := Source("")
+	require.NoError(t, err)
+	connector, err := database.NewConnector(databaseURL, nil)
+	require.NoError(t, err)
+	m, err := NewMigrator(src, connector, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer m.Close()
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	empty := snapshot(t, db)
+	require.Empty(t, empty, "the database must start empty")
+
+	snapshots := []string{empty}
+	for _, version := range m.versions {
+		before := snapshots[len(snapshots)-1]
+
+		require.NoError(t, m.engine.Steps(1), "up %d", version)
+		after := snapshot(t, db)
+		require.NotEqual(t, before, after, "migration %d up does not change the schema", version)
+
+		require.NoError(t, m.engine.Steps(-1), "down %d", version)
+		require.Equal(t, before, snapshot(t, db), "migration %d down does not restore the schema from before its up", version)
+
+		require.NoError(t, m.engine.Steps(1), "up %d again", version)
+		require.Equal(t, after, snapshot(t, db), "migration %d up produces a different schema when applied again", version)
+
+		snapshots = append(snapshots, after)
+	}
+
+	t.Run("all the way down and up again", func(t *testing.T) {
+		latest := snapshots[len(snapshots)-1]
+		require.NoError(t, m.engine.Steps(-len(m.versions)))
+		require.Equal(t, empty, snapshot(t, db), "rolling back every migration leaves objects behind")
+		_, _, err := m.engine.Version()
+		require.ErrorIs(t, err, migrate.ErrNilVersion)
+
+		require.NoError(t, m.engine.Up())
+		require.Equal(t, latest, snapshot(t, db), fmt.Sprintf("re-applying every migration does not reproduce version %d", m.Latest()))
+	})
+}
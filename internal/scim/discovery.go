@@ -0,0 +1,191 @@
+package scim
+
+import (
+	"net/http"
+	"strings"
+)
+
+// schemaAttribute describes an attribute in a Schema resource (RFC 7643 section 7)
+type schemaAttribute struct {
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	MultiValued   bool              `json:"multiValued"`
+	Required      bool              `json:"required"`
+	CaseExact     bool              `json:"caseExact"`
+	Mutability    string            `json:"mutability"`
+	Returned      string            `json:"returned"`
+	Uniqueness    string            `json:"uniqueness"`
+	Description   string            `json:"description,omitempty"`
+	SubAttributes []schemaAttribute `json:"subAttributes,omitempty"`
+}
+
+// attr returns a single-valued, optional, read-write string attribute
+func attr(name, description string) schemaAttribute {
+	return schemaAttribute{
+		Name:        name,
+		Type:        "string",
+		Mutability:  "readWrite",
+		Returned:    "default",
+		Uniqueness:  "none",
+		Description: description,
+	}
+}
+
+// schemas are the Schema resources served by /Schemas
+var schemas = []map[string]any{
+	{
+		"schemas":     []string{SchemaSchema},
+		"id":          UserSchema,
+		"name":        "User",
+		"description": "User Account",
+		"attributes": []schemaAttribute{
+			func() schemaAttribute {
+				a := attr("userName", "Unique identifier for the user, stored as the user's email address.")
+				a.Required, a.Uniqueness = true, "server"
+				return a
+			}(),
+			{
+				Name: "name", Type: "complex", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+				Description: "The components of the user's name.",
+				SubAttributes: []schemaAttribute{
+					attr("formatted", "The full name."),
+					attr("givenName", "The given name."),
+					attr("familyName", "The family name."),
+				},
+			},
+			attr("displayName", "The name of the user, suitable for display."),
+			{
+				Name: "emails", Type: "complex", MultiValued: true, Mutability: "readOnly", Returned: "default", Uniqueness: "none",
+				Description: "Email addresses for the user. Reported from userName.",
+				SubAttributes: []schemaAttribute{
+					attr("value", "Email address."),
+					attr("type", "The type of email address."),
+					{Name: "primary", Type: "boolean", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+				},
+			},
+			{
+				Name: "active", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+				Description: "The user's administrative status.",
+			},
+		},
+		"meta": map[string]string{"resourceType": "Schema"},
+	},
+	{
+		"schemas":     []string{SchemaSchema},
+		"id":          GroupSchema,
+		"name":        "Group",
+		"description": "Group",
+		"attributes": []schemaAttribute{
+			func() schemaAttribute {
+				a := attr("displayName", "A human-readable name for the group.")
+				a.Required, a.Uniqueness = true, "server"
+				return a
+			}(),
+			{
+				Name: "members", Type: "complex", MultiValued: true, Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+				Description: "Direct members of the group. Only User members can be written; nested Group members are managed through the Groups API.",
+				SubAttributes: []schemaAttribute{
+					func() schemaAttribute {
+						a := attr("value", "Identifier of the member.")
+						a.Mutability = "immutable"
+						return a
+					}(),
+					attr("display", "Name of the member."),
+					attr("type", "\"User\" or \"Group\"."),
+				},
+			},
+		},
+		"meta": map[string]string{"resourceType": "Schema"},
+	},
+}
+
+// resourceTypes are the ResourceType resources served by /ResourceTypes
+var resourceTypes = []map[string]any{
+	{
+		"schemas":     []string{ResourceTypeSchema},
+		"id":          "User",
+		"name":        "User",
+		"endpoint":    "/Users",
+		"description": "User Account",
+		"schema":      UserSchema,
+		"meta":        map[string]string{"resourceType": "ResourceType"},
+	},
+	{
+		"schemas":     []string{ResourceTypeSchema},
+		"id":          "Group",
+		"name":        "Group",
+		"endpoint":    "/Groups",
+		"description": "Group",
+		"schema":      GroupSchema,
+		"meta":        map[string]string{"resourceType": "ResourceType"},
+	},
+}
+
+// getServiceProviderConfig handles GET /ServiceProviderConfig
+func (h *Handler) getServiceProviderConfig(w http.ResponseWriter, r *http.Request) error {
+	supported := func(ok bool) map[string]bool { return map[string]bool{"supported": ok} }
+	h.writeJSON(w, r, http.StatusOK, map[string]any{
+		"schemas":          []string{ServiceProviderConfigSchema},
+		"documentationUri": "https://github.com/zcking/go-api-template",
+		"patch":            supported(true),
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": MaxCount, "description": filterLimitations},
+		"changePassword":   supported(false),
+		"sort":             supported(false),
+		"etag":             supported(true),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with a static bearer token",
+			"primary":     true,
+		}},
+		"meta": map[string]string{
+			"resourceType": "ServiceProviderConfig",
+			"location":     h.config.BaseURL + "/ServiceProviderConfig",
+		},
+	})
+	return nil
+}
+
+// listSchemas handles GET /Schemas
+func (h *Handler) listSchemas(w http.ResponseWriter, r *http.Request) error {
+	h.writeJSON(w, r, http.StatusOK, newListResponse(len(schemas), 1, toAny(schemas)))
+	return nil
+}
+
+// getSchema handles GET /Schemas/{id}
+func (h *Handler) getSchema(w http.ResponseWriter, r *http.Request) error {
+	return h.writeByID(w, r, schemas)
+}
+
+// listResourceTypes handles GET /ResourceTypes
+func (h *Handler) listResourceTypes(w http.ResponseWriter, r *http.Request) error {
+	h.writeJSON(w, r, http.StatusOK, newListResponse(len(resourceTypes), 1, toAny(resourceTypes)))
+	return nil
+}
+
+// getResourceType handles GET /ResourceTypes/{id}
+func (h *Handler) getResourceType(w http.ResponseWriter, r *http.Request) error {
+	return h.writeByID(w, r, resourceTypes)
+}
+
+// writeByID writes the discovery resource whose id matches the {id} path value
+func (h *Handler) writeByID(w http.ResponseWriter, r *http.Request, resources []map[string]any) error {
+	id := r.PathValue("id")
+	for _, resource := range resources {
+		if strings.EqualFold(resource["id"].(string), id) {
+			h.writeJSON(w, r, http.StatusOK, resource)
+			return nil
+		}
+	}
+	return newError(http.StatusNotFound, "", "resource %q not found", id)
+}
+
+// toAny converts a slice of resources for a ListResponse
+func toAny(resources []map[string]any) []any {
+	out := make([]any, len(resources))
+	for i, resource := range resources {
+		out[i] = resource
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package scim
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ServiceProviderConfig(t *testing.T) {
+	h, _ := newMockHandler(t)
+
+	rec := serve(t, h, http.MethodGet, "/ServiceProviderConfig", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := decode(t, rec)
+	assert.Equal(t, []any{ServiceProviderConfigSchema}, body["schemas"])
+	assert.Equal(t, map[string]any{"supported": true}, body["patch"])
+	assert.Equal(t, map[string]any{"supported": true}, body["etag"])
+	assert.Equal(t, map[string]any{"supported": true, "maxResults": float64(MaxCount), "description": filterLimitations}, body["filter"])
+	assert.Equal(t, false, body["bulk"].(map[string]any)["supported"])
+}
+
+func TestHandler_Schemas(t *testing.T) {
+	h, _ := newMockHandler(t)
+
+	rec := serve(t, h, http.MethodGet, "/Schemas", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := decode(t, rec)
+	assert.EqualValues(t, 2, body["totalResults"])
+
+	rec = serve(t, h, http.MethodGet, "/Schemas/"+UserSchema, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "User", decode(t, rec)["name"])
+
+	assertError(t, serve(t, h, http.MethodGet, "/Schemas/urn:example:unknown", ""), http.StatusNotFound, "")
+}
+
+func TestHandler_ResourceTypes(t *testing.T) {
+	h, _ := newMockHandler(t)
+
+	rec := serve(t, h, http.MethodGet, "/ResourceTypes", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	resources := decode(t, rec)["Resources"].([]any)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "/Users", resources[0].(map[string]any)["endpoint"])
+	assert.Equal(t, GroupSchema, resources[1].(map[string]any)["schema"])
+
+	rec = serve(t, h, http.MethodGet, "/ResourceTypes/group", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Group", decode(t, rec)["id"])
+}
@@ -0,0 +1,346 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// attributeKind is the type of a filterable attribute
+type attributeKind int
+
+const (
+	kindString attributeKind = iota
+	kindInteger
+	kindBoolean
+	// kindBlindIndex is an encrypted string that can only be compared for
+	// equality, through a blind index column
+	kindBlindIndex
+	// kindEncrypted is an encrypted string that cannot be filtered
+	kindEncrypted
+)
+
+// filterLimitations describes the User attributes that cannot be filtered
+// like the others because they are encrypted. It is served in the filter
+// details of /ServiceProviderConfig.
+const filterLimitations = "userName and emails are encrypted and support only the eq and pr operators; " +
+	"displayName and name.formatted of Users are encrypted and cannot be filtered"
+
+// attribute maps a filterable SCIM attribute onto a column
+type attribute struct {
+	column    string
+	kind      attributeKind
+	caseExact bool
+	// plaintext is the column holding the value of a kindBlindIndex
+	// attribute on rows that are not encrypted yet
+	plaintext string
+}
+
+// userAttributes are the User attributes supported in filters, keyed by
+// lower-case attribute path
+var userAttributes = map[string]attribute{
+	"id":           {column: "id", kind: kindInteger},
+	"username":     {column: "email_index", kind: kindBlindIndex, plaintext: "email"},
+	"emails":       {column: "email_index", kind: kindBlindIndex, plaintext: "email"},
+	"emails.value": {column: "email_index", kind: kindBlindIndex, plaintext: "email"},
+	"externalid":   {column: "external_id", caseExact: true},
+	"active":       {column: "active", kind: kindBoolean},
+	// Names are encrypted and have no blind index
+	"displayname":    {column: "name", kind: kindEncrypted},
+	"name.formatted": {column: "name", kind: kindEncrypted},
+}
+
+// groupAttributes are the Group attributes supported in filters
+var groupAttributes = map[string]attribute{
+	"id":          {column: "id", kind: kindInteger},
+	"displayname": {column: "name"},
+	"externalid":  {column: "external_id", caseExact: true},
+}
+
+// filterToken is a lexical token of a filter expression
+type filterToken struct {
+	text   string
+	quoted bool // text is the decoded value of a JSON string literal
+}
+
+// filterParser translates a SCIM filter (RFC 7644 section 3.4.2.2) into a
+// SQL condition with positional arguments. It supports the eq, co, sw and pr
+// operators combined with and, or, not and parentheses.
+type filterParser struct {
+	tokens     []filterToken
+	pos        int
+	attributes map[string]attribute
+	blindIndex func(string) []byte
+	args       []any
+	argOffset  int
+}
+
+// parseFilter translates filter into a SQL condition over attributes. Its
+// placeholders are numbered after the first argOffset arguments. blindIndex
+// computes the index of values compared with kindBlindIndex attributes.
+func parseFilter(filter string, attributes map[string]attribute, blindIndex func(string) []byte, argOffset int) (string, []any, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) == 0 {
+		return "", nil, invalidFilter("filter is empty")
+	}
+
+	p := &filterParser{tokens: tokens, attributes: attributes, blindIndex: blindIndex, argOffset: argOffset}
+	cond, err := p.parseOr()
+	if err != nil {
+		return "", nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return "", nil, invalidFilter("unexpected %q", p.tokens[p.pos].text)
+	}
+	return cond, p.args, nil
+}
+
+// invalidFilter returns the error for a filter that cannot be parsed or evaluated
+func invalidFilter(format string, args ...any) error {
+	return newError(http.StatusBadRequest, "invalidFilter", format, args...)
+}
+
+// tokenizeFilter splits a filter into words, parentheses and string literals
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(filter) && filter[end] != '"' {
+				if filter[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(filter) {
+				return nil, invalidFilter("unterminated string")
+			}
+			var value string
+			if err := json.Unmarshal([]byte(filter[i:end+1]), &value); err != nil {
+				return nil, invalidFilter("invalid string %s", filter[i:end+1])
+			}
+			tokens = append(tokens, filterToken{text: value, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(filter) && !strings.ContainsRune(" \t\n\r()\"", rune(filter[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{text: filter[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// peekKeyword reports whether the next token is the unquoted keyword kw
+func (p *filterParser) peekKeyword(kw string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	tok := p.tokens[p.pos]
+	return !tok.quoted && strings.EqualFold(tok.text, kw)
+}
+
+// next consumes and returns the next token
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, invalidFilter("unexpected end of filter")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+// parseOr parses: and ("or" and)*
+func (p *filterParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " OR " + right + ")"
+	}
+	return left, nil
+}
+
+// parseAnd parses: factor ("and" factor)*
+func (p *filterParser) parseAnd() (string, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return "", err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " AND " + right + ")"
+	}
+	return left, nil
+}
+
+// parseFactor parses: "not"? "(" or ")" | attrPath "pr" | attrPath op value
+func (p *filterParser) parseFactor() (string, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if !p.peekKeyword("(") {
+			return "", invalidFilter("not must be followed by a parenthesized expression")
+		}
+		inner, err := p.parseFactor()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	}
+
+	if p.peekKeyword("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if !p.peekKeyword(")") {
+			return "", invalidFilter("missing closing parenthesis")
+		}
+		p.pos++
+		return "(" + inner + ")", nil
+	}
+
+	path, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	op, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if path.quoted || op.quoted {
+		return "", invalidFilter("expected an attribute path and operator")
+	}
+	attr, ok := p.attributes[normalizePath(path.text)]
+	if !ok {
+		return "", invalidFilter("filtering on %q is not supported", path.text)
+	}
+	if attr.kind == kindEncrypted {
+		return "", invalidFilter("%s is encrypted and cannot be filtered", path.text)
+	}
+
+	operator := strings.ToLower(op.text)
+	if attr.kind == kindBlindIndex && operator != "eq" && operator != "pr" {
+		return "", invalidFilter("operator %q is not supported for %s, which is encrypted; use eq or pr", operator, path.text)
+	}
+	if operator == "pr" {
+		if attr.kind == kindBlindIndex {
+			return "(" + attr.column + " IS NOT NULL OR " + attr.plaintext + " IS NOT NULL)", nil
+		}
+		return attr.column + " IS NOT NULL", nil
+	}
+	value, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	return p.compare(attr, operator, value)
+}
+
+// compare translates a single attribute comparison
+func (p *filterParser) compare(attr attribute, operator string, value filterToken) (string, error) {
+	if !value.quoted && value.text == "null" {
+		if operator != "eq" {
+			return "", invalidFilter("null can only be compared with eq")
+		}
+		if attr.kind == kindBlindIndex {
+			return "(" + attr.column + " IS NULL AND " + attr.plaintext + " IS NULL)", nil
+		}
+		return attr.column + " IS NULL", nil
+	}
+
+	switch attr.kind {
+	case kindInteger:
+		if operator != "eq" {
+			return "", invalidFilter("operator %q is not supported for %s", operator, attr.column)
+		}
+		// Resource ids are strings in SCIM; an id that is not a number matches nothing.
+		n, err := strconv.ParseInt(value.text, 10, 64)
+		if err != nil {
+			return "FALSE", nil
+		}
+		return attr.column + " = " + p.arg(n), nil
+
+	case kindBoolean:
+		if operator != "eq" {
+			return "", invalidFilter("operator %q is not supported for %s", operator, attr.column)
+		}
+		b, err := strconv.ParseBool(value.text)
+		if err != nil {
+			return "", invalidFilter("%q is not a boolean", value.text)
+		}
+		return attr.column + " = " + p.arg(b), nil
+
+	case kindBlindIndex:
+		if !value.quoted {
+			return "", invalidFilter("%s must be compared with a string", attr.plaintext)
+		}
+		// Rows that are not encrypted yet have no blind index
+		return "(" + attr.column + " = " + p.arg(p.blindIndex(value.text)) +
+			" OR lower(" + attr.plaintext + ") = lower(" + p.arg(value.text) + "))", nil
+	}
+
+	if !value.quoted {
+		return "", invalidFilter("%s must be compared with a string", attr.column)
+	}
+	column, param := attr.column, "%s"
+	if !attr.caseExact {
+		column, param = "lower("+attr.column+")", "lower(%s)"
+	}
+	switch operator {
+	case "eq":
+		return column + " = " + fmt.Sprintf(param, p.arg(value.text)), nil
+	case "co":
+		return column + " LIKE " + fmt.Sprintf(param, p.arg("%"+escapeLike(value.text)+"%")), nil
+	case "sw":
+		return column + " LIKE " + fmt.Sprintf(param, p.arg(escapeLike(value.text)+"%")), nil
+	default:
+		return "", invalidFilter("operator %q is not supported", operator)
+	}
+}
+
+// arg adds a positional argument and returns its placeholder
+func (p *filterParser) arg(v any) string {
+	p.args = append(p.args, v)
+	return fmt.Sprintf("$%d", p.argOffset+len(p.args))
+}
+
+// escapeLike escapes the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// normalizePath lower-cases an attribute path and strips a core schema URN
+// prefix, e.g. "urn:ietf:params:scim:schemas:core:2.0:User:userName"
+func normalizePath(path string) string {
+	path = strings.ToLower(path)
+	for _, schema := range []string{UserSchema, GroupSchema} {
+		if prefix := strings.ToLower(schema) + ":"; strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
@@ -0,0 +1,183 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIndex is a stand-in blind index for filter tests
+func testIndex(value string) []byte {
+	return []byte("index:" + value)
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		offset   int
+		wantCond string
+		wantArgs []any
+	}{
+		{
+			name:     "eq on userName uses the blind index",
+			filter:   `userName eq "Ada@Example.com"`,
+			wantCond: "(email_index = $1 OR lower(email) = lower($2))",
+			wantArgs: []any{testIndex("Ada@Example.com"), "Ada@Example.com"},
+		},
+		{
+			name:     "eq is case exact for externalId",
+			filter:   `externalId eq "00u1"`,
+			wantCond: "external_id = $1",
+			wantArgs: []any{"00u1"},
+		},
+		{
+			name:     "co escapes wildcards",
+			filter:   `externalId co "50%_off"`,
+			wantCond: "external_id LIKE $1",
+			wantArgs: []any{`%50\%\_off%`},
+		},
+		{
+			name:     "sw",
+			filter:   `externalId sw "00u"`,
+			wantCond: "external_id LIKE $1",
+			wantArgs: []any{"00u%"},
+		},
+		{
+			name:     "pr",
+			filter:   `externalId pr`,
+			wantCond: "external_id IS NOT NULL",
+		},
+		{
+			name:     "pr on userName",
+			filter:   `emails pr`,
+			wantCond: "(email_index IS NOT NULL OR email IS NOT NULL)",
+		},
+		{
+			name:     "operators and attributes are case insensitive",
+			filter:   `USERNAME EQ "ada"`,
+			wantCond: "(email_index = $1 OR lower(email) = lower($2))",
+			wantArgs: []any{testIndex("ada"), "ada"},
+		},
+		{
+			name:     "schema URN prefix",
+			filter:   `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "ada"`,
+			wantCond: "(email_index = $1 OR lower(email) = lower($2))",
+			wantArgs: []any{testIndex("ada"), "ada"},
+		},
+		{
+			name:     "boolean",
+			filter:   `active eq false`,
+			wantCond: "active = $1",
+			wantArgs: []any{false},
+		},
+		{
+			name:     "id",
+			filter:   `id eq "42"`,
+			wantCond: "id = $1",
+			wantArgs: []any{int64(42)},
+		},
+		{
+			name:     "non-numeric id matches nothing",
+			filter:   `id eq "abc"`,
+			wantCond: "FALSE",
+		},
+		{
+			name:     "null",
+			filter:   `externalId eq null`,
+			wantCond: "external_id IS NULL",
+		},
+		{
+			name:     "null userName",
+			filter:   `userName eq null`,
+			wantCond: "(email_index IS NULL AND email IS NULL)",
+		},
+		{
+			name:     "and binds tighter than or",
+			filter:   `externalId sw "a" or externalId sw "b" and active eq true`,
+			wantCond: "(external_id LIKE $1 OR (external_id LIKE $2 AND active = $3))",
+			wantArgs: []any{"a%", "b%", true},
+		},
+		{
+			name:     "parentheses and not",
+			filter:   `not (externalId sw "a" or externalId sw "b") and active eq true`,
+			wantCond: "(NOT ((external_id LIKE $1 OR external_id LIKE $2)) AND active = $3)",
+			wantArgs: []any{"a%", "b%", true},
+		},
+		{
+			name:     "escaped quotes in strings",
+			filter:   `externalId eq "say \"hi\""`,
+			wantCond: "external_id = $1",
+			wantArgs: []any{`say "hi"`},
+		},
+		{
+			name:     "placeholders start after offset",
+			filter:   `userName eq "ada"`,
+			offset:   2,
+			wantCond: "(email_index = $3 OR lower(email) = lower($4))",
+			wantArgs: []any{testIndex("ada"), "ada"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args, err := parseFilter(tt.filter, userAttributes, testIndex, tt.offset)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCond, cond)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{name: "empty", filter: "  "},
+		{name: "unknown attribute", filter: `nickName eq "ada"`},
+		{name: "unsupported operator", filter: `userName gt "ada"`},
+		{name: "missing value", filter: `userName eq`},
+		{name: "unterminated string", filter: `userName eq "ada`},
+		{name: "unquoted string value", filter: `userName eq ada`},
+		{name: "co on boolean", filter: `active co "t"`},
+		{name: "invalid boolean", filter: `active eq "maybe"`},
+		{name: "missing closing parenthesis", filter: `(userName eq "ada"`},
+		{name: "trailing tokens", filter: `userName eq "ada" "bob"`},
+		{name: "not without parentheses", filter: `not userName eq "ada"`},
+		{name: "null with co", filter: `userName co null`},
+		{name: "co on encrypted userName", filter: `userName co "ada"`},
+		{name: "sw on encrypted email", filter: `emails.value sw "ada"`},
+		{name: "encrypted displayName", filter: `displayName eq "Ada"`},
+		{name: "encrypted name.formatted", filter: `name.formatted sw "Ada"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseFilter(tt.filter, userAttributes, testIndex, 0)
+			require.Error(t, err)
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, "invalidFilter", scimErr.ScimType)
+		})
+	}
+}
+
+func TestParseFilter_EncryptedAttributes(t *testing.T) {
+	_, _, err := parseFilter(`userName co "ada"`, userAttributes, testIndex, 0)
+	assert.ErrorContains(t, err, `operator "co" is not supported for userName, which is encrypted; use eq or pr`)
+
+	_, _, err = parseFilter(`displayName eq "Ada"`, userAttributes, testIndex, 0)
+	assert.ErrorContains(t, err, "displayName is encrypted and cannot be filtered")
+}
+
+func TestParseFilter_GroupAttributes(t *testing.T) {
+	cond, args, err := parseFilter(`displayName eq "Engineering"`, groupAttributes, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "lower(name) = lower($1)", cond)
+	assert.Equal(t, []any{"Engineering"}, args)
+
+	_, _, err = parseFilter(`userName eq "ada"`, groupAttributes, nil, 0)
+	assert.Error(t, err)
+}
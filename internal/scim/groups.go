@@ -0,0 +1,449 @@
+package scim
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/txn"
+	"github.com/zcking/go-api-template/internal/users"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// groupColumns is the column list scanned by scanGroup
+const groupColumns = "id, name, external_id, created_at, updated_at, version"
+
+// groupRow is a row of the groups table
+type groupRow struct {
+	id         int64
+	name       string
+	externalID sql.NullString
+	createdAt  time.Time
+	updatedAt  time.Time
+	version    int
+}
+
+// scanGroup scans a row of groupColumns
+func scanGroup(row rowScanner) (*groupRow, error) {
+	var g groupRow
+	if err := row.Scan(&g.id, &g.name, &g.externalID, &g.createdAt, &g.updatedAt, &g.version); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// membersQuery lists the direct members of a set of groups, users first.
+// Member groups are named by g.name and users by their users.PIIColumns.
+const membersQuery = `SELECT gm.group_id, COALESCE(gm.user_id, gm.member_group_id), gm.user_id IS NULL, g.name,
+	u.email, u.name, u.email_ciphertext, u.name_ciphertext, u.data_key, u.key_version, COALESCE(u.pii_bound, false)
+FROM group_members gm
+LEFT JOIN users u ON u.id = gm.user_id
+LEFT JOIN groups g ON g.id = gm.member_group_id
+WHERE gm.group_id = ANY($1)
+ORDER BY gm.group_id, gm.user_id IS NULL, COALESCE(gm.user_id, gm.member_group_id)`
+
+// groupResource converts a group row and its members to its SCIM representation
+func (h *Handler) groupResource(g *groupRow, members []Member) *Group {
+	return &Group{
+		Schemas:     []string{GroupSchema},
+		ID:          strconv.FormatInt(g.id, 10),
+		ExternalID:  g.externalID.String,
+		DisplayName: g.name,
+		Members:     members,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      formatTime(g.createdAt),
+			LastModified: formatTime(g.updatedAt),
+			Location:     h.location("Groups", g.id),
+			Version:      etag(g.version),
+		},
+	}
+}
+
+// applyGroup sets the writable attributes of g from a SCIM Group
+func applyGroup(g *groupState, in *Group) error {
+	name := strings.TrimSpace(in.DisplayName)
+	if name == "" {
+		return newError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	ids, err := memberIDs(in.Members)
+	if err != nil {
+		return err
+	}
+	g.row.name = name
+	g.row.externalID = sql.NullString{String: in.ExternalID, Valid: in.ExternalID != ""}
+	g.members = mergeIDs(nil, ids)
+	return nil
+}
+
+// listGroups handles GET /Groups
+func (h *Handler) listGroups(w http.ResponseWriter, r *http.Request) error {
+	page, err := parsePage(r)
+	if err != nil {
+		return err
+	}
+
+	where, args := "", []any{}
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		cond, filterArgs, err := parseFilter(filter, groupAttributes, nil, 0)
+		if err != nil {
+			return err
+		}
+		where, args = " WHERE "+cond, filterArgs
+	}
+
+	var total int
+	if err := h.db.QueryRowContext(r.Context(), "SELECT count(*) FROM groups"+where, args...).Scan(&total); err != nil {
+		return err
+	}
+
+	var rows []*groupRow
+	if page.count > 0 && page.startIndex <= total {
+		query := "SELECT " + groupColumns + " FROM groups" + where +
+			" ORDER BY id LIMIT $" + strconv.Itoa(len(args)+1) + " OFFSET $" + strconv.Itoa(len(args)+2)
+		result, err := h.db.QueryContext(r.Context(), query, append(args, page.count, page.startIndex-1)...)
+		if err != nil {
+			return err
+		}
+		defer result.Close()
+		for result.Next() {
+			g, err := scanGroup(result)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, g)
+		}
+		if err := result.Err(); err != nil {
+			return err
+		}
+	}
+
+	members := map[int64][]Member{}
+	if len(rows) > 0 && !excluded(r, "members") {
+		ids := make([]int64, len(rows))
+		for i, g := range rows {
+			ids[i] = g.id
+		}
+		if members, err = h.loadMembers(r.Context(), h.db, ids); err != nil {
+			return err
+		}
+	}
+
+	resources := make([]any, 0, len(rows))
+	for _, g := range rows {
+		resources = append(resources, h.groupResource(g, members[g.id]))
+	}
+	h.writeJSON(w, r, http.StatusOK, newListResponse(total, page.startIndex, resources))
+	return nil
+}
+
+// getGroup handles GET /Groups/{id}
+func (h *Handler) getGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	g, err := scanGroup(h.db.QueryRowContext(r.Context(), "SELECT "+groupColumns+" FROM groups WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound("Group", id)
+	}
+	if err != nil {
+		return err
+	}
+	if notModified(r, g.version) {
+		w.Header().Set("ETag", etag(g.version))
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	var members []Member
+	if !excluded(r, "members") {
+		byGroup, err := h.loadMembers(r.Context(), h.db, []int64{id})
+		if err != nil {
+			return err
+		}
+		members = byGroup[id]
+	}
+	h.writeGroup(w, r, http.StatusOK, g, members)
+	return nil
+}
+
+// createGroup handles POST /Groups
+func (h *Handler) createGroup(w http.ResponseWriter, r *http.Request) error {
+	var in Group
+	if err := decodeBody(w, r, &in); err != nil {
+		return err
+	}
+	state := &groupState{row: &groupRow{}}
+	if err := applyGroup(state, &in); err != nil {
+		return err
+	}
+
+	var (
+		created *groupRow
+		members map[int64][]Member
+	)
+	err := h.txns.Do(r.Context(), func(ctx context.Context) (err error) {
+		tx, _ := txn.Tx(ctx)
+		created, err = scanGroup(tx.QueryRowContext(ctx,
+			"INSERT INTO groups (name, external_id) VALUES ($1, $2) RETURNING "+groupColumns,
+			state.row.name, state.row.externalID))
+		if err != nil {
+			return groupWriteError(err)
+		}
+		if err = h.writeMembers(ctx, tx, created.id, nil, state.members); err != nil {
+			return err
+		}
+		if members, err = h.loadMembers(ctx, tx, []int64{created.id}); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, groupResourceName(created.id))
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", h.location("Groups", created.id))
+	h.writeGroup(w, r, http.StatusCreated, created, members[created.id])
+	return nil
+}
+
+// replaceGroup handles PUT /Groups/{id}. Nested group members are left untouched.
+func (h *Handler) replaceGroup(w http.ResponseWriter, r *http.Request) error {
+	var in Group
+	if err := decodeBody(w, r, &in); err != nil {
+		return err
+	}
+	return h.updateGroup(w, r, func(g *groupState) error {
+		return applyGroup(g, &in)
+	})
+}
+
+// patchGroup handles PATCH /Groups/{id}
+func (h *Handler) patchGroup(w http.ResponseWriter, r *http.Request) error {
+	var req PatchRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return err
+	}
+	return h.updateGroup(w, r, func(g *groupState) error {
+		return applyGroupPatch(g, req.Operations)
+	})
+}
+
+// updateGroup locks a group, applies change to it and writes the result
+func (h *Handler) updateGroup(w http.ResponseWriter, r *http.Request, change func(*groupState) error) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		updated *groupRow
+		members map[int64][]Member
+	)
+	err = h.txns.Do(r.Context(), func(ctx context.Context) error {
+		tx, _ := txn.Tx(ctx)
+		row, err := scanGroup(tx.QueryRowContext(ctx,
+			"SELECT "+groupColumns+" FROM groups WHERE id = $1 FOR UPDATE", id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound("Group", id)
+		}
+		if err != nil {
+			return err
+		}
+		if err = checkIfMatch(r, row.version); err != nil {
+			return err
+		}
+
+		current, err := userMemberIDs(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		state := &groupState{row: row, members: slices.Clone(current)}
+		if err = change(state); err != nil {
+			return err
+		}
+
+		updated, err = scanGroup(tx.QueryRowContext(ctx,
+			`UPDATE groups SET name = $2, external_id = $3, updated_at = now(), version = version + 1
+			WHERE id = $1 RETURNING `+groupColumns,
+			id, state.row.name, state.row.externalID))
+		if err != nil {
+			return groupWriteError(err)
+		}
+		if err = h.writeMembers(ctx, tx, id, current, state.members); err != nil {
+			return err
+		}
+		if members, err = h.loadMembers(ctx, tx, []int64{id}); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, groupResourceName(id))
+	})
+	if err != nil {
+		return err
+	}
+
+	h.writeGroup(w, r, http.StatusOK, updated, members[id])
+	return nil
+}
+
+// deleteGroup handles DELETE /Groups/{id}
+func (h *Handler) deleteGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	if r.Header.Get("If-Match") != "" {
+		var version int
+		err := h.db.QueryRowContext(r.Context(), "SELECT version FROM groups WHERE id = $1", id).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound("Group", id)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(r, version); err != nil {
+			return err
+		}
+	}
+
+	err = h.txns.Do(r.Context(), func(ctx context.Context) error {
+		tx, _ := txn.Tx(ctx)
+		result, err := tx.ExecContext(ctx, "DELETE FROM groups WHERE id = $1", id)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return notFound("Group", id)
+		}
+		return audit.Record(ctx, tx, groupResourceName(id))
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// groupResourceName names a group in the audit log, as the GroupService does
+func groupResourceName(id int64) string {
+	return fmt.Sprintf("groups/%d", id)
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadMembers returns the direct members of groups, keyed by group id
+func (h *Handler) loadMembers(ctx context.Context, db querier, groupIDs []int64) (map[int64][]Member, error) {
+	rows, err := db.QueryContext(ctx, membersQuery, pq.Array(groupIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make(map[int64][]Member)
+	for rows.Next() {
+		var (
+			groupID, memberID int64
+			isGroup           bool
+			groupName         sql.NullString
+			pii               users.PII
+		)
+		dest := append([]any{&groupID, &memberID, &isGroup, &groupName}, pii.Dest()...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		member := Member{Value: strconv.FormatInt(memberID, 10), Type: "User"}
+		if isGroup {
+			member.Type = "Group"
+			member.Display = groupName.String
+			member.Ref = h.location("Groups", memberID)
+		} else {
+			_, name, err := h.users.OpenPII(memberID, &pii)
+			if err != nil {
+				return nil, err
+			}
+			member.Display = name
+			member.Ref = h.location("Users", memberID)
+		}
+		members[groupID] = append(members[groupID], member)
+	}
+	return members, rows.Err()
+}
+
+// userMemberIDs returns the sorted ids of the direct user members of a group
+func userMemberIDs(ctx context.Context, tx *sql.Tx, groupID int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT user_id FROM group_members WHERE group_id = $1 AND user_id IS NOT NULL ORDER BY user_id", groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// writeMembers changes the direct user members of a group from current to desired
+func (h *Handler) writeMembers(ctx context.Context, tx *sql.Tx, groupID int64, current, desired []int64) error {
+	var removed []int64
+	for _, id := range current {
+		if !slices.Contains(desired, id) {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) > 0 {
+		_, err := tx.ExecContext(ctx,
+			"DELETE FROM group_members WHERE group_id = $1 AND user_id = ANY($2)", groupID, pq.Array(removed))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range desired {
+		if slices.Contains(current, id) {
+			continue
+		}
+		if err := h.groups.AddUserMemberTx(ctx, groupID, id); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return newError(http.StatusBadRequest, "invalidValue", "member %d is not a user", id)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// groupWriteError translates constraint violations from writing a group
+func groupWriteError(err error) error {
+	if isUniqueViolation(err) {
+		return newError(http.StatusConflict, "uniqueness", "displayName or externalId is already in use")
+	}
+	return err
+}
+
+// writeGroup writes a group resource along with its ETag
+func (h *Handler) writeGroup(w http.ResponseWriter, r *http.Request, code int, g *groupRow, members []Member) {
+	w.Header().Set("ETag", etag(g.version))
+	h.writeJSON(w, r, code, h.groupResource(g, members))
+}
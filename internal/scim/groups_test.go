@@ -0,0 +1,341 @@
+package scim
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	groupRowColumns  = []string{"id", "name", "external_id", "created_at", "updated_at", "version"}
+	memberRowColumns = []string{"group_id", "member_id", "is_group", "group_name",
+		"email", "name", "email_ciphertext", "name_ciphertext", "data_key", "key_version", "pii_bound"}
+)
+
+// userMember returns a membersQuery row for a user member
+func userMember(groupID, userID int64, name string) []driver.Value {
+	e, err := testKeyring.Seal(strconv.FormatInt(userID, 10), "user@example.com", name)
+	if err != nil {
+		panic(err)
+	}
+	return []driver.Value{groupID, userID, false, nil, nil, nil, e.Values[0], e.Values[1], e.DataKey, e.KeyVersion, true}
+}
+
+// groupMember returns a membersQuery row for a group member
+func groupMember(groupID, memberID int64, name string) []driver.Value {
+	return []driver.Value{groupID, memberID, true, name, nil, nil, nil, nil, nil, nil, false}
+}
+
+// groupRows returns a single groups row
+func groupRows(id int64, name string, externalID any, version int) *sqlmock.Rows {
+	return sqlmock.NewRows(groupRowColumns).AddRow(id, name, externalID, testTime, testTime, version)
+}
+
+func expectMembers(mock sqlmock.Sqlmock, groupIDs []int64, rows *sqlmock.Rows) {
+	mock.ExpectQuery(regexp.QuoteMeta(membersQuery)).
+		WithArgs(pq.Array(groupIDs)).
+		WillReturnRows(rows)
+}
+
+func TestHandler_ListGroups(t *testing.T) {
+	t.Run("with members", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM groups WHERE lower(name) = lower($1)")).
+			WithArgs("Engineering").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT "+groupColumns+" FROM groups WHERE lower(name) = lower($1) ORDER BY id LIMIT $2 OFFSET $3")).
+			WithArgs("Engineering", DefaultCount, 0).
+			WillReturnRows(groupRows(1, "Engineering", nil, 1))
+		expectMembers(mock, []int64{1}, sqlmock.NewRows(memberRowColumns).
+			AddRow(userMember(1, 7, "Ada")...).
+			AddRow(groupMember(1, 2, "Platform")...))
+
+		rec := serve(t, h, http.MethodGet, `/Groups?filter=displayName+eq+%22Engineering%22`, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		group := decode(t, rec)["Resources"].([]any)[0].(map[string]any)
+		assert.Equal(t, "Engineering", group["displayName"])
+		assert.Equal(t, []any{
+			map[string]any{"value": "7", "display": "Ada", "type": "User", "$ref": Prefix + "/Users/7"},
+			map[string]any{"value": "2", "display": "Platform", "type": "Group", "$ref": Prefix + "/Groups/2"},
+		}, group["members"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("excluded members", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM groups")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT "+groupColumns+" FROM groups ORDER BY id LIMIT $1 OFFSET $2")).
+			WithArgs(DefaultCount, 0).
+			WillReturnRows(groupRows(1, "Engineering", nil, 1))
+
+		rec := serve(t, h, http.MethodGet, "/Groups?excludedAttributes=members", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		group := decode(t, rec)["Resources"].([]any)[0].(map[string]any)
+		assert.NotContains(t, group, "members")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_GetGroup(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT " + groupColumns + " FROM groups WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnRows(groupRows(1, "Engineering", "okta-eng", 5))
+		expectMembers(mock, []int64{1}, sqlmock.NewRows(memberRowColumns))
+
+		rec := serve(t, h, http.MethodGet, "/Groups/1", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `W/"5"`, rec.Header().Get("ETag"))
+		body := decode(t, rec)
+		assert.Equal(t, []any{GroupSchema}, body["schemas"])
+		assert.Equal(t, "okta-eng", body["externalId"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT " + groupColumns + " FROM groups WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(groupRowColumns))
+
+		assertError(t, serve(t, h, http.MethodGet, "/Groups/1", ""), http.StatusNotFound, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_CreateGroup(t *testing.T) {
+	insert := regexp.QuoteMeta("INSERT INTO groups (name, external_id) VALUES ($1, $2) RETURNING " + groupColumns)
+	addMember := regexp.QuoteMeta("INSERT INTO group_members (group_id, user_id) VALUES ($1, $2)")
+
+	t.Run("created with members", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(insert).
+			WithArgs("Engineering", sql.NullString{String: "okta-eng", Valid: true}).
+			WillReturnRows(groupRows(3, "Engineering", "okta-eng", 1))
+		mock.ExpectExec(addMember).WithArgs(int64(3), int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(addMember).WithArgs(int64(3), int64(8)).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectMembers(mock, []int64{3}, sqlmock.NewRows(memberRowColumns).
+			AddRow(userMember(3, 7, "Ada")...).
+			AddRow(userMember(3, 8, "Bob")...))
+		mock.ExpectCommit()
+
+		body := `{"schemas":["` + GroupSchema + `"],"displayName":"Engineering","externalId":"okta-eng",
+			"members":[{"value":"8"},{"value":"7","type":"User"},{"value":"7"}]}`
+		rec := serve(t, h, http.MethodPost, "/Groups", body)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Equal(t, Prefix+"/Groups/3", rec.Header().Get("Location"))
+		assert.Len(t, decode(t, rec)["members"], 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(insert).WillReturnRows(groupRows(3, "Engineering", nil, 1))
+		mock.ExpectExec(addMember).WithArgs(int64(3), int64(99)).
+			WillReturnError(&pq.Error{Code: "23503"})
+		mock.ExpectRollback()
+
+		rec := serve(t, h, http.MethodPost, "/Groups", `{"displayName":"Engineering","members":[{"value":"99"}]}`)
+		assertError(t, rec, http.StatusBadRequest, "invalidValue")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(insert).WillReturnError(&pq.Error{Code: pgUniqueViolation})
+		mock.ExpectRollback()
+
+		rec := serve(t, h, http.MethodPost, "/Groups", `{"displayName":"Engineering"}`)
+		assertError(t, rec, http.StatusConflict, "uniqueness")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing displayName", func(t *testing.T) {
+		h, _ := newMockHandler(t)
+		rec := serve(t, h, http.MethodPost, "/Groups", `{"members":[]}`)
+		assertError(t, rec, http.StatusBadRequest, "invalidValue")
+	})
+}
+
+// expectLockGroup expects the start of updateGroup: locking the group and reading its user members
+func expectLockGroup(mock sqlmock.Sqlmock, id int64, rows *sqlmock.Rows, memberIDs ...int64) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + groupColumns + " FROM groups WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).
+		WillReturnRows(rows)
+	members := sqlmock.NewRows([]string{"user_id"})
+	for _, memberID := range memberIDs {
+		members.AddRow(memberID)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT user_id FROM group_members WHERE group_id = $1 AND user_id IS NOT NULL ORDER BY user_id")).
+		WithArgs(id).
+		WillReturnRows(members)
+}
+
+func expectUpdateGroup(mock sqlmock.Sqlmock, id int64, name string, externalID sql.NullString) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(`UPDATE groups SET name = \$2, external_id = \$3, updated_at = now\(\), version = version \+ 1\s+WHERE id = \$1`).
+		WithArgs(id, name, externalID)
+}
+
+func TestHandler_PatchGroup(t *testing.T) {
+	t.Run("add and remove members", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLockGroup(mock, 1, groupRows(1, "Engineering", nil, 2), 5, 6)
+		expectUpdateGroup(mock, 1, "Engineering", sql.NullString{}).
+			WillReturnRows(groupRows(1, "Engineering", nil, 3))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM group_members WHERE group_id = $1 AND user_id = ANY($2)")).
+			WithArgs(int64(1), pq.Array([]int64{5})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO group_members (group_id, user_id) VALUES ($1, $2)")).
+			WithArgs(int64(1), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectMembers(mock, []int64{1}, sqlmock.NewRows(memberRowColumns).
+			AddRow(userMember(1, 6, "Bob")...).
+			AddRow(userMember(1, 7, "Cy")...))
+		mock.ExpectCommit()
+
+		body := `{"schemas":["` + PatchOpSchema + `"],"Operations":[
+			{"op":"add","path":"members","value":[{"value":"7"}]},
+			{"op":"remove","path":"members[value eq \"5\"]"}]}`
+		rec := serve(t, h, http.MethodPatch, "/Groups/1", body, "If-Match", `W/"2"`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `W/"3"`, rec.Header().Get("ETag"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLockGroup(mock, 1, groupRows(1, "Engineering", "okta-eng", 2), 5)
+		expectUpdateGroup(mock, 1, "Eng", sql.NullString{String: "okta-eng", Valid: true}).
+			WillReturnRows(groupRows(1, "Eng", "okta-eng", 3))
+		expectMembers(mock, []int64{1}, sqlmock.NewRows(memberRowColumns).AddRow(userMember(1, 5, "Ada")...))
+		mock.ExpectCommit()
+
+		body := `{"Operations":[{"op":"replace","value":{"id":"1","displayName":"Eng"}}]}`
+		rec := serve(t, h, http.MethodPatch, "/Groups/1", body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "Eng", decode(t, rec)["displayName"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT " + groupColumns + " FROM groups WHERE id = $1 FOR UPDATE")).
+			WithArgs(int64(1)).
+			WillReturnRows(groupRows(1, "Engineering", nil, 4))
+		mock.ExpectRollback()
+
+		body := `{"Operations":[{"op":"replace","path":"displayName","value":"Eng"}]}`
+		rec := serve(t, h, http.MethodPatch, "/Groups/1", body, "If-Match", `W/"2"`)
+		assertError(t, rec, http.StatusPreconditionFailed, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT " + groupColumns + " FROM groups WHERE id = $1 FOR UPDATE")).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(groupRowColumns))
+		mock.ExpectRollback()
+
+		body := `{"Operations":[{"op":"replace","path":"displayName","value":"Eng"}]}`
+		assertError(t, serve(t, h, http.MethodPatch, "/Groups/1", body), http.StatusNotFound, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_ReplaceGroup(t *testing.T) {
+	h, mock := newMockHandler(t)
+	expectLockGroup(mock, 1, groupRows(1, "Engineering", nil, 2), 5, 6)
+	expectUpdateGroup(mock, 1, "Engineering", sql.NullString{}).
+		WillReturnRows(groupRows(1, "Engineering", nil, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM group_members WHERE group_id = $1 AND user_id = ANY($2)")).
+		WithArgs(int64(1), pq.Array([]int64{5})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectMembers(mock, []int64{1}, sqlmock.NewRows(memberRowColumns).
+		AddRow(userMember(1, 6, "Bob")...).
+		AddRow(groupMember(1, 2, "Platform")...))
+	mock.ExpectCommit()
+
+	// Nested group members are ignored rather than removed or rejected
+	body := `{"displayName":"Engineering","members":[{"value":"6"},{"value":"2","type":"Group"}]}`
+	rec := serve(t, h, http.MethodPut, "/Groups/1", body)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Len(t, decode(t, rec)["members"], 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandler_DeleteGroup(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM groups WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM groups WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		rec := serve(t, h, http.MethodDelete, "/Groups/1", "", "If-Match", `W/"2"`)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM groups WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assertError(t, serve(t, h, http.MethodDelete, "/Groups/1", ""), http.StatusNotFound, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_AuditGroups(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		h, mock := newAuditedHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO groups (name, external_id)")).
+			WillReturnRows(groupRows(3, "Engineering", nil, 1))
+		expectMembers(mock, []int64{3}, sqlmock.NewRows(memberRowColumns))
+		expectAuditEvent(mock, "SCIM POST /Groups", "groups/3", "OK")
+		mock.ExpectCommit()
+
+		rec := serve(t, h, http.MethodPost, "/Groups", `{"displayName":"Engineering"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		h, mock := newAuditedHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM groups WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectAuditEvent(mock, "SCIM DELETE /Groups/{id}", "groups/1", "OK")
+		mock.ExpectCommit()
+
+		rec := serve(t, h, http.MethodDelete, "/Groups/1", "")
+		assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
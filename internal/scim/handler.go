@@ -0,0 +1,296 @@
+package scim
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/groups"
+	"github.com/zcking/go-api-template/internal/txn"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+// Prefix is the path the SCIM API is served under
+const Prefix = "/scim/v2"
+
+// Pagination limits for list endpoints
+const (
+	DefaultCount = 100
+	MaxCount     = 1000
+)
+
+// maxBodyBytes caps the size of request bodies
+const maxBodyBytes = 1 << 20
+
+// contentType is the media type of every SCIM response
+const contentType = "application/scim+json"
+
+// pgUniqueViolation is the Postgres SQLSTATE for unique constraint violations
+const pgUniqueViolation = "23505"
+
+// Config holds configuration for the SCIM API
+type Config struct {
+	// BearerToken authenticates the identity provider. Every request must
+	// carry it in an "Authorization: Bearer" header.
+	BearerToken string
+	// TokenSource, when set, returns the bearer token for each request
+	// instead of BearerToken, so the token can be rotated
+	TokenSource func() string
+	// BaseURL is the externally visible URL of the SCIM API, used to build
+	// meta.location. Defaults to Prefix.
+	BaseURL string
+	// Audit, when set, records the requests that change users and groups in
+	// the audit log, with the identity provider (Actor) as the actor
+	Audit *audit.Service
+}
+
+// Actor is the actor of the audit events of SCIM requests: the identity
+// provider, authenticated by the bearer token
+const Actor = "scim"
+
+// Handler serves the SCIM 2.0 (RFC 7643, RFC 7644) provisioning API. SCIM
+// Users are stored as rows of the users table, with userName kept as the
+// (encrypted) email, and SCIM Groups as rows of the groups table.
+type Handler struct {
+	db     *sql.DB
+	txns   *txn.Manager
+	users  *users.Service
+	groups *groups.Service
+	config Config
+	logger *slog.Logger
+	mux    *http.ServeMux
+}
+
+// NewHandler creates a SCIM handler. Emails and names are encrypted and
+// decrypted with usersService. Group memberships are written through
+// groupsService so they follow the same rules as the GroupService RPCs.
+func NewHandler(db *sql.DB, usersService *users.Service, groupsService *groups.Service, config Config, logger *slog.Logger) *Handler {
+	if config.BaseURL == "" {
+		config.BaseURL = Prefix
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	h := &Handler{
+		db:     db,
+		txns:   txn.NewManager(db, logger),
+		users:  usersService,
+		groups: groupsService,
+		config: config,
+		logger: logger,
+		mux:    http.NewServeMux(),
+	}
+
+	h.handle("GET /ServiceProviderConfig", h.getServiceProviderConfig)
+	h.handle("GET /Schemas", h.listSchemas)
+	h.handle("GET /Schemas/{id}", h.getSchema)
+	h.handle("GET /ResourceTypes", h.listResourceTypes)
+	h.handle("GET /ResourceTypes/{id}", h.getResourceType)
+
+	h.handle("GET /Users", h.listUsers)
+	h.handle("POST /Users", h.createUser)
+	h.handle("GET /Users/{id}", h.getUser)
+	h.handle("PUT /Users/{id}", h.replaceUser)
+	h.handle("PATCH /Users/{id}", h.patchUser)
+	h.handle("DELETE /Users/{id}", h.deleteUser)
+
+	h.handle("GET /Groups", h.listGroups)
+	h.handle("POST /Groups", h.createGroup)
+	h.handle("GET /Groups/{id}", h.getGroup)
+	h.handle("PUT /Groups/{id}", h.replaceGroup)
+	h.handle("PATCH /Groups/{id}", h.patchGroup)
+	h.handle("DELETE /Groups/{id}", h.deleteGroup)
+
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		h.writeError(w, r, newError(http.StatusNotFound, "", "no SCIM endpoint at %s %s", r.Method, r.URL.Path))
+	})
+
+	return h
+}
+
+// ServeHTTP authenticates the request and dispatches it to its endpoint
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+		h.writeError(w, r, newError(http.StatusUnauthorized, "", "missing or invalid bearer token"))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorized reports whether r carries the configured bearer token. An empty
+// token rejects every request rather than leaving the API open.
+func (h *Handler) authorized(r *http.Request) bool {
+	want := h.config.BearerToken
+	if h.config.TokenSource != nil {
+		want = h.config.TokenSource()
+	}
+	if want == "" {
+		return false
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(want)) == 1
+}
+
+// handle registers an endpoint under Prefix. Errors returned by fn are
+// written as SCIM error responses. Endpoints that change state are audited
+// as "SCIM " and pattern (see auditMethod) when Config.Audit is set.
+func (h *Handler) handle(pattern string, fn func(w http.ResponseWriter, r *http.Request) error) {
+	method, path, _ := strings.Cut(pattern, " ")
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			h.writeError(w, r, err)
+		}
+	})
+	if h.config.Audit != nil && method != http.MethodGet {
+		handler = h.config.Audit.HTTPHandler(Actor, auditMethod(pattern), handler)
+	}
+	h.mux.Handle(method+" "+Prefix+path, handler)
+}
+
+// auditMethod is the method recorded in the audit log for requests to the
+// endpoint pattern, e.g. "SCIM PATCH /Users/{id}"
+func auditMethod(pattern string) string {
+	return "SCIM " + pattern
+}
+
+// writeJSON writes v as a SCIM response with the given status code
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, code int, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write SCIM response", "error", err)
+	}
+}
+
+// writeError writes err as a SCIM error response. Errors that are not an
+// *Error are logged and reported as internal errors without their details.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *Error
+	if !errors.As(err, &scimErr) {
+		h.logger.ErrorContext(r.Context(), "SCIM request failed", "error", err, "method", r.Method, "path", r.URL.Path)
+		scimErr = newError(http.StatusInternalServerError, "", "internal error")
+	}
+	h.writeJSON(w, r, scimErr.code, scimErr)
+}
+
+// decodeBody decodes the JSON request body into v
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// parseID parses the {id} path value of r
+func parseID(r *http.Request) (int64, error) {
+	raw := r.PathValue("id")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, newError(http.StatusNotFound, "", "resource %q not found", raw)
+	}
+	return id, nil
+}
+
+// pageParams holds the startIndex and count of a list request
+type pageParams struct {
+	startIndex int
+	count      int
+}
+
+// parsePage reads startIndex (1-based) and count from the query string,
+// clamping them to valid values as RFC 7644 section 3.4.2.4 requires
+func parsePage(r *http.Request) (pageParams, error) {
+	page := pageParams{startIndex: 1, count: DefaultCount}
+	query := r.URL.Query()
+	if raw := query.Get("startIndex"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return page, newError(http.StatusBadRequest, "invalidValue", "startIndex must be an integer")
+		}
+		page.startIndex = max(n, 1)
+	}
+	if raw := query.Get("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return page, newError(http.StatusBadRequest, "invalidValue", "count must be an integer")
+		}
+		page.count = min(max(n, 0), MaxCount)
+	}
+	return page, nil
+}
+
+// etag returns the weak entity tag of a resource version
+func etag(version int) string {
+	return fmt.Sprintf(`W/"%d"`, version)
+}
+
+// checkIfMatch enforces an If-Match precondition against the current version
+func checkIfMatch(r *http.Request, version int) error {
+	header := r.Header.Get("If-Match")
+	if header == "" || matchesETag(header, version) {
+		return nil
+	}
+	return newError(http.StatusPreconditionFailed, "", "resource version is %s", etag(version))
+}
+
+// notModified reports whether an If-None-Match header matches the current version
+func notModified(r *http.Request, version int) bool {
+	header := r.Header.Get("If-None-Match")
+	return header != "" && matchesETag(header, version)
+}
+
+// matchesETag reports whether a comma-separated list of entity tags contains
+// the tag of version. Tags are compared weakly.
+func matchesETag(header string, version int) bool {
+	want := strings.TrimPrefix(etag(version), "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded reports whether attribute is listed in the excludedAttributes query parameter
+func excluded(r *http.Request, attribute string) bool {
+	for _, name := range strings.Split(r.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(name), attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+// location returns the meta.location of a resource
+func (h *Handler) location(endpoint string, id int64) string {
+	return fmt.Sprintf("%s/%s/%d", h.config.BaseURL, endpoint, id)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation
+}
+
+// notFound returns the error for a missing resource
+func notFound(resourceType string, id int64) error {
+	return newError(http.StatusNotFound, "", "%s %d not found", resourceType, id)
+}
@@ -0,0 +1,218 @@
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/groups"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+const testToken = "s3cret"
+
+// testKeyring encrypts the users of handlers under test
+var testKeyring = func() *encryption.Keyring {
+	k, err := encryption.NewKeyring("1", map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return k
+}()
+
+// newMockHandler creates a Handler backed by go-sqlmock
+func newMockHandler(t *testing.T) (*Handler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	usersService := users.NewServiceFromDB(db, testKeyring, logger)
+	return NewHandler(db, usersService, groups.NewService(db, logger), Config{BearerToken: testToken}, logger), mock
+}
+
+// newAuditedHandler creates a Handler backed by go-sqlmock that records its
+// requests in the audit log
+func newAuditedHandler(t *testing.T) (*Handler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	config := Config{BearerToken: testToken, Audit: audit.NewService(db, logger)}
+	return NewHandler(db, users.NewServiceFromDB(db, testKeyring, logger), groups.NewService(db, logger), config, logger), mock
+}
+
+// expectAuditEvent expects an event of the SCIM actor to be appended to the
+// audit log
+func expectAuditEvent(mock sqlmock.Sqlmock, method, resource, outcome string) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_events")).WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_events")).
+		WithArgs(sqlmock.AnyArg(), Actor, method, resource, sqlmock.AnyArg(), outcome,
+			"", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+// serve sends an authenticated request to h and returns the recorded response
+func serve(t *testing.T, h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, Prefix+path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", contentType)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode decodes a JSON response body into a generic map
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return body
+}
+
+// assertError asserts rec is a SCIM error with the given status and scimType
+func assertError(t *testing.T, rec *httptest.ResponseRecorder, code int, scimType string) {
+	t.Helper()
+	require.Equal(t, code, rec.Code, rec.Body.String())
+	body := decode(t, rec)
+	assert.Equal(t, []any{ErrorSchema}, body["schemas"])
+	if scimType != "" {
+		assert.Equal(t, scimType, body["scimType"])
+	}
+}
+
+func TestHandler_Authentication(t *testing.T) {
+	h, _ := newMockHandler(t)
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{name: "valid token", header: "Bearer " + testToken, code: http.StatusOK},
+		{name: "scheme is case insensitive", header: "bearer " + testToken, code: http.StatusOK},
+		{name: "missing header", header: "", code: http.StatusUnauthorized},
+		{name: "wrong token", header: "Bearer nope", code: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Basic " + testToken, code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, Prefix+"/ServiceProviderConfig", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code)
+			if tt.code == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestHandler_EmptyTokenRejectsEverything(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	h := NewHandler(db, users.NewServiceFromDB(db, testKeyring, logger), groups.NewService(db, logger), Config{}, logger)
+
+	req := httptest.NewRequest(http.MethodGet, Prefix+"/ServiceProviderConfig", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestHandler_TokenSource(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	token := "old-token"
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	config := Config{BearerToken: "ignored", TokenSource: func() string { return token }}
+	h := NewHandler(db, users.NewServiceFromDB(db, testKeyring, logger), groups.NewService(db, logger), config, logger)
+
+	request := func(bearer string) int {
+		req := httptest.NewRequest(http.MethodGet, Prefix+"/ServiceProviderConfig", nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, request("old-token"))
+	assert.Equal(t, http.StatusUnauthorized, request("ignored"))
+
+	token = "new-token"
+	assert.Equal(t, http.StatusUnauthorized, request("old-token"), "a rotated token takes effect immediately")
+	assert.Equal(t, http.StatusOK, request("new-token"))
+}
+
+func TestHandler_UnknownEndpoint(t *testing.T) {
+	h, _ := newMockHandler(t)
+	rec := serve(t, h, http.MethodGet, "/Widgets", "")
+	assertError(t, rec, http.StatusNotFound, "")
+	assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    pageParams
+		wantErr bool
+	}{
+		{name: "defaults", query: "", want: pageParams{startIndex: 1, count: DefaultCount}},
+		{name: "explicit", query: "startIndex=11&count=10", want: pageParams{startIndex: 11, count: 10}},
+		{name: "start index below one", query: "startIndex=0", want: pageParams{startIndex: 1, count: DefaultCount}},
+		{name: "negative count", query: "count=-5", want: pageParams{startIndex: 1, count: 0}},
+		{name: "count above max", query: "count=100000", want: pageParams{startIndex: 1, count: MaxCount}},
+		{name: "invalid start index", query: "startIndex=abc", wantErr: true},
+		{name: "invalid count", query: "count=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/Users?"+tt.query, nil)
+			got, err := parsePage(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	assert.True(t, matchesETag(`W/"3"`, 3))
+	assert.True(t, matchesETag(`"3"`, 3))
+	assert.True(t, matchesETag(`W/"1", W/"3"`, 3))
+	assert.True(t, matchesETag(`*`, 3))
+	assert.False(t, matchesETag(`W/"2"`, 3))
+}
@@ -0,0 +1,327 @@
+package scim
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PatchRequest is the body of a PATCH request (RFC 7644 section 3.5.2)
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single add, replace or remove operation
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// memberFilterPath matches the value filter used to remove a single member,
+// e.g. members[value eq "42"]
+var memberFilterPath = regexp.MustCompile(`^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+// operation returns the lower-cased op of o, rejecting unknown operations
+func (o PatchOperation) operation() (string, error) {
+	op := strings.ToLower(o.Op)
+	switch op {
+	case "add", "replace", "remove":
+		return op, nil
+	default:
+		return "", newError(http.StatusBadRequest, "invalidSyntax", "unsupported patch operation %q", o.Op)
+	}
+}
+
+// attributes returns the attribute values an operation without a path sets.
+// Its value must be an object of attribute names to values.
+func (o PatchOperation) attributes() (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(o.Value, &values); err != nil {
+		return nil, newError(http.StatusBadRequest, "invalidValue", "an operation without a path must have an object value")
+	}
+	return values, nil
+}
+
+// applyUserPatch applies patch operations to a user. Attributes the service
+// does not store are ignored, as they are on POST and PUT.
+func applyUserPatch(u *userRow, ops []PatchOperation) error {
+	if len(ops) == 0 {
+		return newError(http.StatusBadRequest, "invalidValue", "Operations is required")
+	}
+	for _, o := range ops {
+		op, err := o.operation()
+		if err != nil {
+			return err
+		}
+		if o.Path != "" {
+			if err := applyUserAttribute(u, op, normalizePath(o.Path), o.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		if op == "remove" {
+			return newError(http.StatusBadRequest, "noTarget", "remove requires a path")
+		}
+		values, err := o.attributes()
+		if err != nil {
+			return err
+		}
+		for name, value := range values {
+			if err := applyUserAttribute(u, op, normalizePath(name), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyUserAttribute applies one operation on a user attribute path
+func applyUserAttribute(u *userRow, op, path string, value json.RawMessage) error {
+	remove := op == "remove"
+	switch path {
+	case "username":
+		if remove {
+			return newError(http.StatusBadRequest, "mutability", "userName is required")
+		}
+		s, err := stringValue(path, value)
+		if err != nil {
+			return err
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			return newError(http.StatusBadRequest, "invalidValue", "userName is required")
+		}
+		u.userName = s
+
+	case "displayname", "name.formatted":
+		if remove {
+			u.name = ""
+			return nil
+		}
+		s, err := stringValue(path, value)
+		if err != nil {
+			return err
+		}
+		u.name = s
+
+	case "name.givenname", "name.familyname":
+		s := ""
+		if !remove {
+			var err error
+			if s, err = stringValue(path, value); err != nil {
+				return err
+			}
+		}
+		given, family := splitName(u.name)
+		if path == "name.givenname" {
+			given = s
+		} else {
+			family = s
+		}
+		u.name = joinName(given, family)
+
+	case "name":
+		if remove {
+			u.name = ""
+			return nil
+		}
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return newError(http.StatusBadRequest, "invalidValue", "name must be an object")
+		}
+		u.name = userDisplayName(&User{Name: &name})
+
+	case "externalid":
+		if remove {
+			u.externalID = sql.NullString{}
+			return nil
+		}
+		s, err := stringValue(path, value)
+		if err != nil {
+			return err
+		}
+		u.externalID = sql.NullString{String: s, Valid: s != ""}
+
+	case "active":
+		if remove {
+			return newError(http.StatusBadRequest, "mutability", "active cannot be removed")
+		}
+		b, err := boolValue(path, value)
+		if err != nil {
+			return err
+		}
+		u.active = b
+	}
+	return nil
+}
+
+// groupState is a group and its direct user members, as changed by a PUT or PATCH
+type groupState struct {
+	row     *groupRow
+	members []int64
+}
+
+// applyGroupPatch applies patch operations to a group. Attributes the
+// service does not store are ignored.
+func applyGroupPatch(g *groupState, ops []PatchOperation) error {
+	if len(ops) == 0 {
+		return newError(http.StatusBadRequest, "invalidValue", "Operations is required")
+	}
+	for _, o := range ops {
+		op, err := o.operation()
+		if err != nil {
+			return err
+		}
+		if o.Path != "" {
+			if err := applyGroupAttribute(g, op, o.Path, o.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		if op == "remove" {
+			return newError(http.StatusBadRequest, "noTarget", "remove requires a path")
+		}
+		values, err := o.attributes()
+		if err != nil {
+			return err
+		}
+		for name, value := range values {
+			if err := applyGroupAttribute(g, op, name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyGroupAttribute applies one operation on a group attribute path
+func applyGroupAttribute(g *groupState, op, rawPath string, value json.RawMessage) error {
+	path := normalizePath(rawPath)
+	if m := memberFilterPath.FindStringSubmatch(path); m != nil {
+		if op != "remove" {
+			return newError(http.StatusBadRequest, "invalidPath", "value filters are only supported by remove")
+		}
+		id, err := memberID(Member{Value: m[1]})
+		if err != nil {
+			return err
+		}
+		g.members = slices.DeleteFunc(g.members, func(member int64) bool { return member == id })
+		return nil
+	}
+
+	remove := op == "remove"
+	switch path {
+	case "displayname":
+		if remove {
+			return newError(http.StatusBadRequest, "mutability", "displayName is required")
+		}
+		s, err := stringValue(path, value)
+		if err != nil {
+			return err
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			return newError(http.StatusBadRequest, "invalidValue", "displayName is required")
+		}
+		g.row.name = s
+
+	case "externalid":
+		if remove {
+			g.row.externalID = sql.NullString{}
+			return nil
+		}
+		s, err := stringValue(path, value)
+		if err != nil {
+			return err
+		}
+		g.row.externalID = sql.NullString{String: s, Valid: s != ""}
+
+	case "members":
+		// remove without a value removes every member; with a value it
+		// removes the listed members (the form used by Azure AD).
+		if remove && len(value) == 0 {
+			g.members = nil
+			return nil
+		}
+		var members []Member
+		if err := json.Unmarshal(value, &members); err != nil {
+			return newError(http.StatusBadRequest, "invalidValue", "members must be an array")
+		}
+		ids, err := memberIDs(members)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "add":
+			g.members = mergeIDs(g.members, ids)
+		case "replace":
+			g.members = mergeIDs(nil, ids)
+		case "remove":
+			g.members = slices.DeleteFunc(g.members, func(member int64) bool { return slices.Contains(ids, member) })
+		}
+	}
+	return nil
+}
+
+// memberIDs returns the user ids of members. Nested group members are
+// managed through GroupService and skipped, so that a Group read from this
+// API can be written back unchanged.
+func memberIDs(members []Member) ([]int64, error) {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		if strings.EqualFold(m.Type, "Group") {
+			continue
+		}
+		id, err := memberID(m)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// memberID parses the user id of a member
+func memberID(m Member) (int64, error) {
+	id, err := strconv.ParseInt(m.Value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, newError(http.StatusBadRequest, "invalidValue", "member %q is not a valid user id", m.Value)
+	}
+	return id, nil
+}
+
+// mergeIDs returns the sorted union of existing and ids without duplicates
+func mergeIDs(existing, ids []int64) []int64 {
+	merged := append(slices.Clone(existing), ids...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// stringValue decodes a string attribute value
+func stringValue(path string, value json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", newError(http.StatusBadRequest, "invalidValue", "%s must be a string", path)
+	}
+	return s, nil
+}
+
+// boolValue decodes a boolean attribute value. Strings such as "False" are
+// accepted because some identity providers send booleans as strings.
+func boolValue(path string, value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, newError(http.StatusBadRequest, "invalidValue", "%s must be a boolean", path)
+}
@@ -0,0 +1,184 @@
+package scim
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ops decodes a JSON array of patch operations
+func ops(t *testing.T, raw string) []PatchOperation {
+	t.Helper()
+	var operations []PatchOperation
+	require.NoError(t, json.Unmarshal([]byte(raw), &operations))
+	return operations
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	base := func() *userRow {
+		return &userRow{
+			id:         1,
+			userName:   "ada@example.com",
+			name:       "Ada Lovelace",
+			externalID: sql.NullString{String: "00u1", Valid: true},
+			active:     true,
+		}
+	}
+
+	tests := []struct {
+		name string
+		ops  string
+		want func(*userRow)
+	}{
+		{
+			name: "replace active with a string boolean",
+			ops:  `[{"op":"Replace","path":"active","value":"False"}]`,
+			want: func(u *userRow) { u.active = false },
+		},
+		{
+			name: "replace without path",
+			ops:  `[{"op":"replace","value":{"userName":"ada@example.org","displayName":"Countess","nickName":"ignored"}}]`,
+			want: func(u *userRow) { u.userName, u.name = "ada@example.org", "Countess" },
+		},
+		{
+			name: "replace family name",
+			ops:  `[{"op":"replace","path":"name.familyName","value":"King"}]`,
+			want: func(u *userRow) { u.name = "Ada King" },
+		},
+		{
+			name: "replace name object",
+			ops:  `[{"op":"add","path":"name","value":{"givenName":"Augusta","familyName":"King"}}]`,
+			want: func(u *userRow) { u.name = "Augusta King" },
+		},
+		{
+			name: "remove externalId",
+			ops:  `[{"op":"remove","path":"externalId"}]`,
+			want: func(u *userRow) { u.externalID = sql.NullString{} },
+		},
+		{
+			name: "schema URN path",
+			ops:  `[{"op":"replace","path":"urn:ietf:params:scim:schemas:core:2.0:User:userName","value":"a@b.c"}]`,
+			want: func(u *userRow) { u.userName = "a@b.c" },
+		},
+		{
+			name: "unsupported attributes are ignored",
+			ops:  `[{"op":"replace","path":"title","value":"Analyst"},{"op":"replace","path":"emails[type eq \"work\"].value","value":"x@y.z"}]`,
+			want: func(u *userRow) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := base(), base()
+			require.NoError(t, applyUserPatch(got, ops(t, tt.ops)))
+			tt.want(want)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestApplyUserPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ops      string
+		scimType string
+	}{
+		{name: "no operations", ops: `[]`, scimType: "invalidValue"},
+		{name: "unknown op", ops: `[{"op":"copy","path":"active"}]`, scimType: "invalidSyntax"},
+		{name: "remove without path", ops: `[{"op":"remove"}]`, scimType: "noTarget"},
+		{name: "remove userName", ops: `[{"op":"remove","path":"userName"}]`, scimType: "mutability"},
+		{name: "empty userName", ops: `[{"op":"replace","path":"userName","value":" "}]`, scimType: "invalidValue"},
+		{name: "non-boolean active", ops: `[{"op":"replace","path":"active","value":"maybe"}]`, scimType: "invalidValue"},
+		{name: "non-object value without path", ops: `[{"op":"replace","value":"x"}]`, scimType: "invalidValue"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyUserPatch(&userRow{userName: "ada@example.com"}, ops(t, tt.ops))
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, tt.scimType, scimErr.ScimType)
+		})
+	}
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		ops         string
+		wantName    string
+		wantMembers []int64
+	}{
+		{
+			name:        "add members",
+			ops:         `[{"op":"add","path":"members","value":[{"value":"9"},{"value":"1"}]}]`,
+			wantName:    "Engineering",
+			wantMembers: []int64{1, 3, 5, 9},
+		},
+		{
+			name:        "remove member by filter",
+			ops:         `[{"op":"remove","path":"members[value eq \"3\"]"}]`,
+			wantName:    "Engineering",
+			wantMembers: []int64{1, 5},
+		},
+		{
+			name:        "remove listed members",
+			ops:         `[{"op":"remove","path":"members","value":[{"value":"1"},{"value":"5"}]}]`,
+			wantName:    "Engineering",
+			wantMembers: []int64{3},
+		},
+		{
+			name:        "remove all members",
+			ops:         `[{"op":"remove","path":"members"}]`,
+			wantName:    "Engineering",
+			wantMembers: nil,
+		},
+		{
+			name:        "replace members and name",
+			ops:         `[{"op":"replace","value":{"displayName":"Eng","members":[{"value":"4"}]}}]`,
+			wantName:    "Eng",
+			wantMembers: []int64{4},
+		},
+		{
+			name:        "nested group members are ignored",
+			ops:         `[{"op":"add","path":"members","value":[{"value":"2","type":"Group"}]}]`,
+			wantName:    "Engineering",
+			wantMembers: []int64{1, 3, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &groupState{row: &groupRow{id: 1, name: "Engineering"}, members: []int64{1, 3, 5}}
+			require.NoError(t, applyGroupPatch(g, ops(t, tt.ops)))
+			assert.Equal(t, tt.wantName, g.row.name)
+			assert.Equal(t, tt.wantMembers, g.members)
+		})
+	}
+}
+
+func TestApplyGroupPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ops      string
+		scimType string
+	}{
+		{name: "remove displayName", ops: `[{"op":"remove","path":"displayName"}]`, scimType: "mutability"},
+		{name: "invalid member id", ops: `[{"op":"add","path":"members","value":[{"value":"abc"}]}]`, scimType: "invalidValue"},
+		{name: "members not an array", ops: `[{"op":"add","path":"members","value":{"value":"1"}}]`, scimType: "invalidValue"},
+		{name: "value filter with add", ops: `[{"op":"add","path":"members[value eq \"1\"]"}]`, scimType: "invalidPath"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &groupState{row: &groupRow{id: 1, name: "Engineering"}}
+			err := applyGroupPatch(g, ops(t, tt.ops))
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, tt.scimType, scimErr.ScimType)
+		})
+	}
+}
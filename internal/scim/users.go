@@ -0,0 +1,404 @@
+package scim
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/txn"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+// userColumns is the column list scanned by scanUser
+const userColumns = "id, " + users.PIIColumns + ", external_id, active, created_at, updated_at, version"
+
+// userNameIndex is the unique index on the blind index of users' emails,
+// which SCIM stores userName as
+const userNameIndex = "idx_users_email_index"
+
+// userRow is a row of the users table
+type userRow struct {
+	id         int64
+	userName   string
+	name       string
+	externalID sql.NullString
+	active     bool
+	createdAt  time.Time
+	updatedAt  time.Time
+	version    int
+}
+
+// scanUser scans a row of userColumns, decrypting the email and name
+func (h *Handler) scanUser(row rowScanner) (*userRow, error) {
+	var u userRow
+	var pii users.PII
+	dest := append([]any{&u.id}, pii.Dest()...)
+	err := row.Scan(append(dest, &u.externalID, &u.active, &u.createdAt, &u.updatedAt, &u.version)...)
+	if err != nil {
+		return nil, err
+	}
+	if u.userName, u.name, err = h.users.OpenPII(u.id, &pii); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// resource converts a user row to its SCIM representation
+func (h *Handler) userResource(u *userRow) *User {
+	given, family := splitName(u.name)
+	active := u.active
+	return &User{
+		Schemas:     []string{UserSchema},
+		ID:          strconv.FormatInt(u.id, 10),
+		ExternalID:  u.externalID.String,
+		UserName:    u.userName,
+		Name:        &Name{Formatted: u.name, GivenName: given, FamilyName: family},
+		DisplayName: u.name,
+		Emails:      []Email{{Value: u.userName, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      formatTime(u.createdAt),
+			LastModified: formatTime(u.updatedAt),
+			Location:     h.location("Users", u.id),
+			Version:      etag(u.version),
+		},
+	}
+}
+
+// applyUser sets the writable attributes of u from a SCIM User. Emails are
+// reported from userName and ignored on input, since a user has a single
+// email address.
+func applyUser(u *userRow, in *User) error {
+	userName := strings.TrimSpace(in.UserName)
+	if userName == "" {
+		return newError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	u.userName = userName
+	u.name = userDisplayName(in)
+	u.externalID = sql.NullString{String: in.ExternalID, Valid: in.ExternalID != ""}
+	u.active = in.Active == nil || *in.Active
+	return nil
+}
+
+// userDisplayName picks the name stored for a SCIM User
+func userDisplayName(in *User) string {
+	if in.Name != nil {
+		if in.Name.Formatted != "" {
+			return in.Name.Formatted
+		}
+		if name := joinName(in.Name.GivenName, in.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+	return in.DisplayName
+}
+
+// splitName splits a stored name into given and family names at the first space
+func splitName(name string) (given, family string) {
+	given, family, _ = strings.Cut(strings.TrimSpace(name), " ")
+	return given, strings.TrimSpace(family)
+}
+
+// joinName joins given and family names
+func joinName(given, family string) string {
+	return strings.TrimSpace(strings.TrimSpace(given) + " " + strings.TrimSpace(family))
+}
+
+// listUsers handles GET /Users
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) error {
+	page, err := parsePage(r)
+	if err != nil {
+		return err
+	}
+
+	where, args := "", []any{}
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		cond, filterArgs, err := parseFilter(filter, userAttributes, h.users.EmailIndex, 0)
+		if err != nil {
+			return err
+		}
+		where, args = " WHERE "+cond, filterArgs
+	}
+
+	var total int
+	if err := h.db.QueryRowContext(r.Context(), "SELECT count(*) FROM users"+where, args...).Scan(&total); err != nil {
+		return err
+	}
+
+	resources := make([]any, 0)
+	if page.count > 0 && page.startIndex <= total {
+		query := "SELECT " + userColumns + " FROM users" + where +
+			" ORDER BY id LIMIT $" + strconv.Itoa(len(args)+1) + " OFFSET $" + strconv.Itoa(len(args)+2)
+		rows, err := h.db.QueryContext(r.Context(), query, append(args, page.count, page.startIndex-1)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			u, err := h.scanUser(rows)
+			if err != nil {
+				return err
+			}
+			resources = append(resources, h.userResource(u))
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	h.writeJSON(w, r, http.StatusOK, newListResponse(total, page.startIndex, resources))
+	return nil
+}
+
+// getUser handles GET /Users/{id}
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	u, err := h.loadUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if notModified(r, u.version) {
+		w.Header().Set("ETag", etag(u.version))
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	h.writeUser(w, r, http.StatusOK, u)
+	return nil
+}
+
+// createUser handles POST /Users
+func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) error {
+	var in User
+	if err := decodeBody(w, r, &in); err != nil {
+		return err
+	}
+	var u userRow
+	if err := applyUser(&u, &in); err != nil {
+		return err
+	}
+	if err := h.checkUserNameAvailable(r.Context(), u.userName, 0); err != nil {
+		return err
+	}
+
+	var created *userRow
+	err := h.txns.Do(r.Context(), func(ctx context.Context) (err error) {
+		tx, _ := txn.Tx(ctx)
+		// The id is allocated first, as the encrypted columns are bound to it
+		id, err := h.users.NextUserID(ctx)
+		if err != nil {
+			return err
+		}
+		sealed, err := h.users.SealPII(id, u.userName, u.name)
+		if err != nil {
+			return err
+		}
+		created, err = h.scanUser(tx.QueryRowContext(ctx,
+			"INSERT INTO users (id, "+users.SealedColumns+", external_id, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING "+userColumns,
+			append(append([]any{id}, sealed...), u.externalID, u.active)...))
+		if err != nil {
+			return userWriteError(err)
+		}
+		return audit.Record(ctx, tx, userResourceName(created.id))
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", h.location("Users", created.id))
+	h.writeUser(w, r, http.StatusCreated, created)
+	return nil
+}
+
+// replaceUser handles PUT /Users/{id}
+func (h *Handler) replaceUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	var in User
+	if err := decodeBody(w, r, &in); err != nil {
+		return err
+	}
+	u, err := h.loadUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(r, u.version); err != nil {
+		return err
+	}
+	userName := u.userName
+	if err := applyUser(u, &in); err != nil {
+		return err
+	}
+	if !strings.EqualFold(u.userName, userName) {
+		audit.SetMethod(r.Context(), users.SCIMReplaceUserEmailMethod)
+	}
+
+	updated, err := h.saveUser(r.Context(), u)
+	if err != nil {
+		return err
+	}
+	h.writeUser(w, r, http.StatusOK, updated)
+	return nil
+}
+
+// patchUser handles PATCH /Users/{id}
+func (h *Handler) patchUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	var req PatchRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return err
+	}
+	u, err := h.loadUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(r, u.version); err != nil {
+		return err
+	}
+	userName := u.userName
+	if err := applyUserPatch(u, req.Operations); err != nil {
+		return err
+	}
+	if !strings.EqualFold(u.userName, userName) {
+		audit.SetMethod(r.Context(), users.SCIMPatchUserEmailMethod)
+	}
+
+	updated, err := h.saveUser(r.Context(), u)
+	if err != nil {
+		return err
+	}
+	h.writeUser(w, r, http.StatusOK, updated)
+	return nil
+}
+
+// deleteUser handles DELETE /Users/{id}
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseID(r)
+	if err != nil {
+		return err
+	}
+	if r.Header.Get("If-Match") != "" {
+		u, err := h.loadUser(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(r, u.version); err != nil {
+			return err
+		}
+	}
+
+	err = h.txns.Do(r.Context(), func(ctx context.Context) error {
+		tx, _ := txn.Tx(ctx)
+		result, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return notFound("User", id)
+		}
+		return audit.Record(ctx, tx, userResourceName(id))
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// loadUser fetches a user by id
+func (h *Handler) loadUser(ctx context.Context, id int64) (*userRow, error) {
+	u, err := h.scanUser(h.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("User", id)
+	}
+	return u, err
+}
+
+// saveUser writes the attributes of u, provided the row is still at the
+// version u was loaded at
+func (h *Handler) saveUser(ctx context.Context, u *userRow) (*userRow, error) {
+	if err := h.checkUserNameAvailable(ctx, u.userName, u.id); err != nil {
+		return nil, err
+	}
+	sealed, err := h.users.SealPII(u.id, u.userName, u.name)
+	if err != nil {
+		return nil, err
+	}
+	var updated *userRow
+	err = h.txns.Do(ctx, func(ctx context.Context) (err error) {
+		tx, _ := txn.Tx(ctx)
+		updated, err = h.scanUser(tx.QueryRowContext(ctx,
+			`UPDATE users SET (`+users.SealedColumns+`) = ($2, $3, $4, $5, $6, $7, $8, $9),
+			external_id = $10, active = $11, updated_at = now(), version = version + 1
+			WHERE id = $1 AND version = $12 RETURNING `+userColumns,
+			append(append([]any{u.id}, sealed...), u.externalID, u.active, u.version)...))
+		if errors.Is(err, sql.ErrNoRows) {
+			return newError(http.StatusPreconditionFailed, "", "User %d was modified concurrently", u.id)
+		}
+		if err != nil {
+			return userWriteError(err)
+		}
+		return audit.Record(ctx, tx, userResourceName(u.id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// userResourceName names a user in the audit log, as the UserService does
+func userResourceName(id int64) string {
+	return fmt.Sprintf("users/%d", id)
+}
+
+// checkUserNameAvailable rejects a userName already used by a user other than exceptID
+func (h *Handler) checkUserNameAvailable(ctx context.Context, userName string, exceptID int64) error {
+	var taken bool
+	err := h.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM users WHERE (email_index = $1 OR lower(email) = lower($2)) AND id <> $3)",
+		h.users.EmailIndex(userName), userName, exceptID).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return newError(http.StatusConflict, "uniqueness", "userName %q is already in use", userName)
+	}
+	return nil
+}
+
+// userWriteError translates constraint violations from writing a user. The
+// check of checkUserNameAvailable can race with another write; the unique
+// blind index settles it.
+func userWriteError(err error) error {
+	if !isUniqueViolation(err) {
+		return err
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == userNameIndex {
+		return newError(http.StatusConflict, "uniqueness", "userName is already in use")
+	}
+	return newError(http.StatusConflict, "uniqueness", "externalId is already in use")
+}
+
+// writeUser writes a user resource along with its ETag
+func (h *Handler) writeUser(w http.ResponseWriter, r *http.Request, code int, u *userRow) {
+	w.Header().Set("ETag", etag(u.version))
+	h.writeJSON(w, r, code, h.userResource(u))
+}
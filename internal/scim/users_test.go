@@ -0,0 +1,539 @@
+package scim
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zcking/go-api-template/internal/users"
+)
+
+var userRowColumns = []string{"id", "email", "name", "email_ciphertext", "name_ciphertext", "data_key", "key_version", "pii_bound",
+	"external_id", "active", "created_at", "updated_at", "version"}
+
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// sealedUserRow returns a users row with an encrypted email and name
+func sealedUserRow(id int64, email, name string, externalID any, active bool, version int) []driver.Value {
+	e, err := testKeyring.Seal(strconv.FormatInt(id, 10), email, name)
+	if err != nil {
+		panic(err)
+	}
+	return []driver.Value{id, nil, nil, e.Values[0], e.Values[1], e.DataKey, e.KeyVersion, true,
+		externalID, active, testTime, testTime, version}
+}
+
+// userRows returns a single users row
+func userRows(id int64, email, name string, externalID any, active bool, version int) *sqlmock.Rows {
+	return sqlmock.NewRows(userRowColumns).AddRow(sealedUserRow(id, email, name, externalID, active, version)...)
+}
+
+// sealedArgs matches the values of users.SealedColumns written for email
+func sealedArgs(email string) []driver.Value {
+	return []driver.Value{nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), testKeyring.BlindIndex(strings.ToLower(email)), sqlmock.AnyArg(), "1", true}
+}
+
+// expectNextUserID expects the id of a new user to be allocated
+func expectNextUserID(mock sqlmock.Sqlmock, id int64) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT nextval('seq_users_id')")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+}
+
+func expectLoadUser(mock sqlmock.Sqlmock, id int64, rows *sqlmock.Rows) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + userColumns + " FROM users WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(rows)
+}
+
+func expectUserNameCheck(mock sqlmock.Sqlmock, userName string, exceptID int64, taken bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM users WHERE (email_index = $1 OR lower(email) = lower($2)) AND id <> $3)")).
+		WithArgs(testKeyring.BlindIndex(strings.ToLower(userName)), userName, exceptID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(taken))
+}
+
+func TestHandler_ListUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		mockSetup func(sqlmock.Sqlmock)
+		check     func(*testing.T, map[string]any)
+		wantCode  int
+		scimType  string
+	}{
+		{
+			name:  "filter by userName",
+			query: `?filter=userName+eq+%22ada%40example.com%22`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				where := "WHERE (email_index = $1 OR lower(email) = lower($2))"
+				index := testKeyring.BlindIndex("ada@example.com")
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM users "+where)).
+					WithArgs(index, "ada@example.com").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT "+userColumns+" FROM users "+where+" ORDER BY id LIMIT $3 OFFSET $4")).
+					WithArgs(index, "ada@example.com", DefaultCount, 0).
+					WillReturnRows(userRows(1, "ada@example.com", "Ada Lovelace", "00u1", true, 1))
+			},
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				assert.Equal(t, []any{ListResponseSchema}, body["schemas"])
+				assert.EqualValues(t, 1, body["totalResults"])
+				assert.EqualValues(t, 1, body["itemsPerPage"])
+				assert.EqualValues(t, 1, body["startIndex"])
+				user := body["Resources"].([]any)[0].(map[string]any)
+				assert.Equal(t, "1", user["id"])
+				assert.Equal(t, "ada@example.com", user["userName"])
+				assert.Equal(t, "00u1", user["externalId"])
+				assert.Equal(t, map[string]any{"formatted": "Ada Lovelace", "givenName": "Ada", "familyName": "Lovelace"}, user["name"])
+				assert.Equal(t, `W/"1"`, user["meta"].(map[string]any)["version"])
+				assert.Equal(t, Prefix+"/Users/1", user["meta"].(map[string]any)["location"])
+			},
+		},
+		{
+			name:  "pagination",
+			query: "?startIndex=3&count=2",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM users")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT "+userColumns+" FROM users ORDER BY id LIMIT $1 OFFSET $2")).
+					WithArgs(2, 2).
+					WillReturnRows(userRows(3, "c@example.com", "C", nil, true, 1).
+						AddRow(sealedUserRow(4, "d@example.com", "D", nil, false, 2)...))
+			},
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				assert.EqualValues(t, 5, body["totalResults"])
+				assert.EqualValues(t, 3, body["startIndex"])
+				assert.Len(t, body["Resources"], 2)
+			},
+		},
+		{
+			name:  "count zero only returns the total",
+			query: "?count=0",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM users")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+			},
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				assert.EqualValues(t, 5, body["totalResults"])
+				assert.Equal(t, []any{}, body["Resources"])
+			},
+		},
+		{
+			name:      "invalid filter",
+			query:     `?filter=nickName+eq+%22ada%22`,
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantCode:  http.StatusBadRequest,
+			scimType:  "invalidFilter",
+		},
+		{
+			name:  "database error",
+			query: "",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM users")).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mock := newMockHandler(t)
+			tt.mockSetup(mock)
+
+			rec := serve(t, h, http.MethodGet, "/Users"+tt.query, "")
+			if tt.wantCode != http.StatusOK {
+				assertError(t, rec, tt.wantCode, tt.scimType)
+			} else {
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				tt.check(t, decode(t, rec))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHandler_GetUser(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 4))
+
+		rec := serve(t, h, http.MethodGet, "/Users/1", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `W/"4"`, rec.Header().Get("ETag"))
+		assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+		body := decode(t, rec)
+		assert.Equal(t, []any{UserSchema}, body["schemas"])
+		assert.Equal(t, true, body["active"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 4))
+
+		rec := serve(t, h, http.MethodGet, "/Users/1", "", "If-None-Match", `W/"4"`)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 9, sqlmock.NewRows(userRowColumns))
+
+		assertError(t, serve(t, h, http.MethodGet, "/Users/9", ""), http.StatusNotFound, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("non-numeric id", func(t *testing.T) {
+		h, _ := newMockHandler(t)
+		assertError(t, serve(t, h, http.MethodGet, "/Users/abc", ""), http.StatusNotFound, "")
+	})
+}
+
+func TestHandler_CreateUser(t *testing.T) {
+	insert := regexp.QuoteMeta("INSERT INTO users (id, " + users.SealedColumns + ", external_id, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING " + userColumns)
+
+	tests := []struct {
+		name      string
+		body      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantCode  int
+		scimType  string
+	}{
+		{
+			name: "created",
+			body: `{"schemas":["` + UserSchema + `"],"userName":"ada@example.com","externalId":"00u1",
+				"name":{"givenName":"Ada","familyName":"Lovelace"},"emails":[{"value":"other@example.com"}],"active":true}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectUserNameCheck(mock, "ada@example.com", 0, false)
+				mock.ExpectBegin()
+				expectNextUserID(mock, 7)
+				mock.ExpectQuery(insert).
+					WithArgs(append(append([]driver.Value{int64(7)}, sealedArgs("ada@example.com")...), sql.NullString{String: "00u1", Valid: true}, true)...).
+					WillReturnRows(userRows(7, "ada@example.com", "Ada Lovelace", "00u1", true, 1))
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name: "inactive without externalId",
+			body: `{"userName":"bob@example.com","displayName":"Bob","active":false}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectUserNameCheck(mock, "bob@example.com", 0, false)
+				mock.ExpectBegin()
+				expectNextUserID(mock, 8)
+				mock.ExpectQuery(insert).
+					WithArgs(append(append([]driver.Value{int64(8)}, sealedArgs("bob@example.com")...), sql.NullString{}, false)...).
+					WillReturnRows(userRows(8, "bob@example.com", "Bob", nil, false, 1))
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "missing userName",
+			body:      `{"displayName":"Ada"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantCode:  http.StatusBadRequest,
+			scimType:  "invalidValue",
+		},
+		{
+			name:      "malformed body",
+			body:      `{"userName":`,
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantCode:  http.StatusBadRequest,
+			scimType:  "invalidSyntax",
+		},
+		{
+			name: "userName taken",
+			body: `{"userName":"ada@example.com"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectUserNameCheck(mock, "ada@example.com", 0, true)
+			},
+			wantCode: http.StatusConflict,
+			scimType: "uniqueness",
+		},
+		{
+			name: "userName taken concurrently",
+			body: `{"userName":"ada@example.com"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectUserNameCheck(mock, "ada@example.com", 0, false)
+				mock.ExpectBegin()
+				expectNextUserID(mock, 7)
+				mock.ExpectQuery(insert).WillReturnError(&pq.Error{Code: pgUniqueViolation, Constraint: userNameIndex})
+				mock.ExpectRollback()
+			},
+			wantCode: http.StatusConflict,
+			scimType: "uniqueness",
+		},
+		{
+			name: "externalId taken",
+			body: `{"userName":"ada@example.com","externalId":"00u1"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectUserNameCheck(mock, "ada@example.com", 0, false)
+				mock.ExpectBegin()
+				expectNextUserID(mock, 7)
+				mock.ExpectQuery(insert).WillReturnError(&pq.Error{Code: pgUniqueViolation})
+				mock.ExpectRollback()
+			},
+			wantCode: http.StatusConflict,
+			scimType: "uniqueness",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mock := newMockHandler(t)
+			tt.mockSetup(mock)
+
+			rec := serve(t, h, http.MethodPost, "/Users", tt.body)
+			if tt.wantCode == http.StatusCreated {
+				require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+				body := decode(t, rec)
+				assert.Equal(t, Prefix+"/Users/"+body["id"].(string), rec.Header().Get("Location"))
+				assert.Equal(t, `W/"1"`, rec.Header().Get("ETag"))
+			} else {
+				assertError(t, rec, tt.wantCode, tt.scimType)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectSaveUser expects the update of a user in a transaction, which the
+// caller finishes
+func expectSaveUser(mock sqlmock.Sqlmock, id int64, email, name string, externalID sql.NullString, active bool, version int) *sqlmock.ExpectedQuery {
+	expectUserNameCheck(mock, email, id, false)
+	mock.ExpectBegin()
+	args := append([]driver.Value{id}, sealedArgs(email)...)
+	return mock.ExpectQuery(`UPDATE users SET \(` + regexp.QuoteMeta(users.SealedColumns) + `\) = \(\$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9\),\s+` +
+		`external_id = \$10, active = \$11, updated_at = now\(\), version = version \+ 1\s+WHERE id = \$1 AND version = \$12`).
+		WithArgs(append(args, externalID, active, version)...)
+}
+
+func TestHandler_ReplaceUser(t *testing.T) {
+	t.Run("replaced", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", "00u1", true, 2))
+		expectSaveUser(mock, 1, "ada@example.org", "Ada L", sql.NullString{}, true, 2).
+			WillReturnRows(userRows(1, "ada@example.org", "Ada L", nil, true, 3))
+		mock.ExpectCommit()
+
+		rec := serve(t, h, http.MethodPut, "/Users/1", `{"userName":"ada@example.org","displayName":"Ada L"}`,
+			"If-Match", `W/"2"`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `W/"3"`, rec.Header().Get("ETag"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 3))
+
+		rec := serve(t, h, http.MethodPut, "/Users/1", `{"userName":"ada@example.org"}`, "If-Match", `W/"2"`)
+		assertError(t, rec, http.StatusPreconditionFailed, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("concurrent modification", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 2))
+		expectSaveUser(mock, 1, "ada@example.com", "Ada", sql.NullString{}, true, 2).
+			WillReturnRows(sqlmock.NewRows(userRowColumns))
+		mock.ExpectRollback()
+
+		rec := serve(t, h, http.MethodPut, "/Users/1", `{"userName":"ada@example.com","displayName":"Ada"}`)
+		assertError(t, rec, http.StatusPreconditionFailed, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, sqlmock.NewRows(userRowColumns))
+
+		rec := serve(t, h, http.MethodPut, "/Users/1", `{"userName":"ada@example.com"}`)
+		assertError(t, rec, http.StatusNotFound, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_PatchUser(t *testing.T) {
+	t.Run("deactivate", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada Lovelace", "00u1", true, 2))
+		expectSaveUser(mock, 1, "ada@example.com", "Ada Lovelace", sql.NullString{String: "00u1", Valid: true}, false, 2).
+			WillReturnRows(userRows(1, "ada@example.com", "Ada Lovelace", "00u1", false, 3))
+		mock.ExpectCommit()
+
+		body := `{"schemas":["` + PatchOpSchema + `"],"Operations":[{"op":"replace","value":{"active":false}}]}`
+		rec := serve(t, h, http.MethodPatch, "/Users/1", body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, false, decode(t, rec)["active"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 2))
+
+		rec := serve(t, h, http.MethodPatch, "/Users/1", `{"Operations":[{"op":"move","path":"active"}]}`)
+		assertError(t, rec, http.StatusBadRequest, "invalidSyntax")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_DeleteUser(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		rec := serve(t, h, http.MethodDelete, "/Users/1", "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE id = $1")).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assertError(t, serve(t, h, http.MethodDelete, "/Users/1", ""), http.StatusNotFound, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		h, mock := newMockHandler(t)
+		expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 3))
+
+		rec := serve(t, h, http.MethodDelete, "/Users/1", "", "If-Match", `W/"1"`)
+		assertError(t, rec, http.StatusPreconditionFailed, "")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandler_AuditUsers(t *testing.T) {
+	insert := regexp.QuoteMeta("INSERT INTO users (id, " + users.SealedColumns + ", external_id, active)")
+	deleteUser := regexp.QuoteMeta("DELETE FROM users WHERE id = $1")
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantCode  int
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			path:   "/Users",
+			body:   `{"userName":"ada@example.com"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectUserNameCheck(mock, "ada@example.com", 0, false)
+				mock.ExpectBegin()
+				expectNextUserID(mock, 7)
+				mock.ExpectQuery(insert).WillReturnRows(userRows(7, "ada@example.com", "", nil, true, 1))
+				expectAuditEvent(mock, users.SCIMCreateUserMethod, "users/7", "OK")
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:   "replace changing the email",
+			method: http.MethodPut,
+			path:   "/Users/1",
+			body:   `{"userName":"ada@example.org","displayName":"Ada"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 2))
+				expectSaveUser(mock, 1, "ada@example.org", "Ada", sql.NullString{}, true, 2).
+					WillReturnRows(userRows(1, "ada@example.org", "Ada", nil, true, 3))
+				expectAuditEvent(mock, users.SCIMReplaceUserEmailMethod, "users/1", "OK")
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "replace keeping the email",
+			method: http.MethodPut,
+			path:   "/Users/1",
+			body:   `{"userName":"Ada@Example.com","displayName":"Ada L"}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 2))
+				expectSaveUser(mock, 1, "Ada@Example.com", "Ada L", sql.NullString{}, true, 2).
+					WillReturnRows(userRows(1, "Ada@Example.com", "Ada L", nil, true, 3))
+				expectAuditEvent(mock, "SCIM PUT /Users/{id}", "users/1", "OK")
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "patch changing the email",
+			method: http.MethodPatch,
+			path:   "/Users/1",
+			body:   `{"Operations":[{"op":"replace","path":"userName","value":"ada@example.org"}]}`,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectLoadUser(mock, 1, userRows(1, "ada@example.com", "Ada", nil, true, 2))
+				expectSaveUser(mock, 1, "ada@example.org", "Ada", sql.NullString{}, true, 2).
+					WillReturnRows(userRows(1, "ada@example.org", "Ada", nil, true, 3))
+				expectAuditEvent(mock, users.SCIMPatchUserEmailMethod, "users/1", "OK")
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			path:   "/Users/1",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAuditEvent(mock, users.SCIMDeleteUserMethod, "users/1", "OK")
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "failed delete is appended on its own",
+			method: http.MethodDelete,
+			path:   "/Users/1",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				expectAuditEvent(mock, users.SCIMDeleteUserMethod, "", "NotFound")
+				mock.ExpectCommit()
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mock := newAuditedHandler(t)
+			tt.mockSetup(mock)
+
+			rec := serve(t, h, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
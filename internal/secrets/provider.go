@@ -0,0 +1,97 @@
+// Package secrets reads secrets such as database passwords from where they
+// are kept, and keeps them current as they are rotated.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Provider returns the current value of a secret
+type Provider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// Static is a secret whose value is known at startup, such as one set in
+// the configuration file or on the command line. It never changes.
+type Static string
+
+// Secret returns s
+func (s Static) Secret(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+// Env reads a secret from the environment variable it names each time it
+// is needed
+type Env string
+
+// Secret returns the value of the environment variable
+func (e Env) Secret(ctx context.Context) (string, error) {
+	value, ok := os.LookupEnv(string(e))
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(e))
+	}
+	return value, nil
+}
+
+// File reads a secret from the file at its path, such as one mounted by
+// Docker or Kubernetes secrets, which update the file when the secret is
+// rotated. Surrounding whitespace, such as a trailing newline, is removed.
+type File string
+
+// Secret returns the contents of the file
+func (f File) Secret(ctx context.Context) (string, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", string(f))
+	}
+	return value, nil
+}
+
+// Command runs a program and reads a secret from its standard output, for
+// secret managers with a command line client. Surrounding whitespace is
+// removed.
+type Command struct {
+	// Args are the program and its arguments
+	Args []string
+	// Timeout bounds how long the program may run; 0 means no limit
+	Timeout time.Duration
+}
+
+// Secret runs the program and returns its output
+func (c Command) Secret(ctx context.Context) (string, error) {
+	if len(c.Args) == 0 {
+		return "", errors.New("secret command is empty")
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Args[0], c.Args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// The output may be part of the secret; stderr only explains the failure
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return "", fmt.Errorf("secret command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+	value := strings.TrimSpace(stdout.String())
+	if value == "" {
+		return "", errors.New("secret command printed nothing")
+	}
+	return value, nil
+}
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSecret writes content to a file in a temporary directory and returns
+// its path
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestProviders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command providers run sh")
+	}
+	t.Setenv("TEST_SECRET", "from-env")
+
+	tests := []struct {
+		name     string
+		provider Provider
+		want     string
+		wantErr  string
+	}{
+		{name: "static", provider: Static("s3cret"), want: "s3cret"},
+		{name: "env", provider: Env("TEST_SECRET"), want: "from-env"},
+		{name: "unset env", provider: Env("TEST_SECRET_UNSET"), wantErr: "environment variable TEST_SECRET_UNSET is not set"},
+		{name: "file", provider: File(writeSecret(t, "from-file\n")), want: "from-file"},
+		{name: "missing file", provider: File(filepath.Join(t.TempDir(), "missing")), wantErr: "failed to read secret file"},
+		{name: "empty file", provider: File(writeSecret(t, "\n")), wantErr: "is empty"},
+		{name: "command", provider: Command{Args: []string{"sh", "-c", "echo from-command"}}, want: "from-command"},
+		{
+			name:     "failing command",
+			provider: Command{Args: []string{"sh", "-c", "echo token; echo access denied >&2; exit 3"}},
+			wantErr:  "secret command failed: exit status 3: access denied",
+		},
+		{name: "silent command", provider: Command{Args: []string{"true"}}, wantErr: "secret command printed nothing"},
+		{
+			name:     "slow command",
+			provider: Command{Args: []string{"sleep", "5"}, Timeout: 10 * time.Millisecond},
+			wantErr:  "secret command failed",
+		},
+		{name: "empty command", provider: Command{}, wantErr: "secret command is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Secret(t.Context())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.NotContains(t, err.Error(), "token", "command output is not included in errors")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
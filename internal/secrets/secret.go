@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Secret holds the current value of a secret, read from its provider when
+// it is loaded and again on each refresh
+type Secret struct {
+	name     string
+	provider Provider
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	value     atomic.Pointer[string]
+	listeners []func()
+}
+
+// Load reads the secret called name, for logs and errors, from provider
+func Load(ctx context.Context, name string, provider Provider, logger *slog.Logger) (*Secret, error) {
+	value, err := provider.Secret(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	s := &Secret{name: name, provider: provider, logger: logger}
+	s.value.Store(&value)
+	return s, nil
+}
+
+// Value returns the current value of the secret
+func (s *Secret) Value() string {
+	return *s.value.Load()
+}
+
+// OnChange registers fn to be called after each refresh that changes the
+// secret. Listeners must be registered before Watch.
+func (s *Secret) OnChange(fn func()) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// Refresh reads the secret from its provider again. If that fails, the
+// current value is kept.
+func (s *Secret) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, err := s.provider.Secret(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", s.name, err)
+	}
+	if value == s.Value() {
+		return nil
+	}
+	s.value.Store(&value)
+	s.logger.Info("secret changed", "secret", s.name)
+	for _, fn := range s.listeners {
+		fn()
+	}
+	return nil
+}
+
+// Watch refreshes the secret every interval until ctx is done. Failed
+// refreshes are logged and retried at the next interval.
+func (s *Secret) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.Warn("keeping the current value of a secret", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// providerFunc adapts a function to a Provider
+type providerFunc func(ctx context.Context) (string, error)
+
+func (f providerFunc) Secret(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func TestLoad(t *testing.T) {
+	s, err := Load(t.Context(), "database.password", Static("s3cret"), testLogger)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", s.Value())
+
+	_, err = Load(t.Context(), "database.password", Env("TEST_SECRET_UNSET"), testLogger)
+	assert.ErrorContains(t, err, "failed to read database.password: environment variable TEST_SECRET_UNSET is not set")
+}
+
+func TestSecret_Refresh(t *testing.T) {
+	path := writeSecret(t, "v1")
+	s, err := Load(t.Context(), "database.password", File(path), testLogger)
+	require.NoError(t, err)
+	changes := 0
+	s.OnChange(func() { changes++ })
+
+	require.NoError(t, s.Refresh(t.Context()))
+	assert.Equal(t, 0, changes, "listeners are not called when the secret is unchanged")
+
+	require.NoError(t, os.WriteFile(path, []byte("v2\n"), 0o600))
+	require.NoError(t, s.Refresh(t.Context()))
+	assert.Equal(t, "v2", s.Value())
+	assert.Equal(t, 1, changes)
+
+	require.NoError(t, os.Remove(path))
+	assert.ErrorContains(t, s.Refresh(t.Context()), "failed to refresh database.password")
+	assert.Equal(t, "v2", s.Value(), "the current value is kept when a refresh fails")
+	assert.Equal(t, 1, changes)
+}
+
+func TestSecret_Watch(t *testing.T) {
+	var calls atomic.Int32
+	provider := providerFunc(func(ctx context.Context) (string, error) {
+		switch calls.Add(1) {
+		case 1:
+			return "v1", nil
+		case 2:
+			return "", errors.New("secret manager unavailable")
+		default:
+			return "v2", nil
+		}
+	})
+	s, err := Load(t.Context(), "auth.scim_bearer_token", provider, testLogger)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		s.Watch(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return s.Value() == "v2" }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+}
@@ -0,0 +1,236 @@
+// Package txn runs units of work in database transactions carried by the
+// context. Repository methods take the transaction from the context (see Tx
+// and Conn) instead of a parameter, so a change that spans several of them
+// commits or rolls back as one.
+package txn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/zcking/go-api-template/internal/database"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultMaxAttempts is how many times Do runs a unit of work that keeps
+// failing with serialization failures
+const DefaultMaxAttempts = 5
+
+// DefaultBackoff is the wait between attempts of a unit of work
+var DefaultBackoff = database.Backoff{Initial: 10 * time.Millisecond, Max: 500 * time.Millisecond}
+
+// ErrNoTransaction is returned by Savepoint when the context carries no
+// transaction
+var ErrNoTransaction = errors.New("no transaction in the context")
+
+// tracerName names the tracer of the package's spans
+const tracerName = "github.com/zcking/go-api-template/internal/txn"
+
+// pgSerializationFailure is the Postgres error code of a transaction that
+// could not be serialized with concurrent ones and may succeed if retried
+const pgSerializationFailure = "40001"
+
+// Manager runs units of work in transactions on a database
+type Manager struct {
+	db          *sql.DB
+	backoff     database.Backoff
+	maxAttempts int
+	tracer      trace.Tracer
+	logger      *slog.Logger
+}
+
+// NewManager creates a transaction manager for db
+func NewManager(db *sql.DB, logger *slog.Logger) *Manager {
+	return &Manager{
+		db:          db,
+		backoff:     DefaultBackoff,
+		maxAttempts: DefaultMaxAttempts,
+		tracer:      otel.Tracer(tracerName),
+		logger:      logger,
+	}
+}
+
+// state is the transaction carried by a context
+type state struct {
+	tx *sql.Tx
+	// isolation is the isolation level tx was begun with
+	isolation sql.IsolationLevel
+	// savepoints counts the savepoints taken, to name the next one
+	savepoints int
+}
+
+type stateKey struct{}
+
+// Tx returns the transaction carried by ctx, if any
+func Tx(ctx context.Context) (*sql.Tx, bool) {
+	st, ok := ctx.Value(stateKey{}).(*state)
+	if !ok {
+		return nil, false
+	}
+	return st.tx, true
+}
+
+// Isolation returns the isolation level the transaction carried by ctx was
+// begun with, or sql.LevelDefault outside of one. sql.LevelDefault is the
+// server's default_transaction_isolation, READ COMMITTED unless it was
+// changed.
+func Isolation(ctx context.Context) sql.IsolationLevel {
+	st, ok := ctx.Value(stateKey{}).(*state)
+	if !ok {
+		return sql.LevelDefault
+	}
+	return st.isolation
+}
+
+// Executor is implemented by both *sql.DB and *sql.Tx
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Conn returns the transaction carried by ctx, or db outside of one
+func Conn(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := Tx(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// Do runs fn in a transaction, which fn and everything it calls find in the
+// context it is given. The transaction commits if fn returns nil and rolls
+// back otherwise, or if fn panics.
+//
+// Called within a transaction, Do runs fn in a savepoint of it instead, so
+// an error rolls back only what fn did and the caller may carry on.
+//
+// A transaction that fails with a serialization failure (40001), from fn or
+// at commit, is rolled back and fn is run again in a new one, up to
+// DefaultMaxAttempts times with backoff in between. fn must therefore not
+// have effects outside of the transaction. Savepoints are not retried on
+// their own: the error reaches the outermost Do, which retries everything.
+func (m *Manager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.DoWith(ctx, nil, fn)
+}
+
+// DoWith is Do with the options of the transaction, such as its isolation
+// level. They are ignored when fn runs in a savepoint.
+//
+// Audit events can only be recorded under READ COMMITTED: audit.Record
+// fails in a transaction begun with a stricter isolation level, whose
+// snapshot could hide the latest event from it and fork the hash chain.
+func (m *Manager) DoWith(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if st, ok := ctx.Value(stateKey{}).(*state); ok {
+		return savepoint(ctx, m.tracer, st, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := m.attempt(ctx, opts, attempt, fn)
+		if err == nil || !serializationFailure(err) || attempt == m.maxAttempts {
+			return err
+		}
+
+		wait := m.backoff.Wait(attempt)
+		m.logger.WarnContext(ctx, "transaction could not be serialized; retrying",
+			"attempt", attempt, "retry_in", wait.Round(time.Millisecond).String(), "error", err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt runs fn once in a new transaction
+func (m *Manager) attempt(ctx context.Context, opts *sql.TxOptions, attempt int, fn func(ctx context.Context) error) (err error) {
+	ctx, span := m.tracer.Start(ctx, "db.transaction",
+		trace.WithAttributes(attribute.Int("db.transaction.attempt", attempt)))
+	defer func() { end(span, err) }()
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	st := &state{tx: tx}
+	if opts != nil {
+		st.isolation = opts.Isolation
+	}
+	if err = fn(context.WithValue(ctx, stateKey{}, st)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Savepoint runs fn in a savepoint of the transaction carried by ctx, for
+// repository methods that make several changes and must undo all of them
+// on error without ending the caller's transaction. It is what a nested
+// Manager.Do does, for code that has no Manager.
+func Savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	st, ok := ctx.Value(stateKey{}).(*state)
+	if !ok {
+		return ErrNoTransaction
+	}
+	return savepoint(ctx, otel.Tracer(tracerName), st, fn)
+}
+
+// savepoint runs fn in a new savepoint of the transaction st
+func savepoint(ctx context.Context, tracer trace.Tracer, st *state, fn func(ctx context.Context) error) (err error) {
+	st.savepoints++
+	name := fmt.Sprintf("sp_%d", st.savepoints)
+	ctx, span := tracer.Start(ctx, "db.savepoint",
+		trace.WithAttributes(attribute.String("db.savepoint.name", name)))
+	defer func() { end(span, err) }()
+
+	if _, err = st.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	if err = fn(ctx); err != nil {
+		if _, rbErr := st.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back to savepoint: %w", rbErr))
+		}
+		return err
+	}
+	_, err = st.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// end ends span with the outcome of the unit of work
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// serializationFailure reports whether err means the transaction could not
+// be serialized with concurrent ones
+func serializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgSerializationFailure
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgSerializationFailure
+}
@@ -0,0 +1,306 @@
+package txn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zcking/go-api-template/internal/database"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestManager(t *testing.T) (*Manager, sqlmock.Sqlmock, *tracetest.SpanRecorder) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	recorder := tracetest.NewSpanRecorder()
+	m := NewManager(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.backoff = database.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	m.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	return m, mock, recorder
+}
+
+func insert(ctx context.Context, db *sql.DB, name string) error {
+	_, err := Conn(ctx, db).ExecContext(ctx, "INSERT INTO users (name) VALUES ($1)", name)
+	return err
+}
+
+func TestManager_Do(t *testing.T) {
+	t.Run("success - repository methods join the transaction", func(t *testing.T) {
+		m, mock, recorder := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).WithArgs("Ada").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO users`).WithArgs("Grace").WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			_, ok := Tx(ctx)
+			assert.True(t, ok)
+			if err := insert(ctx, m.db, "Ada"); err != nil {
+				return err
+			}
+			return insert(ctx, m.db, "Grace")
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "db.transaction", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+
+	t.Run("error - rolled back", func(t *testing.T) {
+		m, mock, recorder := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).WillReturnError(errors.New("boom"))
+		mock.ExpectRollback()
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			return insert(ctx, m.db, "Ada")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, codes.Error, recorder.Ended()[0].Status().Code)
+	})
+
+	t.Run("error - begin fails", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin().WillReturnError(errors.New("no connection"))
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			t.Fatal("fn must not run")
+			return nil
+		})
+		assert.EqualError(t, err, "no connection")
+	})
+
+	t.Run("panic - rolled back and re-raised", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = m.Do(context.Background(), func(ctx context.Context) error { panic("boom") })
+		})
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retry - serialization failure runs fn again", func(t *testing.T) {
+		m, mock, recorder := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		calls := 0
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return insert(ctx, m.db, "Ada")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Len(t, recorder.Ended(), 2, "one span per attempt")
+	})
+
+	t.Run("retry - serialization failure at commit", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		calls := 0
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retry - gives up after the last attempt", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		m.maxAttempts = 2
+		for range 2 {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+		}
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			return &pq.Error{Code: "40001"}
+		})
+		var pqErr *pq.Error
+		require.ErrorAs(t, err, &pqErr)
+		assert.Equal(t, pq.ErrorCode("40001"), pqErr.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retry - other errors are not retried", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		calls := 0
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return &pq.Error{Code: "23505"}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestManager_DoWith(t *testing.T) {
+	m, mock, _ := newTestManager(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err := m.DoWith(context.Background(), &sql.TxOptions{ReadOnly: true}, func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsolation(t *testing.T) {
+	m, mock, _ := newTestManager(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	assert.Equal(t, sql.LevelDefault, Isolation(context.Background()))
+	err := m.Do(context.Background(), func(ctx context.Context) error {
+		assert.Equal(t, sql.LevelDefault, Isolation(ctx))
+		return nil
+	})
+	require.NoError(t, err)
+	err = m.DoWith(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, func(ctx context.Context) error {
+		assert.Equal(t, sql.LevelRepeatableRead, Isolation(ctx))
+		return m.Do(ctx, func(ctx context.Context) error {
+			assert.Equal(t, sql.LevelRepeatableRead, Isolation(ctx), "savepoints keep the transaction's level")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestManager_Do_Savepoint(t *testing.T) {
+	t.Run("success - nested Do releases its savepoint", func(t *testing.T) {
+		m, mock, recorder := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO users`).WithArgs("Ada").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			return m.Do(ctx, func(ctx context.Context) error {
+				return insert(ctx, m.db, "Ada")
+			})
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "db.savepoint", spans[0].Name())
+		assert.Equal(t, "db.transaction", spans[1].Name())
+		assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	})
+
+	t.Run("error - only the savepoint is rolled back", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO users`).WithArgs("Ada").WillReturnError(&pq.Error{Code: "23505"})
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SAVEPOINT sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO users`).WithArgs("Grace").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			err := m.Do(ctx, func(ctx context.Context) error { return insert(ctx, m.db, "Ada") })
+			assert.Error(t, err)
+			return m.Do(ctx, func(ctx context.Context) error { return insert(ctx, m.db, "Grace") })
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retry - serialization failure in a savepoint retries the transaction", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO users`).WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO users`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			return m.Do(ctx, func(ctx context.Context) error { return insert(ctx, m.db, "Ada") })
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSavepoint(t *testing.T) {
+	t.Run("runs in a savepoint of the transaction", func(t *testing.T) {
+		m, mock, _ := newTestManager(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO users`).WithArgs("Ada").WillReturnError(errors.New("boom"))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := m.Do(context.Background(), func(ctx context.Context) error {
+			err := Savepoint(ctx, func(ctx context.Context) error { return insert(ctx, m.db, "Ada") })
+			assert.EqualError(t, err, "boom")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - no transaction", func(t *testing.T) {
+		err := Savepoint(context.Background(), func(ctx context.Context) error {
+			t.Fatal("fn must not run")
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrNoTransaction)
+	})
+}
+
+func TestConn(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Same(t, db, Conn(context.Background(), db))
+	_, ok := Tx(context.Background())
+	assert.False(t, ok)
+}
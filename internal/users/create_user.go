@@ -2,29 +2,71 @@ package users
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/users/userdb"
 )
 
 // CreateUser creates a new user in the database
 func (s *Service) CreateUser(ctx context.Context, req *userspb.CreateUserRequest) (*userspb.CreateUserResponse, error) {
-	// Insert user into database
-	row := s.db.QueryRowContext(ctx, "INSERT INTO users (email, name) VALUES ($1, $2) RETURNING id;", req.GetEmail(), req.GetName())
-	if row.Err() != nil {
-		return nil, row.Err()
+	var user *userspb.User
+	err := inTx(ctx, s.store, func(tx tx) (err error) {
+		if user, err = s.insertUser(ctx, tx, req); err != nil {
+			return err
+		}
+		return tx.record(ctx, fmt.Sprintf("users/%d", user.Id))
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &userspb.CreateUserResponse{User: user}, nil
+}
 
-	var userID int64
-	if err := row.Scan(&userID); err != nil {
+// CreateUserTx creates a new user within the transaction carried by ctx (see
+// txn.Manager), or on its own outside of one. It is the same path as
+// CreateUser, for callers that create a user as part of a larger atomic
+// change (e.g. accepting an invitation).
+func (s *Service) CreateUserTx(ctx context.Context, req *userspb.CreateUserRequest) (*userspb.User, error) {
+	return s.insertUser(ctx, conn(ctx, s.store), req)
+}
+
+func (s *Service) insertUser(ctx context.Context, q querier, req *userspb.CreateUserRequest) (*userspb.User, error) {
+	// The id is allocated first, as the encrypted columns are bound to it
+	userID, err := q.queries().NextUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.keyring.Seal(piiOwner(userID), req.GetEmail(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	// Insert user into database, leaving the plaintext columns null
+	err = q.queries().CreateUser(ctx, userdb.CreateUserParams{
+		ID:              userID,
+		EmailCiphertext: e.Values[0],
+		NameCiphertext:  e.Values[1],
+		EmailIndex:      s.EmailIndex(req.GetEmail()),
+		DataKey:         e.DataKey,
+		KeyVersion:      pgtype.Text{String: e.KeyVersion, Valid: true},
+		PiiBound:        true,
+	})
+	if uniqueViolation(err) {
+		return nil, status.Error(codes.AlreadyExists, "a user with this email already exists")
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	// Build response
-	user := &userspb.User{
+	return &userspb.User{
 		Id:    userID,
 		Email: req.GetEmail(),
 		Name:  req.GetName(),
-	}
-
-	return &userspb.CreateUserResponse{User: user}, nil
+	}, nil
 }
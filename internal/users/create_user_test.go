@@ -2,17 +2,36 @@ package users
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"log/slog"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/txn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// nextUserID matches the query allocating the id of a new user
+var nextUserID = regexp.QuoteMeta("SELECT nextval('seq_users_id')::bigint AS id")
+
+// insertUser matches the query inserting a user
+var insertUser = regexp.QuoteMeta("INSERT INTO users (id, " + SealedColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)")
+
+// sealedUserArgs matches the id and values of SealedColumns for a new user
+func sealedUserArgs(id int64, email string) []driver.Value {
+	return []driver.Value{id, nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), testKeyring.BlindIndex(email), sqlmock.AnyArg(), "1", true}
+}
+
 func TestService_CreateUser(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -29,10 +48,12 @@ func TestService_CreateUser(t *testing.T) {
 				Email: "john.doe@example.com",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-				mock.ExpectQuery(`INSERT INTO users \(email, name\) VALUES \(\$1, \$2\) RETURNING id`).
-					WithArgs("john.doe@example.com", "John Doe").
-					WillReturnRows(rows)
+				mock.ExpectBegin()
+				mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+				mock.ExpectExec(insertUser).
+					WithArgs(sealedUserArgs(1, "john.doe@example.com")...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 			expectedUser: &userspb.User{
 				Id:    1,
@@ -48,13 +69,33 @@ func TestService_CreateUser(t *testing.T) {
 				Email: "jane.doe@example.com",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO users \(email, name\) VALUES \(\$1, \$2\) RETURNING id`).
-					WithArgs("jane.doe@example.com", "Jane Doe").
+				mock.ExpectBegin()
+				mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+				mock.ExpectExec(insertUser).
+					WithArgs(sealedUserArgs(1, "jane.doe@example.com")...).
 					WillReturnError(errors.New("database connection failed"))
+				mock.ExpectRollback()
 			},
 			expectedError: true,
 			errorContains: "database connection failed",
 		},
+		{
+			name: "error - email already in use",
+			req: &userspb.CreateUserRequest{
+				Name:  "Jane Doe",
+				Email: "jane.doe@example.com",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+				mock.ExpectExec(insertUser).
+					WithArgs(sealedUserArgs(1, "jane.doe@example.com")...).
+					WillReturnError(&pq.Error{Code: pgUniqueViolation, Constraint: "idx_users_email_index"})
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+			errorContains: "code = AlreadyExists",
+		},
 		{
 			name: "error - scan error",
 			req: &userspb.CreateUserRequest{
@@ -62,10 +103,9 @@ func TestService_CreateUser(t *testing.T) {
 				Email: "test@example.com",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id"}).AddRow("invalid")
-				mock.ExpectQuery(`INSERT INTO users \(email, name\) VALUES \(\$1, \$2\) RETURNING id`).
-					WithArgs("test@example.com", "Test User").
-					WillReturnRows(rows)
+				mock.ExpectBegin()
+				mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("invalid"))
+				mock.ExpectRollback()
 			},
 			expectedError: true,
 		},
@@ -83,7 +123,7 @@ func TestService_CreateUser(t *testing.T) {
 
 			// Create service with mock DB
 			logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-			service := &Service{db: db, logger: logger}
+			service := NewServiceFromDB(db, testKeyring, logger)
 			ctx := context.Background()
 
 			// Execute test
@@ -112,3 +152,102 @@ func TestService_CreateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestService_CreateUserTx(t *testing.T) {
+	t.Run("joins the transaction carried by the context", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+		mock.ExpectExec(insertUser).
+			WithArgs(sealedUserArgs(7, "john.doe@example.com")...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+		service := NewServiceFromDB(db, testKeyring, logger)
+
+		var user *userspb.User
+		err = txn.NewManager(db, logger).Do(context.Background(), func(ctx context.Context) (err error) {
+			user, err = service.CreateUserTx(ctx, &userspb.CreateUserRequest{
+				Name:  "John Doe",
+				Email: "john.doe@example.com",
+			})
+			return err
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(7), user.Id)
+		assert.Equal(t, "John Doe", user.Name)
+		assert.Equal(t, "john.doe@example.com", user.Email)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("outside of a transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+		mock.ExpectExec(insertUser).
+			WithArgs(sealedUserArgs(7, "john.doe@example.com")...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		service := NewServiceFromDB(db, testKeyring, slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+		user, err := service.CreateUserTx(context.Background(), &userspb.CreateUserRequest{
+			Name:  "John Doe",
+			Email: "john.doe@example.com",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), user.Id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestService_CreateUser_Pgx(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectQuery(nextUserID).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		mock.ExpectExec(insertUser).
+			WithArgs(pgxSealedUserArgs(1, "john.doe@example.com")...).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		resp, err := service.CreateUser(t.Context(), &userspb.CreateUserRequest{Name: "John Doe", Email: "john.doe@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), resp.GetUser().GetId())
+		assert.Equal(t, "John Doe", resp.GetUser().GetName())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectQuery(nextUserID).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		mock.ExpectExec(insertUser).
+			WithArgs(pgxSealedUserArgs(1, "john.doe@example.com")...).
+			WillReturnError(errors.New("duplicate key value violates unique constraint"))
+		mock.ExpectRollback()
+
+		_, err := service.CreateUser(t.Context(), &userspb.CreateUserRequest{Name: "John Doe", Email: "john.doe@example.com"})
+		assert.ErrorContains(t, err, "duplicate key")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("email already in use", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectQuery(nextUserID).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		mock.ExpectExec(insertUser).
+			WithArgs(pgxSealedUserArgs(1, "john.doe@example.com")...).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "idx_users_email_index"})
+		mock.ExpectRollback()
+
+		_, err := service.CreateUser(t.Context(), &userspb.CreateUserRequest{Name: "John Doe", Email: "john.doe@example.com"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
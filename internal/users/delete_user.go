@@ -0,0 +1,26 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteUser deletes a user, for operator tooling. Their memberships and
+// sign-ins are deleted with them; erasing a user with the PrivacyService also
+// removes their invitations and redacts their audit events. It returns a
+// NotFound status error if the user does not exist.
+func (s *Service) DeleteUser(ctx context.Context, id int64) error {
+	return inTx(ctx, s.store, func(tx tx) error {
+		n, err := tx.queries().DeleteUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return status.Errorf(codes.NotFound, "user %d not found", id)
+		}
+		return tx.record(ctx, fmt.Sprintf("users/%d", id))
+	})
+}
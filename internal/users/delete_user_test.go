@@ -0,0 +1,103 @@
+package users
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestService_DeleteUser(t *testing.T) {
+	deleteUser := regexp.QuoteMeta("DELETE FROM users WHERE id = $1")
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   bool
+		wantCode  codes.Code
+	}{
+		{
+			name: "deleted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnError(errors.New("connection refused"))
+				mock.ExpectRollback()
+			},
+			wantErr:  true,
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+			service := NewServiceFromDB(db, testKeyring, logger)
+
+			err = service.DeleteUser(t.Context(), 1)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			} else {
+				require.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_DeleteUser_Pgx(t *testing.T) {
+	deleteUser := regexp.QuoteMeta("DELETE FROM users WHERE id = $1")
+
+	t.Run("deleted", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, service.DeleteUser(t.Context(), 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteUser).WithArgs(int64(1)).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectRollback()
+
+		err := service.DeleteUser(t.Context(), 1)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,43 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/users/userdb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetUserByID returns a user by id, for other services that act on behalf
+// of users. It returns a NotFound status error if the user does not exist.
+func (s *Service) GetUserByID(ctx context.Context, id int64) (*userspb.User, error) {
+	row, err := conn(ctx, s.store).queries().GetUser(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "user %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.openUser(row)
+}
+
+// openUser decrypts a user read by GetUser or, converted, ListUsers
+func (s *Service) openUser(row userdb.GetUserRow) (*userspb.User, error) {
+	pii := PII{
+		email:           row.Email,
+		name:            row.Name,
+		emailCiphertext: row.EmailCiphertext,
+		nameCiphertext:  row.NameCiphertext,
+		dataKey:         row.DataKey,
+		keyVersion:      row.KeyVersion,
+		bound:           row.PiiBound,
+	}
+	email, name, err := s.OpenPII(row.ID, &pii)
+	if err != nil {
+		return nil, err
+	}
+	return &userspb.User{Id: row.ID, Email: email, Name: name}, nil
+}
@@ -0,0 +1,223 @@
+package users
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	activitySourceAudit   = "audit"
+	activitySourceSession = "session"
+)
+
+// Audit methods of the SCIM requests that change a user's lifecycle. SCIM
+// requests are not RPCs: the SCIM handler names them "SCIM " and their
+// route, with " userName" appended when a PUT or PATCH changes the user's
+// userName, which is their email.
+const (
+	SCIMCreateUserMethod       = "SCIM POST /Users"
+	SCIMReplaceUserEmailMethod = "SCIM PUT /Users/{id} userName"
+	SCIMPatchUserEmailMethod   = "SCIM PATCH /Users/{id} userName"
+	SCIMDeleteUserMethod       = "SCIM DELETE /Users/{id}"
+)
+
+// auditActivityTypes maps the audited methods that change a user's lifecycle
+// to the activity they represent
+var auditActivityTypes = map[string]userspb.ActivityEvent_Type{
+	userspb.UserService_CreateUser_FullMethodName:                   userspb.ActivityEvent_TYPE_CREATED,
+	invitationspb.InvitationService_AcceptInvitation_FullMethodName: userspb.ActivityEvent_TYPE_CREATED,
+	SCIMCreateUserMethod:       userspb.ActivityEvent_TYPE_CREATED,
+	SCIMReplaceUserEmailMethod: userspb.ActivityEvent_TYPE_EMAIL_CHANGED,
+	SCIMPatchUserEmailMethod:   userspb.ActivityEvent_TYPE_EMAIL_CHANGED,
+	SCIMDeleteUserMethod:       userspb.ActivityEvent_TYPE_DELETED,
+}
+
+// activityCursor is the position after the last event of a page. Events are
+// ordered newest first, so the next page starts at or before the time of
+// the last event, skipping the events at exactly that time that have already
+// been returned.
+type activityCursor struct {
+	before time.Time
+	skip   int
+}
+
+func (c activityCursor) encode() string {
+	raw := fmt.Sprintf("%d.%d", c.before.UnixMicro(), c.skip)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeActivityCursor(token string) (activityCursor, error) {
+	if token == "" {
+		return activityCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return activityCursor{}, pagination.ErrInvalidPageToken
+	}
+	micros, skip, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return activityCursor{}, pagination.ErrInvalidPageToken
+	}
+	before, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return activityCursor{}, pagination.ErrInvalidPageToken
+	}
+	n, err := strconv.Atoi(skip)
+	if err != nil || n < 0 || n > pagination.MaxPageSize {
+		return activityCursor{}, pagination.ErrInvalidPageToken
+	}
+	return activityCursor{before: time.UnixMicro(before).UTC(), skip: n}, nil
+}
+
+// GetUserActivity returns a user's lifecycle events, newest first. Events are
+// merged from the audit log (changes made through the API) and the sign-in
+// history of the OpenID Connect provider. Activity of deleted users remains
+// readable for as long as its sources retain it.
+func (s *Service) GetUserActivity(ctx context.Context, req *userspb.GetUserActivityRequest) (*userspb.GetUserActivityResponse, error) {
+	if req.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	cursor, err := decodeActivityCursor(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+
+	// Each source returns its newest events up to the cursor. Reading one more
+	// than the page from each is enough to fill the page after merging and to
+	// know whether there is a next one.
+	limit := cursor.skip + pageSize + 1
+	audited, err := s.auditActivity(ctx, req.GetUserId(), cursor.before, limit)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionActivity(ctx, req.GetUserId(), cursor.before, limit)
+	if err != nil {
+		return nil, err
+	}
+	events := mergeActivity(audited, sessions)
+
+	if len(events) == 0 && cursor.before.IsZero() {
+		// Distinguish a user without activity from one that never existed
+		if _, err := s.GetUserByID(ctx, req.GetUserId()); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &userspb.GetUserActivityResponse{}
+	end := min(len(events), cursor.skip+pageSize)
+	if cursor.skip < end {
+		resp.Events = events[cursor.skip:end]
+	}
+	if len(events) > end {
+		last := events[end-1].GetCreateTime().AsTime()
+		next := activityCursor{before: last}
+		for _, e := range events[:end] {
+			if e.GetCreateTime().AsTime().Equal(last) {
+				next.skip++
+			}
+		}
+		resp.NextPageToken = next.encode()
+	}
+	return resp, nil
+}
+
+// mergeActivity merges two lists of events ordered newest first. Events at
+// the same time keep a fixed order (a before b) so pages stay stable.
+func mergeActivity(a, b []*userspb.ActivityEvent) []*userspb.ActivityEvent {
+	merged := make([]*userspb.ActivityEvent, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0].GetCreateTime().AsTime().Before(b[0].GetCreateTime().AsTime()) {
+			merged, b = append(merged, b[0]), b[1:]
+		} else {
+			merged, a = append(merged, a[0]), a[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// auditActivity reads the audited lifecycle changes of a user, newest first
+func (s *Service) auditActivity(ctx context.Context, userID int64, before time.Time, limit int) ([]*userspb.ActivityEvent, error) {
+	methods := slices.Sorted(maps.Keys(auditActivityTypes))
+	query := "SELECT created_at, actor, method FROM audit_events WHERE resource = $1 AND outcome = 'OK' AND method = ANY($2)"
+	args := []any{fmt.Sprintf("users/%d", userID), methods}
+	if !before.IsZero() {
+		args = append(args, before)
+		query += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := conn(ctx, s.store).query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*userspb.ActivityEvent, 0)
+	for rows.Next() {
+		var createdAt time.Time
+		var actor, method string
+		if err := rows.Scan(&createdAt, &actor, &method); err != nil {
+			return nil, err
+		}
+		events = append(events, &userspb.ActivityEvent{
+			Type:       auditActivityTypes[method],
+			CreateTime: timestamppb.New(createdAt),
+			Actor:      actor,
+			Source:     activitySourceAudit,
+			Detail:     method,
+		})
+	}
+	return events, rows.Err()
+}
+
+// sessionActivity reads a user's sign-ins, newest first. A sign-in is an
+// OpenID Connect authorization code that was exchanged for tokens.
+func (s *Service) sessionActivity(ctx context.Context, userID int64, before time.Time, limit int) ([]*userspb.ActivityEvent, error) {
+	query := "SELECT auth_time, client_id FROM oidc_authorization_codes WHERE user_id = $1 AND used_at IS NOT NULL"
+	args := []any{userID}
+	if !before.IsZero() {
+		args = append(args, before)
+		query += fmt.Sprintf(" AND auth_time <= $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY auth_time DESC, code_hash DESC LIMIT $%d", len(args))
+
+	rows, err := conn(ctx, s.store).query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*userspb.ActivityEvent, 0)
+	for rows.Next() {
+		var authTime time.Time
+		var clientID string
+		if err := rows.Scan(&authTime, &clientID); err != nil {
+			return nil, err
+		}
+		events = append(events, &userspb.ActivityEvent{
+			Type:       userspb.ActivityEvent_TYPE_LOGIN,
+			CreateTime: timestamppb.New(authTime),
+			Actor:      strconv.FormatInt(userID, 10),
+			Source:     activitySourceSession,
+			Detail:     clientID,
+		})
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,235 @@
+package users
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	invitationspb "github.com/zcking/go-api-template/gen/go/invitations/v1"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// auditMethods are the audited methods read into the activity timeline
+var auditMethods = []string{
+	invitationspb.InvitationService_AcceptInvitation_FullMethodName,
+	userspb.UserService_CreateUser_FullMethodName,
+	SCIMDeleteUserMethod,
+	SCIMPatchUserEmailMethod,
+	SCIMCreateUserMethod,
+	SCIMReplaceUserEmailMethod,
+}
+
+func TestService_GetUserActivity(t *testing.T) {
+	t1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	t2, t3 := t1.Add(time.Hour), t1.Add(2*time.Hour)
+
+	auditQuery := regexp.QuoteMeta("SELECT created_at, actor, method FROM audit_events WHERE resource = $1 AND outcome = 'OK' AND method = ANY($2)")
+	sessionQuery := regexp.QuoteMeta("SELECT auth_time, client_id FROM oidc_authorization_codes WHERE user_id = $1 AND used_at IS NOT NULL")
+	methods := pq.Array(auditMethods)
+	auditRows := func(times ...time.Time) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"created_at", "actor", "method"})
+		for _, ts := range times {
+			rows.AddRow(ts, "1", userspb.UserService_CreateUser_FullMethodName)
+		}
+		return rows
+	}
+	sessionRows := func(times ...time.Time) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"auth_time", "client_id"})
+		for _, ts := range times {
+			rows.AddRow(ts, "my-app")
+		}
+		return rows
+	}
+	type event struct {
+		typ    userspb.ActivityEvent_Type
+		time   time.Time
+		source string
+	}
+	firstPageToken := activityCursor{before: t2, skip: 1}.encode()
+	t4 := t1.Add(3 * time.Hour)
+
+	tests := []struct {
+		name          string
+		req           *userspb.GetUserActivityRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		want          []event
+		wantNextToken string
+		wantCode      codes.Code
+	}{
+		{
+			name: "first page merges sources newest first",
+			req:  &userspb.GetUserActivityRequest{UserId: 42, PageSize: 2},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery+regexp.QuoteMeta(" ORDER BY created_at DESC, id DESC LIMIT $3")).
+					WithArgs("users/42", methods, 3).
+					WillReturnRows(auditRows(t2))
+				mock.ExpectQuery(sessionQuery+regexp.QuoteMeta(" ORDER BY auth_time DESC, code_hash DESC LIMIT $2")).
+					WithArgs(int64(42), 3).
+					WillReturnRows(sessionRows(t3, t2, t1))
+			},
+			want: []event{
+				{userspb.ActivityEvent_TYPE_LOGIN, t3, activitySourceSession},
+				{userspb.ActivityEvent_TYPE_CREATED, t2, activitySourceAudit},
+			},
+			wantNextToken: firstPageToken,
+		},
+		{
+			name: "next page skips events already returned at the same time",
+			req:  &userspb.GetUserActivityRequest{UserId: 42, PageSize: 2, PageToken: firstPageToken},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery+regexp.QuoteMeta(" AND created_at <= $3 ORDER BY created_at DESC, id DESC LIMIT $4")).
+					WithArgs("users/42", methods, t2, 4).
+					WillReturnRows(auditRows(t2))
+				mock.ExpectQuery(sessionQuery+regexp.QuoteMeta(" AND auth_time <= $2 ORDER BY auth_time DESC, code_hash DESC LIMIT $3")).
+					WithArgs(int64(42), t2, 4).
+					WillReturnRows(sessionRows(t2, t1))
+			},
+			want: []event{
+				{userspb.ActivityEvent_TYPE_LOGIN, t2, activitySourceSession},
+				{userspb.ActivityEvent_TYPE_LOGIN, t1, activitySourceSession},
+			},
+		},
+		{
+			name: "lifecycle of a provisioned user",
+			req:  &userspb.GetUserActivityRequest{UserId: 42},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery).
+					WithArgs("users/42", methods, 51).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "actor", "method"}).
+						AddRow(t4, "scim", SCIMDeleteUserMethod).
+						AddRow(t3, "scim", SCIMPatchUserEmailMethod).
+						AddRow(t2, "scim", SCIMReplaceUserEmailMethod).
+						AddRow(t1, "scim", SCIMCreateUserMethod))
+				mock.ExpectQuery(sessionQuery).WillReturnRows(sessionRows())
+			},
+			want: []event{
+				{userspb.ActivityEvent_TYPE_DELETED, t4, activitySourceAudit},
+				{userspb.ActivityEvent_TYPE_EMAIL_CHANGED, t3, activitySourceAudit},
+				{userspb.ActivityEvent_TYPE_EMAIL_CHANGED, t2, activitySourceAudit},
+				{userspb.ActivityEvent_TYPE_CREATED, t1, activitySourceAudit},
+			},
+		},
+		{
+			name: "user created by accepting an invitation",
+			req:  &userspb.GetUserActivityRequest{UserId: 42},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "actor", "method"}).
+						AddRow(t1, "42", invitationspb.InvitationService_AcceptInvitation_FullMethodName))
+				mock.ExpectQuery(sessionQuery).WillReturnRows(sessionRows())
+			},
+			want: []event{{userspb.ActivityEvent_TYPE_CREATED, t1, activitySourceAudit}},
+		},
+		{
+			name: "user without activity",
+			req:  &userspb.GetUserActivityRequest{UserId: 42},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery).WillReturnRows(auditRows())
+				mock.ExpectQuery(sessionQuery).WillReturnRows(sessionRows())
+				mock.ExpectQuery(selectUserByID).
+					WithArgs(int64(42)).
+					WillReturnRows(sqlmock.NewRows(userColumnNames).AddRow(sealedRow(42, "ada@example.com", "Ada")...))
+			},
+		},
+		{
+			name: "user not found",
+			req:  &userspb.GetUserActivityRequest{UserId: 42},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery).WillReturnRows(auditRows())
+				mock.ExpectQuery(sessionQuery).WillReturnRows(sessionRows())
+				mock.ExpectQuery(selectUserByID).
+					WillReturnRows(sqlmock.NewRows(userColumnNames))
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:      "missing user id",
+			req:       &userspb.GetUserActivityRequest{},
+			mockSetup: func(sqlmock.Sqlmock) {},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "invalid page token",
+			req:       &userspb.GetUserActivityRequest{UserId: 42, PageToken: "!"},
+			mockSetup: func(sqlmock.Sqlmock) {},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name: "database error",
+			req:  &userspb.GetUserActivityRequest{UserId: 42},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(auditQuery).WillReturnError(errors.New("connection refused"))
+			},
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+			service := NewServiceFromDB(db, testKeyring, logger)
+
+			resp, err := service.GetUserActivity(t.Context(), tt.req)
+			if tt.wantCode != codes.OK {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				got := make([]event, 0, len(resp.Events))
+				for _, e := range resp.Events {
+					got = append(got, event{e.Type, e.CreateTime.AsTime(), e.Source})
+				}
+				assert.Equal(t, append([]event{}, tt.want...), got)
+				assert.Equal(t, tt.wantNextToken, resp.NextPageToken)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetUserActivity_Pgx(t *testing.T) {
+	t1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	service, mock := newPgxService(t, testKeyring)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT created_at, actor, method FROM audit_events WHERE resource = $1 AND outcome = 'OK' AND method = ANY($2) ORDER BY created_at DESC, id DESC LIMIT $3")).
+		WithArgs("users/42", auditMethods, 11).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at", "actor", "method"}).AddRow(t1, "1", userspb.UserService_CreateUser_FullMethodName))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT auth_time, client_id FROM oidc_authorization_codes WHERE user_id = $1 AND used_at IS NOT NULL ORDER BY auth_time DESC, code_hash DESC LIMIT $2")).
+		WithArgs(int64(42), 11).
+		WillReturnRows(pgxmock.NewRows([]string{"auth_time", "client_id"}).AddRow(t2, "my-app"))
+
+	resp, err := service.GetUserActivity(t.Context(), &userspb.GetUserActivityRequest{UserId: 42, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.GetEvents(), 2)
+	assert.Equal(t, userspb.ActivityEvent_TYPE_LOGIN, resp.GetEvents()[0].GetType())
+	assert.Equal(t, userspb.ActivityEvent_TYPE_CREATED, resp.GetEvents()[1].GetType())
+	assert.NoError(t, mock.ExpectationsWereMet(), "methods are passed to pgx as a native array")
+}
+
+func TestDecodeActivityCursor(t *testing.T) {
+	cursor := activityCursor{before: time.Date(2026, 3, 1, 12, 0, 0, 1000, time.UTC), skip: 2}
+	decoded, err := decodeActivityCursor(cursor.encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	for _, token := range []string{"!", "MTIz", "YS4x", "MS5h", "MS4tMQ"} {
+		_, err := decodeActivityCursor(token)
+		assert.Error(t, err, token)
+	}
+}
@@ -0,0 +1,111 @@
+package users
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// selectUserByID matches the query reading one user
+var selectUserByID = regexp.QuoteMeta("SELECT id, " + PIIColumns + " FROM users WHERE id = $1")
+
+func TestService_GetUserByID(t *testing.T) {
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		want      *userspb.User
+		wantCode  codes.Code
+		wantErr   bool
+	}{
+		{
+			name: "found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(selectUserByID).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows(userColumnNames).AddRow(sealedRow(1, "ada@example.com", "Ada")...))
+			},
+			want: &userspb.User{Id: 1, Email: "ada@example.com", Name: "Ada"},
+		},
+		{
+			name: "not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(selectUserByID).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows(userColumnNames))
+			},
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(selectUserByID).
+					WithArgs(int64(1)).
+					WillReturnError(errors.New("connection refused"))
+			},
+			wantErr:  true,
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+			service := NewServiceFromDB(db, testKeyring, logger)
+
+			got, err := service.GetUserByID(t.Context(), 1)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want.GetId(), got.GetId())
+				assert.Equal(t, tt.want.GetEmail(), got.GetEmail())
+				assert.Equal(t, tt.want.GetName(), got.GetName())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetUserByID_Pgx(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectQuery(selectUserByID).
+			WithArgs(int64(1)).
+			WillReturnRows(pgxmock.NewRows(userColumnNames).AddRow(pgxRow(sealedRow(int64(1), "ada@example.com", "Ada"))...))
+
+		got, err := service.GetUserByID(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "ada@example.com", got.GetEmail())
+		assert.Equal(t, "Ada", got.GetName())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectQuery(selectUserByID).
+			WithArgs(int64(1)).
+			WillReturnRows(pgxmock.NewRows(userColumnNames))
+
+		_, err := service.GetUserByID(t.Context(), 1)
+		assert.Equal(t, codes.NotFound, status.Code(err), "pgx.ErrNoRows is sql.ErrNoRows")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,71 @@
+package users
+
+import (
+	"context"
+	"strings"
+
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ImportUsers creates users in bulk, for operator tooling, and returns the
+// number created. They are copied into the users table in one transaction,
+// with COPY on pgx, so either all of them are created or none are. Unlike
+// CreateUser it does not return the new users' ids.
+func (s *Service) ImportUsers(ctx context.Context, reqs []*userspb.CreateUserRequest) (int64, error) {
+	for i, req := range reqs {
+		if req.GetEmail() == "" {
+			return 0, status.Errorf(codes.InvalidArgument, "user %d: email is required", i+1)
+		}
+	}
+
+	var n int64
+	err := inTx(ctx, s.store, func(tx tx) (err error) {
+		// The ids are allocated first, as the encrypted columns are bound
+		// to them
+		ids, err := nextUserIDs(ctx, tx, len(reqs))
+		if err != nil {
+			return err
+		}
+		rows := make([][]any, len(reqs))
+		for i, req := range reqs {
+			sealed, err := s.SealPII(ids[i], req.GetEmail(), req.GetName())
+			if err != nil {
+				return err
+			}
+			rows[i] = append([]any{ids[i]}, sealed...)
+		}
+
+		n, err = tx.copyFrom(ctx, "users", strings.Split("id, "+SealedColumns, ", "), rows)
+		if uniqueViolation(err) {
+			return status.Error(codes.AlreadyExists, "an imported email is already in use")
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// nextUserIDs allocates the ids of n new users
+func nextUserIDs(ctx context.Context, q querier, n int) ([]int64, error) {
+	rows, err := q.query(ctx, "SELECT nextval('seq_users_id') FROM generate_series(1, $1)", n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,86 @@
+package users
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var importReqs = []*userspb.CreateUserRequest{
+	{Name: "Ada Lovelace", Email: "ada@example.com"},
+	{Name: "Grace Hopper", Email: "grace@example.com"},
+}
+
+// allocateUserIDs matches the query allocating the ids of imported users
+var allocateUserIDs = regexp.QuoteMeta("SELECT nextval('seq_users_id') FROM generate_series(1, $1)")
+
+// importColumns are the columns imported users are copied into
+var importColumns = strings.Split("id, "+SealedColumns, ", ")
+
+func TestService_ImportUsers_Pgx(t *testing.T) {
+	t.Run("copies the users", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectQuery(allocateUserIDs).WithArgs(2).WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(int64(1)).AddRow(int64(2)))
+		mock.ExpectCopyFrom(pgx.Identifier{"users"}, importColumns).WillReturnResult(2)
+		mock.ExpectCommit()
+
+		n, err := service.ImportUsers(t.Context(), importReqs)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("copy fails", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+		mock.ExpectBegin()
+		mock.ExpectQuery(allocateUserIDs).WithArgs(2).WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(int64(1)).AddRow(int64(2)))
+		mock.ExpectCopyFrom(pgx.Identifier{"users"}, importColumns).
+			WillReturnError(errors.New("duplicate key value violates unique constraint"))
+		mock.ExpectRollback()
+
+		_, err := service.ImportUsers(t.Context(), importReqs)
+		assert.ErrorContains(t, err, "duplicate key")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing email", func(t *testing.T) {
+		service, mock := newPgxService(t, testKeyring)
+
+		_, err := service.ImportUsers(t.Context(), []*userspb.CreateUserRequest{{Name: "Ada"}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.ErrorContains(t, err, "user 1: email is required")
+		assert.NoError(t, mock.ExpectationsWereMet(), "nothing is written")
+	})
+}
+
+func TestService_ImportUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(allocateUserIDs).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(int64(1)).AddRow(int64(2)))
+	prepared := mock.ExpectPrepare(regexp.QuoteMeta(`INSERT INTO "users" (id, ` + SealedColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`))
+	prepared.ExpectExec().WithArgs(sealedUserArgs(1, "ada@example.com")...).WillReturnResult(sqlmock.NewResult(1, 1))
+	prepared.ExpectExec().WithArgs(sealedUserArgs(2, "grace@example.com")...).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	service := NewServiceFromDB(db, testKeyring, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	n, err := service.ImportUsers(t.Context(), importReqs)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
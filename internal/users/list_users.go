@@ -4,27 +4,24 @@ import (
 	"context"
 
 	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/users/userdb"
 )
 
 // ListUsers retrieves all users from the database
 func (s *Service) ListUsers(ctx context.Context, req *userspb.ListUsersRequest) (*userspb.ListUsersResponse, error) {
-	// Query all users
-	rows, err := s.db.QueryContext(ctx, "SELECT * FROM users")
+	rows, err := conn(ctx, s.store).queries().ListUsers(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	users := make([]*userspb.User, 0)
-
-	// Scan each row into a user
-	for rows.Next() {
-		var user userspb.User
-		err := rows.Scan(&user.Id, &user.Email, &user.Name)
+	users := make([]*userspb.User, 0, len(rows))
+	for _, row := range rows {
+		// ListUsersRow has the columns of GetUserRow
+		user, err := s.openUser(userdb.GetUserRow(row))
 		if err != nil {
 			return nil, err
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	return &userspb.ListUsersResponse{Users: users}, nil
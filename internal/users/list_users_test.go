@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
@@ -24,10 +25,10 @@ func TestService_ListUsers(t *testing.T) {
 		{
 			name: "success - returns multiple users",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "email", "name"}).
-					AddRow(1, "john.doe@example.com", "John Doe").
-					AddRow(2, "jane.smith@example.com", "Jane Smith")
-				mock.ExpectQuery(`SELECT \* FROM users`).
+				rows := sqlmock.NewRows(userColumnNames).
+					AddRow(sealedRow(1, "john.doe@example.com", "John Doe")...).
+					AddRow(plaintextRow(2, "jane.smith@example.com", "Jane Smith")...)
+				mock.ExpectQuery(selectUsers).
 					WillReturnRows(rows)
 			},
 			expectedUsers: []*userspb.User{
@@ -47,8 +48,8 @@ func TestService_ListUsers(t *testing.T) {
 		{
 			name: "success - returns empty list",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "email", "name"})
-				mock.ExpectQuery(`SELECT \* FROM users`).
+				rows := sqlmock.NewRows(userColumnNames)
+				mock.ExpectQuery(selectUsers).
 					WillReturnRows(rows)
 			},
 			expectedUsers: []*userspb.User{},
@@ -57,7 +58,7 @@ func TestService_ListUsers(t *testing.T) {
 		{
 			name: "error - database query fails",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT \* FROM users`).
+				mock.ExpectQuery(selectUsers).
 					WillReturnError(errors.New("failed to query database"))
 			},
 			expectedError: true,
@@ -66,13 +67,24 @@ func TestService_ListUsers(t *testing.T) {
 		{
 			name: "error - scan error",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "email", "name"}).
-					AddRow("invalid", "test@example.com", "Test")
-				mock.ExpectQuery(`SELECT \* FROM users`).
+				rows := sqlmock.NewRows(userColumnNames).
+					AddRow(plaintextRow("invalid", "test@example.com", "Test")...)
+				mock.ExpectQuery(selectUsers).
 					WillReturnRows(rows)
 			},
 			expectedError: true,
 		},
+		{
+			name: "error - undecryptable user",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				row := sealedRow(1, "test@example.com", "Test")
+				row[6] = "0"
+				mock.ExpectQuery(selectUsers).
+					WillReturnRows(sqlmock.NewRows(userColumnNames).AddRow(row...))
+			},
+			expectedError: true,
+			errorContains: "failed to decrypt user",
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,7 +99,7 @@ func TestService_ListUsers(t *testing.T) {
 
 			// Create service with mock DB
 			logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-			service := &Service{db: db, logger: logger}
+			service := NewServiceFromDB(db, testKeyring, logger)
 			ctx := context.Background()
 
 			// Execute test
@@ -118,3 +130,18 @@ func TestService_ListUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ListUsers_Pgx(t *testing.T) {
+	service, mock := newPgxService(t, testKeyring)
+	mock.ExpectQuery(selectUsers).
+		WillReturnRows(pgxmock.NewRows(userColumnNames).
+			AddRow(pgxRow(sealedRow(int64(1), "john.doe@example.com", "John Doe"))...).
+			AddRow(pgxRow(plaintextRow(int64(2), "jane.smith@example.com", "Jane Smith"))...))
+
+	resp, err := service.ListUsers(t.Context(), &userspb.ListUsersRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), 2)
+	assert.Equal(t, "john.doe@example.com", resp.GetUsers()[0].GetEmail())
+	assert.Equal(t, "Jane Smith", resp.GetUsers()[1].GetName())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/zcking/go-api-template/internal/encryption"
+)
+
+// PIIColumns are the users columns holding a user's email and name, in the
+// order scanned by PII.Dest. The plaintext email and name columns are only
+// set on rows written before encryption was introduced, until
+// ReencryptUsers encrypts them.
+const PIIColumns = "email, name, email_ciphertext, name_ciphertext, data_key, key_version, pii_bound"
+
+// SealedColumns are the users columns written with the values returned by
+// SealPII, in order
+const SealedColumns = "email, name, email_ciphertext, name_ciphertext, email_index, data_key, key_version, pii_bound"
+
+// PII is a user's email and name as stored in the users table. Encrypted
+// PII is bound to the user's id, except on rows sealed before ids were bound
+// and not yet re-sealed by ReencryptUsers.
+type PII struct {
+	email           pgtype.Text
+	name            pgtype.Text
+	emailCiphertext []byte
+	nameCiphertext  []byte
+	dataKey         []byte
+	keyVersion      pgtype.Text
+	bound           bool
+}
+
+// Dest returns the scan destinations of PIIColumns, for pgx and
+// database/sql alike
+func (p *PII) Dest() []any {
+	return []any{&p.email, &p.name, &p.emailCiphertext, &p.nameCiphertext, &p.dataKey, &p.keyVersion, &p.bound}
+}
+
+// envelope returns the encrypted form of the PII of user id, or nil if p is
+// still plaintext
+func (p *PII) envelope(id int64) *encryption.Envelope {
+	if !p.keyVersion.Valid {
+		return nil
+	}
+	var owner string
+	if p.bound {
+		owner = piiOwner(id)
+	}
+	return &encryption.Envelope{
+		KeyVersion: p.keyVersion.String,
+		Owner:      owner,
+		DataKey:    p.dataKey,
+		Values:     [][]byte{p.emailCiphertext, p.nameCiphertext},
+	}
+}
+
+// OpenPII decrypts the email and name of user id
+func (s *Service) OpenPII(id int64, p *PII) (email, name string, err error) {
+	e := p.envelope(id)
+	if e == nil {
+		return p.email.String, p.name.String, nil
+	}
+	values, err := s.keyring.Open(e)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt user: %w", err)
+	}
+	return values[0], values[1], nil
+}
+
+// SealPII encrypts the email and name of user id and returns the values to
+// write to SealedColumns. The plaintext columns are cleared. New users get
+// their id from NextUserID, so it is known before the row is written.
+func (s *Service) SealPII(id int64, email, name string) ([]any, error) {
+	e, err := s.keyring.Seal(piiOwner(id), email, name)
+	if err != nil {
+		return nil, err
+	}
+	return []any{nil, nil, e.Values[0], e.Values[1], s.EmailIndex(email), e.DataKey, e.KeyVersion, true}, nil
+}
+
+// NextUserID allocates the id of a new user, for callers that insert users
+// themselves with SealPII
+func (s *Service) NextUserID(ctx context.Context) (int64, error) {
+	return conn(ctx, s.store).queries().NextUserID(ctx)
+}
+
+// piiOwner is the encryption owner of the PII of user id
+func piiOwner(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// EmailIndex returns the blind index of an email address, for equality
+// lookups on the email_index column. Emails are compared case-insensitively.
+func (s *Service) EmailIndex(email string) []byte {
+	return s.keyring.BlindIndex(strings.ToLower(email))
+}
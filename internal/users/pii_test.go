@@ -0,0 +1,105 @@
+package users
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zcking/go-api-template/internal/encryption"
+)
+
+// testKeyring is the keyring of the services under test
+var testKeyring = newTestKeyring("1")
+
+// newTestKeyring creates a keyring whose primary key is version primary.
+// Versions "1" and "2" are available.
+func newTestKeyring(primary string) *encryption.Keyring {
+	k, err := encryption.NewKeyring(primary, map[string][]byte{
+		"1": bytes.Repeat([]byte{1}, 32),
+		"2": bytes.Repeat([]byte{2}, 32),
+	}, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// userColumnNames are the column names of "id, " + PIIColumns
+var userColumnNames = []string{"id", "email", "name", "email_ciphertext", "name_ciphertext", "data_key", "key_version", "pii_bound"}
+
+// sealedRow returns a row of "id, " + PIIColumns for an encrypted user
+func sealedRow(id any, email, name string) []driver.Value {
+	e, err := testKeyring.Seal(fmt.Sprint(id), email, name)
+	if err != nil {
+		panic(err)
+	}
+	return []driver.Value{id, nil, nil, e.Values[0], e.Values[1], e.DataKey, e.KeyVersion, true}
+}
+
+// unboundRow returns a row of "id, " + PIIColumns for a user encrypted
+// before PII was bound to the user id
+func unboundRow(id any, email, name string) []driver.Value {
+	e, err := testKeyring.Seal("", email, name)
+	if err != nil {
+		panic(err)
+	}
+	return []driver.Value{id, nil, nil, e.Values[0], e.Values[1], e.DataKey, e.KeyVersion, false}
+}
+
+// plaintextRow returns a row of "id, " + PIIColumns for a user written
+// before encryption was introduced
+func plaintextRow(id any, email, name string) []driver.Value {
+	return []driver.Value{id, email, name, nil, nil, nil, nil, false}
+}
+
+// selectUsers matches the query reading users
+var selectUsers = regexp.QuoteMeta("SELECT id, " + PIIColumns + " FROM users")
+
+func TestService_SealPII(t *testing.T) {
+	service := NewServiceFromDB(nil, testKeyring, slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+
+	sealed, err := service.SealPII(1, "Ada@Example.com", "Ada")
+	require.NoError(t, err)
+	require.Len(t, sealed, 8)
+	assert.Nil(t, sealed[0], "plaintext email is cleared")
+	assert.Nil(t, sealed[1], "plaintext name is cleared")
+	assert.Equal(t, service.EmailIndex("ada@example.com"), sealed[4], "email index is case-insensitive")
+	assert.Equal(t, "1", sealed[6])
+	assert.Equal(t, true, sealed[7], "bound to the user id")
+
+	pii := PII{
+		emailCiphertext: sealed[2].([]byte),
+		nameCiphertext:  sealed[3].([]byte),
+		dataKey:         sealed[5].([]byte),
+		bound:           true,
+	}
+	pii.keyVersion.String, pii.keyVersion.Valid = "1", true
+	email, name, err := service.OpenPII(1, &pii)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada@Example.com", email)
+	assert.Equal(t, "Ada", name)
+
+	t.Run("error - copied to another user", func(t *testing.T) {
+		_, _, err := service.OpenPII(2, &pii)
+		assert.ErrorContains(t, err, "failed to decrypt user")
+	})
+
+	t.Run("unbound", func(t *testing.T) {
+		row := unboundRow(1, "ada@example.com", "Ada")
+		unbound := PII{
+			emailCiphertext: row[3].([]byte),
+			nameCiphertext:  row[4].([]byte),
+			dataKey:         row[5].([]byte),
+		}
+		unbound.keyVersion.String, unbound.keyVersion.Valid = "1", true
+		email, _, err := service.OpenPII(1, &unbound)
+		require.NoError(t, err)
+		assert.Equal(t, "ada@example.com", email)
+	})
+}
@@ -0,0 +1,94 @@
+package users
+
+import (
+	"context"
+)
+
+// ReencryptUsers moves up to limit users with an id above after onto the
+// primary key of the keyring: data keys wrapped by an older key version are
+// re-wrapped, and users still stored in plaintext or sealed before PII was
+// bound to the user id are sealed again. Rows are
+// locked only for the duration of one batch and rows locked by other
+// transactions are skipped, so it runs alongside normal traffic. A user
+// whose PII cannot be decrypted, e.g. because its key version was removed
+// from the keyring, is logged and left as is.
+//
+// It returns the number of users updated and the id of the last user it
+// looked at; callers pass that id as after for the next batch, so rows left
+// as is do not block the ones behind them, and stop when it does not
+// change.
+func (s *Service) ReencryptUsers(ctx context.Context, after int64, limit int) (updated int, last int64, err error) {
+	last = after
+	err = inTx(ctx, s.store, func(tx tx) error {
+		primary := s.keyring.Primary()
+		rows, err := tx.query(ctx,
+			"SELECT id, "+PIIColumns+" FROM users WHERE (key_version IS DISTINCT FROM $1 OR NOT pii_bound) AND id > $2 ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED",
+			primary, after, limit)
+		if err != nil {
+			return err
+		}
+		type staleUser struct {
+			id  int64
+			pii PII
+		}
+		var stale []staleUser
+		for rows.Next() {
+			var u staleUser
+			if err := rows.Scan(append([]any{&u.id}, u.pii.Dest()...)...); err != nil {
+				rows.Close()
+				return err
+			}
+			stale = append(stale, u)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// The updates are sent as one batch
+		updates := make([]statement, 0, len(stale))
+		// Bound users only need their data key rewrapped; the others are
+		// read, from plaintext or their unbound envelope, and sealed again.
+		for _, u := range stale {
+			if u.pii.bound {
+				e := u.pii.envelope(u.id)
+				if err := s.keyring.Rewrap(e); err != nil {
+					s.logger.ErrorContext(ctx, "failed to rewrap user data key",
+						"user_id", u.id, "key_version", e.KeyVersion, "error", err)
+					continue
+				}
+				updates = append(updates, statement{
+					sql:  "UPDATE users SET data_key = $2, key_version = $3 WHERE id = $1",
+					args: []any{u.id, e.DataKey, e.KeyVersion},
+				})
+				continue
+			}
+			email, name, err := s.OpenPII(u.id, &u.pii)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to decrypt user",
+					"user_id", u.id, "key_version", u.pii.keyVersion.String, "error", err)
+				continue
+			}
+			sealed, err := s.SealPII(u.id, email, name)
+			if err != nil {
+				return err
+			}
+			updates = append(updates, statement{
+				sql:  "UPDATE users SET (" + SealedColumns + ") = ($2, $3, $4, $5, $6, $7, $8, $9) WHERE id = $1",
+				args: append([]any{u.id}, sealed...),
+			})
+		}
+		if err := tx.execBatch(ctx, updates); err != nil {
+			return err
+		}
+		updated = len(updates)
+		if len(stale) > 0 {
+			last = stale[len(stale)-1].id
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, after, err
+	}
+	return updated, last, nil
+}
@@ -0,0 +1,156 @@
+package users
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ReencryptUsers(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT id, " + PIIColumns + " FROM users WHERE (key_version IS DISTINCT FROM $1 OR NOT pii_bound) AND id > $2 ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED")
+
+	t.Run("success - rewraps and encrypts", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).
+			WithArgs("2", int64(0), 100).
+			WillReturnRows(sqlmock.NewRows(userColumnNames).
+				AddRow(sealedRow(1, "ada@example.com", "Ada")...).
+				AddRow(plaintextRow(2, "Bob@example.com", "Bob")...))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET data_key = $2, key_version = $3 WHERE id = $1")).
+			WithArgs(int64(1), sqlmock.AnyArg(), "2").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET ("+SealedColumns+") = ($2, $3, $4, $5, $6, $7, $8, $9) WHERE id = $1")).
+			WithArgs(int64(2), nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), testKeyring.BlindIndex("bob@example.com"), sqlmock.AnyArg(), "2", true).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		service := NewServiceFromDB(db, newTestKeyring("2"), slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+		n, last, err := service.ReencryptUsers(t.Context(), 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, int64(2), last)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - binds unbound users to their id", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).
+			WithArgs("1", int64(0), 100).
+			WillReturnRows(sqlmock.NewRows(userColumnNames).AddRow(unboundRow(3, "ada@example.com", "Ada")...))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET ("+SealedColumns+") = ($2, $3, $4, $5, $6, $7, $8, $9) WHERE id = $1")).
+			WithArgs(int64(3), nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), testKeyring.BlindIndex("ada@example.com"), sqlmock.AnyArg(), "1", true).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		service := NewServiceFromDB(db, testKeyring, slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+		n, last, err := service.ReencryptUsers(t.Context(), 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, int64(3), last)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - nothing to do", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(userColumnNames))
+		mock.ExpectCommit()
+
+		service := NewServiceFromDB(db, testKeyring, slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+		n, last, err := service.ReencryptUsers(t.Context(), 7, 100)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Equal(t, int64(7), last, "the cursor stays put when no rows remain")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - skips a key version not in the keyring", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		unknown := sealedRow(1, "ada@example.com", "Ada")
+		unknown[6] = "0"
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).
+			WithArgs("2", int64(0), 100).
+			WillReturnRows(sqlmock.NewRows(userColumnNames).
+				AddRow(unknown...).
+				AddRow(sealedRow(3, "bob@example.com", "Bob")...))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET data_key = $2, key_version = $3 WHERE id = $1")).
+			WithArgs(int64(3), sqlmock.AnyArg(), "2").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		service := NewServiceFromDB(db, newTestKeyring("2"), slog.New(slog.NewJSONHandler(io.Discard, nil)))
+		n, last, err := service.ReencryptUsers(t.Context(), 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, int64(3), last, "the cursor moves past the skipped row")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestService_ReencryptUsers_Pgx(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT id, " + PIIColumns + " FROM users WHERE (key_version IS DISTINCT FROM $1 OR NOT pii_bound) AND id > $2 ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED")
+
+	t.Run("success - updates in one batch", func(t *testing.T) {
+		service, mock := newPgxService(t, newTestKeyring("2"))
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).
+			WithArgs("2", int64(0), 100).
+			WillReturnRows(pgxmock.NewRows(userColumnNames).
+				AddRow(pgxRow(sealedRow(int64(1), "ada@example.com", "Ada"))...).
+				AddRow(pgxRow(plaintextRow(int64(2), "Bob@example.com", "Bob"))...))
+		batch := mock.ExpectBatch()
+		batch.ExpectExec(regexp.QuoteMeta("UPDATE users SET data_key = $2, key_version = $3 WHERE id = $1")).
+			WithArgs(int64(1), pgxmock.AnyArg(), "2").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		batch.ExpectExec(regexp.QuoteMeta("UPDATE users SET ("+SealedColumns+") = ($2, $3, $4, $5, $6, $7, $8, $9) WHERE id = $1")).
+			WithArgs(int64(2), nil, nil, pgxmock.AnyArg(), pgxmock.AnyArg(), testKeyring.BlindIndex("bob@example.com"), pgxmock.AnyArg(), "2", true).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+
+		n, last, err := service.ReencryptUsers(t.Context(), 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, int64(2), last)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update fails", func(t *testing.T) {
+		service, mock := newPgxService(t, newTestKeyring("2"))
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).
+			WithArgs("2", int64(0), 100).
+			WillReturnRows(pgxmock.NewRows(userColumnNames).AddRow(pgxRow(sealedRow(int64(1), "ada@example.com", "Ada"))...))
+		batch := mock.ExpectBatch()
+		batch.ExpectExec(regexp.QuoteMeta("UPDATE users SET data_key = $2, key_version = $3 WHERE id = $1")).
+			WithArgs(int64(1), pgxmock.AnyArg(), "2").
+			WillReturnError(errors.New("deadlock detected"))
+		mock.ExpectRollback()
+
+		_, _, err := service.ReencryptUsers(t.Context(), 0, 100)
+		assert.ErrorContains(t, err, "deadlock detected")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,72 +1,221 @@
 package users
 
 import (
+	"cmp"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 
-	"github.com/XSAM/otelsql"
-	_ "github.com/lib/pq"
 	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
-	"go.opentelemetry.io/otel/attribute"
-	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"github.com/zcking/go-api-template/internal/database"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/secrets"
 )
 
-// Service handles gRPC requests for user operations
+// Service handles gRPC requests for user operations. Its own queries go
+// through pgx; the database/sql pool returned by DB is shared with the
+// services that have not moved to pgx yet.
 type Service struct {
 	userspb.UnimplementedUserServiceServer
-	db     *sql.DB
-	logger *slog.Logger
+	store   store
+	db      *sql.DB
+	keyring *encryption.Keyring
+	logger  *slog.Logger
+	// idleConns is the number of idle connections the database/sql pool keeps
+	idleConns int
+	// pool is the pgx pool of store, nil when the service uses database/sql
+	pool *pgxpool.Pool
+	// listener receives change notifications, nil when the service uses
+	// database/sql
+	listener listener
+	// router routes the queries of store between pool and replicas, nil
+	// when the service uses database/sql
+	router     *database.Router
+	replicas   []*pgxpool.Pool
+	stopRouter context.CancelFunc
 }
 
 // Config holds configuration for database connections
 type Config struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	// DSN is the connection string, a URL or keyword=value pairs, which may
+	// list several hosts (see database.ParseDSN)
+	DSN string
+	// Pool holds the connection pool settings. NewService splits
+	// Pool.MaxOpenConns between its pgx and database/sql pools (see
+	// database.Pool.Split); its LISTEN connection is one more.
+	Pool database.Pool
+	// Credentials, when set, provides the password instead of Password.
+	// Each new connection uses its current value, so rotated credentials
+	// take effect without a restart.
+	Credentials *secrets.Secret
+	// Replicas are the connection strings of read replicas, one host each,
+	// that serve the queries of read-only RPCs (see database.Router). They
+	// use Pool and Credentials too.
+	Replicas []string
+	// ReplicaCheckInterval is how often the replicas are checked; 0 checks
+	// them every 2 seconds
+	ReplicaCheckInterval time.Duration
 }
 
-// NewService creates a new user service with a database connection
-func NewService(config Config, logger *slog.Logger) (*Service, error) {
+// NewService creates a new user service with a pgx connection pool for its
+// queries and a database/sql pool for DB, which share the connections
+// config.Pool allows. Emails and names are encrypted with keys from keyring.
+func NewService(config Config, keyring *encryption.Keyring, logger *slog.Logger) (*Service, error) {
+	connector, err := database.NewConnector(config.DSN, config.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database connection string: %w", err)
+	}
+	dsn := connector.DSN()
 	logger.Info("setting up database connection",
-		"host", config.Host,
-		"port", config.Port,
-		"dbname", config.DBName,
+		"hosts", dsn.Hosts,
+		"dbname", dsn.Params["dbname"],
+		"target_session_attrs", dsn.TargetSessionAttrs,
+		"replicas", len(config.Replicas),
 	)
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
-
-	// Open database connection with OpenTelemetry instrumentation
-	db, err := otelsql.Open("postgres", connStr,
-		otelsql.WithAttributes(
-			semconv.DBSystemPostgreSQL,
-			attribute.String("db.name", config.DBName),
-			attribute.String("db.user", config.User),
-			attribute.String("net.peer.name", config.Host),
-			attribute.String("net.peer.port", config.Port),
-		),
-	)
+	// Open database connections with OpenTelemetry instrumentation
+	pgxPool, sqlPool := config.Pool.Split()
+	pool, err := database.OpenPool(config.DSN, config.Credentials, pgxPool)
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.Open(connector, sqlPool)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	listener, err := database.NewListener(config.DSN, config.Credentials, logger)
 	if err != nil {
+		pool.Close()
+		db.Close()
 		return nil, err
 	}
 
-	// Test the connection
-	if err := db.Ping(); err != nil {
+	// Test the connections
+	if err := errors.Join(pool.Ping(context.Background()), db.Ping()); err != nil {
+		pool.Close()
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	// Replicas are optional, so they are not pinged: Router.Run only uses
+	// them once they answer
+	replicas, err := openReplicas(config)
+	if err != nil {
+		pool.Close()
+		db.Close()
+		return nil, err
+	}
+	conns := make([]database.Replica, len(replicas))
+	for i, p := range replicas {
+		conns[i] = database.Replica{Name: p.Config().ConnConfig.Host, Conn: p}
+	}
+	router := database.NewRouter(pool, conns, logger)
+	routerCtx, stopRouter := context.WithCancel(context.Background())
+	go router.Run(routerCtx, cmp.Or(config.ReplicaCheckInterval, 2*time.Second))
+
+	s := &Service{
+		store:      pgxStore{router},
+		db:         db,
+		keyring:    keyring,
+		logger:     logger,
+		idleConns:  sqlPool.IdleConns(),
+		pool:       pool,
+		listener:   listener,
+		router:     router,
+		replicas:   replicas,
+		stopRouter: stopRouter,
+	}
+	if config.Credentials != nil {
+		config.Credentials.OnChange(s.closeIdleConns)
+	}
+	return s, nil
+}
+
+// openReplicas opens a pgx pool for each of config.Replicas
+func openReplicas(config Config) ([]*pgxpool.Pool, error) {
+	var replicas []*pgxpool.Pool
+	for i, dsn := range config.Replicas {
+		p, err := database.OpenPool(dsn, config.Credentials, config.Pool)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, p)
+	}
+	return replicas, nil
+}
+
+// NewServiceFromDB creates a new user service that runs its queries on an
+// existing database/sql connection, for tests and callers that have not
+// moved to pgx. It cannot watch for changes (see WatchUsers).
+func NewServiceFromDB(db *sql.DB, keyring *encryption.Keyring, logger *slog.Logger) *Service {
 	return &Service{
-		db:     db,
-		logger: logger,
-	}, nil
+		store:     sqlStore{db},
+		db:        db,
+		keyring:   keyring,
+		logger:    logger,
+		idleConns: database.Pool{}.IdleConns(),
+	}
+}
+
+// Router returns the router of the service's queries, whose interceptor
+// marks the read-only RPCs that replicas may serve. It is nil when the
+// service uses database/sql.
+func (s *Service) Router() *database.Router {
+	return s.router
+}
+
+// DB returns the service's database connection pool so other services can share it
+func (s *Service) DB() *sql.DB {
+	return s.db
+}
+
+// Ping checks that the database can be reached through the service's pools
+func (s *Service) Ping(ctx context.Context) error {
+	if s.pool != nil {
+		if err := s.pool.Ping(ctx); err != nil {
+			return err
+		}
+	}
+	return s.db.PingContext(ctx)
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (s *Service) Close() error {
 	s.logger.Info("shutting down database connection")
+	if s.stopRouter != nil {
+		s.stopRouter()
+	}
+	for _, replica := range s.replicas {
+		replica.Close()
+	}
+	if s.pool != nil {
+		s.pool.Close()
+	}
 	return s.db.Close()
 }
+
+// closeIdleConns closes the pool's idle connections after the credentials
+// change, so they are replaced by connections that use the new ones.
+// Connections in use are not interrupted: their requests finish, and they
+// stay valid since Postgres only checks credentials when a connection opens.
+func (s *Service) closeIdleConns() {
+	s.logger.Info("database credentials changed; replacing idle connections")
+	s.db.SetMaxIdleConns(0)
+	s.db.SetMaxIdleConns(s.idleConns)
+	if s.pool != nil {
+		// Reset closes idle connections now and the others when released
+		s.pool.Reset()
+	}
+	for _, replica := range s.replicas {
+		replica.Reset()
+	}
+}
@@ -0,0 +1,366 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+
+	"github.com/zcking/go-api-template/internal/audit"
+	"github.com/zcking/go-api-template/internal/txn"
+	"github.com/zcking/go-api-template/internal/users/userdb"
+)
+
+// store runs the service's queries. NewService uses pgxStore, on a pgx
+// pool; NewServiceFromDB uses sqlStore, on database/sql, for callers that
+// still share a *sql.DB until they move to pgx. Queries are written once,
+// with pgx's native argument types, and sqlStore adapts them for lib/pq.
+// Static queries are generated from userdb/queries.sql (see package userdb);
+// the rest, built at run time, go through query, queryRow and exec.
+type store interface {
+	querier
+	begin(ctx context.Context) (tx, error)
+}
+
+// querier is implemented by stores and their transactions
+type querier interface {
+	query(ctx context.Context, query string, args ...any) (rows, error)
+	queryRow(ctx context.Context, query string, args ...any) row
+	// exec runs a statement and returns the number of rows it affected
+	exec(ctx context.Context, query string, args ...any) (int64, error)
+	// queries returns the generated queries, run on the querier
+	queries() *userdb.Queries
+}
+
+// rows is implemented by pgx.Rows and, through sqlRows, *sql.Rows
+type rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close()
+}
+
+// row is implemented by pgx.Row and *sql.Row
+type row interface {
+	Scan(dest ...any) error
+}
+
+// statement is a query and its arguments
+type statement struct {
+	sql  string
+	args []any
+}
+
+// tx is a transaction of a store
+type tx interface {
+	querier
+	// execBatch runs statements in order, in one round trip where the
+	// driver supports it
+	execBatch(ctx context.Context, statements []statement) error
+	// copyFrom inserts rows into columns of table, with COPY where the driver
+	// supports it, and returns the number of rows inserted
+	copyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error)
+	// record appends the audit event of the current RPC (see audit.Record)
+	record(ctx context.Context, resource string) error
+	commit(ctx context.Context) error
+	rollback(ctx context.Context) error
+}
+
+// inTx runs fn in a transaction of s, committed if fn succeeds. Within a
+// transaction carried by ctx (see txn.Manager), fn runs in a savepoint of
+// that one instead, so the change commits or rolls back with the caller's.
+// The caller's transaction is on the shared *sql.DB, so a pgxStore's
+// changes go through it too rather than through the pgx pool.
+func inTx(ctx context.Context, s store, fn func(tx tx) error) (err error) {
+	if ambient, ok := txn.Tx(ctx); ok {
+		return txn.Savepoint(ctx, func(context.Context) error { return fn(sqlTx{ambient}) })
+	}
+	t, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = t.rollback(ctx)
+		}
+	}()
+	if err = fn(t); err != nil {
+		return err
+	}
+	return t.commit(ctx)
+}
+
+// conn returns the querier for the transaction carried by ctx, if any, so
+// reads see the caller's uncommitted changes, or s outside of one
+func conn(ctx context.Context, s store) querier {
+	if ambient, ok := txn.Tx(ctx); ok {
+		return sqlTx{ambient}
+	}
+	return s
+}
+
+// pgxPool is the part of *pgxpool.Pool that pgxStore uses
+type pgxPool interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// pgxStore runs queries on a pgx pool
+type pgxStore struct {
+	pool pgxPool
+}
+
+func (s pgxStore) query(ctx context.Context, query string, args ...any) (rows, error) {
+	return s.pool.Query(ctx, query, args...)
+}
+
+func (s pgxStore) queryRow(ctx context.Context, query string, args ...any) row {
+	return s.pool.QueryRow(ctx, query, args...)
+}
+
+func (s pgxStore) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	tag, err := s.pool.Exec(ctx, query, args...)
+	return tag.RowsAffected(), err
+}
+
+func (s pgxStore) queries() *userdb.Queries { return userdb.New(s.pool) }
+
+func (s pgxStore) begin(ctx context.Context) (tx, error) {
+	t, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pgxTx{t}, nil
+}
+
+// pgxTx is a transaction of a pgxStore
+type pgxTx struct {
+	tx pgx.Tx
+}
+
+func (t pgxTx) query(ctx context.Context, query string, args ...any) (rows, error) {
+	return t.tx.Query(ctx, query, args...)
+}
+
+func (t pgxTx) queryRow(ctx context.Context, query string, args ...any) row {
+	return t.tx.QueryRow(ctx, query, args...)
+}
+
+func (t pgxTx) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	tag, err := t.tx.Exec(ctx, query, args...)
+	return tag.RowsAffected(), err
+}
+
+func (t pgxTx) queries() *userdb.Queries { return userdb.New(t.tx) }
+
+func (t pgxTx) execBatch(ctx context.Context, statements []statement) error {
+	batch := &pgx.Batch{}
+	for _, s := range statements {
+		batch.Queue(s.sql, s.args...)
+	}
+	// Close reads every result and returns the first error
+	return t.tx.SendBatch(ctx, batch).Close()
+}
+
+func (t pgxTx) copyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return t.tx.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+}
+
+func (t pgxTx) record(ctx context.Context, resource string) error {
+	return audit.RecordPgx(ctx, t.tx, resource)
+}
+
+func (t pgxTx) commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
+func (t pgxTx) rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
+
+// sqlStore runs queries on a database/sql pool using lib/pq
+type sqlStore struct {
+	db *sql.DB
+}
+
+func (s sqlStore) query(ctx context.Context, query string, args ...any) (rows, error) {
+	r, err := s.db.QueryContext(ctx, query, sqlArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{r}, nil
+}
+
+func (s sqlStore) queryRow(ctx context.Context, query string, args ...any) row {
+	return s.db.QueryRowContext(ctx, query, sqlArgs(args)...)
+}
+
+func (s sqlStore) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	result, err := s.db.ExecContext(ctx, query, sqlArgs(args)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s sqlStore) queries() *userdb.Queries { return userdb.New(sqlDBTX{s.db}) }
+
+func (s sqlStore) begin(ctx context.Context) (tx, error) {
+	t, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sqlTx{t}, nil
+}
+
+// sqlTx is a transaction of a sqlStore, or one carried by the context of a
+// caller that shares the *sql.DB (see inTx and conn)
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+func (t sqlTx) query(ctx context.Context, query string, args ...any) (rows, error) {
+	r, err := t.tx.QueryContext(ctx, query, sqlArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{r}, nil
+}
+
+func (t sqlTx) queryRow(ctx context.Context, query string, args ...any) row {
+	return t.tx.QueryRowContext(ctx, query, sqlArgs(args)...)
+}
+
+func (t sqlTx) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	result, err := t.tx.ExecContext(ctx, query, sqlArgs(args)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (t sqlTx) queries() *userdb.Queries { return userdb.New(sqlDBTX{t.tx}) }
+
+// execBatch runs statements one at a time: database/sql has no batches
+func (t sqlTx) execBatch(ctx context.Context, statements []statement) error {
+	for _, s := range statements {
+		if _, err := t.exec(ctx, s.sql, s.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFrom inserts rows one at a time with a prepared statement, as
+// database/sql has no COPY
+func (t sqlTx) copyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	stmt, err := t.tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		pq.QuoteIdentifier(table), strings.Join(columns, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx, sqlArgs(r)...); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(rows)), nil
+}
+
+func (t sqlTx) record(ctx context.Context, resource string) error {
+	return audit.Record(ctx, t.tx, resource)
+}
+
+func (t sqlTx) commit(context.Context) error   { return t.tx.Commit() }
+func (t sqlTx) rollback(context.Context) error { return t.tx.Rollback() }
+
+// sqlConn is the part of *sql.DB and *sql.Tx that sqlDBTX uses
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqlDBTX runs the generated queries, written for pgx, on database/sql
+type sqlDBTX struct {
+	conn sqlConn
+}
+
+func (d sqlDBTX) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	result, err := d.conn.ExecContext(ctx, query, sqlArgs(args)...)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	// CommandTag.RowsAffected reads the count from the end of the tag
+	return pgconn.NewCommandTag(fmt.Sprintf("EXEC %d", n)), nil
+}
+
+func (d sqlDBTX) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	r, err := d.conn.QueryContext(ctx, query, sqlArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{r}, nil
+}
+
+func (d sqlDBTX) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return d.conn.QueryRowContext(ctx, query, sqlArgs(args)...)
+}
+
+// sqlRows adapts *sql.Rows to rows and pgx.Rows. Like pgx.Rows, Close drops
+// its error; errors reading the rows are returned by Err.
+type sqlRows struct {
+	*sql.Rows
+}
+
+func (r sqlRows) Close() { _ = r.Rows.Close() }
+
+// The rest of pgx.Rows is not used by the generated queries
+
+func (r sqlRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r sqlRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r sqlRows) RawValues() [][]byte                          { return nil }
+func (r sqlRows) Conn() *pgx.Conn                              { return nil }
+
+func (r sqlRows) Values() ([]any, error) {
+	return nil, errors.New("reading row values is not supported on database/sql")
+}
+
+// sqlArgs converts the arguments of pgx queries to their lib/pq form:
+// string slices, which pgx encodes as arrays natively, are wrapped with
+// pq.Array
+func sqlArgs(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		if v, ok := arg.([]string); ok {
+			arg = pq.Array(v)
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// pgUniqueViolation is the Postgres SQLSTATE for unique constraint violations
+const pgUniqueViolation = "23505"
+
+// uniqueViolation reports whether err is a unique constraint violation from
+// either store
+func uniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolation
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation
+}
@@ -0,0 +1,178 @@
+package users
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/lib/pq"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userspb "github.com/zcking/go-api-template/gen/go/users/v1"
+	"github.com/zcking/go-api-template/internal/encryption"
+	"github.com/zcking/go-api-template/internal/txn"
+)
+
+// newPgxService returns a service whose queries go through a mock pgx pool
+func newPgxService(t *testing.T, keyring *encryption.Keyring) (*Service, pgxmock.PgxPoolIface) {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(mock.Close)
+	return &Service{store: pgxStore{mock}, keyring: keyring, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, mock
+}
+
+// pgxRow converts a row built for sqlmock to pgxmock's values
+func pgxRow(values []driver.Value) []any {
+	row := make([]any, len(values))
+	for i, v := range values {
+		row[i] = v
+	}
+	return row
+}
+
+// pgxSealedUserArgs matches the id and values of SealedColumns for a new
+// user, as the pgtype values passed by the generated CreateUser
+func pgxSealedUserArgs(id int64, email string) []any {
+	return []any{id, pgtype.Text{}, pgtype.Text{}, pgxmock.AnyArg(), pgxmock.AnyArg(), testKeyring.BlindIndex(email), pgxmock.AnyArg(), pgtype.Text{String: "1", Valid: true}, true}
+}
+
+func TestSQLArgs(t *testing.T) {
+	methods := []string{"/users.v1.UserService/CreateUser"}
+	args := []any{"users/42", methods, 3}
+
+	converted := sqlArgs(args)
+
+	assert.Equal(t, []any{"users/42", pq.Array(methods), 3}, converted, "string slices are arrays for lib/pq")
+	assert.Equal(t, []any{"users/42", methods, 3}, args, "the pgx arguments are left as they are")
+}
+
+func TestSQLTx_CopyFrom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare(`INSERT INTO "users" \(email, name\) VALUES \(\$1, \$2\)`)
+	prepared.ExpectExec().WithArgs("ada@example.com", "Ada").WillReturnResult(sqlmock.NewResult(1, 1))
+	prepared.ExpectExec().WithArgs("bob@example.com", "Bob").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	tx, err := sqlStore{db}.begin(t.Context())
+	require.NoError(t, err)
+	n, err := tx.copyFrom(t.Context(), "users", []string{"email", "name"}, [][]any{{"ada@example.com", "Ada"}, {"bob@example.com", "Bob"}})
+	require.NoError(t, err)
+	require.NoError(t, tx.commit(t.Context()))
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLDBTX(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	queries := sqlStore{db}.queries()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE id = $1")).
+		WithArgs(int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	n, err := queries.DeleteUser(t.Context(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "rows affected are carried by the command tag")
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE id = $1")).
+		WillReturnResult(sqlmock.NewErrorResult(errors.New("rows affected unavailable")))
+	_, err = queries.DeleteUser(t.Context(), 42)
+	assert.ErrorContains(t, err, "rows affected unavailable")
+
+	mock.ExpectQuery("SELECT id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	rows, err := sqlDBTX{db}.Query(t.Context(), "SELECT id FROM users")
+	require.NoError(t, err)
+	defer rows.Close()
+	_, err = rows.Values()
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGeneratedQueries checks userdb was generated from the current
+// queries.sql; run make generate after changing it
+func TestGeneratedQueries(t *testing.T) {
+	source, err := os.ReadFile("userdb/queries.sql")
+	require.NoError(t, err)
+	generated, err := os.ReadFile("userdb/queries.sql.go")
+	require.NoError(t, err)
+
+	blocks := strings.Split(string(source), "-- name: ")[1:]
+	require.NotEmpty(t, blocks)
+	for _, block := range blocks {
+		name, body, _ := strings.Cut(block, "\n")
+		var lines []string
+		for _, line := range strings.Split(body, "\n") {
+			if line != "" && !strings.HasPrefix(line, "--") {
+				lines = append(lines, line)
+			}
+		}
+		query := strings.TrimSuffix(strings.Join(lines, "\n"), ";")
+		assert.Contains(t, string(generated), "-- name: "+name+"\n"+query+"\n`", name)
+	}
+}
+
+func TestInTx_JoinsAmbientTransaction(t *testing.T) {
+	t.Run("pgx service writes through the caller's transaction", func(t *testing.T) {
+		service, pool := newPgxService(t, testKeyring)
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT sp_1")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+		mock.ExpectExec(insertUser).
+			WithArgs(sealedUserArgs(7, "ada@example.com")...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT sp_1")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		err = txn.NewManager(db, logger).Do(t.Context(), func(ctx context.Context) error {
+			_, err := service.CreateUser(ctx, &userspb.CreateUserRequest{Name: "Ada", Email: "ada@example.com"})
+			return err
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.NoError(t, pool.ExpectationsWereMet(), "nothing may run on the pgx pool")
+	})
+
+	t.Run("a failed change rolls back only its savepoint", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT sp_1")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(nextUserID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+		mock.ExpectExec(insertUser).WillReturnError(errors.New("duplicate key"))
+		mock.ExpectExec(regexp.QuoteMeta("ROLLBACK TO SAVEPOINT sp_1")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		service := NewServiceFromDB(db, testKeyring, logger)
+		err = txn.NewManager(db, logger).Do(t.Context(), func(ctx context.Context) error {
+			_, err := service.CreateUser(ctx, &userspb.CreateUserRequest{Name: "Ada", Email: "ada@example.com"})
+			assert.ErrorContains(t, err, "duplicate key")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,19 @@
+-- name: CreateUser :exec
+-- CreateUser inserts a user with an id allocated by NextUserID
+INSERT INTO users (id, email, name, email_ciphertext, name_ciphertext, email_index, data_key, key_version, pii_bound) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: DeleteUser :execrows
+-- DeleteUser deletes a user and returns the number of rows deleted
+DELETE FROM users WHERE id = $1;
+
+-- name: GetUser :one
+-- GetUser returns the PII columns of a user
+SELECT id, email, name, email_ciphertext, name_ciphertext, data_key, key_version, pii_bound FROM users WHERE id = $1;
+
+-- name: ListUsers :many
+-- ListUsers returns the PII columns of every user
+SELECT id, email, name, email_ciphertext, name_ciphertext, data_key, key_version, pii_bound FROM users;
+
+-- name: NextUserID :one
+-- NextUserID allocates the id of a new user
+SELECT nextval('seq_users_id')::bigint AS id;
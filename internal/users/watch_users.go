@@ -0,0 +1,41 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// usersChangedChannel is the channel the users table notifies changes on
+const usersChangedChannel = "users_changed"
+
+// UserChange is a change to a user
+type UserChange struct {
+	// Op is INSERT, UPDATE or DELETE
+	Op string `json:"op"`
+	// ID is the id of the user
+	ID int64 `json:"id"`
+}
+
+// listener receives notifications (see database.Listener)
+type listener interface {
+	Listen(ctx context.Context, channel string, fn func(payload string)) error
+}
+
+// WatchUsers calls fn with each change to users until ctx is done. Changes
+// are notified by a trigger on the users table, whatever makes them, and
+// received on a connection of their own. Changes made while that
+// connection is down are missed.
+func (s *Service) WatchUsers(ctx context.Context, fn func(UserChange)) error {
+	if s.listener == nil {
+		return errors.New("watching users requires a pgx connection (see NewService)")
+	}
+	return s.listener.Listen(ctx, usersChangedChannel, func(payload string) {
+		var change UserChange
+		if err := json.Unmarshal([]byte(payload), &change); err != nil {
+			s.logger.Warn("ignoring malformed users notification", "payload", payload, "error", err)
+			return
+		}
+		fn(change)
+	})
+}
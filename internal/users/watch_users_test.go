@@ -0,0 +1,48 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeListener delivers payloads on the channel it is asked to listen on
+type fakeListener struct {
+	channel  string
+	payloads []string
+}
+
+func (l *fakeListener) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	l.channel = channel
+	for _, p := range l.payloads {
+		fn(p)
+	}
+	return nil
+}
+
+func TestService_WatchUsers(t *testing.T) {
+	var logs bytes.Buffer
+	listener := &fakeListener{payloads: []string{`{"op":"INSERT","id":7}`, `not json`, `{"op":"DELETE","id":3}`}}
+	service := &Service{listener: listener, logger: slog.New(slog.NewTextHandler(&logs, nil))}
+
+	var changes []UserChange
+	require.NoError(t, service.WatchUsers(t.Context(), func(c UserChange) {
+		changes = append(changes, c)
+	}))
+
+	assert.Equal(t, "users_changed", listener.channel)
+	assert.Equal(t, []UserChange{{Op: "INSERT", ID: 7}, {Op: "DELETE", ID: 3}}, changes)
+	assert.Contains(t, logs.String(), "ignoring malformed users notification")
+}
+
+func TestService_WatchUsers_DatabaseSQL(t *testing.T) {
+	service := NewServiceFromDB(nil, testKeyring, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	err := service.WatchUsers(t.Context(), func(UserChange) {})
+	assert.ErrorContains(t, err, "watching users requires a pgx connection")
+}
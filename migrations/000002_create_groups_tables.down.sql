@@ -0,0 +1,11 @@
+-- Drop group_members table
+DROP TABLE IF EXISTS group_members;
+
+-- Drop group_members sequence
+DROP SEQUENCE IF EXISTS seq_group_members_id;
+
+-- Drop groups table
+DROP TABLE IF EXISTS groups;
+
+-- Drop groups sequence
+DROP SEQUENCE IF EXISTS seq_groups_id;
@@ -0,0 +1,37 @@
+-- Create sequence for groups table
+CREATE SEQUENCE IF NOT EXISTS seq_groups_id START 1;
+
+-- Create groups table
+CREATE TABLE IF NOT EXISTS groups (
+    id INTEGER PRIMARY KEY DEFAULT nextval('seq_groups_id'),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create sequence for group_members table
+CREATE SEQUENCE IF NOT EXISTS seq_group_members_id START 1;
+
+-- Create group_members join table. Each row is a direct membership of either
+-- a user or a nested group (member_group_id) in group_id.
+CREATE TABLE IF NOT EXISTS group_members (
+    id INTEGER PRIMARY KEY DEFAULT nextval('seq_group_members_id'),
+    group_id INTEGER NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    member_group_id INTEGER REFERENCES groups (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT group_members_one_member CHECK ((user_id IS NULL) <> (member_group_id IS NULL)),
+    CONSTRAINT group_members_no_self CHECK (member_group_id <> group_id)
+);
+
+-- A user or nested group can only be a direct member of a group once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_members_group_user
+    ON group_members (group_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_members_group_member_group
+    ON group_members (group_id, member_group_id) WHERE member_group_id IS NOT NULL;
+
+-- Reverse lookups used when walking memberships upwards
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id
+    ON group_members (user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_group_members_member_group_id
+    ON group_members (member_group_id) WHERE member_group_id IS NOT NULL;
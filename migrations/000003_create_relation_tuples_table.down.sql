@@ -0,0 +1,5 @@
+-- Drop relation_tuples table
+DROP TABLE IF EXISTS relation_tuples;
+
+-- Drop relation_tuples sequence
+DROP SEQUENCE IF EXISTS seq_relation_tuples_id;
@@ -0,0 +1,22 @@
+-- Create sequence for relation_tuples table
+CREATE SEQUENCE IF NOT EXISTS seq_relation_tuples_id START 1;
+
+-- Create relation_tuples table. Each row is one relationship
+-- object_type:object_id#relation@subject_type:subject_id[#subject_relation].
+-- An empty subject_relation means the subject is the object itself rather
+-- than a userset.
+CREATE TABLE IF NOT EXISTS relation_tuples (
+    id INTEGER PRIMARY KEY DEFAULT nextval('seq_relation_tuples_id'),
+    object_type TEXT NOT NULL,
+    object_id TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    subject_type TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    subject_relation TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT relation_tuples_unique UNIQUE (object_type, object_id, relation, subject_type, subject_id, subject_relation)
+);
+
+-- Reverse lookups by subject (e.g. when deleting a subject's relationships)
+CREATE INDEX IF NOT EXISTS idx_relation_tuples_subject
+    ON relation_tuples (subject_type, subject_id, subject_relation);
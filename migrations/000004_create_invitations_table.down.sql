@@ -0,0 +1,5 @@
+-- Drop invitations table
+DROP TABLE IF EXISTS invitations;
+
+-- Drop invitations sequence
+DROP SEQUENCE IF EXISTS seq_invitations_id;
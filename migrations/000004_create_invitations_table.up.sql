@@ -0,0 +1,22 @@
+-- Create sequence for invitations table
+CREATE SEQUENCE IF NOT EXISTS seq_invitations_id START 1;
+
+-- Create invitations table. Only a SHA-256 hash of the invitation token is
+-- stored; the token itself is returned once, when it is issued.
+CREATE TABLE IF NOT EXISTS invitations (
+    id INTEGER PRIMARY KEY DEFAULT nextval('seq_invitations_id'),
+    email TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    group_ids INTEGER[] NOT NULL DEFAULT '{}',
+    token_hash BYTEA NOT NULL UNIQUE,
+    send_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_user_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+-- Lookups of pending invitations by email
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
@@ -0,0 +1,16 @@
+-- Drop group provisioning metadata
+DROP INDEX IF EXISTS idx_groups_external_id;
+ALTER TABLE groups
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS external_id;
+
+-- Drop user provisioning metadata
+DROP INDEX IF EXISTS idx_users_external_id;
+DROP INDEX IF EXISTS idx_users_lower_email;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS active,
+    DROP COLUMN IF EXISTS external_id;
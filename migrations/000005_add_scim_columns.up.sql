@@ -0,0 +1,20 @@
+-- Add provisioning metadata to users. version is incremented on every change
+-- and is exposed as the resource ETag.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS external_id TEXT,
+    ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- userName (email) lookups are case-insensitive
+CREATE INDEX IF NOT EXISTS idx_users_lower_email ON users (lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users (external_id) WHERE external_id IS NOT NULL;
+
+-- Add provisioning metadata to groups
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS external_id TEXT,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_external_id ON groups (external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Drop OIDC tables
+DROP TABLE IF EXISTS oidc_signing_keys;
+DROP INDEX IF EXISTS idx_oidc_authorization_codes_expires_at;
+DROP TABLE IF EXISTS oidc_authorization_codes;
+DROP TABLE IF EXISTS oidc_clients;
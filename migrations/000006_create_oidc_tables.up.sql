@@ -0,0 +1,34 @@
+-- Create oidc_clients table. Confidential clients have a SHA-256 hash of
+-- their secret; public clients (secret_hash IS NULL) authenticate with PKCE only.
+CREATE TABLE IF NOT EXISTS oidc_clients (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    secret_hash BYTEA,
+    redirect_uris TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create oidc_authorization_codes table. Codes are single use and only their
+-- SHA-256 hash is stored.
+CREATE TABLE IF NOT EXISTS oidc_authorization_codes (
+    code_hash BYTEA PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES oidc_clients (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    nonce TEXT NOT NULL DEFAULT '',
+    code_challenge TEXT NOT NULL,
+    auth_time TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_authorization_codes_expires_at ON oidc_authorization_codes (expires_at);
+
+-- Create oidc_signing_keys table. The newest key signs tokens; older keys are
+-- published until every token they signed has expired.
+CREATE TABLE IF NOT EXISTS oidc_signing_keys (
+    kid TEXT PRIMARY KEY,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+-- Drop audit_events table, its triggers and indexes
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+
+-- Drop audit_events sequence
+DROP SEQUENCE IF EXISTS seq_audit_events_id;
@@ -0,0 +1,38 @@
+-- Create sequence for audit_events table
+CREATE SEQUENCE IF NOT EXISTS seq_audit_events_id START 1;
+
+-- Create audit_events table. Every mutating RPC appends one row. Each row's
+-- hash covers its fields and the previous row's hash, so rewriting or
+-- deleting a past row breaks the chain.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT PRIMARY KEY DEFAULT nextval('seq_audit_events_id'),
+    created_at TIMESTAMPTZ NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL,
+    resource TEXT NOT NULL DEFAULT '',
+    request_digest BYTEA NOT NULL,
+    outcome TEXT NOT NULL,
+    trace_id TEXT NOT NULL DEFAULT '',
+    source_ip TEXT NOT NULL DEFAULT '',
+    prev_hash BYTEA NOT NULL,
+    hash BYTEA NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_resource ON audit_events (resource, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+
+-- The table is append-only: reject updates, deletes and truncation
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
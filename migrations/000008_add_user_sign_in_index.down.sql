@@ -0,0 +1,2 @@
+-- Drop sign-in history index
+DROP INDEX IF EXISTS idx_oidc_authorization_codes_user_sign_ins;
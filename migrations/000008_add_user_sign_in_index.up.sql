@@ -0,0 +1,5 @@
+-- Exchanged authorization codes are kept as the users' sign-in history, read
+-- newest first by GetUserActivity
+CREATE INDEX IF NOT EXISTS idx_oidc_authorization_codes_user_sign_ins
+    ON oidc_authorization_codes (user_id, auth_time DESC)
+    WHERE used_at IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Create operations table. Each row is a google.longrunning.Operation,
+-- serialized in operation; done is kept in its own column for filtering.
+CREATE TABLE IF NOT EXISTS operations (
+    name TEXT PRIMARY KEY,
+    done BOOLEAN NOT NULL DEFAULT false,
+    operation BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_operations_created_at ON operations (created_at);
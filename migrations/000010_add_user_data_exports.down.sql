@@ -0,0 +1,12 @@
+-- Make audit_events strictly append-only again
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE audit_events DROP COLUMN IF EXISTS redacted_at;
+
+-- Drop user_data_exports table and sequence
+DROP TABLE IF EXISTS user_data_exports;
+DROP SEQUENCE IF EXISTS seq_user_data_exports_id;
@@ -0,0 +1,35 @@
+-- Create sequence for user_data_exports table
+CREATE SEQUENCE IF NOT EXISTS seq_user_data_exports_id START 1;
+
+-- Create user_data_exports table. bundle holds the export as newline-delimited
+-- JSON until it expires.
+CREATE TABLE IF NOT EXISTS user_data_exports (
+    id BIGINT PRIMARY KEY DEFAULT nextval('seq_user_data_exports_id'),
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    bundle BYTEA NOT NULL,
+    record_count INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_expires_at ON user_data_exports (expires_at);
+
+-- Erasing a user redacts the personal fields of their audit events
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS redacted_at TIMESTAMPTZ;
+
+-- Redaction is the only permitted change: it blanks request_digest (and
+-- source_ip) once and must leave every other column as it was
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.redacted_at IS NULL AND NEW.redacted_at IS NOT NULL
+        AND NEW.request_digest = ''::bytea
+        AND NEW.source_ip IN ('', OLD.source_ip)
+        AND (NEW.id, NEW.created_at, NEW.actor, NEW.method, NEW.resource, NEW.outcome, NEW.trace_id, NEW.prev_hash, NEW.hash)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.created_at, OLD.actor, OLD.method, OLD.resource, OLD.outcome, OLD.trace_id, OLD.prev_hash, OLD.hash) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
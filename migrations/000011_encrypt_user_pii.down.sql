@@ -0,0 +1,20 @@
+-- Encrypted values cannot be decrypted here; refuse to drop them
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE email IS NULL) THEN
+        RAISE EXCEPTION 'users contain encrypted rows; decrypt them before rolling back';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_users_key_version;
+DROP INDEX IF EXISTS idx_users_email_index;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_encrypted_or_plaintext;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS key_version,
+    DROP COLUMN IF EXISTS data_key,
+    DROP COLUMN IF EXISTS email_index,
+    DROP COLUMN IF EXISTS name_ciphertext,
+    DROP COLUMN IF EXISTS email_ciphertext,
+    ALTER COLUMN name SET NOT NULL,
+    ALTER COLUMN email SET NOT NULL;
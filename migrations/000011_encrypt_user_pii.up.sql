@@ -0,0 +1,21 @@
+-- Store users' email and name encrypted. Each row has its own data key,
+-- wrapped by the key-encryption key of key_version. email_index is a keyed
+-- hash of the lower-cased email for equality lookups.
+--
+-- The plaintext columns are kept, nullable, for rows written before this
+-- migration; the re-encryption job encrypts those rows and clears them.
+ALTER TABLE users
+    ALTER COLUMN email DROP NOT NULL,
+    ALTER COLUMN name DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS email_ciphertext BYTEA,
+    ADD COLUMN IF NOT EXISTS name_ciphertext BYTEA,
+    ADD COLUMN IF NOT EXISTS email_index BYTEA,
+    ADD COLUMN IF NOT EXISTS data_key BYTEA,
+    ADD COLUMN IF NOT EXISTS key_version TEXT;
+
+ALTER TABLE users ADD CONSTRAINT users_email_encrypted_or_plaintext
+    CHECK (email IS NOT NULL OR (email_ciphertext IS NOT NULL AND data_key IS NOT NULL AND key_version IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_users_email_index ON users (email_index);
+-- The re-encryption job looks for rows not on the primary key version
+CREATE INDEX IF NOT EXISTS idx_users_key_version ON users (key_version);
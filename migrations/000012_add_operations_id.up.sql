@@ -0,0 +1,4 @@
+-- Number operations so ListOperations can page through them by keyset
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS id BIGSERIAL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_operations_id ON operations (id);
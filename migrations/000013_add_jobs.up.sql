@@ -0,0 +1,24 @@
+-- Create jobs table, the background job queue. Workers claim pending jobs
+-- with SELECT ... FOR UPDATE SKIP LOCKED; jobs that succeed are deleted and
+-- jobs that run out of attempts are kept as failed.
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    unique_key TEXT,
+    state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'running', 'failed')),
+    run_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    last_error TEXT,
+    locked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Jobs that are ready to run, in the order workers claim them
+CREATE INDEX IF NOT EXISTS idx_jobs_ready ON jobs (run_at, id) WHERE state IN ('pending', 'running');
+
+-- At most one pending or running job per kind and unique key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (kind, unique_key)
+    WHERE unique_key IS NOT NULL AND state IN ('pending', 'running');
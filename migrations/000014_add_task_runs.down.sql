@@ -0,0 +1,2 @@
+-- Drop task_runs table
+DROP TABLE IF EXISTS task_runs;
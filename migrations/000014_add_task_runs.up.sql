@@ -0,0 +1,13 @@
+-- Create task_runs table, the run history of scheduled tasks. A task runs
+-- at most once per scheduled time, however many instances are running.
+CREATE TABLE IF NOT EXISTS task_runs (
+    id BIGSERIAL PRIMARY KEY,
+    task TEXT NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT,
+    instance TEXT NOT NULL,
+    UNIQUE (task, scheduled_at)
+);
@@ -0,0 +1,22 @@
+-- Notify the users_changed channel when a user is created, changed or
+-- deleted, whatever makes the change. The payload is {"op": ..., "id": ...}.
+-- Updates that only re-encrypt a user do not bump version and are not
+-- notified.
+CREATE OR REPLACE FUNCTION notify_users_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('users_changed', json_build_object(
+        'op', TG_OP,
+        'id', CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_changed_insert_delete
+    AFTER INSERT OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION notify_users_changed();
+
+CREATE TRIGGER users_changed_update
+    AFTER UPDATE ON users
+    FOR EACH ROW WHEN (OLD.version IS DISTINCT FROM NEW.version)
+    EXECUTE FUNCTION notify_users_changed();
@@ -0,0 +1,21 @@
+-- Redaction may again only blank request_digest and source_ip
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.redacted_at IS NULL AND NEW.redacted_at IS NOT NULL
+        AND NEW.request_digest = ''::bytea
+        AND NEW.source_ip IN ('', OLD.source_ip)
+        AND (NEW.id, NEW.created_at, NEW.actor, NEW.method, NEW.resource, NEW.outcome, NEW.trace_id, NEW.prev_hash, NEW.hash)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.created_at, OLD.actor, OLD.method, OLD.resource, OLD.outcome, OLD.trace_id, OLD.prev_hash, OLD.hash) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE audit_events
+    DROP COLUMN IF EXISTS source_ip_commitment,
+    DROP COLUMN IF EXISTS source_ip_salt,
+    DROP COLUMN IF EXISTS request_digest_commitment,
+    DROP COLUMN IF EXISTS request_digest_salt;
@@ -0,0 +1,32 @@
+-- The hash of an audit event covers salted commitments to request_digest and
+-- source_ip, sha256(salt || value), instead of the values themselves, so
+-- that it can still be checked once redaction has cleared them. Redaction
+-- clears a value together with its salt; the commitment, from which the value
+-- cannot be recovered, stays. Events recorded before this migration have no
+-- commitments and keep the hash of their values.
+ALTER TABLE audit_events
+    ADD COLUMN IF NOT EXISTS request_digest_salt BYTEA,
+    ADD COLUMN IF NOT EXISTS request_digest_commitment BYTEA,
+    ADD COLUMN IF NOT EXISTS source_ip_salt BYTEA,
+    ADD COLUMN IF NOT EXISTS source_ip_commitment BYTEA;
+
+-- Redaction is the only permitted change: it blanks request_digest and its
+-- salt once, may blank source_ip and its salt, and must leave every other
+-- column as it was
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.redacted_at IS NULL AND NEW.redacted_at IS NOT NULL
+        AND NEW.request_digest = ''::bytea AND NEW.request_digest_salt IS NULL
+        AND ((NEW.source_ip = '' AND NEW.source_ip_salt IS NULL)
+            OR (NEW.source_ip, NEW.source_ip_salt) IS NOT DISTINCT FROM (OLD.source_ip, OLD.source_ip_salt))
+        AND (NEW.id, NEW.created_at, NEW.actor, NEW.method, NEW.resource, NEW.outcome, NEW.trace_id, NEW.prev_hash, NEW.hash,
+                NEW.request_digest_commitment, NEW.source_ip_commitment)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.created_at, OLD.actor, OLD.method, OLD.resource, OLD.outcome, OLD.trace_id, OLD.prev_hash, OLD.hash,
+                OLD.request_digest_commitment, OLD.source_ip_commitment) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_email_index;
+CREATE INDEX IF NOT EXISTS idx_users_email_index ON users (email_index);
@@ -0,0 +1,7 @@
+-- Emails are unique: the blind index of the lower-cased email is unique
+-- among encrypted rows, so concurrent inserts of the same email cannot both
+-- commit. Rows still in plaintext have no index and are not covered until
+-- the re-encryption job encrypts them. Fails if encrypted users already share
+-- an email; merge or delete the duplicates first.
+DROP INDEX IF EXISTS idx_users_email_index;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_index ON users (email_index) WHERE email_index IS NOT NULL;
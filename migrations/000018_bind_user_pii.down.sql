@@ -0,0 +1,11 @@
+-- Bound rows cannot be decrypted without pii_bound; refuse to drop it
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE pii_bound) THEN
+        RAISE EXCEPTION 'users contain PII bound to their id, which cannot be decrypted without pii_bound';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_users_pii_unbound;
+ALTER TABLE users DROP COLUMN IF EXISTS pii_bound;
@@ -0,0 +1,8 @@
+-- Bind encrypted PII to its row: rows written from now on are sealed with
+-- the user id as additional data, so their ciphertexts and data key do not
+-- decrypt when copied onto another row. Existing rows are not bound until
+-- the re-encryption job re-seals them.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pii_bound BOOLEAN NOT NULL DEFAULT false;
+
+-- The re-encryption job looks for rows not bound yet
+CREATE INDEX IF NOT EXISTS idx_users_pii_unbound ON users (id) WHERE NOT pii_bound;
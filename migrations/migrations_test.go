@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFS checks the embedded migrations are exactly the files on disk
+func TestFS(t *testing.T) {
+	onDisk, err := filepath.Glob("*.sql")
+	require.NoError(t, err)
+	embedded, err := fs.Glob(FS, "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, embedded)
+	assert.Equal(t, onDisk, embedded)
+
+	for _, name := range onDisk {
+		want, err := os.ReadFile(name)
+		require.NoError(t, err)
+		got, err := FS.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), name)
+	}
+}